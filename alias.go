@@ -0,0 +1,30 @@
+package sessionmixer
+
+// aliases maps friendly names to exact ALSA control names, from the config's aliases section
+var aliases map[string]string
+
+// SetAliases applies the config's control name aliases (nil = none)
+func SetAliases(names map[string]string) {
+	aliases = names
+}
+
+// ResolveControlName returns the ALSA control name for an alias, or the name itself if it is
+// not an alias
+func ResolveControlName(name string) string {
+	if resolved, ok := aliases[name]; ok {
+		return resolved
+	}
+	return name
+}
+
+// aliasOf returns the alias of an ALSA control name, or "" if it has none; the first in sort
+// order if it has several
+func aliasOf(name string) string {
+	var alias string
+	for friendly, resolved := range aliases {
+		if resolved == name && (alias == "" || friendly < alias) {
+			alias = friendly
+		}
+	}
+	return alias
+}
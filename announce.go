@@ -0,0 +1,175 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+// defaultAnnounceDelay is how long a value must settle before it is announced
+const defaultAnnounceDelay = 300 * time.Millisecond
+
+// Announcer speaks (or prints) short announcements for screen reader users: ImGui draws its
+// widgets itself and exposes nothing to AT-SPI, so the focused gang and its value changes are
+// announced instead. Announcements are debounced: while a fader is dragged only the value it
+// settles on is spoken, and a newer announcement replaces one not yet spoken
+type Announcer struct {
+	command []string
+	delay   time.Duration
+	out     io.Writer
+
+	mu      sync.Mutex
+	pending string
+
+	wake     chan struct{}
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewAnnouncer creates an announcer; without a command, announcements are printed to stdout
+// (one per line, for a terminal screen reader or a braille display)
+func NewAnnouncer(config AnnounceConfig) *Announcer {
+	delay := config.Delay
+	if delay <= 0 {
+		delay = defaultAnnounceDelay
+	}
+	return &Announcer{
+		command: config.Command,
+		delay:   delay,
+		out:     os.Stdout,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start begins speaking announcements
+func (a *Announcer) Start() {
+	go a.run()
+}
+
+// Stop stops the announcer, dropping any pending announcement
+func (a *Announcer) Stop() {
+	a.stopOnce.Do(func() { close(a.stop) })
+	<-a.done
+}
+
+// Announce queues text, replacing any pending announcement (thread-safe)
+func (a *Announcer) Announce(text string) {
+	a.mu.Lock()
+	a.pending = text
+	a.mu.Unlock()
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (a *Announcer) run() {
+	defer close(a.done)
+	timer := time.NewTimer(a.delay)
+	timer.Stop()
+	for {
+		select {
+		case <-a.stop:
+			timer.Stop()
+			return
+		case <-a.wake:
+			timer.Reset(a.delay)
+		case <-timer.C:
+			a.mu.Lock()
+			text := a.pending
+			a.pending = ""
+			a.mu.Unlock()
+			if text != "" {
+				a.speak(text)
+			}
+		}
+	}
+}
+
+// speak runs the speech command with the text as its last argument, or prints the text
+func (a *Announcer) speak(text string) {
+	if len(a.command) == 0 {
+		fmt.Fprintln(a.out, text)
+		return
+	}
+	args := append(append([]string{}, a.command[1:]...), text)
+	if err := exec.Command(a.command[0], args...).Run(); err != nil {
+		log.Printf("Announce: %v", err)
+	}
+}
+
+// announceState is the mixer's view of what was last announced
+type announceState struct {
+	gang  *GangedFader // Gang last announced (nil = none)
+	value string       // Its announced value
+	err   error        // Last UI error announced
+}
+
+// SetAnnouncer enables announcements of the gang under the pointer or keyboard focus
+func (sm *SessionMixer) SetAnnouncer(announcer *Announcer) {
+	sm.announcer = announcer
+}
+
+// announceFocus announces the gang under the pointer (or else the keyboard-focused gang) when
+// it changes, then each change of its value, whatever made it; new UI errors are announced too
+func (sm *SessionMixer) announceFocus() {
+	if err := recentUIError(); err != nil && err != sm.announced.err {
+		sm.announced.err = err
+		sm.announcer.Announce(err.Error())
+		return
+	}
+
+	gang := sm.hovered
+	if gang == nil {
+		gang = sm.focusedGang()
+	}
+	if gang == nil {
+		sm.announced.gang = nil
+		return
+	}
+	value := describeValue(gang)
+	switch {
+	case gang != sm.announced.gang:
+		sm.announcer.Announce(gang.GetName() + ", " + value)
+	case value != sm.announced.value:
+		sm.announcer.Announce(value)
+	}
+	sm.announced.gang = gang
+	sm.announced.value = value
+}
+
+// trackHovered records the gang whose fader was just drawn if the pointer is over it
+func (sm *SessionMixer) trackHovered(gang *GangedFader) {
+	if sm.announcer != nil && imgui.IsItemHoveredV(imgui.HoveredFlagsAllowWhenDisabled) {
+		sm.hovered = gang
+	}
+}
+
+// describeValue is the spoken value of a gang, with its mute, lock and cap states
+func describeValue(gang *GangedFader) string {
+	var text string
+	if gang.IsToggle() {
+		text = toggleState(gang)
+	} else {
+		text = gang.FormatValue(gang.GetCurrentValue())
+	}
+	if gang.IsMuted() {
+		text += ", " + tr("muted")
+	}
+	if gang.IsLocked() && !gang.IsReadOnly() {
+		text += ", " + tr("locked")
+	}
+	if gang.IsCapped() {
+		text += ", " + tr("capped")
+	}
+	return text
+}
@@ -0,0 +1,58 @@
+package sessionmixer
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe to write from the announcer goroutine
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.Write(p)
+}
+
+func (sb *syncBuffer) String() string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.String()
+}
+
+func TestAnnouncerSpeaksSettledValue(t *testing.T) {
+	out := &syncBuffer{}
+	a := NewAnnouncer(AnnounceConfig{Delay: 20 * time.Millisecond})
+	a.out = out
+	a.Start()
+	defer a.Stop()
+
+	// A drag: only the value it settles on is announced
+	for _, text := range []string{"-10.0 dB", "-8.0 dB", "-6.0 dB"} {
+		a.Announce(text)
+	}
+	deadline := time.Now().Add(time.Second)
+	for out.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := out.String(); got != "-6.0 dB\n" {
+		t.Errorf("announced %q, want the settled value only", got)
+	}
+}
+
+func TestDescribeValue(t *testing.T) {
+	gang, _ := newFakeGang(t, "Vocal", 0, 160, 80, 1)
+	if err := gang.Mute(); err != nil {
+		t.Fatal(err)
+	}
+	gang.SetLocked(true)
+	want := gang.FormatValue(0) + ", muted, locked"
+	if got := describeValue(gang); got != want {
+		t.Errorf("describeValue() = %q, want %q", got, want)
+	}
+}
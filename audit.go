@@ -0,0 +1,286 @@
+package sessionmixer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// auditSettle is how long a gang must rest before its change is logged, so a fader drag or a
+	// fade is one entry
+	auditSettle = time.Second
+
+	// auditInterval is how often settled changes are written
+	auditInterval = 250 * time.Millisecond
+
+	// auditMaxSize is the size at which the log is rotated; one previous log is kept
+	auditMaxSize = 1 << 20
+)
+
+// Audit sources: who or what changed a gang
+const (
+	AuditSourceMixer    = "mixer"    // This mixer's own automation (scenes, duckers, panic, IPC); claimed writes carry their write source
+	AuditSourceHardware = "hardware" // Another application or the device itself
+)
+
+// AuditEntry is one line of the audit log (line-delimited JSON): a settled gang change, or a
+// scene recall
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	Gang     string    `json:"gang,omitempty"`
+	From     int64     `json:"from"`
+	To       int64     `json:"to"`
+	FromText string    `json:"from_text,omitempty"`
+	ToText   string    `json:"to_text,omitempty"`
+	Scene    string    `json:"scene,omitempty"` // Scene recalls
+	Source   string    `json:"source"`
+}
+
+// pendingChange is a gang change waiting to settle
+type pendingChange struct {
+	entry AuditEntry
+	gang  *GangedFader
+	last  time.Time
+}
+
+// auditChange is a change reported to the audit goroutine
+type auditChange struct {
+	gang     *GangedFader
+	from, to int64
+	scene    string
+	source   string
+	at       time.Time
+}
+
+// AuditLog appends the settled gang changes of the running mixer to a file read by
+// `sessionmixer history`
+type AuditLog struct {
+	path    string
+	file    *os.File
+	size    int64
+	changes chan auditChange
+	pending map[*GangedFader]*pendingChange
+	dropped atomic.Int64
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+	err      error // Error of the final write
+}
+
+// audit is the running audit log (nil when not logging)
+var audit atomic.Pointer[AuditLog]
+
+// StartAudit opens the audit log at path for appending and starts logging gang changes
+func StartAudit(path string) (*AuditLog, error) {
+	al := &AuditLog{
+		path:    path,
+		changes: make(chan auditChange, 256),
+		pending: make(map[*GangedFader]*pendingChange),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := al.open(); err != nil {
+		return nil, err
+	}
+	audit.Store(al)
+	go al.run()
+	return al, nil
+}
+
+// Stop writes the pending changes and closes the log; blocks until the goroutine has exited
+func (al *AuditLog) Stop() error {
+	al.stopOnce.Do(func() {
+		audit.CompareAndSwap(al, nil)
+		close(al.stop)
+		<-al.done
+	})
+	return al.err
+}
+
+func (al *AuditLog) run() {
+	defer close(al.done)
+	ticker := time.NewTicker(auditInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-al.stop:
+			al.flush(time.Time{})
+			if err := al.file.Close(); err != nil && al.err == nil {
+				al.err = err
+			}
+			if dropped := al.dropped.Load(); dropped > 0 {
+				log.Printf("Audit log: %d change(s) dropped while the log was busy", dropped)
+			}
+			return
+		case change := <-al.changes:
+			al.add(change)
+		case now := <-ticker.C:
+			al.flush(now)
+		}
+	}
+}
+
+// add merges a change into the gang's pending entry; the first source of a burst is kept
+func (al *AuditLog) add(change auditChange) {
+	if change.scene != "" {
+		al.write(AuditEntry{Time: change.at, Scene: change.scene, Source: change.source})
+		return
+	}
+	if p, ok := al.pending[change.gang]; ok {
+		p.entry.To = change.to
+		p.last = change.at
+		return
+	}
+	al.pending[change.gang] = &pendingChange{
+		entry: AuditEntry{Time: change.at, Gang: change.gang.GetName(), From: change.from, To: change.to, Source: change.source},
+		gang:  change.gang,
+		last:  change.at,
+	}
+}
+
+// flush writes the changes that have rested for auditSettle before now (all of them for a zero
+// now), oldest first; bursts that return to where they started are dropped
+func (al *AuditLog) flush(now time.Time) {
+	var settled []*pendingChange
+	for gang, p := range al.pending {
+		if now.IsZero() || now.Sub(p.last) >= auditSettle {
+			settled = append(settled, p)
+			delete(al.pending, gang)
+		}
+	}
+	slices.SortFunc(settled, func(a, b *pendingChange) int { return a.entry.Time.Compare(b.entry.Time) })
+	for _, p := range settled {
+		if p.entry.From == p.entry.To {
+			continue
+		}
+		format := unitFormatter(p.gang.GetUnit())
+		p.entry.FromText = format(p.gang, p.entry.From)
+		p.entry.ToText = format(p.gang, p.entry.To)
+		al.write(p.entry)
+	}
+}
+
+// write appends an entry, rotating the log when it has grown past auditMaxSize
+func (al *AuditLog) write(entry AuditEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if al.size+int64(len(data)) >= auditMaxSize {
+		if err := al.rotate(); err != nil {
+			log.Printf("Audit log: %v", err)
+			return
+		}
+	}
+	n, err := al.file.Write(append(data, '\n'))
+	al.size += int64(n)
+	if err != nil {
+		log.Printf("Audit log: %v", err)
+	}
+}
+
+// open opens the log for appending
+func (al *AuditLog) open() error {
+	file, err := os.OpenFile(al.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	al.file, al.size = file, info.Size()
+	return nil
+}
+
+// rotate moves the log to path.1, replacing the previous one, and starts a new log
+func (al *AuditLog) rotate() error {
+	al.file.Close()
+	if err := os.Rename(al.path, al.path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	return al.open()
+}
+
+// report hands a change to the audit goroutine without blocking the caller
+func (al *AuditLog) report(change auditChange) {
+	select {
+	case al.changes <- change:
+	default:
+		al.dropped.Add(1)
+	}
+}
+
+// auditGangChange logs a gang moving from one value to another, if the audit log is running
+func auditGangChange(gang *GangedFader, from, to int64, source string) {
+	if al := audit.Load(); al != nil && from != to {
+		al.report(auditChange{gang: gang, from: from, to: to, source: source, at: time.Now()})
+	}
+}
+
+// auditScene logs a scene recall, if the audit log is running
+func auditScene(name string) {
+	if al := audit.Load(); al != nil {
+		al.report(auditChange{scene: name, source: AuditSourceMixer, at: time.Now()})
+	}
+}
+
+// ReadAudit returns the entries of the audit log at path and its rotated predecessor, oldest
+// first; a missing log yields no entries and unreadable lines are skipped
+func ReadAudit(path string) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	for _, p := range []string{path + ".1", path} {
+		file, err := os.Open(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var entry AuditEntry
+			if json.Unmarshal(scanner.Bytes(), &entry) == nil {
+				entries = append(entries, entry)
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b AuditEntry) int { return a.Time.Compare(b.Time) })
+	return entries, nil
+}
+
+// AuditValuesAt reconstructs the gang values at t from the changes logged after it: each gang
+// changed since t held the value its first later change started from. Gangs unchanged since t
+// are not included
+func AuditValuesAt(entries []AuditEntry, t time.Time) map[string]int64 {
+	values := make(map[string]int64)
+	for _, entry := range entries {
+		if entry.Gang == "" || !entry.Time.After(t) {
+			continue
+		}
+		if _, ok := values[entry.Gang]; !ok {
+			values[entry.Gang] = entry.From
+		}
+	}
+	return values
+}
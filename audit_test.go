@@ -0,0 +1,27 @@
+package sessionmixer
+
+import (
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestAuditValuesAt(t *testing.T) {
+	start := time.Date(2026, 10, 15, 14, 0, 0, 0, time.Local)
+	entries := []AuditEntry{
+		{Time: start, Gang: "Vocal", From: 80, To: 90},
+		{Time: start.Add(time.Minute), Scene: "night"},
+		{Time: start.Add(2 * time.Minute), Gang: "Vocal", From: 90, To: 100},
+		{Time: start.Add(3 * time.Minute), Gang: "Music", From: 40, To: 20},
+		{Time: start.Add(4 * time.Minute), Gang: "Music", From: 20, To: 0},
+	}
+
+	// Each gang changed after the time reverts to where its first later change started
+	want := map[string]int64{"Vocal": 90, "Music": 40}
+	if got := AuditValuesAt(entries, start.Add(time.Minute)); !maps.Equal(got, want) {
+		t.Errorf("AuditValuesAt() = %v, want %v", got, want)
+	}
+	if got := AuditValuesAt(entries, start.Add(5*time.Minute)); len(got) != 0 {
+		t.Errorf("AuditValuesAt() after the last change = %v, want none", got)
+	}
+}
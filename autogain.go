@@ -0,0 +1,220 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+const (
+	// autogainPollInterval is how often running autogain switches are checked for completion
+	autogainPollInterval = 100 * time.Millisecond
+
+	// autogainTimeout bounds how long a run waits for the device to finish
+	autogainTimeout = 60 * time.Second
+)
+
+// AutogainResult records the outcome of autogain on one input
+type AutogainResult struct {
+	Input      int
+	GainBefore int64
+	GainAfter  int64
+	Done       bool // The device cleared the autogain switch
+	Err        error
+}
+
+// AutogainRunner runs the device's autogain on a selection of inputs at once
+// Each selected input's autogain switch is turned on; the device clears it when done
+type AutogainRunner struct {
+	inputs   []*InputChannel
+	selected map[int]bool
+
+	mu      sync.Mutex
+	running bool
+	started time.Time
+	results []AutogainResult
+}
+
+// NewAutogainRunner creates a runner for the inputs that provide autogain, or returns nil
+// if none do
+func NewAutogainRunner(panel *InputPanel) *AutogainRunner {
+	ar := &AutogainRunner{selected: make(map[int]bool)}
+	for _, in := range panel.GetInputs() {
+		if in.GetAutogain() != nil && in.GetGain() != nil {
+			ar.inputs = append(ar.inputs, in)
+		}
+	}
+	if len(ar.inputs) == 0 {
+		return nil
+	}
+	return ar
+}
+
+// Run starts autogain on the given inputs and waits for completion in the background
+func (ar *AutogainRunner) Run(inputs []*InputChannel) error {
+	if IsObserver() {
+		return ErrObserver
+	}
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	if ar.running {
+		return fmt.Errorf("autogain is already running")
+	}
+	if len(inputs) == 0 {
+		return fmt.Errorf("no inputs selected")
+	}
+
+	ar.results = nil
+	for _, in := range inputs {
+		result := AutogainResult{Input: in.GetNumber(), GainBefore: in.GetGain().GetCurrentValue()}
+		if err := in.GetAutogain().GetControl().SetValue(1); err != nil {
+			result.Err = err
+			result.Done = true
+		}
+		ar.results = append(ar.results, result)
+	}
+	ar.running = true
+	ar.started = time.Now()
+
+	go ar.wait(inputs)
+	return nil
+}
+
+// wait polls the autogain switches until every input finished or the timeout expires
+func (ar *AutogainRunner) wait(inputs []*InputChannel) {
+	ticker := time.NewTicker(autogainPollInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ar.mu.Lock()
+		pending := 0
+		for i, in := range inputs {
+			result := &ar.results[i]
+			if result.Done {
+				continue
+			}
+			value, err := in.GetAutogain().ReadValue()
+			if err != nil {
+				result.Err, result.Done = err, true
+				continue
+			}
+			if value != 0 {
+				pending++
+				continue
+			}
+			result.Done = true
+			if gain, err := in.GetGain().ReadValue(); err == nil {
+				in.GetGain().HandleHWChange(gain)
+				result.GainAfter = gain
+			} else {
+				result.Err = err
+			}
+		}
+
+		timedOut := time.Since(ar.started) > autogainTimeout
+		if pending == 0 || timedOut {
+			for i := range ar.results {
+				if !ar.results[i].Done {
+					ar.results[i].Err = fmt.Errorf("timed out")
+				}
+			}
+			ar.running = false
+			ar.mu.Unlock()
+			return
+		}
+		ar.mu.Unlock()
+	}
+}
+
+// IsRunning returns true while autogain is in progress
+func (ar *AutogainRunner) IsRunning() bool {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	return ar.running
+}
+
+// GetResults returns the results of the current or last run
+func (ar *AutogainRunner) GetResults() []AutogainResult {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+	return append([]AutogainResult(nil), ar.results...)
+}
+
+// Draw renders the input selection, run button, progress and summary
+func (ar *AutogainRunner) Draw() {
+	running := ar.IsRunning()
+	if running {
+		imgui.BeginDisabled()
+	}
+	for i, in := range ar.inputs {
+		if i > 0 {
+			imgui.SameLine()
+		}
+		selected := ar.selected[in.GetNumber()]
+		if imgui.Checkbox(trf("Input %d##autogain_%d", in.GetNumber(), i), &selected) {
+			ar.selected[in.GetNumber()] = selected
+		}
+	}
+	if imgui.Button(tr("Run Autogain")) {
+		var inputs []*InputChannel
+		for _, in := range ar.inputs {
+			if ar.selected[in.GetNumber()] {
+				inputs = append(inputs, in)
+			}
+		}
+		if err := ar.Run(inputs); err != nil {
+			log.Printf("Autogain: %v", err)
+		}
+	}
+	if running {
+		imgui.EndDisabled()
+	}
+
+	results := ar.GetResults()
+	if len(results) == 0 {
+		return
+	}
+
+	done := 0
+	for _, result := range results {
+		if result.Done {
+			done++
+		}
+	}
+	if running {
+		imgui.SameLine()
+		imgui.ProgressBarV(float32(done)/float32(len(results)), scaledVec2(imgui.Vec2{X: 200, Y: 0}),
+			fmt.Sprintf("%d/%d inputs", done, len(results)))
+	}
+
+	if imgui.BeginTableV("autogain_table", 3, imgui.TableFlagsNone, scaledVec2(imgui.Vec2{X: 300, Y: 0}), 0.0) {
+		imgui.TableSetupColumn(tr("Input"))
+		imgui.TableSetupColumn(tr("Gain"))
+		imgui.TableSetupColumn(tr("Status"))
+		imgui.TableHeadersRow()
+		for _, result := range results {
+			imgui.TableNextRow()
+			imgui.TableNextColumn()
+			imgui.Text(trf("%d", result.Input))
+			imgui.TableNextColumn()
+			switch {
+			case result.Done && result.Err == nil:
+				imgui.Text(trf("%d -> %d", result.GainBefore, result.GainAfter))
+			default:
+				imgui.Text(trf("%d", result.GainBefore))
+			}
+			imgui.TableNextColumn()
+			switch {
+			case result.Err != nil:
+				imgui.TextColored(imgui.Vec4{X: 1.0, Y: 0.2, Z: 0.2, W: 1.0}, result.Err.Error())
+			case result.Done:
+				imgui.Text(tr("done"))
+			default:
+				imgui.TextDisabled(tr("running"))
+			}
+		}
+		imgui.EndTable()
+	}
+}
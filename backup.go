@@ -0,0 +1,206 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/michaelquigley/df/dd"
+)
+
+const (
+	// defaultBackupInterval and defaultBackupKeep apply when the config leaves them unset
+	defaultBackupInterval = 10 * time.Minute
+	defaultBackupKeep     = 12
+
+	// backupPrefix and backupLayout name the backup files, which sort oldest first
+	backupPrefix = "backup-"
+	backupLayout = "20060102-150405"
+)
+
+// Backups snapshots the full mix (gang values and routing) at a fixed interval into a ring
+// buffer of scene files, so an earlier mix can be recovered; a snapshot is only written when the
+// mix changed since the previous one
+type Backups struct {
+	dir      string
+	interval time.Duration
+	keep     int
+	scenes   *SceneManager
+	last     *Scene
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewBackups creates backups of the scene manager's gangs and routing in dir
+func NewBackups(dir string, config *BackupConfig, scenes *SceneManager) *Backups {
+	b := &Backups{
+		dir:      dir,
+		interval: defaultBackupInterval,
+		keep:     defaultBackupKeep,
+		scenes:   scenes,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if config != nil && config.Interval > 0 {
+		b.interval = config.Interval
+	}
+	if config != nil && config.Keep > 0 {
+		b.keep = config.Keep
+	}
+	return b
+}
+
+// Start takes a snapshot every interval in a background goroutine
+func (b *Backups) Start() {
+	go func() {
+		defer close(b.done)
+		ticker := time.NewTicker(b.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.stop:
+				return
+			case now := <-ticker.C:
+				if err := b.snapshot(now); err != nil {
+					log.Printf("Backup: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops taking snapshots; blocks until the goroutine has exited
+func (b *Backups) Stop() {
+	b.stopOnce.Do(func() {
+		close(b.stop)
+		<-b.done
+	})
+}
+
+// snapshot writes the current mix as a backup if it changed, then drops the oldest backups
+// beyond the ring size
+func (b *Backups) snapshot(now time.Time) error {
+	scene := b.scenes.Capture(backupPrefix + now.Format(backupLayout))
+	if last := b.last; last != nil && maps.Equal(last.Gangs, scene.Gangs) && maps.Equal(last.Routing, scene.Routing) {
+		return nil
+	}
+	if err := os.MkdirAll(b.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create backups directory: %w", err)
+	}
+	if err := dd.UnbindToYAML(scene, filepath.Join(b.dir, scene.Name+".yaml")); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	b.last = scene
+
+	names, err := ListBackups(b.dir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names[min(len(names), b.keep):] {
+		if err := os.Remove(filepath.Join(b.dir, name+".yaml")); err != nil {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListBackups returns the names of the backups in dir, newest first
+func ListBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || filepath.Ext(name) != ".yaml" {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(name, ".yaml"))
+	}
+	slices.Sort(names)
+	slices.Reverse(names)
+	return names, nil
+}
+
+// LoadBackup reads the named backup from dir as a scene
+func LoadBackup(dir, name string) (*Scene, error) {
+	if !strings.HasPrefix(name, backupPrefix) || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("invalid backup name '%s'", name)
+	}
+	scene, err := dd.NewFromYAML[Scene](filepath.Join(dir, name+".yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load backup '%s': %w", name, err)
+	}
+	return scene, nil
+}
+
+// BackupTime returns when the named backup was taken
+func BackupTime(name string) (time.Time, bool) {
+	t, err := time.ParseInLocation(backupLayout, strings.TrimPrefix(name, backupPrefix), time.Local)
+	return t, err == nil
+}
+
+// GetDir returns the backups directory
+func (b *Backups) GetDir() string {
+	return b.dir
+}
+
+// SetBackups enables restoring periodic backups from the scenes panel (nil = off)
+func (sm *SessionMixer) SetBackups(backups *Backups) {
+	sm.backups = backups
+}
+
+// drawBackups renders the backup picker of the scenes panel; the list is re-read when opened
+func (sm *SessionMixer) drawBackups() {
+	imgui.SeparatorText(tr("Backups"))
+	imgui.SetNextItemWidth(200)
+	if imgui.BeginCombo("##backup_select", backupLabel(sm.selectedBackup)) {
+		if imgui.IsWindowAppearing() {
+			names, err := ListBackups(sm.backups.GetDir())
+			logError(err)
+			sm.backupNames = names
+		}
+		if len(sm.backupNames) == 0 {
+			imgui.TextDisabled(tr("No backups yet"))
+		}
+		for _, name := range sm.backupNames {
+			if imgui.SelectableBoolV(backupLabel(name), name == sm.selectedBackup, imgui.SelectableFlagsNone, imgui.Vec2{}) {
+				sm.selectedBackup = name
+			}
+		}
+		imgui.EndCombo()
+	}
+	imgui.SameLine()
+	if imgui.Button(tr("Restore##backup")) && sm.selectedBackup != "" {
+		scene, err := LoadBackup(sm.backups.GetDir(), sm.selectedBackup)
+		if err == nil {
+			err = sm.scenes.Apply(scene)
+		}
+		logError(err)
+	}
+}
+
+// backupLabel describes a backup by the time it was taken
+func backupLabel(name string) string {
+	t, ok := BackupTime(name)
+	if !ok {
+		return name
+	}
+	if time.Since(t) < 24*time.Hour {
+		return t.Format("15:04:05")
+	}
+	return t.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,43 @@
+package sessionmixer
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBackupsKeepRing(t *testing.T) {
+	gang, _ := newFakeGang(t, "Vocal", 0, 160, 80, 1)
+	dir := t.TempDir()
+	backups := NewBackups(dir, &BackupConfig{Keep: 2}, NewSceneManager(t.TempDir(), []*GangedFader{gang}, nil))
+
+	start := time.Date(2026, 10, 15, 14, 0, 0, 0, time.Local)
+	for i, value := range []int64{80, 80, 90, 100} {
+		if err := gang.HandleUIChange(value); err != nil {
+			t.Fatal(err)
+		}
+		if err := backups.snapshot(start.Add(time.Duration(i) * time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The unchanged mix at 14:01 is skipped and the oldest snapshot dropped
+	names, err := ListBackups(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"backup-20261015-140300", "backup-20261015-140200"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("ListBackups() = %v, want %v", names, want)
+	}
+	scene, err := LoadBackup(dir, names[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := scene.Gangs["Vocal"]; got != 100 {
+		t.Errorf("newest backup holds %d, want 100", got)
+	}
+	if when, ok := BackupTime(names[1]); !ok || !when.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("BackupTime(%s) = %v, %v", names[1], when, ok)
+	}
+}
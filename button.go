@@ -0,0 +1,136 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// Button actions of surface buttons and input devices
+const (
+	ButtonMute      = "mute"       // Toggle a gang's mute; lit while muted
+	ButtonRecall    = "recall"     // Recall a scene; lit while it is the current scene
+	ButtonSwitch    = "switch"     // Hold a momentary switch (talkback) or toggle a latching one; lit while on
+	ButtonProfile   = "profile"    // Switch to a profile, or cycle through them; lit while it is current
+	ButtonSceneNext = "scene_next" // Recall the next stored scene
+	ButtonScenePrev = "scene_prev" // Recall the previous stored scene
+)
+
+// ButtonTargets are what button actions act on
+type ButtonTargets struct {
+	Scenes   *SceneManager
+	Switches []*Switch
+	Profiles *ProfileManager
+}
+
+// buttonAction is a button's action with its resolved target; gangs are looked up by name on
+// each press, as a profile switch replaces them
+type buttonAction struct {
+	action  string
+	gang    string
+	scene   string
+	profile string
+	sw      *Switch
+	targets ButtonTargets
+	source  string // Write source of the button's mutes
+}
+
+// newButtonAction resolves an action against gangs and targets; its mutes are writes from source
+func newButtonAction(action, gang, scene, sw, profile string, gangs []*GangedFader, targets ButtonTargets, source string) (*buttonAction, error) {
+	ba := &buttonAction{action: action, gang: gang, scene: scene, profile: profile, targets: targets, source: source}
+	switch action {
+	case ButtonMute:
+		if findGang(gangs, gang) == nil {
+			return nil, fmt.Errorf("unknown gang '%s'", gang)
+		}
+	case ButtonRecall:
+		if targets.Scenes == nil || scene == "" {
+			return nil, fmt.Errorf("recall requires a scene")
+		}
+	case ButtonSceneNext, ButtonScenePrev:
+		if targets.Scenes == nil {
+			return nil, fmt.Errorf("scenes are not available")
+		}
+	case ButtonSwitch:
+		for _, s := range targets.Switches {
+			if s.GetName() == sw {
+				ba.sw = s
+			}
+		}
+		if ba.sw == nil {
+			return nil, fmt.Errorf("unknown switch '%s'", sw)
+		}
+		if ba.sw.GetChannel().GetControl().Type != scarlettctl.ControlTypeBoolean {
+			return nil, fmt.Errorf("switch '%s' is not an on/off control", sw)
+		}
+	case ButtonProfile:
+		if targets.Profiles == nil {
+			return nil, fmt.Errorf("profiles are not available")
+		}
+		if profile != "" && !slices.Contains(targets.Profiles.GetNames(), profile) {
+			return nil, fmt.Errorf("unknown profile '%s'", profile)
+		}
+	default:
+		return nil, fmt.Errorf("unknown action '%s'", action)
+	}
+	return ba, nil
+}
+
+// handle acts on a press or release; only momentary switches act on release
+func (ba *buttonAction) handle(gangs []*GangedFader, pressed bool) error {
+	if ba.action == ButtonSwitch {
+		channel := ba.sw.GetChannel()
+		if ba.sw.IsMomentary() {
+			return channel.HandleUIChange(boolToValue(pressed))
+		}
+		if pressed {
+			return channel.HandleUIChange(boolToValue(channel.GetCurrentValue() == 0))
+		}
+		return nil
+	}
+	if !pressed {
+		return nil
+	}
+	switch ba.action {
+	case ButtonMute:
+		gang := findGang(gangs, ba.gang)
+		if gang == nil || gang.IsLocked() || !gang.Claim(ba.source) {
+			return nil
+		}
+		if gang.IsMuted() {
+			return gang.Unmute()
+		}
+		return gang.Mute()
+	case ButtonRecall:
+		return ba.targets.Scenes.Recall(ba.scene)
+	case ButtonSceneNext:
+		return ba.targets.Scenes.Step(1)
+	case ButtonScenePrev:
+		return ba.targets.Scenes.Step(-1)
+	case ButtonProfile:
+		if ba.profile == "" {
+			ba.targets.Profiles.Next()
+			return nil
+		}
+		return ba.targets.Profiles.Request(ba.profile)
+	}
+	return nil
+}
+
+// isLit returns whether the button's LED should be on
+func (ba *buttonAction) isLit(gangs []*GangedFader) bool {
+	switch ba.action {
+	case ButtonSwitch:
+		return ba.sw.GetChannel().GetCurrentValue() != 0
+	case ButtonMute:
+		gang := findGang(gangs, ba.gang)
+		return gang != nil && gang.IsMuted()
+	case ButtonRecall:
+		current := ba.targets.Scenes.GetCurrent()
+		return current != nil && current.Name == ba.scene
+	case ButtonProfile:
+		return ba.profile != "" && ba.targets.Profiles.GetCurrent() == ba.profile
+	}
+	return false
+}
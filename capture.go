@@ -0,0 +1,168 @@
+package sessionmixer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// Capture record types
+const (
+	CaptureTypeGangs  = "gangs"  // The gang layout, with each control's range and current value
+	CaptureTypeEvent  = "event"  // A hardware change delivered by the event monitor
+	CaptureTypeChange = "change" // A value requested for a gang (UI, scenes, duckers, remotes...)
+	CaptureTypeWrite  = "write"  // A value written to a hardware control
+)
+
+// CaptureRecord is one line of an event capture (line-delimited JSON)
+type CaptureRecord struct {
+	Time    time.Time      `json:"time"`
+	Type    string         `json:"type"`
+	Gangs   []CapturedGang `json:"gangs,omitempty"`   // gangs records
+	Gang    string         `json:"gang,omitempty"`    // change records
+	NumID   uint           `json:"num_id,omitempty"`  // event and write records
+	Control string         `json:"control,omitempty"` // event and write records
+	Value   int64          `json:"value"`
+	Error   string         `json:"error,omitempty"` // Failed writes
+}
+
+// CapturedGang describes a gang well enough to rebuild it without the hardware
+type CapturedGang struct {
+	Name     string            `json:"name"`
+	Unit     string            `json:"unit,omitempty"`
+	TaperDb  float32           `json:"taper_db,omitempty"`
+	Display  string            `json:"display,omitempty"`
+	Ceiling  *int64            `json:"ceiling,omitempty"`
+	Controls []CapturedControl `json:"controls"`
+	Levels   []CapturedControl `json:"levels,omitempty"`
+}
+
+// CapturedControl is a hardware control of a captured gang and its value when captured
+type CapturedControl struct {
+	NumID uint                    `json:"num_id"`
+	Name  string                  `json:"name"`
+	Type  scarlettctl.ControlType `json:"type"`
+	Min   int64                   `json:"min"`
+	Max   int64                   `json:"max"`
+	Value int64                   `json:"value"`
+}
+
+// EventCapture writes the hardware events, gang changes and hardware writes of a session to a
+// file, for reproducing synchronization bugs with `sessionmixer replay`
+type EventCapture struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	err     error // First write error; capturing stops after it
+}
+
+// capture is the active event capture (nil when not capturing)
+var capture atomic.Pointer[EventCapture]
+
+// StartCapture starts capturing to path; CaptureGangs must be called with the gangs before
+// their events are meaningful
+func StartCapture(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create capture: %w", err)
+	}
+	capture.Store(&EventCapture{file: file, encoder: json.NewEncoder(file)})
+	return nil
+}
+
+// StopCapture stops the active capture and closes its file, returning the first write error
+func StopCapture() error {
+	ec := capture.Swap(nil)
+	if ec == nil {
+		return nil
+	}
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	if err := ec.file.Close(); err != nil && ec.err == nil {
+		ec.err = err
+	}
+	if ec.err != nil {
+		return fmt.Errorf("failed to write capture: %w", ec.err)
+	}
+	return nil
+}
+
+// CaptureGangs records the gang layout (at startup and after each profile switch)
+func CaptureGangs(gangs []*GangedFader) {
+	ec := capture.Load()
+	if ec == nil {
+		return
+	}
+	record := CaptureRecord{Time: time.Now(), Type: CaptureTypeGangs}
+	for _, gang := range gangs {
+		cg := CapturedGang{
+			Name:    gang.GetName(),
+			Unit:    gang.GetUnit(),
+			TaperDb: gang.GetTaperDb(),
+			Display: gang.GetDisplay(),
+		}
+		if ceiling, ok := gang.GetCeiling(); ok {
+			cg.Ceiling = &ceiling
+		}
+		for _, ch := range gang.GetChannels() {
+			cg.Controls = append(cg.Controls, capturedControl(ch.GetControl(), ch.GetCurrentValue()))
+		}
+		for i, ctl := range gang.GetLevelControls() {
+			value, _ := gang.readLevel(i)
+			cg.Levels = append(cg.Levels, capturedControl(ctl, value))
+		}
+		record.Gangs = append(record.Gangs, cg)
+	}
+	ec.write(record)
+}
+
+// captureEvent records a hardware change delivered by the event monitor
+func captureEvent(control *scarlettctl.Control, value int64) {
+	if ec := capture.Load(); ec != nil {
+		ec.write(CaptureRecord{Time: time.Now(), Type: CaptureTypeEvent, NumID: control.NumID, Control: control.Name, Value: value})
+	}
+}
+
+// captureChange records a value requested for a gang, before the gang mode and ceiling apply
+func captureChange(gang *GangedFader, value int64) {
+	if ec := capture.Load(); ec != nil {
+		ec.write(CaptureRecord{Time: time.Now(), Type: CaptureTypeChange, Gang: gang.GetName(), Value: value})
+	}
+}
+
+// captureWrite records a value written to a hardware control
+func captureWrite(control *scarlettctl.Control, value int64, err error) {
+	if ec := capture.Load(); ec != nil {
+		record := CaptureRecord{Time: time.Now(), Type: CaptureTypeWrite, NumID: control.NumID, Control: control.Name, Value: value}
+		if err != nil {
+			record.Error = err.Error()
+		}
+		ec.write(record)
+	}
+}
+
+// write appends a record; records are written unbuffered so a capture survives a crash
+func (ec *EventCapture) write(record CaptureRecord) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	if ec.err != nil {
+		return
+	}
+	ec.err = ec.encoder.Encode(record)
+}
+
+func capturedControl(control *scarlettctl.Control, value int64) CapturedControl {
+	return CapturedControl{
+		NumID: control.NumID,
+		Name:  control.Name,
+		Type:  control.Type,
+		Min:   control.Min,
+		Max:   control.Max,
+		Value: value,
+	}
+}
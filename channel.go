@@ -2,18 +2,27 @@ package sessionmixer
 
 import (
 	"fmt"
-	"log"
 	"math"
 	"sync/atomic"
+	"time"
 
 	"github.com/michaelquigley/scarlettctl"
 )
 
+// ControlIO reads and writes the value of a hardware control
+// *scarlettctl.Control implements it; tests substitute a fake so channels and gangs can be
+// exercised without hardware
+type ControlIO interface {
+	GetValue() (int64, error)
+	SetValue(value int64) error
+}
+
 // MixerChannel represents a single fader control in the mixer
 // Implements bidirectional update strategy from BIDIRECTIONAL_UPDATE_STRATEGY.md
 type MixerChannel struct {
-	// Hardware control
+	// Hardware control; control describes it (name, NumID, range) and io reads and writes it
 	control *scarlettctl.Control
+	io      ControlIO
 
 	// Display properties
 	displayName string
@@ -21,8 +30,9 @@ type MixerChannel struct {
 
 	// Cached values (thread-safe via atomic operations)
 	// These are caches, not authoritative state - hardware is source of truth
-	lastUIValue int64 // Last value set BY the UI
-	lastHWValue int64 // Last value FROM hardware
+	lastUIValue int64        // Last value set BY the UI
+	lastHWValue int64        // Last value FROM hardware
+	wroteAt     atomic.Int64 // When the UI last wrote (UnixNano), to tell echoes from external changes
 }
 
 // NewMixerChannel creates a new mixer channel from a hardware control
@@ -30,15 +40,25 @@ func NewMixerChannel(control *scarlettctl.Control, displayName, unit string) (*M
 	if control == nil {
 		return nil, fmt.Errorf("control cannot be nil")
 	}
+	return NewMixerChannelIO(control, control, displayName, unit)
+}
+
+// NewMixerChannelIO creates a new mixer channel described by control whose value is read and
+// written through io
+func NewMixerChannelIO(control *scarlettctl.Control, io ControlIO, displayName, unit string) (*MixerChannel, error) {
+	if control == nil || io == nil {
+		return nil, fmt.Errorf("control cannot be nil")
+	}
 
 	// Read initial value from hardware
-	initialValue, err := control.GetValue()
+	initialValue, err := io.GetValue()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read initial value: %w", err)
 	}
 
 	ch := &MixerChannel{
 		control:     control,
+		io:          io,
 		displayName: displayName,
 		unit:        unit,
 		lastUIValue: initialValue,
@@ -48,15 +68,36 @@ func NewMixerChannel(control *scarlettctl.Control, displayName, unit string) (*M
 	return ch, nil
 }
 
+// observer is set in read-only observer mode, in which every write to the hardware is refused
+var observer atomic.Bool
+
+// SetObserver enables or disables read-only observer mode
+func SetObserver(enabled bool) {
+	observer.Store(enabled)
+}
+
+// IsObserver returns true in read-only observer mode
+func IsObserver() bool {
+	return observer.Load()
+}
+
 // HandleUIChange is called when the user changes the fader in the UI
 // Implements immediate write with value equality check (no debouncing)
 // This is part of the UI → Hardware flow in the bidirectional update strategy
 func (ch *MixerChannel) HandleUIChange(newValue int64) error {
+	if observer.Load() {
+		return fmt.Errorf("%s: %w", ch.control.Name, ErrObserver)
+	}
+
+	// Last line of defense: never write outside the control's own range
+	low, high := controlRange(ch.control)
+	newValue, rangeErr := clampValue(ch.control.Name, newValue, low, high)
+
 	// CRITICAL: Value equality check - skip if unchanged
 	// This prevents redundant writes when dragging
 	oldValue := atomic.LoadInt64(&ch.lastUIValue)
 	if oldValue == newValue {
-		return nil // No change, don't write
+		return rangeErr // No change, don't write
 	}
 
 	// Update cached value
@@ -64,13 +105,16 @@ func (ch *MixerChannel) HandleUIChange(newValue int64) error {
 
 	// IMMEDIATE write to hardware - no debouncing, no delay
 	// The ALSA driver will handle batching rapid updates naturally
-	err := ch.control.SetValue(newValue)
+	started := time.Now()
+	ch.wroteAt.Store(started.UnixNano())
+	err := ch.io.SetValue(newValue)
+	Stats.RecordWrite(ch.control, newValue, started)
+	captureWrite(ch.control, newValue, err)
 	if err != nil {
-		log.Printf("Failed to write to %s: %v", ch.control.Name, err)
-		return err
+		return &ErrWriteFailed{Control: ch.control.Name, Cause: err}
 	}
 
-	return nil
+	return rangeErr
 }
 
 // HandleHWChange is called when hardware state changes (from event monitor)
@@ -100,6 +144,11 @@ func (ch *MixerChannel) GetCurrentValue() int64 {
 	return atomic.LoadInt64(&ch.lastUIValue)
 }
 
+// ReadValue reads the current value from the hardware, bypassing the caches
+func (ch *MixerChannel) ReadValue() (int64, error) {
+	return ch.io.GetValue()
+}
+
 // GetControl returns the underlying hardware control
 func (ch *MixerChannel) GetControl() *scarlettctl.Control {
 	return ch.control
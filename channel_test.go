@@ -0,0 +1,180 @@
+package sessionmixer
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// fakeControl is a ControlIO holding a value in memory and recording every write
+type fakeControl struct {
+	mu     sync.Mutex
+	value  int64
+	writes []int64
+	getErr error
+	setErr error
+}
+
+func (fc *fakeControl) GetValue() (int64, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.value, fc.getErr
+}
+
+func (fc *fakeControl) SetValue(value int64) error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if fc.setErr != nil {
+		return fc.setErr
+	}
+	fc.value = value
+	fc.writes = append(fc.writes, value)
+	return nil
+}
+
+// set changes the value behind the channel's back, as a hardware change without an event would
+func (fc *fakeControl) set(value int64) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.value = value
+}
+
+func (fc *fakeControl) getWrites() []int64 {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return append([]int64(nil), fc.writes...)
+}
+
+// newFakeChannel creates a channel over a fake integer control with the given range and value
+func newFakeChannel(t *testing.T, numID uint, name string, min, max, value int64) (*MixerChannel, *fakeControl) {
+	t.Helper()
+	control := &scarlettctl.Control{NumID: numID, Name: name, Type: scarlettctl.ControlTypeInteger, Min: min, Max: max}
+	return newFakeChannelFor(t, control, value)
+}
+
+// newFakeChannelFor creates a channel over a fake holding value, described by control
+func newFakeChannelFor(t *testing.T, control *scarlettctl.Control, value int64) (*MixerChannel, *fakeControl) {
+	t.Helper()
+	fake := &fakeControl{value: value}
+	ch, err := NewMixerChannelIO(control, fake, control.Name, "db")
+	if err != nil {
+		t.Fatalf("NewMixerChannelIO: %v", err)
+	}
+	return ch, fake
+}
+
+func TestMixerChannelReadsInitialValue(t *testing.T) {
+	ch, _ := newFakeChannel(t, 1, "Mix A Input 01", 0, 160, 80)
+	if got := ch.GetCurrentValue(); got != 80 {
+		t.Errorf("GetCurrentValue() = %d, want 80", got)
+	}
+}
+
+func TestMixerChannelInitialReadError(t *testing.T) {
+	control := &scarlettctl.Control{NumID: 1, Name: "Mix A Input 01"}
+	fake := &fakeControl{getErr: errors.New("device gone")}
+	if _, err := NewMixerChannelIO(control, fake, "in", "db"); err == nil {
+		t.Fatal("expected an error when the initial read fails")
+	}
+	if _, err := NewMixerChannelIO(nil, fake, "in", "db"); err == nil {
+		t.Fatal("expected an error for a nil control")
+	}
+}
+
+func TestMixerChannelUIChangeWritesOnce(t *testing.T) {
+	ch, fake := newFakeChannel(t, 1, "Mix A Input 01", 0, 160, 80)
+	for _, value := range []int64{100, 100, 100, 120} {
+		if err := ch.HandleUIChange(value); err != nil {
+			t.Fatalf("HandleUIChange(%d): %v", value, err)
+		}
+	}
+	if got, want := fake.getWrites(), []int64{100, 120}; !slices.Equal(got, want) {
+		t.Errorf("writes = %v, want %v", got, want)
+	}
+	if got := ch.GetCurrentValue(); got != 120 {
+		t.Errorf("GetCurrentValue() = %d, want 120", got)
+	}
+}
+
+func TestMixerChannelUnchangedValueIsNotWritten(t *testing.T) {
+	ch, fake := newFakeChannel(t, 1, "Mix A Input 01", 0, 160, 80)
+	if err := ch.HandleUIChange(80); err != nil {
+		t.Fatal(err)
+	}
+	if writes := fake.getWrites(); len(writes) != 0 {
+		t.Errorf("writes = %v, want none", writes)
+	}
+}
+
+func TestMixerChannelHWChangeUpdatesCache(t *testing.T) {
+	ch, fake := newFakeChannel(t, 1, "Mix A Input 01", 0, 160, 80)
+	ch.HandleHWChange(40)
+	if got := ch.GetCurrentValue(); got != 40 {
+		t.Errorf("GetCurrentValue() = %d, want 40", got)
+	}
+
+	// The echo of a hardware change must not be written back (no feedback loop)
+	if err := ch.HandleUIChange(40); err != nil {
+		t.Fatal(err)
+	}
+	if writes := fake.getWrites(); len(writes) != 0 {
+		t.Errorf("writes = %v, want none", writes)
+	}
+}
+
+func TestMixerChannelWriteFailed(t *testing.T) {
+	ch, fake := newFakeChannel(t, 1, "Mix A Input 01", 0, 160, 80)
+	cause := errors.New("EIO")
+	fake.setErr = cause
+
+	err := ch.HandleUIChange(100)
+	var writeErr *ErrWriteFailed
+	if !errors.As(err, &writeErr) {
+		t.Fatalf("HandleUIChange error = %v, want ErrWriteFailed", err)
+	}
+	if writeErr.Control != "Mix A Input 01" || !errors.Is(err, cause) {
+		t.Errorf("unexpected write error: %v", err)
+	}
+}
+
+func TestMixerChannelObserverRefusesWrites(t *testing.T) {
+	SetObserver(true)
+	defer SetObserver(false)
+
+	ch, fake := newFakeChannel(t, 1, "Mix A Input 01", 0, 160, 80)
+	if err := ch.HandleUIChange(100); !errors.Is(err, ErrObserver) {
+		t.Errorf("HandleUIChange error = %v, want ErrObserver", err)
+	}
+	if writes := fake.getWrites(); len(writes) != 0 {
+		t.Errorf("writes = %v, want none", writes)
+	}
+}
+
+func TestMixerChannelReadValue(t *testing.T) {
+	ch, fake := newFakeChannel(t, 1, "Mix A Input 01", 0, 160, 80)
+	fake.set(30)
+	value, err := ch.ReadValue()
+	if err != nil || value != 30 {
+		t.Errorf("ReadValue() = %d, %v; want 30", value, err)
+	}
+	if got := ch.GetCurrentValue(); got != 80 {
+		t.Errorf("ReadValue changed the cache: GetCurrentValue() = %d, want 80", got)
+	}
+}
+
+func TestMixerChannelClampsToControlRange(t *testing.T) {
+	control := &scarlettctl.Control{NumID: 1, Name: "Clock Source", Type: scarlettctl.ControlTypeEnumerated, Items: []string{"Internal", "S/PDIF", "ADAT"}}
+	ch, fake := newFakeChannelFor(t, control, 0)
+
+	err := ch.HandleUIChange(7)
+	var rangeErr *ErrOutOfRange
+	if !errors.As(err, &rangeErr) || rangeErr.Max != 2 {
+		t.Fatalf("HandleUIChange error = %v, want ErrOutOfRange with max 2", err)
+	}
+	if got := fake.getWrites(); !slices.Equal(got, []int64{2}) {
+		t.Errorf("writes = %v, want [2]", got)
+	}
+}
@@ -0,0 +1,39 @@
+package sessionmixer
+
+import "github.com/michaelquigley/scarlettctl"
+
+// Every write is clamped twice: to the gang's range (and its protection ceiling) in
+// GangedFader.HandleUIChange, and to the control's own range in MixerChannel.HandleUIChange, so
+// a value from any source (remote clients, scenes saved on another device, MIDI, keybindings)
+// never reaches the hardware out of range
+
+// clampValue limits value to [min, max], returning an *ErrOutOfRange naming name if it had to
+func clampValue(name string, value, min, max int64) (int64, error) {
+	if value >= min && value <= max {
+		return value, nil
+	}
+	err := &ErrOutOfRange{Name: name, Value: value, Min: min, Max: max}
+	if value < min {
+		return min, err
+	}
+	return max, err
+}
+
+// controlRange returns the valid values of a control: 0/1 for switches, the item indexes of
+// enumerated controls and the reported range otherwise
+func controlRange(control *scarlettctl.Control) (int64, int64) {
+	switch control.Type {
+	case scarlettctl.ControlTypeBoolean:
+		return 0, 1
+	case scarlettctl.ControlTypeEnumerated:
+		return 0, int64(max(len(control.Items)-1, 0))
+	default:
+		return control.Min, control.Max
+	}
+}
+
+// ClampValue limits a value to the gang's range, returning the clamped value and an
+// *ErrOutOfRange if it had to be changed
+func (gf *GangedFader) ClampValue(value int64) (int64, error) {
+	return clampValue("gang '"+gf.name+"'", value, gf.min, gf.max)
+}
@@ -0,0 +1,61 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"math"
+)
+
+// GangClipboard holds a copied gang value in dB, so it can be pasted into gangs with
+// different raw ranges; values only paste between gangs with the same unit
+type GangClipboard struct {
+	source string
+	unit   string
+	db     float64
+	valid  bool
+}
+
+// Copy stores the current value of gang
+func (gc *GangClipboard) Copy(gang *GangedFader) {
+	gc.source = gang.GetName()
+	gc.unit = gang.GetUnit()
+	gc.db = gang.ValueToDb(gang.GetCurrentValue())
+	gc.valid = true
+}
+
+// HasValue returns true once a value has been copied
+func (gc *GangClipboard) HasValue() bool {
+	return gc.valid
+}
+
+// CanPaste returns true if the copied value can be pasted into gang
+func (gc *GangClipboard) CanPaste(gang *GangedFader) bool {
+	return gc.valid && gang.GetUnit() == gc.unit && !gang.IsLocked()
+}
+
+// Paste writes the copied value to every compatible gang; incompatible gangs are skipped
+// and the last error is returned
+func (gc *GangClipboard) Paste(gangs ...*GangedFader) error {
+	var lastErr error
+	for _, gang := range gangs {
+		if !gc.CanPaste(gang) {
+			continue
+		}
+		if err := gang.HandleGuardedChange(gang.DbToValue(gc.db)); err != nil {
+			log.Printf("Failed to paste to %s: %v", gang.GetName(), err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// String describes the copied value, e.g. "Mains: -6.0 dB"
+func (gc *GangClipboard) String() string {
+	if !gc.valid {
+		return ""
+	}
+	if math.IsInf(gc.db, -1) {
+		return fmt.Sprintf("%s: -inf dB", gc.source)
+	}
+	return fmt.Sprintf("%s: %.1f dB", gc.source, gc.db)
+}
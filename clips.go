@@ -0,0 +1,229 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/michaelquigley/df/dd"
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// clipInterval is how often the clip log samples level controls
+const clipInterval = 20 * time.Millisecond
+
+// maxClipTimes bounds the number of timestamps kept per control
+const maxClipTimes = 100
+
+// ClipReport is the persisted summary of clip events during a session
+type ClipReport struct {
+	Started  time.Time
+	Ended    time.Time
+	Controls []ClipStats
+}
+
+// ClipStats summarizes the clip events of a single level control
+type ClipStats struct {
+	Control        string
+	Gang           string
+	Count          int
+	Times          []time.Time // Most recent clip onsets (bounded)
+	MaxOvershootDb float64     // Highest level observed above the clip threshold
+}
+
+// clipEntry tracks a single level control
+type clipEntry struct {
+	control  *scarlettctl.Control
+	stats    ClipStats
+	clipping bool
+}
+
+// ClipLog samples every configured level control and records clip events
+// A clip event is counted each time a level rises to or above the threshold
+type ClipLog struct {
+	thresholdDb float64
+
+	mu      sync.Mutex
+	entries []*clipEntry
+	started time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewClipLog creates a clip log for the level controls of all gangs
+// Level controls shared between gangs are tracked once
+func NewClipLog(gangs []*GangedFader, thresholdDb float32) *ClipLog {
+	cl := &ClipLog{
+		thresholdDb: float64(thresholdDb),
+		started:     time.Now(),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+
+	seen := make(map[uint]bool)
+	for _, gang := range gangs {
+		for _, control := range gang.GetLevelControls() {
+			if seen[control.NumID] {
+				continue
+			}
+			seen[control.NumID] = true
+			cl.entries = append(cl.entries, &clipEntry{
+				control: control,
+				stats:   ClipStats{Control: control.Name, Gang: gang.GetName()},
+			})
+		}
+	}
+
+	return cl
+}
+
+// Start begins sampling level controls in a background goroutine
+func (cl *ClipLog) Start() {
+	go func() {
+		defer close(cl.done)
+		ticker := time.NewTicker(clipInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-cl.stop:
+				return
+			case now := <-ticker.C:
+				cl.Update(now)
+			}
+		}
+	}()
+}
+
+// Stop stops sampling; blocks until the goroutine has exited
+func (cl *ClipLog) Stop() {
+	cl.stopOnce.Do(func() {
+		close(cl.stop)
+		<-cl.done
+	})
+}
+
+// Update samples every level control once
+func (cl *ClipLog) Update(now time.Time) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	for _, entry := range cl.entries {
+		level, err := entry.control.GetValue()
+		if err != nil {
+			continue
+		}
+		db := levelToDb(level, entry.control.Max)
+		if db < cl.thresholdDb {
+			entry.clipping = false
+			continue
+		}
+
+		overshoot := db - cl.thresholdDb
+		if !entry.clipping {
+			entry.clipping = true
+			entry.stats.Count++
+			entry.stats.Times = append(entry.stats.Times, now)
+			if len(entry.stats.Times) > maxClipTimes {
+				entry.stats.Times = entry.stats.Times[len(entry.stats.Times)-maxClipTimes:]
+			}
+		}
+		entry.stats.MaxOvershootDb = math.Max(entry.stats.MaxOvershootDb, overshoot)
+	}
+}
+
+// Report returns a snapshot of the clip statistics
+func (cl *ClipLog) Report() *ClipReport {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	report := &ClipReport{Started: cl.started, Ended: time.Now()}
+	for _, entry := range cl.entries {
+		stats := entry.stats
+		stats.Times = append([]time.Time(nil), entry.stats.Times...)
+		report.Controls = append(report.Controls, stats)
+	}
+	return report
+}
+
+// Reset clears all clip statistics and restarts the session clock
+func (cl *ClipLog) Reset() {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	cl.started = time.Now()
+	for _, entry := range cl.entries {
+		entry.stats = ClipStats{Control: entry.stats.Control, Gang: entry.stats.Gang}
+		entry.clipping = false
+	}
+}
+
+// Save writes the current report to path
+func (cl *ClipLog) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := dd.UnbindToYAML(cl.Report(), path); err != nil {
+		return fmt.Errorf("failed to save clip report: %w", err)
+	}
+	return nil
+}
+
+// LoadClipReport reads a clip report saved by ClipLog.Save
+func LoadClipReport(path string) (*ClipReport, error) {
+	return dd.NewFromYAML[ClipReport](path)
+}
+
+// Draw renders the clip summary table
+func (cl *ClipLog) Draw() {
+	report := cl.Report()
+	if len(report.Controls) == 0 {
+		imgui.TextDisabled(tr("No level controls configured"))
+		return
+	}
+
+	imgui.Text(trf("Since %s", report.Started.Format("15:04:05")))
+	imgui.SameLine()
+	if imgui.Button(tr("Reset##clips")) {
+		cl.Reset()
+	}
+
+	imgui.BeginTableV("clips_table", 5,
+		imgui.TableFlagsNone,
+		scaledVec2(imgui.Vec2{X: 640, Y: 0}), 0.0)
+	imgui.TableSetupColumnV(tr("Gang"), imgui.TableColumnFlagsWidthFixed, 120, 0)
+	imgui.TableSetupColumnV(tr("Level Control"), imgui.TableColumnFlagsWidthFixed, 240, 0)
+	imgui.TableSetupColumnV(tr("Clips"), imgui.TableColumnFlagsWidthFixed, 60, 0)
+	imgui.TableSetupColumnV(tr("Last"), imgui.TableColumnFlagsWidthFixed, 80, 0)
+	imgui.TableSetupColumnV(tr("Max Over"), imgui.TableColumnFlagsWidthFixed, 80, 0)
+	imgui.TableHeadersRow()
+
+	for _, stats := range report.Controls {
+		imgui.TableNextRow()
+		imgui.TableNextColumn()
+		imgui.Text(stats.Gang)
+		imgui.TableNextColumn()
+		imgui.Text(stats.Control)
+		imgui.TableNextColumn()
+		imgui.Text(trf("%d", stats.Count))
+		imgui.TableNextColumn()
+		if len(stats.Times) > 0 {
+			imgui.Text(stats.Times[len(stats.Times)-1].Format("15:04:05"))
+		} else {
+			imgui.TextDisabled(tr("-"))
+		}
+		imgui.TableNextColumn()
+		if stats.Count > 0 {
+			imgui.Text(trf("%.1f dB", stats.MaxOvershootDb))
+		} else {
+			imgui.TextDisabled(tr("-"))
+		}
+	}
+
+	imgui.EndTable()
+}
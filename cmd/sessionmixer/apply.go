@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/michaelquigley/df/dl"
+	"github.com/michaelquigley/scarlettctl"
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newApplyCommand().cmd)
+}
+
+type applyCommand struct {
+	cmd  *cobra.Command
+	fade time.Duration
+}
+
+func newApplyCommand() *applyCommand {
+	cmd := &cobra.Command{
+		Use:   "apply <scene>",
+		Short: "Recall a scene through the running mixer, or on the card directly if none is running",
+		Args:  cobra.ExactArgs(1),
+	}
+	out := &applyCommand{cmd: cmd}
+	cmd.Flags().DurationVar(&out.fade, "fade", 0, "fade from the current values to the scene over this time")
+	cmd.RunE = out.run
+	return out
+}
+
+func (cmd *applyCommand) run(_ *cobra.Command, args []string) error {
+	if cmd.fade < 0 {
+		return errors.New("--fade must not be negative")
+	}
+	cfg, err := sessionmixer.LoadMainConfig()
+	if err != nil {
+		return err
+	}
+
+	if err := applyScene(cfg, args[0], cmd.fade); err != nil {
+		return err
+	}
+	return nil
+}
+
+// applyScene recalls a stored scene through the running mixer, or on the card directly if none
+// is running, printing the result
+func applyScene(cfg *sessionmixer.Config, name string, fade time.Duration) error {
+	path, err := sessionmixer.IPCSocketPath()
+	if err != nil {
+		return err
+	}
+	result, err := sessionmixer.SendIPCTimeout(path, fade+time.Minute, "apply", name, fade.String())
+	if err == nil {
+		fmt.Println(result)
+		return nil
+	}
+	if !errors.Is(err, sessionmixer.ErrNoInstance) {
+		return err
+	}
+
+	dl.Debugf("no running mixer (%v); applying on card '%d' directly", err, cfg.Card)
+	card, err := scarlettctl.OpenCard(cfg.Card)
+	if err != nil {
+		return errors.Wrapf(err, "error opening card '%d'", cfg.Card)
+	}
+	defer card.Close()
+	gangs, err := sessionmixer.NewControlMapper(card, cfg).LoadGangs()
+	if err != nil {
+		return errors.Wrap(err, "error loading gangs")
+	}
+	routing, err := sessionmixer.NewRoutingPanel(card)
+	if err != nil {
+		return errors.Wrap(err, "error loading routing controls")
+	}
+	scenesDir, err := sessionmixer.ScenesDir()
+	if err != nil {
+		return err
+	}
+	scenes := sessionmixer.NewSceneManager(scenesDir, gangs, routing)
+	if err := scenes.Fade(name, fade); err != nil {
+		return err
+	}
+	fmt.Printf("recalled scene '%s'\n", name)
+	return nil
+}
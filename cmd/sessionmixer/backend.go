@@ -0,0 +1,500 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/michaelquigley/df/dl"
+	"github.com/michaelquigley/scarlettctl"
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/pkg/errors"
+)
+
+// backend holds the hardware-facing state shared by the interactive and headless commands
+type backend struct {
+	cfg       *sessionmixer.Config
+	card      *scarlettctl.Card
+	gangs     []*sessionmixer.GangedFader
+	switches  []*sessionmixer.Switch
+	inputs    *sessionmixer.InputPanel
+	routing   *sessionmixer.RoutingPanel
+	status    *sessionmixer.StatusBar
+	scenes    *sessionmixer.SceneManager
+	monitor   *sessionmixer.EventMonitor
+	journal   *sessionmixer.StateJournal
+	audit     *sessionmixer.AuditLog
+	backups   *sessionmixer.Backups
+	recovered *sessionmixer.Scene // Mix left by a session that did not end cleanly, if it differs
+	duckers   []*sessionmixer.Ducker
+	gates     []*sessionmixer.Gate
+	coughs    []*sessionmixer.CoughSwitch
+	scheduler *sessionmixer.Scheduler
+	surface   *sessionmixer.Surface
+	evdev     []*sessionmixer.EvdevDevice
+	debug     *sessionmixer.DebugServer
+	profiles  *sessionmixer.ProfileManager
+	panic     *sessionmixer.Panic
+	idle      *sessionmixer.IdleDimmer
+	obs       *sessionmixer.OBSClient
+	remote    *sessionmixer.RemoteServer
+	osc       *sessionmixer.OSCServer
+	ipc       *sessionmixer.IPCServer
+	locks     *sessionmixer.ControlLocks
+}
+
+// openBackend opens the configured card, loads all controls and starts the event monitor,
+// duckers and scene scheduler; the caller must close the returned backend
+func openBackend(cfg *sessionmixer.Config) (*backend, error) {
+	card, err := scarlettctl.OpenCard(cfg.Card)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error opening card '%d'", cfg.Card)
+	}
+	b := &backend{cfg: cfg, card: card}
+	if err := b.load(); err != nil {
+		b.close()
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *backend) load() error {
+	if err := sessionmixer.SetWritePolicy(b.cfg.WritePolicy); err != nil {
+		return err
+	}
+	if b.cfg.Debug != nil {
+		debug, err := sessionmixer.StartDebugServer(b.cfg.Debug.Listen)
+		if err != nil {
+			return errors.Wrap(err, "error starting debug server")
+		}
+		dl.Infof("debug server listening on http://%s/debug/", debug.GetAddr())
+		b.debug = debug
+	}
+
+	mapper := sessionmixer.NewControlMapper(b.card, b.cfg)
+	gangs, err := mapper.LoadGangs()
+	if err != nil {
+		return errors.Wrap(err, "error loading gangs")
+	}
+	b.gangs = gangs
+	sessionmixer.CaptureGangs(gangs)
+	switches, err := mapper.LoadSwitches()
+	if err != nil {
+		return errors.Wrap(err, "error loading switches")
+	}
+	b.switches = switches
+
+	inputs, err := sessionmixer.NewInputPanel(b.card)
+	if err != nil {
+		return errors.Wrap(err, "error loading input controls")
+	}
+	var interlock *sessionmixer.PhantomInterlock
+	if b.cfg.PhantomSafety != nil {
+		interlock, err = sessionmixer.NewPhantomInterlock(b.cfg.PhantomSafety, gangs)
+		if err != nil {
+			return errors.Wrap(err, "error configuring phantom safety")
+		}
+		inputs.SetPhantomInterlock(interlock)
+	}
+	if err := inputs.SetInputLinks(b.cfg.InputLinks); err != nil {
+		return errors.Wrap(err, "error linking input gains")
+	}
+	b.inputs = inputs
+
+	routing, err := sessionmixer.NewRoutingPanel(b.card)
+	if err != nil {
+		return errors.Wrap(err, "error loading routing controls")
+	}
+	b.routing = routing
+
+	status, err := sessionmixer.NewStatusBar(b.card)
+	if err != nil {
+		return errors.Wrap(err, "error loading status controls")
+	}
+	b.status = status
+
+	scenesDir, err := sessionmixer.ScenesDir()
+	if err != nil {
+		return err
+	}
+	b.scenes = sessionmixer.NewSceneManager(scenesDir, gangs, routing)
+
+	schedule, err := sessionmixer.LoadSchedule(b.cfg)
+	if err != nil {
+		return err
+	}
+	scheduler, err := sessionmixer.NewScheduler(schedule, b.scenes)
+	if err != nil {
+		return errors.Wrap(err, "error loading schedule")
+	}
+
+	duckers, err := mapper.LoadDuckers(gangs)
+	if err != nil {
+		return errors.Wrap(err, "error loading duckers")
+	}
+	gates, err := mapper.LoadGates(gangs)
+	if err != nil {
+		return errors.Wrap(err, "error loading gates")
+	}
+
+	auditPath, err := sessionmixer.AuditLogPath()
+	if err != nil {
+		return err
+	}
+	if b.audit, err = sessionmixer.StartAudit(auditPath); err != nil {
+		dl.Error(err) // The mixer works without its history
+	}
+
+	monitor := sessionmixer.NewEventMonitor(b.card, gangs)
+	monitor.AddChannels(inputs.GetChannels()...)
+	monitor.AddChannels(routing.GetChannels()...)
+	monitor.AddChannels(status.GetChannels()...)
+	for _, sw := range switches {
+		monitor.AddChannels(sw.GetChannel())
+	}
+	if b.cfg.Polling != nil {
+		if err := monitor.SetPolling(b.cfg.Polling); err != nil {
+			return errors.Wrap(err, "error configuring control polling")
+		}
+	}
+	if err := monitor.Start(); err != nil {
+		return errors.Wrap(err, "error starting event monitor")
+	}
+	b.monitor = monitor
+
+	if err := b.startJournal(); err != nil {
+		return err
+	}
+	if b.cfg.Backups != nil {
+		dir, err := sessionmixer.BackupsDir()
+		if err != nil {
+			return err
+		}
+		b.backups = sessionmixer.NewBackups(dir, b.cfg.Backups, b.scenes)
+		b.backups.Start()
+	}
+
+	// An observer refuses every write, so nothing that writes on its own is started
+	observer := sessionmixer.IsObserver()
+
+	if !observer {
+		for _, ducker := range duckers {
+			ducker.Start()
+		}
+		b.duckers = duckers
+		for _, gate := range gates {
+			gate.Start()
+		}
+		b.gates = gates
+
+		coughs, err := mapper.LoadCoughs(gangs)
+		if err != nil {
+			return errors.Wrap(err, "error loading cough switches")
+		}
+		for _, cough := range coughs {
+			cough.Start()
+		}
+		b.coughs = coughs
+	}
+
+	if b.cfg.LockControls && !observer {
+		locks, err := sessionmixer.NewControlLocks(b.card, gangs, switches)
+		if err != nil {
+			return errors.Wrap(err, "error locking controls")
+		}
+		dl.Infof("locked %d controls against other applications", locks.Count())
+		b.locks = locks
+	}
+
+	if b.cfg.IdleDim != nil && !observer {
+		idle, err := sessionmixer.NewIdleDimmer(*b.cfg.IdleDim, gangs)
+		if err != nil {
+			return errors.Wrap(err, "error configuring idle dim")
+		}
+		idle.Start()
+		b.idle = idle
+	}
+
+	if scheduler.HasEntries() && !observer {
+		scheduler.Start()
+		b.scheduler = scheduler
+	}
+
+	profiles, err := sessionmixer.NewProfileManager(b.card, b.cfg)
+	if err != nil {
+		return errors.Wrap(err, "error loading profiles")
+	}
+	profiles.OnSwitch(func(gangs []*sessionmixer.GangedFader) { b.gangs = gangs })
+	profiles.OnSwitch(sessionmixer.CaptureGangs)
+	profiles.OnSwitch(monitor.SetGangs)
+	profiles.OnSwitch(b.scenes.SetGangs)
+	if interlock != nil {
+		profiles.OnSwitch(interlock.SetGangs)
+	}
+	for _, ducker := range b.duckers {
+		profiles.OnSwitch(ducker.SetGangs)
+	}
+	for _, gate := range b.gates {
+		profiles.OnSwitch(gate.SetGangs)
+	}
+	for _, cough := range b.coughs {
+		profiles.OnSwitch(cough.SetGangs)
+	}
+	if b.idle != nil {
+		profiles.OnSwitch(b.idle.SetGangs)
+	}
+	if b.locks != nil {
+		profiles.OnSwitch(b.locks.SetGangs)
+	}
+	b.profiles = profiles
+
+	if b.cfg.Panic != nil {
+		p, err := sessionmixer.NewPanic(*b.cfg.Panic, gangs)
+		if err != nil {
+			return errors.Wrap(err, "error configuring panic")
+		}
+		profiles.OnSwitch(p.SetGangs)
+		b.panic = p
+	}
+
+	if b.cfg.OBS != nil && !observer {
+		obs, err := sessionmixer.NewOBSClient(*b.cfg.OBS, gangs, b.scenes)
+		if err != nil {
+			return errors.Wrap(err, "error configuring OBS link")
+		}
+		profiles.OnSwitch(obs.SetGangs)
+		obs.Start()
+		b.obs = obs
+	}
+
+	if b.cfg.Remote != nil {
+		remote := sessionmixer.NewRemoteServer(b.cfg, gangs)
+		remote.SetSceneManager(b.scenes)
+		if err := remote.Start(); err != nil {
+			return errors.Wrap(err, "error starting remote server")
+		}
+		profiles.OnSwitch(remote.SetGangs)
+		dl.Infof("remote server listening on %s", remote.GetAddr())
+		b.remote = remote
+	}
+
+	if b.cfg.OSC != nil {
+		osc, err := sessionmixer.NewOSCServer(*b.cfg.OSC, gangs, b.scenes)
+		if err != nil {
+			return errors.Wrap(err, "error configuring OSC server")
+		}
+		if err := osc.Start(); err != nil {
+			return errors.Wrap(err, "error starting OSC server")
+		}
+		profiles.OnSwitch(osc.SetGangs)
+		dl.Infof("OSC server listening on %s", osc.GetAddr())
+		b.osc = osc
+	}
+
+	socketPath, err := sessionmixer.IPCSocketPath()
+	if err != nil {
+		return err
+	}
+	ipc := sessionmixer.NewIPCServer(socketPath)
+	ipc.Handle("profile", b.handleProfile)
+	ipc.Handle("apply", b.handleApply)
+	ipc.Handle("panic", b.handlePanic)
+	if err := ipc.Start(); err != nil {
+		return errors.Wrap(err, "error starting control socket")
+	}
+	b.ipc = ipc
+
+	if b.cfg.Surface != nil && !observer {
+		surface, err := sessionmixer.NewSurface(*b.cfg.Surface, gangs)
+		if err != nil {
+			return errors.Wrap(err, "error opening control surface")
+		}
+		if err := surface.BindButtons(b.buttonTargets()); err != nil {
+			surface.Stop()
+			return errors.Wrap(err, "error configuring control surface buttons")
+		}
+		profiles.OnSwitch(surface.SetGangs)
+		surface.Start()
+		b.surface = surface
+	}
+
+	if !observer {
+		for _, config := range b.cfg.InputDevices {
+			input, err := sessionmixer.NewEvdevDevice(config, gangs, b.buttonTargets())
+			if err != nil {
+				return errors.Wrap(err, "error opening input device")
+			}
+			profiles.OnSwitch(input.SetGangs)
+			input.Start()
+			b.evdev = append(b.evdev, input)
+		}
+	}
+
+	return nil
+}
+
+// buttonTargets returns what surface buttons and input devices act on
+func (b *backend) buttonTargets() sessionmixer.ButtonTargets {
+	return sessionmixer.ButtonTargets{Scenes: b.scenes, Switches: b.switches, Profiles: b.profiles}
+}
+
+// startCapture captures hardware events, gang changes and writes to path (a bare file name goes
+// in the recordings directory) for `sessionmixer replay`; must be called before openBackend
+func startCapture(path string) error {
+	capturePath, err := sessionmixer.RecordingPath(path)
+	if err != nil {
+		return err
+	}
+	if err := sessionmixer.StartCapture(capturePath); err != nil {
+		return err
+	}
+	dl.Infof("capturing hardware events to '%s'", capturePath)
+	return nil
+}
+
+// stopCapture stops a capture started by startCapture, after the backend has been closed
+func stopCapture() {
+	if err := sessionmixer.StopCapture(); err != nil {
+		dl.Error(err)
+	}
+}
+
+// startJournal recovers the mix journaled by a session that did not end cleanly, saving it as a
+// scene when the hardware now holds something else, and starts journaling this session
+func (b *backend) startJournal() error {
+	path, err := sessionmixer.JournalPath()
+	if err != nil {
+		return err
+	}
+	recovered, err := sessionmixer.LoadJournal(path)
+	if err != nil {
+		dl.Error(err)
+	} else if recovered != nil && b.scenes.Differs(recovered) {
+		if err := b.scenes.Store(recovered); err != nil {
+			dl.Error(err)
+		} else {
+			dl.Infof("last session did not end cleanly; its mix is saved as scene '%s'", recovered.Name)
+			b.recovered = recovered
+		}
+	}
+	b.journal = sessionmixer.NewStateJournal(path, b.scenes)
+	b.journal.Start()
+	return nil
+}
+
+// handleProfile serves `sessionmixer profile`: with no arguments it reports the profiles,
+// otherwise it requests a switch, applied by the command's UI or main loop
+func (b *backend) handleProfile(args []string) (string, error) {
+	if len(args) == 0 {
+		return fmt.Sprintf("current: %s\nprofiles: %s", b.profiles.GetCurrent(), strings.Join(b.profiles.GetNames(), ", ")), nil
+	}
+	if err := b.profiles.Request(args[0]); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("switching to profile '%s'", args[0]), nil
+}
+
+// handleApply serves `sessionmixer apply`: recalls a scene, fading over the optional duration
+func (b *backend) handleApply(args []string) (string, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return "", errors.New("usage: apply <scene> [fade]")
+	}
+	if sessionmixer.IsObserver() {
+		return "", sessionmixer.ErrObserver
+	}
+	var fade time.Duration
+	if len(args) == 2 {
+		var err error
+		if fade, err = time.ParseDuration(args[1]); err != nil {
+			return "", errors.Wrap(err, "invalid fade")
+		}
+	}
+	if err := b.scenes.Fade(args[0], fade); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("recalled scene '%s'", args[0]), nil
+}
+
+// handlePanic serves `sessionmixer panic`: fades the output gangs to silence, or back to their
+// previous values with the "restore" argument
+func (b *backend) handlePanic(args []string) (string, error) {
+	if b.panic == nil {
+		return "", errors.New("panic is not configured")
+	}
+	if len(args) == 1 && args[0] == "restore" {
+		if !b.panic.IsEngaged() {
+			return "panic is not engaged", nil
+		}
+		if err := b.panic.Restore(); err != nil {
+			return "", err
+		}
+		return "restored the output gangs", nil
+	}
+	if len(args) > 0 {
+		return "", errors.New("usage: panic [restore]")
+	}
+	if err := b.panic.Engage(); err != nil {
+		return "", err
+	}
+	return "silenced the output gangs", nil
+}
+
+// close stops all background activity and closes the card
+func (b *backend) close() {
+	if b.ipc != nil {
+		b.ipc.Stop()
+	}
+	if b.remote != nil {
+		b.remote.Stop()
+	}
+	if b.osc != nil {
+		b.osc.Stop()
+	}
+	if b.obs != nil {
+		b.obs.Stop()
+	}
+	for _, input := range b.evdev {
+		input.Stop()
+	}
+	if b.surface != nil {
+		b.surface.Stop()
+	}
+	if b.scheduler != nil {
+		b.scheduler.Stop()
+	}
+	for _, cough := range b.coughs {
+		cough.Stop()
+	}
+	if b.idle != nil {
+		b.idle.Stop()
+	}
+	if b.journal != nil {
+		if err := b.journal.Stop(); err != nil {
+			dl.Error(err)
+		}
+	}
+	if b.backups != nil {
+		b.backups.Stop()
+	}
+	for _, ducker := range b.duckers {
+		ducker.Stop()
+	}
+	for _, gate := range b.gates {
+		gate.Stop()
+	}
+	if b.monitor != nil {
+		b.monitor.Stop()
+	}
+	if b.audit != nil {
+		if err := b.audit.Stop(); err != nil {
+			dl.Error(err)
+		}
+	}
+	if b.debug != nil {
+		b.debug.Stop()
+	}
+	if b.locks != nil {
+		b.locks.Release()
+	}
+	b.card.Close()
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newBackupsCommand().cmd)
+}
+
+type backupsCommand struct {
+	cmd *cobra.Command
+}
+
+func newBackupsCommand() *backupsCommand {
+	cmd := &cobra.Command{
+		Use:   "backups [<backup> <scene>]",
+		Short: "List the periodic backups of the mix, or keep one as a scene to recall with apply",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) != 0 && len(args) != 2 {
+				return errors.New("expected no arguments, or a backup and a scene name")
+			}
+			return nil
+		},
+	}
+	out := &backupsCommand{cmd: cmd}
+	cmd.RunE = out.run
+	return out
+}
+
+func (cmd *backupsCommand) run(_ *cobra.Command, args []string) error {
+	// Apply the storage overrides, which may move the data directory
+	if _, err := sessionmixer.LoadMainConfig(); err != nil {
+		return err
+	}
+	dir, err := sessionmixer.BackupsDir()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		names, err := sessionmixer.ListBackups(dir)
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Printf("no backups in '%s' (enable them with the 'backups' config section)\n", dir)
+			return nil
+		}
+		for _, name := range names {
+			taken := ""
+			if t, ok := sessionmixer.BackupTime(name); ok {
+				taken = t.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%s  %s\n", name, taken)
+		}
+		return nil
+	}
+
+	backup, err := sessionmixer.LoadBackup(dir, args[0])
+	if err != nil {
+		return err
+	}
+	scenesDir, err := sessionmixer.ScenesDir()
+	if err != nil {
+		return err
+	}
+	backup.Name = args[1]
+	if err := sessionmixer.NewSceneManager(scenesDir, nil, nil).Store(backup); err != nil {
+		return err
+	}
+	fmt.Printf("kept backup '%s' as scene '%s'; recall it with 'sessionmixer apply %s'\n", args[0], args[1], args[1])
+	return nil
+}
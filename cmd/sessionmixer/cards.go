@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/michaelquigley/scarlettctl"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newCardsCommand().cmd)
+}
+
+type cardsCommand struct {
+	cmd *cobra.Command
+}
+
+func newCardsCommand() *cardsCommand {
+	cmd := &cobra.Command{
+		Use:   "cards",
+		Short: "List detected ALSA cards and whether they are supported",
+		Args:  cobra.NoArgs,
+	}
+	out := &cardsCommand{cmd: cmd}
+	cmd.RunE = out.run
+	return out
+}
+
+// alsaCard is one entry of /proc/asound/cards
+type alsaCard struct {
+	number int
+	id     string
+	driver string
+	name   string
+}
+
+// cardLine matches the first line of a /proc/asound/cards entry, e.g.
+// " 1 [Gen            ]: USB-Audio - Scarlett 4i4 4th Gen"
+var cardLine = regexp.MustCompile(`^\s*(\d+)\s+\[(.*?)\s*\]:\s*(\S+)\s+-\s+(.*)$`)
+
+func (cmd *cardsCommand) run(_ *cobra.Command, _ []string) error {
+	cards, err := readAlsaCards()
+	if err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		fmt.Println("no ALSA cards found")
+		return nil
+	}
+
+	supported := make(map[int]bool)
+	if scarletts, err := scarlettctl.ListCards(); err == nil {
+		for _, card := range scarletts {
+			supported[card.Number] = true
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CARD\tID\tNAME\tDRIVER\tUSB SERIAL\tSUPPORTED")
+	for _, card := range cards {
+		serial := usbSerial(card.number)
+		if serial == "" {
+			serial = "-"
+		}
+		yes := "no"
+		if supported[card.number] {
+			yes = "yes"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", card.number, card.id, card.name, card.driver, serial, yes)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if len(supported) > 0 {
+		fmt.Println("\nset `card:` in ~/.config/sessionmixer/session.yaml to a supported card number")
+	}
+	return nil
+}
+
+// readAlsaCards parses /proc/asound/cards
+func readAlsaCards() ([]alsaCard, error) {
+	f, err := os.Open("/proc/asound/cards")
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading ALSA cards")
+	}
+	defer f.Close()
+
+	var cards []alsaCard
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		m := cardLine.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue // Continuation line with the long name
+		}
+		number, _ := strconv.Atoi(m[1])
+		cards = append(cards, alsaCard{number: number, id: m[2], driver: m[3], name: m[4]})
+	}
+	return cards, scanner.Err()
+}
+
+// usbSerial returns the USB serial number of a card's device, or "" for non-USB cards
+// The card's sysfs device is the USB interface; the serial belongs to its parent device
+func usbSerial(number int) string {
+	device, err := filepath.EvalSymlinks(fmt.Sprintf("/sys/class/sound/card%d/device", number))
+	if err != nil {
+		return ""
+	}
+	serial, err := os.ReadFile(filepath.Join(filepath.Dir(device), "serial"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(serial))
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newClipsCommand().cmd)
+}
+
+type clipsCommand struct {
+	cmd *cobra.Command
+}
+
+func newClipsCommand() *clipsCommand {
+	cmd := &cobra.Command{
+		Use:   "clips",
+		Short: "Report clip events from the last session",
+		Args:  cobra.NoArgs,
+	}
+	out := &clipsCommand{cmd: cmd}
+	cmd.RunE = out.run
+	return out
+}
+
+func (cmd *clipsCommand) run(_ *cobra.Command, _ []string) error {
+	// Apply the config's storage overrides if it loads; the report is readable without one
+	_, _ = sessionmixer.LoadMainConfig()
+	path, err := sessionmixer.ClipReportPath()
+	if err != nil {
+		return err
+	}
+	report, err := sessionmixer.LoadClipReport(path)
+	if err != nil {
+		return errors.Wrapf(err, "error loading clip report '%s'", path)
+	}
+
+	fmt.Printf("session: %s - %s\n\n", report.Started.Format("2006-01-02 15:04:05"), report.Ended.Format("15:04:05"))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GANG\tLEVEL CONTROL\tCLIPS\tMAX OVER\tTIMES")
+	for _, stats := range report.Controls {
+		maxOver := "-"
+		if stats.Count > 0 {
+			maxOver = fmt.Sprintf("%.1f dB", stats.MaxOvershootDb)
+		}
+		times := ""
+		for i, t := range stats.Times {
+			if i > 0 {
+				times += " "
+			}
+			times += t.Format("15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", stats.Gang, stats.Control, stats.Count, maxOver, times)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+
+	"github.com/michaelquigley/dfx"
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newConnectCommand().cmd)
+}
+
+type connectCommand struct {
+	cmd      *cobra.Command
+	page     string
+	token    string
+	scale    float32
+	maxFPS   int
+	software bool
+}
+
+func newConnectCommand() *connectCommand {
+	cmd := &cobra.Command{
+		Use:   "connect <host:port>",
+		Short: "Run as a client of a remote mixer that owns the hardware",
+		Args:  cobra.ExactArgs(1),
+	}
+	out := &connectCommand{cmd: cmd}
+	cmd.Flags().StringVar(&out.page, "page", "", "show only the gangs of a restricted page")
+	cmd.Flags().StringVar(&out.token, "token", "", "token of the remote server (default: $"+sessionmixer.TokenEnv+")")
+	cmd.Flags().Float32Var(&out.scale, "scale", 0, "UI scale factor for high-DPI displays (default: from the environment or the display)")
+	cmd.Flags().IntVar(&out.maxFPS, "max-fps", 0, "cap the frame rate to save CPU/GPU (e.g. on battery)")
+	cmd.Flags().BoolVar(&out.software, "software", false, "render with the software OpenGL rasterizer (old or missing GPU drivers)")
+	cmd.RunE = out.run
+	return out
+}
+
+func (cmd *connectCommand) run(_ *cobra.Command, args []string) error {
+	token := cmd.token
+	if token == "" {
+		token = os.Getenv(sessionmixer.TokenEnv)
+	}
+
+	if err := sessionmixer.CheckDisplay(); err != nil {
+		reportNoGraphics(err)
+		return err
+	}
+	if err := sessionmixer.SetWaylandHints(nil); err != nil {
+		return err
+	}
+	sessionmixer.SetUIScale(cmd.scale)
+	sessionmixer.SetRendering(&sessionmixer.RenderingConfig{MaxFPS: cmd.maxFPS, Software: cmd.software})
+
+	client := sessionmixer.NewRemoteClient(args[0], token, cmd.page)
+	client.Start()
+	defer client.Stop()
+
+	title := "SessionMixer - " + args[0]
+	if cmd.page != "" {
+		title = "SessionMixer - " + cmd.page
+	}
+	err := runWindow(dfx.New(sessionmixer.NewRemoteMixer(client), dfx.Config{
+		Title:  title,
+		Width:  int(530 * sessionmixer.UIScale()),
+		Height: int(370 * sessionmixer.UIScale()),
+	}))
+	reportNoGraphics(err)
+	return err
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/michaelquigley/df/dl"
+	"github.com/michaelquigley/scarlettctl"
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newDaemonCommand().cmd)
+}
+
+type daemonCommand struct {
+	cmd     *cobra.Command
+	card    int
+	wait    time.Duration
+	notify  bool
+	capture string
+}
+
+func newDaemonCommand() *daemonCommand {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run headless, applying duckers and scheduled scene recalls",
+		Args:  cobra.NoArgs,
+	}
+	out := &daemonCommand{cmd: cmd}
+	cmd.Flags().IntVar(&out.card, "card", -1, "ALSA card number, overriding the config (e.g. passed by udev)")
+	cmd.Flags().DurationVar(&out.wait, "wait", 0, "wait this long for the card to appear before giving up")
+	cmd.Flags().BoolVar(&out.notify, "notify", false, "report readiness and liveness to systemd (Type=notify) and save the mix as scene '"+shutdownScene+"' on stop")
+	cmd.Flags().StringVar(&out.capture, "capture", "", "capture hardware events, gang changes and writes to a file for `sessionmixer replay`; a bare file name goes in the recordings directory")
+	cmd.RunE = out.run
+	return out
+}
+
+// shutdownScene is the scene the mix is saved to when a --notify daemon stops cleanly
+const shutdownScene = "last"
+
+func (cmd *daemonCommand) run(_ *cobra.Command, _ []string) error {
+	cfg, err := sessionmixer.LoadMainConfig()
+	if err != nil {
+		return err
+	}
+
+	if cmd.card >= 0 {
+		cfg.Card = cmd.card
+	}
+	if err := waitForCard(cfg.Card, cmd.wait); err != nil {
+		return err
+	}
+
+	if cmd.capture != "" {
+		if err := startCapture(cmd.capture); err != nil {
+			return err
+		}
+		defer stopCapture()
+	}
+
+	b, err := openBackend(cfg)
+	if err != nil {
+		return err
+	}
+	defer b.close()
+
+	if b.recovered != nil {
+		dl.Infof("restore it with 'sessionmixer apply %s'", b.recovered.Name)
+	}
+	if cfg.StartupScene != "" {
+		if err := b.scenes.Recall(cfg.StartupScene); err != nil {
+			dl.Errorf("error recalling startup scene '%s': %v", cfg.StartupScene, err)
+		} else {
+			dl.Infof("recalled startup scene '%s'", cfg.StartupScene)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var watchdog <-chan time.Time
+	if cmd.notify {
+		if ok, err := sessionmixer.SdNotify("READY=1"); err != nil {
+			dl.Errorf("error notifying systemd: %v", err)
+		} else if !ok {
+			dl.Info("not running under systemd; ignoring --notify")
+		}
+		if interval := sessionmixer.SdWatchdogInterval(); interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			watchdog = ticker.C
+		}
+	}
+
+	dl.Infof("running headless on card '%d' (%d gangs, %d duckers)", cfg.Card, len(b.gangs), len(b.duckers))
+	for {
+		select {
+		case <-ctx.Done():
+			dl.Info("shutting down")
+			if cmd.notify {
+				cmd.shutdown(b)
+			}
+			return nil
+		case <-watchdog:
+			// Only report liveness while the card is being followed; a failed event monitor
+			// lets the watchdog expire so systemd restarts the daemon
+			if !b.monitor.HasFailed() {
+				if _, err := sessionmixer.SdNotify("WATCHDOG=1"); err != nil {
+					dl.Errorf("error notifying systemd: %v", err)
+				}
+			}
+		case <-b.profiles.Requests():
+			if err := b.profiles.Apply(); err != nil {
+				dl.Error(err)
+			}
+		}
+	}
+}
+
+// shutdown tells systemd the daemon is stopping and saves the current mix so that it can be
+// recalled on the next start (startup_scene: last)
+func (cmd *daemonCommand) shutdown(b *backend) {
+	if _, err := sessionmixer.SdNotify("STOPPING=1"); err != nil {
+		dl.Errorf("error notifying systemd: %v", err)
+	}
+	if err := b.scenes.Save(shutdownScene); err != nil {
+		dl.Errorf("error saving scene '%s': %v", shutdownScene, err)
+	} else {
+		dl.Infof("saved mix as scene '%s'", shutdownScene)
+	}
+}
+
+// cardPollInterval is how often waitForCard retries opening the card
+const cardPollInterval = 500 * time.Millisecond
+
+// waitForCard retries opening the card until it succeeds or the timeout passes; a freshly
+// plugged-in device may not accept control access as soon as udev reports it
+func waitForCard(number int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		card, err := scarlettctl.OpenCard(number)
+		if err == nil {
+			card.Close()
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Wrapf(err, "error opening card '%d'", number)
+		}
+		time.Sleep(cardPollInterval)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/michaelquigley/scarlettctl"
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newDoctorCommand().cmd)
+}
+
+type doctorCommand struct {
+	cmd    *cobra.Command
+	failed int
+}
+
+func newDoctorCommand() *doctorCommand {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the card, driver, config, permissions and display environment",
+		Args:  cobra.NoArgs,
+	}
+	out := &doctorCommand{cmd: cmd}
+	cmd.RunE = out.run
+	return out
+}
+
+func (cmd *doctorCommand) run(_ *cobra.Command, _ []string) error {
+	cmd.checkDisplay()
+	cmd.checkKernel()
+
+	path, _ := sessionmixer.MainConfigPath()
+	cfg, err := sessionmixer.LoadMainConfig()
+	if err != nil {
+		cmd.fail("config", err.Error(), "create "+path+" (see example-config.yaml)")
+	} else {
+		cmd.ok("config", path+" loaded")
+	}
+
+	cmd.checkCards(cfg)
+	if cfg != nil {
+		cmd.checkPermissions(cfg.Card)
+		cmd.checkCard(cfg)
+	}
+
+	if cmd.failed > 0 {
+		return errors.Errorf("%d check(s) failed", cmd.failed)
+	}
+	return nil
+}
+
+func (cmd *doctorCommand) ok(check, detail string) {
+	fmt.Printf("[ ok ] %-12s %s\n", check, detail)
+}
+
+func (cmd *doctorCommand) warn(check, detail, hint string) {
+	fmt.Printf("[warn] %-12s %s\n", check, detail)
+	if hint != "" {
+		fmt.Printf("       %-12s -> %s\n", "", hint)
+	}
+}
+
+func (cmd *doctorCommand) fail(check, detail, hint string) {
+	cmd.failed++
+	fmt.Printf("[FAIL] %-12s %s\n", check, detail)
+	if hint != "" {
+		fmt.Printf("       %-12s -> %s\n", "", hint)
+	}
+}
+
+func (cmd *doctorCommand) checkDisplay() {
+	switch {
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		cmd.ok("display", "Wayland ("+os.Getenv("WAYLAND_DISPLAY")+")")
+	case os.Getenv("DISPLAY") != "":
+		cmd.ok("display", "X11 ("+os.Getenv("DISPLAY")+")")
+	default:
+		cmd.warn("display", "no DISPLAY or WAYLAND_DISPLAY set", "`run` needs a graphical session; use `daemon` for headless operation or `run --fallback terminal`")
+	}
+}
+
+func (cmd *doctorCommand) checkKernel() {
+	release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		cmd.warn("kernel", "unable to read kernel release: "+err.Error(), "")
+		return
+	}
+	cmd.ok("kernel", strings.TrimSpace(string(release)))
+}
+
+func (cmd *doctorCommand) checkCards(cfg *sessionmixer.Config) {
+	cards, err := scarlettctl.ListCards()
+	if err != nil {
+		cmd.fail("cards", err.Error(), "check the USB connection and that the device is powered on (`aplay -l`)")
+		return
+	}
+	var names []string
+	found := false
+	for _, card := range cards {
+		names = append(names, fmt.Sprintf("%d: %s", card.Number, card.Name))
+		found = found || (cfg != nil && card.Number == cfg.Card)
+	}
+	cmd.ok("cards", strings.Join(names, ", "))
+	if cfg != nil && !found {
+		cmd.fail("cards", fmt.Sprintf("configured card %d is not a supported device", cfg.Card), "set `card` to one of the numbers above")
+	}
+}
+
+func (cmd *doctorCommand) checkPermissions(cardNum int) {
+	path := fmt.Sprintf("/dev/snd/controlC%d", cardNum)
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		cmd.fail("permissions", err.Error(), "add your user to the `audio` group (then log out and back in)")
+		return
+	}
+	f.Close()
+	cmd.ok("permissions", path+" is readable and writable")
+}
+
+func (cmd *doctorCommand) checkCard(cfg *sessionmixer.Config) {
+	card, err := scarlettctl.OpenCard(cfg.Card)
+	if err != nil {
+		cmd.fail("card", err.Error(), "")
+		return
+	}
+	defer card.Close()
+
+	controls, err := card.GetControls()
+	if err != nil {
+		cmd.fail("driver", "unable to enumerate controls: "+err.Error(), "")
+		return
+	}
+	if firmware, err := card.FindControl("Firmware Version"); err == nil {
+		if version, err := firmware.GetValue(); err == nil {
+			cmd.ok("firmware", fmt.Sprintf("%d", version))
+		}
+	}
+	if _, err := card.GetMixerInputs(); err != nil {
+		cmd.warn("driver", fmt.Sprintf("%d controls but no mixer controls found", len(controls)),
+			"the Scarlett2 mixer driver may be disabled; on kernels before 6.7 add `options snd_usb_audio vid=0x1235 device_setup=1` to /etc/modprobe.d/scarlett.conf")
+	} else {
+		cmd.ok("driver", fmt.Sprintf("%s (%d controls)", card.Name, len(controls)))
+	}
+
+	missing := 0
+	for _, name := range configuredControls(cfg, card) {
+		resolved := sessionmixer.ResolveControlName(name)
+		if _, err := card.FindControl(resolved); err != nil {
+			label := fmt.Sprintf("'%s'", name)
+			if resolved != name {
+				label = fmt.Sprintf("'%s' (alias of '%s')", resolved, name)
+			}
+			cmd.fail("controls", label+" not found on the card", "")
+			missing++
+		}
+	}
+	if missing > 0 {
+		cmd.warn("controls", fmt.Sprintf("%d configured control(s) missing", missing), "compare with `scarlettctl list`; names are case-sensitive")
+		return
+	}
+
+	mapper := sessionmixer.NewControlMapper(card, cfg)
+	gangs, err := mapper.LoadGangs()
+	if err != nil {
+		cmd.fail("gangs", err.Error(), "")
+		return
+	}
+	for _, gang := range gangs {
+		if gang.GetMode() == sessionmixer.GangModeScaled {
+			cmd.warn("gangs", fmt.Sprintf("'%s' members have different ranges; values are scaled to each control", gang.GetName()),
+				"gang controls of the same kind to keep their values identical")
+		}
+	}
+	if _, err := mapper.LoadSwitches(); err != nil {
+		cmd.fail("switches", err.Error(), "")
+		return
+	}
+	cmd.ok("controls", "all configured controls found and valid")
+}
+
+// configuredControls returns the names of every control the config refers to
+func configuredControls(cfg *sessionmixer.Config, card *scarlettctl.Card) []string {
+	var names []string
+	gangControls := cfg.GangControls
+	if generated, err := sessionmixer.ExpandGangTemplates(cfg.GangTemplates); err == nil {
+		gangControls = append(slices.Clone(gangControls), generated...)
+	}
+	for _, gc := range gangControls {
+		names = append(names, gc.Controls...)
+		names = append(names, gc.Levels...)
+	}
+	for _, sc := range cfg.Switches {
+		if sc.Device == "" || strings.Contains(strings.ToLower(card.Name), strings.ToLower(sc.Device)) {
+			names = append(names, sc.Control)
+		}
+	}
+	for _, dc := range cfg.Duckers {
+		names = append(names, dc.Trigger)
+	}
+	for _, gc := range cfg.Gates {
+		names = append(names, gc.Trigger)
+	}
+	if cfg.GainStaging != nil {
+		for _, level := range cfg.GainStaging.Levels {
+			names = append(names, level.Level)
+		}
+	}
+	if cfg.Polling != nil {
+		for name := range cfg.Polling.Controls {
+			names = append(names, name)
+		}
+	}
+	return names
+}
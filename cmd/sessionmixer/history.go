@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newHistoryCommand().cmd)
+}
+
+type historyCommand struct {
+	cmd     *cobra.Command
+	control string
+	since   time.Duration
+	revert  string
+	fade    time.Duration
+}
+
+func newHistoryCommand() *historyCommand {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show the timeline of gang changes from the audit log, or revert the mix to an earlier time",
+		Args:  cobra.NoArgs,
+	}
+	out := &historyCommand{cmd: cmd}
+	cmd.Flags().StringVar(&out.control, "control", "", "only show gangs whose name contains this (case-insensitive)")
+	cmd.Flags().DurationVar(&out.since, "since", 0, "only show changes made within this time (e.g. 10m)")
+	cmd.Flags().StringVar(&out.revert, "revert", "", "revert the logged gangs to their values at this time (15:04, 15:04:05, RFC3339, or a duration ago like 10m)")
+	cmd.Flags().DurationVar(&out.fade, "fade", 0, "fade to the reverted values over this time")
+	cmd.RunE = out.run
+	return out
+}
+
+func (cmd *historyCommand) run(_ *cobra.Command, _ []string) error {
+	if cmd.since < 0 || cmd.fade < 0 {
+		return errors.New("--since and --fade must not be negative")
+	}
+	cfg, err := sessionmixer.LoadMainConfig()
+	if err != nil {
+		return err
+	}
+	path, err := sessionmixer.AuditLogPath()
+	if err != nil {
+		return err
+	}
+	entries, err := sessionmixer.ReadAudit(path)
+	if err != nil {
+		return err
+	}
+
+	if cmd.revert != "" {
+		at, err := parseHistoryTime(cmd.revert, time.Now())
+		if err != nil {
+			return err
+		}
+		return cmd.revertTo(cfg, entries, at)
+	}
+
+	var from time.Time
+	if cmd.since > 0 {
+		from = time.Now().Add(-cmd.since)
+	}
+	shown := 0
+	for _, entry := range entries {
+		if entry.Time.Before(from) || !cmd.matches(entry) {
+			continue
+		}
+		stamp := entry.Time.Local().Format("2006-01-02 15:04:05")
+		if entry.Scene != "" {
+			fmt.Printf("%s  scene '%s' recalled  (%s)\n", stamp, entry.Scene, entry.Source)
+		} else {
+			fmt.Printf("%s  %-20s %s -> %s  (%s)\n", stamp, entry.Gang, historyValue(entry.FromText, entry.From), historyValue(entry.ToText, entry.To), entry.Source)
+		}
+		shown++
+	}
+	if shown == 0 {
+		fmt.Printf("no matching changes in '%s'\n", path)
+	}
+	return nil
+}
+
+// matches reports whether an entry passes the --control filter; scene recalls are shown only
+// without one
+func (cmd *historyCommand) matches(entry sessionmixer.AuditEntry) bool {
+	if cmd.control == "" {
+		return true
+	}
+	return entry.Gang != "" && strings.Contains(strings.ToLower(entry.Gang), strings.ToLower(cmd.control))
+}
+
+// revertTo stores the values the logged gangs held at the given time as a scene and recalls it
+func (cmd *historyCommand) revertTo(cfg *sessionmixer.Config, entries []sessionmixer.AuditEntry, at time.Time) error {
+	values := sessionmixer.AuditValuesAt(entries, at)
+	for name := range values {
+		if !cmd.matches(sessionmixer.AuditEntry{Gang: name}) {
+			delete(values, name)
+		}
+	}
+	if len(values) == 0 {
+		fmt.Printf("no logged changes since %s; nothing to revert\n", at.Format("2006-01-02 15:04:05"))
+		return nil
+	}
+
+	scenesDir, err := sessionmixer.ScenesDir()
+	if err != nil {
+		return err
+	}
+	scene := &sessionmixer.Scene{Name: "revert-" + at.Format("20060102-150405"), Gangs: values}
+	if err := sessionmixer.NewSceneManager(scenesDir, nil, nil).Store(scene); err != nil {
+		return err
+	}
+	fmt.Printf("stored %d gang value(s) from %s as scene '%s'\n", len(values), at.Format("2006-01-02 15:04:05"), scene.Name)
+	return applyScene(cfg, scene.Name, cmd.fade)
+}
+
+// historyValue prefers the formatted value logged with a change over the raw one
+func historyValue(text string, raw int64) string {
+	if text != "" {
+		return text
+	}
+	return fmt.Sprint(raw)
+}
+
+// parseHistoryTime parses a clock time today, a full timestamp, or a duration before now
+func parseHistoryTime(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, errors.New("--revert duration must not be negative")
+		}
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.Local), nil
+		}
+	}
+	return time.Time{}, errors.Errorf("unrecognized time '%s'", value)
+}
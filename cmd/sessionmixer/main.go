@@ -3,14 +3,17 @@ package main
 import (
 	"context"
 	"log/slog"
+	"os"
 
 	"github.com/charmbracelet/fang"
 	"github.com/michaelquigley/df/dl"
+	"github.com/michaelquigley/sessionmixer"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	dl.Init(dl.DefaultOptions().SetLevel(slog.LevelInfo).SetTrimPrefix("github.com/michaelquigley/"))
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "configuration file (default: $"+sessionmixer.ConfigEnv+" or ~/.config/sessionmixer/session.yaml)")
 }
 
 var rootCmd = &cobra.Command{
@@ -19,12 +22,17 @@ var rootCmd = &cobra.Command{
 		if verbose {
 			dl.Init(dl.DefaultOptions().SetLevel(slog.LevelDebug).SetTrimPrefix("github.com/michaelquigley/"))
 		}
+		if configPath != "" {
+			sessionmixer.SetMainConfigPath(configPath)
+		}
 	},
 }
 var verbose bool
+var configPath string
 
 func main() {
 	if err := fang.Execute(context.Background(), rootCmd, fang.WithoutManpage(), fang.WithoutCompletions(), fang.WithoutVersion()); err != nil {
-		dl.Fatal(err)
+		dl.Error(err)
+		os.Exit(sessionmixer.ExitCode(err))
 	}
 }
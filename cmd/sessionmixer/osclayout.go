@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/michaelquigley/scarlettctl"
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newOSCLayoutCommand().cmd)
+}
+
+type oscLayoutCommand struct {
+	cmd  *cobra.Command
+	host string
+}
+
+func newOSCLayoutCommand() *oscLayoutCommand {
+	cmd := &cobra.Command{
+		Use:   "osc-layout [file]",
+		Short: "Generate an Open Stage Control layout for the OSC server (a fader and mute per gang, a button per scene)",
+		Args:  cobra.MaximumNArgs(1),
+	}
+	out := &oscLayoutCommand{cmd: cmd}
+	cmd.Flags().StringVar(&out.host, "host", "", "host the layout sends to (default: the osc listen host, or 127.0.0.1 for a wildcard address)")
+	cmd.RunE = out.run
+	return out
+}
+
+func (cmd *oscLayoutCommand) run(_ *cobra.Command, args []string) error {
+	cfg, err := sessionmixer.LoadMainConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.OSC == nil {
+		return errors.New("no osc server is configured (add an 'osc' section with a listen address)")
+	}
+	host, port, err := net.SplitHostPort(cfg.OSC.Listen)
+	if err != nil {
+		return errors.Wrapf(err, "invalid osc listen address '%s'", cfg.OSC.Listen)
+	}
+	if cmd.host != "" {
+		host = cmd.host
+	} else if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+
+	// The gangs are numbered in bank order, so they are loaded the way the mixer loads them
+	card, err := scarlettctl.OpenCard(cfg.Card)
+	if err != nil {
+		return errors.Wrapf(err, "error opening card '%d'", cfg.Card)
+	}
+	defer card.Close()
+	gangs, err := sessionmixer.NewControlMapper(card, cfg).LoadGangs()
+	if err != nil {
+		return errors.Wrap(err, "error loading gangs")
+	}
+	scenesDir, err := sessionmixer.ScenesDir()
+	if err != nil {
+		return err
+	}
+	scenes, err := sessionmixer.NewSceneManager(scenesDir, nil, nil).List()
+	if err != nil {
+		return errors.Wrap(err, "error listing scenes")
+	}
+
+	layout, err := sessionmixer.OSCLayout(gangs, scenes, net.JoinHostPort(host, port))
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		fmt.Println(string(layout))
+		return nil
+	}
+	if err := os.WriteFile(args[0], layout, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %d gangs and %d scenes to '%s'\n", len(gangs), len(scenes), args[0])
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/michaelquigley/df/dl"
+	"github.com/michaelquigley/scarlettctl"
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newPanicCommand().cmd)
+}
+
+type panicCommand struct {
+	cmd     *cobra.Command
+	restore bool
+}
+
+func newPanicCommand() *panicCommand {
+	cmd := &cobra.Command{
+		Use:   "panic",
+		Short: "Fade the output gangs to silence (e.g. on feedback), or restore them",
+		Args:  cobra.NoArgs,
+	}
+	out := &panicCommand{cmd: cmd}
+	cmd.Flags().BoolVar(&out.restore, "restore", false, "fade the output gangs back to their levels before the panic")
+	cmd.RunE = out.run
+	return out
+}
+
+func (cmd *panicCommand) run(_ *cobra.Command, _ []string) error {
+	cfg, err := sessionmixer.LoadMainConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.Panic == nil {
+		return errors.New("panic is not configured")
+	}
+
+	var args []string
+	if cmd.restore {
+		args = append(args, "restore")
+	}
+	path, err := sessionmixer.IPCSocketPath()
+	if err != nil {
+		return err
+	}
+	result, err := sessionmixer.SendIPCTimeout(path, time.Minute, "panic", args...)
+	if err == nil {
+		fmt.Println(result)
+		return nil
+	}
+	if !errors.Is(err, sessionmixer.ErrNoInstance) {
+		return err
+	}
+	if cmd.restore {
+		return errors.Wrap(err, "the levels before the panic are held by the running mixer")
+	}
+
+	dl.Debugf("no running mixer (%v); silencing on card '%d' directly", err, cfg.Card)
+	card, err := scarlettctl.OpenCard(cfg.Card)
+	if err != nil {
+		return errors.Wrapf(err, "error opening card '%d'", cfg.Card)
+	}
+	defer card.Close()
+	gangs, err := sessionmixer.NewControlMapper(card, cfg).LoadGangs()
+	if err != nil {
+		return errors.Wrap(err, "error loading gangs")
+	}
+	p, err := sessionmixer.NewPanic(*cfg.Panic, gangs)
+	if err != nil {
+		return err
+	}
+	if err := p.Engage(); err != nil {
+		return err
+	}
+	fmt.Println("silenced the output gangs")
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newProfileCommand().cmd)
+}
+
+type profileCommand struct {
+	cmd *cobra.Command
+}
+
+func newProfileCommand() *profileCommand {
+	cmd := &cobra.Command{
+		Use:   "profile [name]",
+		Short: "Switch the running mixer to a gang profile, or list profiles",
+		Args:  cobra.MaximumNArgs(1),
+	}
+	out := &profileCommand{cmd: cmd}
+	cmd.RunE = out.run
+	return out
+}
+
+func (cmd *profileCommand) run(_ *cobra.Command, args []string) error {
+	// Apply the storage overrides, which may move the socket into the state directory
+	_, _ = sessionmixer.LoadMainConfig()
+	path, err := sessionmixer.IPCSocketPath()
+	if err != nil {
+		return err
+	}
+	result, err := sessionmixer.SendIPC(path, "profile", args...)
+	if err != nil {
+		return err
+	}
+	fmt.Println(result)
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newReplayCommand().cmd)
+}
+
+type replayCommand struct {
+	cmd     *cobra.Command
+	verbose bool
+}
+
+func newReplayCommand() *replayCommand {
+	cmd := &cobra.Command{
+		Use:   "replay <file>",
+		Short: "Replay a capture (run/daemon --capture) through the mixer logic without the hardware",
+		Args:  cobra.ExactArgs(1),
+	}
+	out := &replayCommand{cmd: cmd}
+	cmd.Flags().BoolVarP(&out.verbose, "verbose", "v", false, "print every replayed record and the writes it caused")
+	cmd.RunE = out.run
+	return out
+}
+
+func (cmd *replayCommand) run(_ *cobra.Command, args []string) error {
+	path, err := sessionmixer.RecordingPath(args[0])
+	if err != nil {
+		return err
+	}
+
+	var start time.Time
+	result, err := sessionmixer.Replay(path, func(step sessionmixer.ReplayStep) {
+		if start.IsZero() {
+			start = step.Record.Time
+		}
+		if !cmd.verbose {
+			return
+		}
+		record := step.Record
+		offset := fmt.Sprintf("%6d  +%.3fs", step.Line, record.Time.Sub(start).Seconds())
+		switch record.Type {
+		case sessionmixer.CaptureTypeGangs:
+			fmt.Printf("%s  gangs   %d gangs\n", offset, len(record.Gangs))
+		case sessionmixer.CaptureTypeEvent:
+			fmt.Printf("%s  event   %s = %d\n", offset, record.Control, record.Value)
+		case sessionmixer.CaptureTypeChange:
+			fmt.Printf("%s  change  %s = %d\n", offset, record.Gang, record.Value)
+		case sessionmixer.CaptureTypeWrite:
+			fmt.Printf("%s  write   %s = %d (recorded)\n", offset, record.Control, record.Value)
+		}
+		if step.Err != nil {
+			fmt.Printf("%s  error   %v\n", strings.Repeat(" ", len(offset)), step.Err)
+		}
+		for _, write := range step.Writes {
+			fmt.Printf("%s  -> %s = %d\n", strings.Repeat(" ", len(offset)), write.Control, write.Value)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d events, %d changes; %d writes recorded, %d replayed\n", result.Events, result.Changes, result.Recorded, result.Replayed)
+	if result.Mismatch != "" {
+		return errors.Errorf("replay diverged from the capture: %s", result.Mismatch)
+	}
+	fmt.Println("replay matches the capture")
+	return nil
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"time"
+
 	"github.com/michaelquigley/sessionmixer"
+	"github.com/michaelquigley/df/dl"
 	"github.com/michaelquigley/dfx"
-	"github.com/michaelquigley/scarlettctl"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -13,7 +15,17 @@ func init() {
 }
 
 type runCommand struct {
-	cmd *cobra.Command
+	cmd            *cobra.Command
+	record         string
+	recordInterval time.Duration
+	page           string
+	readOnly       bool
+	capture        string
+	announce       bool
+	scale          float32
+	maxFPS         int
+	software       bool
+	fallback       string
 }
 
 func newRunCommand() *runCommand {
@@ -23,6 +35,16 @@ func newRunCommand() *runCommand {
 		Args:  cobra.NoArgs,
 	}
 	out := &runCommand{cmd: cmd}
+	cmd.Flags().StringVar(&out.record, "record", "", "record gang values and peak levels to a CSV (or .json) file; a bare file name goes in the recordings directory")
+	cmd.Flags().DurationVar(&out.recordInterval, "record-interval", time.Second, "interval between recorded samples")
+	cmd.Flags().StringVar(&out.page, "page", "", "show only the gangs of a restricted page, hiding and locking everything else")
+	cmd.Flags().BoolVar(&out.readOnly, "read-only", false, "show values and meters but refuse every write to the hardware (e.g. a monitor screen)")
+	cmd.Flags().StringVar(&out.capture, "capture", "", "capture hardware events, gang changes and writes to a file for `sessionmixer replay`; a bare file name goes in the recordings directory")
+	cmd.Flags().BoolVar(&out.announce, "announce", false, "announce the focused gang and its value changes for screen reader users (speech command from the announce config, else printed)")
+	cmd.Flags().Float32Var(&out.scale, "scale", 0, "UI scale factor for high-DPI displays, overriding ui_scale (default: from the environment or the display)")
+	cmd.Flags().IntVar(&out.maxFPS, "max-fps", 0, "cap the frame rate to save CPU/GPU (e.g. on battery), overriding rendering.max_fps")
+	cmd.Flags().BoolVar(&out.software, "software", false, "render with the software OpenGL rasterizer (old or missing GPU drivers)")
+	cmd.Flags().StringVar(&out.fallback, "fallback", "", "when the window cannot be opened: 'terminal' keeps running and prints gang changes, overriding rendering.fallback")
 	cmd.RunE = out.run
 	return out
 }
@@ -33,29 +55,222 @@ func (cmd *runCommand) run(_ *cobra.Command, _ []string) error {
 		return err
 	}
 
-	card, err := scarlettctl.OpenCard(cfg.Card)
+	if cmd.scale > 0 {
+		sessionmixer.SetUIScale(cmd.scale)
+	}
+	if cmd.software {
+		sessionmixer.UseSoftwareRendering()
+	}
+	fallback := cmd.fallback
+	if fallback == "" && cfg.Rendering != nil {
+		fallback = cfg.Rendering.Fallback
+	}
+	if fallback != sessionmixer.FallbackNone && fallback != sessionmixer.FallbackTerminal {
+		return errors.Errorf("unknown fallback '%s' (expected '%s')", fallback, sessionmixer.FallbackTerminal)
+	}
+	// Without a display there is no point in opening the hardware, unless it keeps running
+	noDisplay := sessionmixer.CheckDisplay()
+	if noDisplay != nil && fallback != sessionmixer.FallbackTerminal {
+		reportNoGraphics(noDisplay)
+		return noDisplay
+	}
+	if err := sessionmixer.SetWaylandHints(cfg.Wayland); err != nil {
+		return errors.Wrap(err, "error applying wayland hints")
+	}
+	if cmd.maxFPS > 0 {
+		rendering := sessionmixer.RenderingConfig{MaxFPS: cmd.maxFPS}
+		if cfg.Rendering != nil {
+			rendering.Vsync = cfg.Rendering.Vsync
+		}
+		sessionmixer.SetRendering(&rendering)
+	}
+
+	// Set before opening the backend, which leaves out everything that writes on its own
+	sessionmixer.SetObserver(cmd.readOnly)
+
+	if cmd.capture != "" {
+		if err := startCapture(cmd.capture); err != nil {
+			return err
+		}
+		defer stopCapture()
+	}
+
+	b, err := openBackend(cfg)
 	if err != nil {
-		return errors.Wrapf(err, "error opening card '%d'", cfg.Card)
+		return err
+	}
+	defer b.close()
+	gangs := b.gangs
+
+	sampler := sessionmixer.NewHistorySampler(gangs)
+	sampler.Start()
+	defer sampler.Stop()
+
+	readouts := sessionmixer.NewReadoutPoller(gangs)
+	b.profiles.OnSwitch(sampler.SetGangs)
+	b.profiles.OnSwitch(readouts.SetGangs)
+	if readouts.HasReadouts() || b.profiles.HasProfiles() {
+		readouts.Start()
+		defer readouts.Stop()
+	}
+
+	if cmd.record != "" {
+		recordPath, err := sessionmixer.RecordingPath(cmd.record)
+		if err != nil {
+			return err
+		}
+		recorder, err := sessionmixer.NewRecorder(recordPath, cmd.recordInterval, gangs)
+		if err != nil {
+			return errors.Wrap(err, "error starting recorder")
+		}
+		recorder.Start()
+		defer func() {
+			if err := recorder.Stop(); err != nil {
+				dl.Error(err)
+			}
+		}()
 	}
-	defer card.Close()
 
-	mapper := sessionmixer.NewControlMapper(card, cfg)
-	gangs, err := mapper.LoadGangs()
+	clipPath, err := sessionmixer.ClipReportPath()
 	if err != nil {
-		return errors.Wrap(err, "error loading gangs")
+		return err
 	}
+	clips := sessionmixer.NewClipLog(gangs, cfg.ClipThresholdDb)
+	clips.Start()
+	defer func() {
+		clips.Stop()
+		if err := clips.Save(clipPath); err != nil {
+			dl.Error(err)
+		}
+	}()
 
-	monitor := sessionmixer.NewEventMonitor(card, gangs)
-	if err := monitor.Start(); err != nil {
-		return errors.Wrap(err, "error starting event monitor")
+	displayPath, err := sessionmixer.DisplayPrefsPath()
+	if err != nil {
+		return err
+	}
+	display, err := sessionmixer.LoadDisplayPrefs(displayPath)
+	if err != nil {
+		return err
 	}
-	defer monitor.Stop()
 
-	mixer := sessionmixer.NewSessionMixer(card, cfg, gangs)
-	app := dfx.New(mixer, dfx.Config{
-		Title:  "SessionMixer",
-		Width:  530,
-		Height: 370,
-	})
-	return app.Run()
+	mixer := sessionmixer.NewSessionMixer(b.card, cfg, gangs)
+	mixer.SetDisplayPrefs(display, displayPath)
+	cfgPath, err := sessionmixer.MainConfigPath()
+	if err != nil {
+		return err
+	}
+	if cmd.page != "" {
+		page, err := sessionmixer.FindPage(cfg, cmd.page, gangs)
+		if err != nil {
+			return err
+		}
+		mixer.SetPage(page)
+	} else if !cmd.readOnly {
+		picker, err := sessionmixer.NewGangPicker(b.card, cfg, cfgPath)
+		if err != nil {
+			return errors.Wrap(err, "error listing controls")
+		}
+		mixer.SetGangPicker(picker)
+		mixer.SetConfigEditor(sessionmixer.NewConfigEditor(b.card, cfg, cfgPath))
+	}
+	mixer.SetMonitor(b.monitor)
+	mixer.SetProfiles(b.profiles)
+	windowPath, err := sessionmixer.WindowPrefsPath()
+	if err != nil {
+		return err
+	}
+	window, err := sessionmixer.LoadWindowPrefs(windowPath)
+	if err != nil {
+		return err
+	}
+	mixer.SetWindowPrefs(window, windowPath)
+	if cfg.Wayland != nil {
+		mixer.SetDocked(cfg.Wayland.Dock)
+	}
+	defer func() {
+		if err := mixer.SaveWindowGeometry(); err != nil {
+			dl.Error(err)
+		}
+	}()
+	b.profiles.OnSwitch(mixer.SetGangs)
+	mixer.SetInputPanel(b.inputs)
+	mixer.SetRoutingPanel(b.routing)
+	mixer.SetSceneManager(b.scenes)
+	mixer.SetBackups(b.backups)
+	mixer.SetStatusBar(b.status)
+	mixer.SetSwitches(b.switches)
+	mixer.SetClipLog(clips)
+	mixer.SetSurface(b.surface)
+	mixer.SetCoughs(b.coughs)
+	mixer.SetPanic(b.panic)
+	if b.idle != nil {
+		mixer.SetIdleDimmer(b.idle)
+	}
+	if cfg.Announce != nil || cmd.announce {
+		config := sessionmixer.AnnounceConfig{}
+		if cfg.Announce != nil {
+			config = *cfg.Announce
+		}
+		announcer := sessionmixer.NewAnnouncer(config)
+		announcer.Start()
+		defer announcer.Stop()
+		mixer.SetAnnouncer(announcer)
+	}
+	mixer.SetAutogainRunner(sessionmixer.NewAutogainRunner(b.inputs))
+	if cfg.Listen != nil {
+		listen, err := sessionmixer.NewListenBus(b.card, cfg.Listen, gangs)
+		if err != nil {
+			return errors.Wrap(err, "error configuring listen bus")
+		}
+		defer func() {
+			if err := listen.Clear(); err != nil {
+				dl.Error(err)
+			}
+		}()
+		b.profiles.OnSwitch(listen.SetGangs)
+		mixer.SetListenBus(listen)
+	}
+	if cfg.GainStaging != nil && b.inputs.HasInputs() {
+		stager, err := sessionmixer.NewGainStager(cfg.GainStaging, b.card, b.inputs)
+		if err != nil {
+			return errors.Wrap(err, "error configuring gain staging")
+		}
+		mixer.SetGainStager(stager)
+	}
+	if cmd.page == "" && !cmd.readOnly {
+		// Keybindings and the restore offer reach gangs, scenes and profiles outside a restricted page
+		if err := mixer.SetKeybindings(cfg.Keybindings); err != nil {
+			return errors.Wrap(err, "error loading keybindings")
+		}
+		mixer.SetRecovered(b.recovered)
+	}
+	width, height := int(530*sessionmixer.UIScale()), int(370*sessionmixer.UIScale())
+	if geometry, ok := window.Get(sessionmixer.DefaultProfile); ok {
+		width, height = geometry.Width, geometry.Height
+	}
+	title := "SessionMixer"
+	if cmd.page != "" {
+		title += " - " + cmd.page
+	}
+	if cmd.readOnly {
+		title += " (read-only)"
+	}
+	err = noDisplay
+	if err == nil {
+		err = runWindow(dfx.New(mixer, dfx.Config{
+			Title:  title,
+			Width:  width,
+			Height: height,
+		}))
+	}
+	var graphicsErr *sessionmixer.ErrNoGraphics
+	if !errors.As(err, &graphicsErr) {
+		return err
+	}
+	if fallback != sessionmixer.FallbackTerminal {
+		reportNoGraphics(err)
+		return err
+	}
+	dl.Errorf("%v; falling back to the terminal", err)
+	return runTerminal(gangs)
 }
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newSchemaCommand().cmd)
+}
+
+type schemaCommand struct {
+	cmd *cobra.Command
+}
+
+func newSchemaCommand() *schemaCommand {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print a JSON Schema for the configuration file",
+		Args:  cobra.NoArgs,
+	}
+	out := &schemaCommand{cmd: cmd}
+	cmd.RunE = out.run
+	return out
+}
+
+func (cmd *schemaCommand) run(_ *cobra.Command, _ []string) error {
+	data, err := json.MarshalIndent(sessionmixer.ConfigSchema(), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "error encoding schema")
+	}
+	fmt.Println(string(data))
+	return nil
+}
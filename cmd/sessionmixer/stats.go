@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newStatsCommand().cmd)
+}
+
+type statsCommand struct {
+	cmd  *cobra.Command
+	addr string
+}
+
+func newStatsCommand() *statsCommand {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show write and event latency statistics of a running mixer (requires the debug listener)",
+		Args:  cobra.NoArgs,
+	}
+	out := &statsCommand{cmd: cmd}
+	cmd.Flags().StringVar(&out.addr, "addr", "", "debug listener address (default: debug.listen from the config)")
+	cmd.RunE = out.run
+	return out
+}
+
+func (cmd *statsCommand) run(_ *cobra.Command, _ []string) error {
+	addr := cmd.addr
+	if addr == "" {
+		cfg, err := sessionmixer.LoadMainConfig()
+		if err != nil {
+			return err
+		}
+		if cfg.Debug == nil {
+			return errors.New("no debug listener configured; set debug.listen or pass --addr")
+		}
+		addr = cfg.Debug.Listen
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(fmt.Sprintf("http://%s/debug/stats", addr))
+	if err != nil {
+		return errors.Wrapf(err, "error contacting the debug listener at '%s'", addr)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("debug listener returned %s", resp.Status)
+	}
+	var snapshot sessionmixer.StatsSnapshot
+	if err := json.NewDecoder(resp.Body).Decode(&snapshot); err != nil {
+		return errors.Wrap(err, "error decoding stats")
+	}
+
+	fmt.Printf("uptime %s, %d events (%.1f/s), %d writes (%.1f/s), frame %s (max %s)\n\n",
+		snapshot.Uptime, snapshot.Events, snapshot.EventsPerSecond, snapshot.Writes, snapshot.WritesPerSecond,
+		snapshot.FrameTime, snapshot.FrameTimeMax)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CONTROL\tWRITES\tP50\tP95\tP99\tMAX\tROUND TRIPS\tRT P50\tRT P95\tRT P99")
+	for _, cs := range snapshot.Controls {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n", cs.Control,
+			cs.Write.Count, cs.Write.P50, cs.Write.P95, cs.Write.P99, cs.Write.Max,
+			cs.RoundTrip.Count, cs.RoundTrip.P50, cs.RoundTrip.P95, cs.RoundTrip.P99)
+	}
+	return w.Flush()
+}
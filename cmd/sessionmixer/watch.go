@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/michaelquigley/scarlettctl"
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newWatchCommand().cmd)
+}
+
+type watchCommand struct {
+	cmd      *cobra.Command
+	json     bool
+	levels   bool
+	interval time.Duration
+}
+
+func newWatchCommand() *watchCommand {
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Print gang value changes (and optionally signal levels) as they happen",
+		Args:  cobra.NoArgs,
+	}
+	out := &watchCommand{cmd: cmd}
+	cmd.Flags().BoolVar(&out.json, "json", false, "print one JSON object per line")
+	cmd.Flags().BoolVar(&out.levels, "levels", false, "include signal level changes of gangs with levels")
+	cmd.Flags().DurationVar(&out.interval, "interval", 100*time.Millisecond, "sampling interval")
+	cmd.RunE = out.run
+	return out
+}
+
+func (cmd *watchCommand) run(_ *cobra.Command, _ []string) error {
+	if cmd.interval <= 0 {
+		return errors.New("--interval must be positive")
+	}
+	cfg, err := sessionmixer.LoadMainConfig()
+	if err != nil {
+		return err
+	}
+
+	// Watches the card directly, alongside a running mixer if there is one
+	card, err := scarlettctl.OpenCard(cfg.Card)
+	if err != nil {
+		return errors.Wrapf(err, "error opening card '%d'", cfg.Card)
+	}
+	defer card.Close()
+	gangs, err := sessionmixer.NewControlMapper(card, cfg).LoadGangs()
+	if err != nil {
+		return errors.Wrap(err, "error loading gangs")
+	}
+	monitor := sessionmixer.NewEventMonitor(card, gangs)
+	if cfg.Polling != nil {
+		if err := monitor.SetPolling(cfg.Polling); err != nil {
+			return errors.Wrap(err, "error configuring control polling")
+		}
+	}
+	if err := monitor.Start(); err != nil {
+		return errors.Wrap(err, "error starting event monitor")
+	}
+	defer monitor.Stop()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	encoder := json.NewEncoder(os.Stdout)
+	sessionmixer.WatchGangs(gangs, cmd.interval, cmd.levels, ctx.Done(), func(event sessionmixer.WatchEvent) {
+		if cmd.json {
+			encoder.Encode(event)
+			return
+		}
+		printWatchEvent(event)
+	})
+	return nil
+}
+
+// printWatchEvent prints a watch event as a line of text
+func printWatchEvent(event sessionmixer.WatchEvent) {
+	timestamp := event.Time.Format("15:04:05.000")
+	switch event.Type {
+	case "value":
+		muted := ""
+		if event.Muted {
+			muted = " (muted)"
+		}
+		fmt.Printf("%s  %-20s %s%s\n", timestamp, event.Gang, event.Text, muted)
+	case "level":
+		fmt.Printf("%s  %-20s level %.1f dBFS\n", timestamp, event.Gang, *event.LevelDb)
+	}
+}
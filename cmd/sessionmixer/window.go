@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/michaelquigley/df/dl"
+	"github.com/michaelquigley/dfx"
+	"github.com/michaelquigley/sessionmixer"
+	"github.com/pkg/errors"
+)
+
+// runWindow runs the window's event loop; the backend panics when it cannot create the window
+// or its GL context, which is returned as ErrNoGraphics (a panic after the first frame is a bug
+// and is not hidden)
+func runWindow(app *dfx.App) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if sessionmixer.Stats.Snapshot().Frames > 0 {
+				panic(r)
+			}
+			err = &sessionmixer.ErrNoGraphics{Cause: fmt.Errorf("%v", r)}
+		}
+	}()
+	return app.Run()
+}
+
+// reportNoGraphics logs what to try when the window cannot be opened
+func reportNoGraphics(err error) {
+	var graphicsErr *sessionmixer.ErrNoGraphics
+	if !errors.As(err, &graphicsErr) {
+		return
+	}
+	for _, hint := range sessionmixer.GraphicsHints() {
+		dl.Infof("hint: %s", hint)
+	}
+}
+
+// runTerminal keeps a mixer whose window could not be opened running in the terminal: the
+// backend (duckers, schedule, control surface, remote server) stays up and gang changes are
+// printed until interrupted
+func runTerminal(gangs []*sessionmixer.GangedFader) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dl.Infof("running in the terminal (%d gangs); Ctrl-C to quit", len(gangs))
+	sessionmixer.WatchGangs(gangs, 100*time.Millisecond, false, ctx.Done(), printWatchEvent)
+	return nil
+}
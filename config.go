@@ -1,34 +1,485 @@
 package sessionmixer
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/michaelquigley/df/dd"
 )
 
 type Config struct {
-	Card         int `dd:"+required"`
-	GangControls []GangControl
+	Version         int // Schema version (see ConfigVersion); older configs are migrated on load
+	Card            int `dd:"+required"`
+	GangControls    []GangControl
+	GangTemplates   []GangTemplate // Gangs generated for every input and mix, appended to gang_controls
+	Profiles        []Profile      // Alternative gang sets, switched at runtime (the top-level gangs are "default")
+	Pages           []PageConfig   // Restricted views exposing a subset of gangs (run --page)
+	Switches        []SwitchControl
+	Duckers         []DuckerControl
+	Gates           []GateControl      // Mute gangs (e.g. an open mic's cue sends) while a level stays below a threshold
+	Coughs          []CoughControl     // Momentary mutes held from a key or MIDI note
+	PhantomSafety   *PhantomSafety     // Optional interlock applied when toggling phantom power from the UI
+	Protection      *ProtectionConfig  // Optional ceiling for output/monitor gangs
+	Panic           *PanicConfig       // Optional emergency mute of the output gangs
+	IdleDim         *IdleDimConfig     // Optional dimming of the monitors after a time without activity
+	Surface         *SurfaceConfig     // Optional MIDI control surface
+	InputDevices    []EvdevConfig      // Gamepads and footswitches whose buttons and axes trigger actions
+	OBS             *OBSConfig         // Optional OBS link over obs-websocket
+	Remote          *RemoteConfig      // Optional server for client-mode instances (connect)
+	OSC             *OSCConfig         // Optional OSC server for TouchOSC, Open Stage Control and similar (osc-layout)
+	WritePolicy     *WritePolicyConfig // Optional arbitration between the UI, surfaces, OSC and remote clients writing the same gang
+	GainStaging     *GainStagingConfig // Optional gain staging assistant
+	InputLinks      []InputLink        // Linked preamp gain groups (stereo pairs)
+	Listen          *ListenConfig      // Optional PFL/AFL listen bus emulation
+	Units           []UnitConfig       // Custom display units for gangs
+	Polling         *PollingConfig     // Optional control polling where hardware events are unavailable
+	Debug           *DebugConfig       // Optional debug HTTP listener (pprof, goroutine dumps, stats)
+	Storage         *StorageConfig     // Optional data and state directory overrides
+	ClipThresholdDb float32            // Level (dBFS) at or above which a meter counts as clipping (default 0)
+	Aliases         map[string]string  // Friendly name -> exact ALSA control name, usable wherever a control is named
+	Announce        *AnnounceConfig    // Optional screen reader announcements of the focused gang and its value
+	Rendering       *RenderingConfig   // Optional frame rate cap and vsync
+	Wayland         *WaylandConfig     // Optional window identity and docked strip for Wayland compositors
+	Backups         *BackupConfig      // Optional periodic snapshots of the mix, kept as a ring of backups
+	StageChanges    bool               // Start with fader changes in the UI staged until applied (toggleable in the UI)
+	UIScale         float32            // UI scale factor for high-DPI displays (0 = from the environment or the display)
+	Locale          string             // UI language (e.g. "de"); empty = from the environment (LC_ALL, LC_MESSAGES, LANG)
+	LevelOffsets    map[string]float32 // Level control name -> meter calibration offset (dB)
+	LevelEvents     bool               // Feed meters from hardware events instead of polling (drivers that emit meter events)
+	LockControls    bool               // Lock the gang and switch controls against writes from other applications while running
+	Keybindings     []Keybinding       // Keyboard shortcuts for mixer actions
+	StartupScene    string             // Scene recalled when the daemon starts (e.g. when udev starts it on device connect)
+	Schedule        []ScheduleEntry    // Time-based scene recalls
+	ScheduleFile    string             // Optional YAML file with additional schedule entries
 }
 
 type GangControl struct {
-	Name     string   `dd:"+required"`
-	Controls []string `dd:"+required"`
+	Name        string   `dd:"+required"`
+	Controls    []string `dd:"+required"`
+	Unit        string
+	TaperDb     float32       // If > 0, use DecibelTaper(TaperDb); otherwise LinearTaper
+	Levels      []string      // Optional level control names for signal indication
+	PostLevels  []string      // Optional mix output level controls, metered instead of levels when "post" is selected
+	MeterSource string        // Optional: "pre" (default; levels) or "post" (post_levels), toggleable in the UI
+	Meter       *MeterConfig  // Optional meter range and color transitions for the levels
+	Description string        // Optional notes shown as a tooltip and in the gang details popup
+	DefaultDb   *float32      // Optional value (dB) for "reset to default"; unity (0 dB) if omitted, not allowed for raw gangs
+	Tags        []string      // Optional tags (e.g. "drums", "cue1") used for filtering and tag views
+	Safe        bool          // Exclude from scene recalls and mass operations (toggleable in the UI)
+	Display     string        // Optional: meter | readout makes a read-only display channel (no fader)
+	MaxJumpDb   float32       // Optional: changes larger than this (dB) in one action need confirmation or are ramped
+	JumpRamp    time.Duration // Optional: ramp guarded jumps over this time instead of asking for confirmation
+	External    string        // Optional: follow (default) | ignore | prompt when the controls are changed outside sessionmixer
+}
+
+// MeterConfig sets a gang meter's range and color transitions
+type MeterConfig struct {
+	RangeDb  float32 // dB below full scale shown by the meter (default 96)
+	YellowDb float32 // Level (dBFS) where the meter turns yellow (default: half the range)
+	RedDb    float32 // Level (dBFS) where the meter turns red (default: a fifth of the range)
+}
+
+// GangTemplate generates the cross-product of inputs and mixes as gangs (see ExpandGangTemplates)
+type GangTemplate struct {
+	Name     string         `dd:"+required"` // Gang name with {input} and {mix}, e.g. "{input} → {mix}"
+	Controls []string       `dd:"+required"` // Control names with {input_ch} and {mix_ch}, e.g. "Mix {mix_ch} Input {input_ch} Playback Volume"
+	Inputs   []TemplateItem `dd:"+required"`
+	Mixes    []TemplateItem `dd:"+required"`
 	Unit     string
-	TaperDb  float32  // If > 0, use DecibelTaper(TaperDb); otherwise LinearTaper
-	Levels   []string // Optional level control names for signal indication
+	TaperDb  float32
+	Tags     []string // May use {input} and {mix}, e.g. "cue:{mix}"
+	Safe     bool
+}
+
+// TemplateItem is an input or mix of a gang template
+type TemplateItem struct {
+	Name     string   `dd:"+required"` // Substituted for {input} or {mix}
+	Channels []string `dd:"+required"` // Substituted for {input_ch} or {mix_ch}; two make a stereo pair
+}
+
+type Profile struct {
+	Name         string        `dd:"+required"`
+	GangControls []GangControl `dd:"+required"` // Replaces the top-level gang_controls while active
+}
+
+type SwitchControl struct {
+	Name    string `dd:"+required"`
+	Control string `dd:"+required"`
+	Device  string // Optional; only applies when the card name contains this string
+	Mode    string // "latching" (default) or "momentary" (on while held; boolean controls only)
+}
+
+type DuckerControl struct {
+	Name        string        `dd:"+required"`
+	Trigger     string        `dd:"+required"` // Level control that triggers ducking
+	ThresholdDb float32       // Trigger level (dBFS) above which ducking engages
+	Hold        time.Duration // How long the trigger must stay above threshold before ducking
+	Gangs       []string      `dd:"+required"` // Gangs to reduce while ducked
+	DepthDb     float32       `dd:"+required"` // How far to reduce the gangs (dB)
+	Release     time.Duration // Ramp time to restore the gangs once the trigger falls below threshold
+}
+
+// GateControl mutes gangs while a trigger level stays below a threshold (see Gate)
+type GateControl struct {
+	Name        string        `dd:"+required"`
+	Trigger     string        `dd:"+required"` // Level control that opens the gate
+	ThresholdDb float32       // Trigger level (dBFS) below which the gate closes
+	Hold        time.Duration // How long the trigger must stay below threshold before closing (default 2s)
+	Gangs       []string      `dd:"+required"` // Gangs muted while the gate is closed
+}
+
+type PageConfig struct {
+	Name  string   `dd:"+required"`
+	Gangs []string `dd:"+required"` // Gangs shown on the page, in order; all others are hidden and locked
+	Token string   `dd:"+secret"`   // Remote clients presenting this token get only this page (needs remote.token)
+}
+
+type CoughControl struct {
+	Name    string        `dd:"+required"`
+	Gang    string        `dd:"+required"` // Gang muted while the switch is held
+	Fade    time.Duration // Fade out and in time (default 30ms)
+	Device  string        // Optional ALSA rawmidi device (or "virtual" sequencer ports) whose note holds the switch
+	Note    int           // MIDI note number held on the device
+	Channel int           // MIDI channel (0-15) of the note
+}
+
+type Keybinding struct {
+	Keys    string  `dd:"+required"` // Key or chord, e.g. "F1" or "Ctrl+M"
+	Action  string  `dd:"+required"` // "mute", "lock", "recall", "dim", "profile", "cough", "panic" or "restore"
+	Gang    string  // Target gang for mute and lock; dim applies to all gangs if empty
+	Scene   string  // Scene for recall
+	Profile string  // Profile to switch to; profile cycles through all profiles if empty
+	Cough   string  // Cough switch held by the key (cough bindings are always momentary)
+	DimDb   float32 // Dim depth (default 20 dB)
+	Mode    string  // "latching" (default; toggles on press) or "momentary" (active while held)
+}
+
+type SurfaceConfig struct {
+	Device     string           `dd:"+required"` // ALSA rawmidi device, "hw:2,0" or "/dev/snd/midiC2D0"
+	Protocol   string           // "cc" (default) or "mackie" (pitch bend faders, bank buttons)
+	Faders     int              // Number of surface faders (default 8)
+	Channel    int              // MIDI channel (0-15) for "cc" mode
+	FaderCC    int              // CC number of the first fader in "cc" mode; faders use consecutive CCs
+	BankUpCC   int              // CC of the bank up button in "cc" mode (0 = none)
+	BankDownCC int              // CC of the bank down button in "cc" mode (0 = none)
+	FaderMode  string           // "cc" mode faders: "cc" (default; 7-bit), "cc14" (14-bit MSB on fader_cc+i, LSB 32 higher) or "pitch_bend" (fader i on channel+i)
+	Encoders   string           // "cc" mode fader CCs: "absolute" (default), or relative encoders sending "twos_complement" or "sign_magnitude" steps
+	Takeover   bool             // Soft takeover for faders without motors: a fader only moves its gang once it reaches the gang's value
+	Buttons    []SurfaceButton  // Notes bound to mutes, scene recalls, switches and profiles, with LED feedback
+	Smoothing  *SmoothingConfig // Optional smoothing of fader positions from a jittery surface
+}
+
+type SurfaceButton struct {
+	Note    int    // MIDI note number of the button, on the surface's channel
+	Action  string `dd:"+required"` // "mute", "recall", "switch", "profile", "scene_next" or "scene_prev"
+	Gang    string // Gang muted and unmuted by "mute"
+	Scene   string // Scene recalled by "recall"
+	Switch  string // Switch held (momentary, e.g. talkback) or toggled (latching) by "switch"
+	Profile string // Profile switched to by "profile"; cycles through all profiles if empty
+}
+
+type EvdevConfig struct {
+	Device   string `dd:"+required"` // Input device node, e.g. "/dev/input/by-id/usb-...-event-joystick"
+	Grab     bool   // Take the device exclusively, so a footswitch that types keys does not also type into other applications
+	Bindings []EvdevBinding
+}
+
+type EvdevBinding struct {
+	Input   string `dd:"+required"` // Key or button ("BTN_SOUTH", "KEY_PAGEDOWN" or an event code), or an axis direction ("ABS_HAT0X+", "ABS_Y-")
+	Action  string `dd:"+required"` // "mute", "recall", "switch", "profile", "scene_next" or "scene_prev"
+	Gang    string // Gang muted and unmuted by "mute"
+	Scene   string // Scene recalled by "recall"
+	Switch  string // Switch held (momentary, e.g. talkback) or toggled (latching) by "switch"
+	Profile string // Profile switched to by "profile"; cycles through all profiles if empty
+}
+
+type RemoteConfig struct {
+	Listen string `dd:"+required"` // Address of the remote server, e.g. "0.0.0.0:7070"
+	Token  string `dd:"+secret"`   // Token clients must present (strongly recommended off loopback)
+}
+
+type OSCConfig struct {
+	Listen    string           `dd:"+required"` // UDP address of the OSC server, e.g. "0.0.0.0:9000" (no authentication; keep it on a trusted network)
+	Smoothing *SmoothingConfig // Optional smoothing of fader positions from jittery clients
+}
+
+type SmoothingConfig struct {
+	Interval time.Duration // Shortest time between two writes to a gang; the latest position wins (default 20ms)
+	Speed    float64       // Optional slew limit: largest fader travel per second (1 = the full travel)
+	Deadband float64       // Optional: position changes this small (e.g. 0.005) are dropped as jitter; the ends of the travel always apply
+}
+
+type WritePolicyConfig struct {
+	Mode     string        // "last_writer" (default), "priority" or "lockout"
+	Priority []string      // Write sources from highest to lowest priority (default ui, midi, input, osc, remote)
+	Hold     time.Duration // How long a source keeps a gang after its last write (default 1s)
+}
+
+type OBSConfig struct {
+	Address  string         `dd:"+required"` // obs-websocket address, e.g. "localhost:4455"
+	Password string         `dd:"+secret"`   // obs-websocket password, if authentication is enabled
+	Scenes   []OBSSceneLink // Actions taken when the OBS program scene changes
+	Inputs   []OBSInputLink // Gang mute states mirrored onto OBS inputs
+}
+
+type OBSSceneLink struct {
+	ObsScene string   `dd:"+required"` // OBS scene name
+	Scene    string   // Mixer scene to recall
+	Mute     []string // Gangs to mute
+	Unmute   []string // Gangs to unmute
+}
+
+type OBSInputLink struct {
+	Gang  string `dd:"+required"` // Gang whose mute state is mirrored
+	Input string `dd:"+required"` // OBS input (audio source) name
+}
+
+type GainStagingConfig struct {
+	Levels      []GainStagingLevel `dd:"+required"` // Level control metering each physical input
+	TargetMinDb float32            // Lower end of the target peak range (default -18 dBFS)
+	TargetMaxDb float32            // Upper end of the target peak range (default -10 dBFS)
+	Duration    time.Duration      // Calibration period (default 10s)
+	StepDb      float32            // dB per gain control step (default 1)
+}
+
+// GainStagingLevel names the level control metering a physical input for gain staging
+type GainStagingLevel struct {
+	Input int    `dd:"+required"` // Physical input number (1-based)
+	Level string `dd:"+required"`
+}
+
+type ListenConfig struct {
+	Mix     string  `dd:"+required"` // Monitoring mix the listen bus solos into, e.g. "Mix C"
+	Mode    string  // "pfl" (default; soloed at level_db) or "afl" (soloed at the gang's level)
+	LevelDb float32 // PFL send level (default 0 dB)
+}
+
+type PollingConfig struct {
+	Fallback time.Duration            // If > 0, poll every monitored control at this interval when the event monitor fails
+	Controls map[string]time.Duration // Control name -> poll interval, for controls the driver emits no events for
+}
+
+type DebugConfig struct {
+	Listen string `dd:"+required"` // Address of the debug HTTP listener, e.g. "127.0.0.1:6060"
+}
+
+type StorageConfig struct {
+	DataDir  string // Scenes and recordings (default $XDG_DATA_HOME/sessionmixer)
+	StateDir string // Session state: clip reports, display preferences (default $XDG_STATE_HOME/sessionmixer)
+}
+
+type InputLink struct {
+	Inputs []int `dd:"+required"` // Physical input numbers whose preamp gains move together
+}
+
+type ScheduleEntry struct {
+	At    string   `dd:"+required"` // Local time of day, "HH:MM"
+	Scene string   `dd:"+required"` // Scene to recall
+	Days  []string // Optional weekdays ("mon".."sun"); empty means every day
+}
+
+type PhantomSafety struct {
+	Confirm bool          // Require confirmation before switching phantom power
+	Settle  time.Duration // How long sends stay muted/dimmed after switching (default 3s)
+	DimDb   float32       // If > 0, dim sends by this many dB; otherwise mute them
+	Sends   []PhantomSend // Gangs carrying each physical input
+}
+
+// PhantomSend lists the gangs carrying a physical input, attenuated when its phantom power is
+// switched
+type PhantomSend struct {
+	Input int      `dd:"+required"` // Physical input number (1-based)
+	Gangs []string `dd:"+required"`
+}
+
+// ProtectionConfig caps output and monitor gangs at a maximum level, whatever sets them
+type ProtectionConfig struct {
+	MaxDb float32  // Ceiling (dB) no write may exceed
+	Gangs []string `dd:"+required"` // Protected gangs, by name (in any profile)
+}
+
+// PanicConfig configures the emergency mute (fade-to-black) of the output gangs
+type PanicConfig struct {
+	Gangs   []string      `dd:"+required"` // Output gangs silenced by the panic action
+	Fade    time.Duration // Fade to silence (default 100ms)
+	Restore time.Duration // Fade back to the previous values (default 1s)
+}
+
+// WaylandConfig makes the mixer behave like a panel widget under wlroots compositors
+type WaylandConfig struct {
+	AppID string // Window identity matched by compositor rules and desktop files (default "sessionmixer")
+	Dock  string // "top" or "bottom" keeps the window a strip, to be docked at that edge ("" = normal window)
+}
+
+// RenderingConfig trades smoothness for CPU/GPU usage (e.g. on battery)
+type RenderingConfig struct {
+	MaxFPS   int    // Frame rate cap (0 = unlimited, or the display refresh with vsync)
+	Vsync    *bool  // Sync to the display refresh (default: the GL driver's setting)
+	Software bool   // Render with Mesa's software rasterizer (old or missing GPU drivers)
+	Fallback string // When the window cannot be opened: "" exits with diagnostics, "terminal" keeps running without it
+}
+
+// AnnounceConfig configures the accessibility announcements of the focused gang and its value
+type AnnounceConfig struct {
+	Command []string      // Speech command, given the text as its last argument (e.g. [spd-say]); empty prints to stdout
+	Delay   time.Duration // Time a value must settle before it is announced (default 300ms)
+}
+
+// BackupConfig configures the periodic snapshots of the mix
+type BackupConfig struct {
+	Interval time.Duration // Time between snapshots (default 10m); unchanged mixes are not snapshotted again
+	Keep     int           // Number of snapshots kept, oldest dropped first (default 12)
+}
+
+// IdleDimConfig configures the session timer that dims the monitors when nobody is around
+type IdleDimConfig struct {
+	After time.Duration `dd:"+required"` // Time without activity before dimming (e.g. 30m)
+	Gangs []string      `dd:"+required"` // Monitor gangs to dim
+	DimDb float32       // Dim depth (default 20 dB)
+}
+
+// UnitConfig defines a custom display unit computed from a control's raw value
+// The input is the raw value, or its position (0.0-1.0) in the control range when normalized;
+// "linear" displays scale*x+offset and "log" displays scale*log10(x)+offset (-∞ at x <= 0)
+type UnitConfig struct {
+	Name      string
+	Formula   string // linear (default) | log
+	Scale     float64
+	Offset    float64
+	Normalize bool   // Use the position in the control range instead of the raw value
+	Decimals  int    // Digits after the decimal point
+	Suffix    string // Appended to the value (e.g. " %", " ms")
+}
+
+// ConfigDir returns the sessionmixer configuration directory ($XDG_CONFIG_HOME/sessionmixer,
+// default ~/.config/sessionmixer); scenes and session state live in the data and state
+// directories (see storage.go)
+func ConfigDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// ConfigEnv names the environment variable overriding the main configuration file path
+const ConfigEnv = "SESSIONMIXER_CONFIG"
+
+// TokenEnv names the environment variable holding the remote server token for `connect`
+const TokenEnv = "SESSIONMIXER_TOKEN"
+
+// mainConfigOverride is the path set with SetMainConfigPath (e.g. from --config)
+var mainConfigOverride string
+
+// SetMainConfigPath overrides the main configuration file path; "" restores the default lookup
+// Takes precedence over the SESSIONMIXER_CONFIG environment variable
+func SetMainConfigPath(path string) {
+	mainConfigOverride = path
+}
+
+// MainConfigPath returns the path of the main configuration file: the path set with
+// SetMainConfigPath, then $SESSIONMIXER_CONFIG, then the first of session.yaml, session.yml
+// and session.json in the config directory that exists, or session.yaml if none do
+// Scenes, clips and other state stay in the config directory regardless
+func MainConfigPath() (string, error) {
+	if mainConfigOverride != "" {
+		return mainConfigOverride, nil
+	}
+	if path := os.Getenv(ConfigEnv); path != "" {
+		return path, nil
+	}
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	for _, name := range []string{"session.yaml", "session.yml", "session.json"} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return filepath.Join(dir, "session.yaml"), nil
 }
 
 func LoadMainConfig() (*Config, error) {
-	home, err := os.UserHomeDir()
+	configPath, err := MainConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := LoadConfig(configPath)
 	if err != nil {
 		return nil, err
 	}
-	configPath := filepath.Join(home, ".config", "sessionmixer", "session.yaml")
-	return LoadConfig(configPath)
+	SetStorage(cfg.Storage)
+	SetAliases(cfg.Aliases)
+	SetLocale(cfg.Locale)
+	SetUIScale(cfg.UIScale)
+	SetRendering(cfg.Rendering)
+	return cfg, nil
 }
 
+// LoadConfig reads a configuration file; the format is chosen by extension (.json is JSON,
+// anything else YAML); .toml files are rejected
 func LoadConfig(path string) (*Config, error) {
-	return dd.NewFromYAML[Config](path)
+	doc, err := readConfigDocument(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+	from, changed, err := migrateConfigDocument(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+	cfg, err := dd.New[Config](doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+	if changed {
+		upgradeConfigFile(cfg, path, from)
+	}
+	return cfg, nil
+}
+
+// configFormat returns the format of a configuration file from its extension (.json is JSON,
+// .toml is TOML, anything else YAML)
+func configFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json"
+	case ".toml":
+		return "toml"
+	default:
+		return "yaml"
+	}
+}
+
+// errTOMLConfig is returned for TOML configuration files, which are not supported
+var errTOMLConfig = errors.New("TOML configuration files are not supported (use YAML or JSON)")
+
+// SaveConfig writes the configuration to path in the format of its extension (JSON or YAML)
+// The file is replaced atomically (written alongside, then renamed) and the previous version is
+// kept as path.bak; comments and formatting of a hand-edited file are not preserved
+func SaveConfig(cfg *Config, path string) error {
+	tmp := path + ".tmp"
+	write := dd.UnbindToYAML
+	switch configFormat(path) {
+	case "json":
+		write = dd.UnbindToJSON
+	case "toml":
+		return fmt.Errorf("failed to save config: %w", errTOMLConfig)
+	}
+	if err := write(cfg, tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	if previous, err := os.ReadFile(path); err == nil {
+		if err := os.WriteFile(path+".bak", previous, 0644); err != nil {
+			os.Remove(tmp)
+			return fmt.Errorf("failed to back up config: %w", err)
+		}
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,99 @@
+package sessionmixer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// loadTestConfig writes yaml to a session.yaml in a temporary directory and loads it
+func loadTestConfig(t *testing.T, yaml string) *Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "session.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	return cfg
+}
+
+func TestLoadConfigPhantomSafety(t *testing.T) {
+	cfg := loadTestConfig(t, `
+version: 2
+card: 1
+phantom_safety:
+  confirm: true
+  settle: 3s
+  dim_db: 0
+  sends:
+    - input: 1
+      gangs: ["MainMix"]
+`)
+	sends := cfg.PhantomSafety.Sends
+	if len(sends) != 1 || sends[0].Input != 1 || len(sends[0].Gangs) != 1 || sends[0].Gangs[0] != "MainMix" {
+		t.Errorf("sends = %+v", sends)
+	}
+}
+
+func TestLoadConfigGainStaging(t *testing.T) {
+	cfg := loadTestConfig(t, `
+version: 2
+card: 1
+gain_staging:
+  levels:
+    - input: 1
+      level: "pcm:0.0/Level Meter[0]"
+    - input: 2
+      level: "pcm:0.0/Level Meter[1]"
+  target_min_db: -18
+  target_max_db: -10
+  duration: 10s
+  step_db: 1
+`)
+	levels := cfg.GainStaging.Levels
+	if len(levels) != 2 || levels[1].Input != 2 || levels[1].Level != "pcm:0.0/Level Meter[1]" {
+		t.Errorf("levels = %+v", levels)
+	}
+}
+
+func TestLoadConfigMigratesInputMaps(t *testing.T) {
+	// Version 1 keyed the sends and levels by input number
+	cfg := loadTestConfig(t, `
+version: 1
+card: 1
+phantom_safety:
+  sends:
+    2: ["Guitar"]
+    1: ["MainMix"]
+gain_staging:
+  levels:
+    1: "pcm:0.0/Level Meter[0]"
+`)
+	if cfg.Version != ConfigVersion {
+		t.Errorf("version = %d, want %d", cfg.Version, ConfigVersion)
+	}
+	sends := cfg.PhantomSafety.Sends
+	if len(sends) != 2 || sends[0].Input != 1 || sends[1].Input != 2 || sends[1].Gangs[0] != "Guitar" {
+		t.Errorf("sends = %+v", sends)
+	}
+	if levels := cfg.GainStaging.Levels; len(levels) != 1 || levels[0].Input != 1 || levels[0].Level != "pcm:0.0/Level Meter[0]" {
+		t.Errorf("levels = %+v", levels)
+	}
+}
+
+func TestLoadConfigRejectsTOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.toml")
+	if err := os.WriteFile(path, []byte("version = 2\ncard = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(path); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("LoadConfig(%s) = %v, want ErrInvalidConfig", path, err)
+	}
+	if err := SaveConfig(&Config{}, path); err == nil {
+		t.Error("SaveConfig wrote a TOML file")
+	}
+}
@@ -0,0 +1,244 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// ConfigEditor is the "edit gangs" dialog: it edits a working copy of the configured gangs
+// (rename, unit and taper, controls and levels, order), validates it against the card and
+// saves it back to the config file
+type ConfigEditor struct {
+	card   *scarlettctl.Card
+	config *Config
+	path   string
+
+	open       bool
+	gangs      []GangControl // Working copy of config.GangControls
+	selected   int
+	addControl string
+	addLevel   string
+	addPost    string
+	message    string
+	saved      bool // Saved this session (applied on the next start)
+}
+
+// NewConfigEditor creates the editor; the config is saved to path
+func NewConfigEditor(card *scarlettctl.Card, config *Config, path string) *ConfigEditor {
+	return &ConfigEditor{
+		card:   card,
+		config: config,
+		path:   path,
+	}
+}
+
+// Open shows the dialog on the next frame, discarding any unsaved edits
+func (ce *ConfigEditor) Open() {
+	ce.open = true
+	ce.gangs = make([]GangControl, len(ce.config.GangControls))
+	for i, gc := range ce.config.GangControls {
+		ce.gangs[i] = cloneGangControl(gc)
+	}
+	ce.selected = 0
+	ce.addControl = ""
+	ce.addLevel = ""
+	ce.addPost = ""
+	ce.message = ""
+}
+
+// IsSaved returns true if the gangs were saved this session
+func (ce *ConfigEditor) IsSaved() bool {
+	return ce.saved
+}
+
+// Draw renders the dialog when open
+func (ce *ConfigEditor) Draw() {
+	if ce.open {
+		imgui.OpenPopupStr(tr("Edit Gangs"))
+		ce.open = false
+	}
+	if !imgui.BeginPopupModalV(tr("Edit Gangs"), nil, imgui.WindowFlagsAlwaysAutoResize) {
+		return
+	}
+
+	// Gang list with reordering
+	imgui.BeginChildStrV("gang_list", scaledVec2(imgui.Vec2{X: 220, Y: 360}), imgui.ChildFlagsBorders, imgui.WindowFlagsNone)
+	for i := range ce.gangs {
+		imgui.PushIDInt(int32(i))
+		if imgui.ArrowButton("up", imgui.DirUp) && i > 0 {
+			ce.gangs[i-1], ce.gangs[i] = ce.gangs[i], ce.gangs[i-1]
+			ce.selected = i - 1
+		}
+		imgui.SameLine()
+		if imgui.ArrowButton("down", imgui.DirDown) && i < len(ce.gangs)-1 {
+			ce.gangs[i+1], ce.gangs[i] = ce.gangs[i], ce.gangs[i+1]
+			ce.selected = i + 1
+		}
+		imgui.SameLine()
+		if imgui.SelectableBoolV(ce.gangs[i].Name, i == ce.selected, imgui.SelectableFlagsNone, imgui.Vec2{}) {
+			ce.selected = i
+		}
+		imgui.PopID()
+	}
+	imgui.EndChild()
+
+	imgui.SameLine()
+	imgui.BeginChildStrV("gang_edit", scaledVec2(imgui.Vec2{X: 420, Y: 360}), imgui.ChildFlagsBorders, imgui.WindowFlagsNone)
+	if ce.selected < len(ce.gangs) {
+		ce.drawGang(&ce.gangs[ce.selected])
+	}
+	imgui.EndChild()
+
+	if ce.message != "" {
+		imgui.TextColored(errorColor, ce.message)
+	}
+	if imgui.Button(tr("Save")) {
+		if err := ce.save(); err != nil {
+			ce.message = err.Error()
+		} else {
+			imgui.CloseCurrentPopup()
+		}
+	}
+	imgui.SameLine()
+	if imgui.Button(tr("Cancel")) {
+		imgui.CloseCurrentPopup()
+	}
+	imgui.EndPopup()
+}
+
+// drawGang renders the fields of one gang
+func (ce *ConfigEditor) drawGang(gc *GangControl) {
+	imgui.SetNextItemWidth(250)
+	imgui.InputTextWithHint(tr("Name"), tr("gang name"), &gc.Name, imgui.InputTextFlagsNone, nil)
+	unit := gc.Unit
+	if unit == "" {
+		unit = "db"
+	}
+	imgui.SetNextItemWidth(250)
+	if imgui.BeginCombo(tr("Unit"), unit) {
+		for _, u := range configUnits(ce.config) {
+			if imgui.SelectableBoolV(u, u == unit, imgui.SelectableFlagsNone, imgui.Vec2{}) {
+				gc.Unit = u
+			}
+		}
+		imgui.EndCombo()
+	}
+	imgui.SetNextItemWidth(250)
+	imgui.InputFloatV(tr("Taper (dB)"), &gc.TaperDb, 6, 12, "%.0f", imgui.InputTextFlagsNone)
+
+	imgui.SeparatorText(tr("Controls"))
+	ce.drawNames("control", &gc.Controls, &ce.addControl)
+	imgui.SeparatorText(tr("Levels"))
+	ce.drawNames("level", &gc.Levels, &ce.addLevel)
+	imgui.SeparatorText(tr("Post Levels (mix output)"))
+	ce.drawNames("post level", &gc.PostLevels, &ce.addPost)
+}
+
+// drawNames renders a reorderable list of control names with remove buttons and an input to
+// add a name; added names are checked against the card immediately
+func (ce *ConfigEditor) drawNames(kind string, names *[]string, input *string) {
+	for i := 0; i < len(*names); i++ {
+		imgui.PushIDStr(fmt.Sprintf("%s_%d", kind, i))
+		if imgui.ArrowButton("up", imgui.DirUp) && i > 0 {
+			(*names)[i-1], (*names)[i] = (*names)[i], (*names)[i-1]
+		}
+		imgui.SameLine()
+		if imgui.ArrowButton("down", imgui.DirDown) && i < len(*names)-1 {
+			(*names)[i+1], (*names)[i] = (*names)[i], (*names)[i+1]
+		}
+		imgui.SameLine()
+		remove := imgui.SmallButton(tr("x"))
+		imgui.SameLine()
+		imgui.Text((*names)[i])
+		imgui.PopID()
+		if remove {
+			*names = slices.Delete(*names, i, i+1)
+			i--
+		}
+	}
+	imgui.SetNextItemWidth(250)
+	imgui.InputTextWithHint("##add_"+kind, kind+" name", input, imgui.InputTextFlagsNone, nil)
+	imgui.SameLine()
+	if imgui.Button(tr("Add##add_") + kind) {
+		name := strings.TrimSpace(*input)
+		if _, err := findControl(ce.card, name); err != nil {
+			ce.message = err.Error()
+		} else {
+			*names = append(*names, name)
+			*input = ""
+			ce.message = ""
+		}
+	}
+}
+
+// validate checks the working copy for empty and duplicate names, then loads it against the
+// card with the same rules as startup (controls exist, types match, levels exist)
+func (ce *ConfigEditor) validate() error {
+	seen := make(map[string]bool)
+	for i := range ce.gangs {
+		ce.gangs[i].Name = strings.TrimSpace(ce.gangs[i].Name)
+		name := ce.gangs[i].Name
+		if name == "" {
+			return fmt.Errorf("gang %d: a name is required", i)
+		}
+		if seen[name] {
+			return fmt.Errorf("gang %d: a gang named '%s' already exists", i, name)
+		}
+		seen[name] = true
+		if len(ce.gangs[i].Controls) == 0 {
+			return fmt.Errorf("gang %d (%s): at least one control is required", i, name)
+		}
+	}
+	cfg := *ce.config
+	cfg.GangControls = ce.gangs
+	if _, err := NewControlMapper(ce.card, &cfg).LoadGangs(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// save validates the working copy and writes it to the config file
+func (ce *ConfigEditor) save() error {
+	if err := ce.validate(); err != nil {
+		return err
+	}
+	previous := ce.config.GangControls
+	ce.config.GangControls = ce.gangs
+	if err := SaveConfig(ce.config, ce.path); err != nil {
+		ce.config.GangControls = previous
+		return err
+	}
+	ce.gangs = nil
+	ce.saved = true
+	return nil
+}
+
+// cloneGangControl returns a deep copy of a gang's configuration
+func cloneGangControl(gc GangControl) GangControl {
+	gc.Controls = slices.Clone(gc.Controls)
+	gc.Levels = slices.Clone(gc.Levels)
+	gc.PostLevels = slices.Clone(gc.PostLevels)
+	gc.Tags = slices.Clone(gc.Tags)
+	if gc.DefaultDb != nil {
+		defaultDb := *gc.DefaultDb
+		gc.DefaultDb = &defaultDb
+	}
+	if gc.Meter != nil {
+		meter := *gc.Meter
+		gc.Meter = &meter
+	}
+	return gc
+}
+
+// configUnits returns the built-in and configured units
+func configUnits(config *Config) []string {
+	units := []string{"db", "raw"}
+	for _, unit := range config.Units {
+		units = append(units, unit.Name)
+	}
+	return units
+}
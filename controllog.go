@@ -0,0 +1,199 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// changedColor highlights the control that changed last, for a moment
+var changedColor = imgui.Vec4{X: 0.4, Y: 1.0, Z: 0.4, W: 1.0}
+
+// ControlChange is a control seen changing while listening: its last value and how often it
+// changed
+type ControlChange struct {
+	NumID uint
+	Name  string
+	Alias string // Configured alias of the control, if any
+	Gang  string // Gang owning the control, if any
+	Value int64
+	Text  string // Value as shown: the item of an enumerated control, on/off, or the number
+	Count int
+	Last  time.Time
+}
+
+// ControlLog latches every hardware control change while listening, one row per control with its
+// latest value, to find which ALSA control a physical knob or button moves
+type ControlLog struct {
+	listening atomic.Bool
+
+	mu      sync.Mutex
+	changes map[uint]*ControlChange
+
+	// UI state
+	filter     string
+	hideMeters bool
+}
+
+// changeLog is fed by every event monitor
+var changeLog = &ControlLog{changes: make(map[uint]*ControlChange), hideMeters: true}
+
+// SetListening starts or stops latching changes; the latched changes are kept until Clear
+func (cl *ControlLog) SetListening(listening bool) {
+	cl.listening.Store(listening)
+}
+
+// IsListening returns true while changes are latched
+func (cl *ControlLog) IsListening() bool {
+	return cl.listening.Load()
+}
+
+// Clear drops the latched changes
+func (cl *ControlLog) Clear() {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	clear(cl.changes)
+}
+
+// record latches a change of control to value, made by the hardware (or echoing a write) and
+// owned by gang (nil if none); does nothing unless listening
+func (cl *ControlLog) record(control *scarlettctl.Control, value int64, gang *GangedFader) {
+	if !cl.listening.Load() {
+		return
+	}
+	now := time.Now()
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	change, ok := cl.changes[control.NumID]
+	if !ok {
+		change = &ControlChange{NumID: control.NumID, Name: control.Name, Alias: aliasOf(control.Name)}
+		if gang != nil {
+			change.Gang = gang.GetName()
+		}
+		cl.changes[control.NumID] = change
+	} else if change.Value == value {
+		return // Repeated events (e.g. polling) are not changes
+	}
+	change.Value = value
+	change.Text = controlValueText(control, value)
+	change.Count++
+	change.Last = now
+}
+
+// Changes returns the latched changes matching filter (case-insensitive, on the name, alias
+// or gang), most recent first
+func (cl *ControlLog) Changes(filter string, hideMeters bool) []ControlChange {
+	filter = strings.ToLower(filter)
+	cl.mu.Lock()
+	var changes []ControlChange
+	for _, change := range cl.changes {
+		if hideMeters && strings.Contains(strings.ToLower(change.Name), "meter") {
+			continue
+		}
+		if filter != "" && !strings.Contains(strings.ToLower(change.Name+"\x00"+change.Alias+"\x00"+change.Gang), filter) {
+			continue
+		}
+		changes = append(changes, *change)
+	}
+	cl.mu.Unlock()
+	slices.SortFunc(changes, func(a, b ControlChange) int {
+		return b.Last.Compare(a.Last)
+	})
+	return changes
+}
+
+// controlValueText formats a control value for the change list
+func controlValueText(control *scarlettctl.Control, value int64) string {
+	switch control.Type {
+	case scarlettctl.ControlTypeEnumerated:
+		return enumItemName(control, value)
+	case scarlettctl.ControlTypeBoolean:
+		if value != 0 {
+			return tr("On")
+		}
+		return tr("Off")
+	}
+	return fmt.Sprintf("%d", value)
+}
+
+// drawControlLog renders the listen toggle, filter and the latched changes
+func drawControlLog() {
+	cl := changeLog
+	listening := cl.IsListening()
+	if imgui.Checkbox(tr("Listen##control_log"), &listening) {
+		cl.SetListening(listening)
+	}
+	imgui.SameLine()
+	if imgui.Button(tr("Clear##control_log")) {
+		cl.Clear()
+	}
+	imgui.SameLine()
+	imgui.Checkbox(tr("Hide meters"), &cl.hideMeters)
+	imgui.SameLine()
+	imgui.SetNextItemWidth(200)
+	imgui.InputTextWithHint("##control_log_filter", tr("filter controls"), &cl.filter, imgui.InputTextFlagsNone, nil)
+
+	changes := cl.Changes(cl.filter, cl.hideMeters)
+	if len(changes) == 0 {
+		if listening {
+			imgui.TextDisabled(tr("Move a control on the interface to see its name here"))
+		} else {
+			imgui.TextDisabled(tr("Tick Listen, then move a control on the interface"))
+		}
+		return
+	}
+
+	now := time.Now()
+	if imgui.BeginTableV("control_log_table", 6, imgui.TableFlagsRowBg|imgui.TableFlagsSizingFixedFit|imgui.TableFlagsScrollY,
+		imgui.Vec2{Y: scaled(240)}, 0.0) {
+		imgui.TableSetupScrollFreeze(0, 1)
+		imgui.TableSetupColumn(tr("Control"))
+		imgui.TableSetupColumn(tr("NumID"))
+		imgui.TableSetupColumn(tr("Value"))
+		imgui.TableSetupColumn(tr("Changes"))
+		imgui.TableSetupColumn(tr("Gang"))
+		imgui.TableSetupColumn(tr("Last"))
+		imgui.TableHeadersRow()
+		for _, change := range changes {
+			imgui.TableNextRow()
+			imgui.TableNextColumn()
+			// The most recent change is highlighted for a moment, so the moved control stands out
+			if now.Sub(change.Last) < time.Second {
+				imgui.TextColored(changedColor, change.Name)
+			} else {
+				imgui.Text(change.Name)
+			}
+			if imgui.IsItemClicked() {
+				imgui.SetClipboardText(change.Name)
+			}
+			if imgui.BeginItemTooltip() {
+				if change.Alias != "" {
+					imgui.TextUnformatted(trf("Alias: %s", change.Alias))
+				}
+				imgui.TextUnformatted(tr("Click to copy the name"))
+				imgui.EndTooltip()
+			}
+			imgui.TableNextColumn()
+			imgui.Text(fmt.Sprintf("%d", change.NumID))
+			imgui.TableNextColumn()
+			imgui.Text(change.Text)
+			imgui.TableNextColumn()
+			imgui.Text(fmt.Sprintf("%d", change.Count))
+			imgui.TableNextColumn()
+			if change.Gang != "" {
+				imgui.Text(change.Gang)
+			} else {
+				imgui.TextDisabled(tr("-"))
+			}
+			imgui.TableNextColumn()
+			imgui.Text(change.Last.Format("15:04:05"))
+		}
+		imgui.EndTable()
+	}
+}
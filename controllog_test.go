@@ -0,0 +1,68 @@
+package sessionmixer
+
+import (
+	"testing"
+
+	"github.com/michaelquigley/scarlettctl"
+)
+
+func TestControlLogLatchesChanges(t *testing.T) {
+	cl := &ControlLog{changes: make(map[uint]*ControlChange)}
+	knob := &scarlettctl.Control{NumID: 3, Name: "Line In 1 Gain Capture Volume", Type: scarlettctl.ControlTypeInteger}
+	air := &scarlettctl.Control{NumID: 7, Name: "Line In 1 Air Capture Enum", Type: scarlettctl.ControlTypeEnumerated, Items: []string{"Off", "Presence", "Presence + Drive"}}
+	meter := &scarlettctl.Control{NumID: 9, Name: "Level Meter", Type: scarlettctl.ControlTypeInteger}
+
+	cl.record(knob, 10, nil)
+	if len(cl.Changes("", false)) != 0 {
+		t.Fatal("changes latched while not listening")
+	}
+
+	cl.SetListening(true)
+	cl.record(knob, 10, nil)
+	cl.record(knob, 11, nil)
+	cl.record(knob, 11, nil) // Repeated value, not a change
+	cl.record(meter, 100, nil)
+	cl.record(air, 2, nil)
+
+	changes := cl.Changes("", true)
+	if len(changes) != 2 {
+		t.Fatalf("got %d changes, want 2 (meters hidden)", len(changes))
+	}
+	if changes[0].Name != air.Name || changes[0].Text != "Presence + Drive" {
+		t.Errorf("most recent change = %+v, want the Air enum", changes[0])
+	}
+	if changes[1].Value != 11 || changes[1].Count != 2 {
+		t.Errorf("knob change = %+v, want value 11 after 2 changes", changes[1])
+	}
+	if got := cl.Changes("GAIN", false); len(got) != 1 || got[0].NumID != 3 {
+		t.Errorf("filter matched %v", got)
+	}
+
+	cl.Clear()
+	if len(cl.Changes("", false)) != 0 {
+		t.Error("changes left after Clear")
+	}
+}
+
+func TestEventMonitorFeedsControlLog(t *testing.T) {
+	changeLog.SetListening(true)
+	defer func() {
+		changeLog.SetListening(false)
+		changeLog.Clear()
+	}()
+
+	gang, _ := newFakeGang(t, "Vocal", 0, 160, 80, 1)
+	em := NewEventMonitor(nil, []*GangedFader{gang})
+	event(t, em, 1, 90)
+	event(t, em, 42, 1) // Not owned by any gang
+
+	changes := changeLog.Changes("", false)
+	if len(changes) != 2 {
+		t.Fatalf("got %d changes, want 2", len(changes))
+	}
+	for _, change := range changes {
+		if want := map[uint]string{1: "Vocal", 42: ""}[change.NumID]; change.Gang != want {
+			t.Errorf("control %d: gang = %q, want %q", change.NumID, change.Gang, want)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// coughInterval is the step of a cough switch fade
+	coughInterval = 5 * time.Millisecond
+
+	// defaultCoughFade is the fade time used when a cough switch does not set one
+	defaultCoughFade = 30 * time.Millisecond
+)
+
+// CoughSwitch mutes a gang only while held (a key or a MIDI note), fading out on press and back
+// in on release; the release restores the exact raw value the gang had before the press
+// A gang moved by someone else while held is left where it is
+type CoughSwitch struct {
+	config CoughControl
+	fade   time.Duration
+	port   *MIDIPort // nil if not triggered by MIDI
+
+	mu    sync.Mutex
+	gangs []*GangedFader
+	held  bool
+
+	// Runtime state (owned by the fade goroutine)
+	gang    *GangedFader // Gang being faded (nil when idle)
+	base    int64        // Gang value captured on press
+	lastSet int64        // Value most recently written by the switch
+	level   float64      // 0 = open (base), 1 = closed (minimum)
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewCoughSwitch creates a cough switch for a gang, opening its MIDI device if one is configured
+func NewCoughSwitch(config CoughControl, gangs []*GangedFader) (*CoughSwitch, error) {
+	if findGang(gangs, config.Gang) == nil {
+		return nil, fmt.Errorf("unknown gang '%s'", config.Gang)
+	}
+	if config.Note < 0 || config.Note > 127 {
+		return nil, fmt.Errorf("note %d is out of range", config.Note)
+	}
+	if config.Channel < 0 || config.Channel > 15 {
+		return nil, fmt.Errorf("channel %d is out of range", config.Channel)
+	}
+	fade := config.Fade
+	if fade <= 0 {
+		fade = defaultCoughFade
+	}
+
+	cs := &CoughSwitch{
+		config: config,
+		fade:   fade,
+		gangs:  gangs,
+		stop:   make(chan struct{}),
+	}
+	if config.Device != "" {
+		port, err := OpenMIDIPort(config.Device)
+		if err != nil {
+			return nil, err
+		}
+		cs.port = port
+	}
+	return cs, nil
+}
+
+// GetName returns the cough switch name
+func (cs *CoughSwitch) GetName() string {
+	return cs.config.Name
+}
+
+// SetGangs replaces the gangs the switch resolves its gang from (e.g. after a profile switch)
+func (cs *CoughSwitch) SetGangs(gangs []*GangedFader) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.gangs = gangs
+}
+
+// Press closes the gang (thread-safe)
+func (cs *CoughSwitch) Press() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.held = true
+}
+
+// Release reopens the gang (thread-safe)
+func (cs *CoughSwitch) Release() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.held = false
+}
+
+// IsHeld returns true while the switch is pressed (thread-safe)
+func (cs *CoughSwitch) IsHeld() bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.held
+}
+
+// Start begins fading and, with a MIDI device, reading its notes in background goroutines
+func (cs *CoughSwitch) Start() {
+	if cs.port != nil {
+		cs.wg.Add(1)
+		go func() {
+			defer cs.wg.Done()
+			if err := cs.port.Read(cs.handleMessage); err != nil {
+				select {
+				case <-cs.stop:
+				default:
+					log.Printf("Cough %s: read from %s failed: %v", cs.config.Name, cs.port.GetPath(), err)
+				}
+			}
+		}()
+	}
+	cs.wg.Add(1)
+	go func() {
+		defer cs.wg.Done()
+		ticker := time.NewTicker(coughInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-cs.stop:
+				cs.restore()
+				return
+			case <-ticker.C:
+				cs.update()
+			}
+		}
+	}()
+}
+
+// Stop stops the switch, restoring a closed gang immediately
+// Must only be called after Start; blocks until the goroutines have exited
+func (cs *CoughSwitch) Stop() {
+	cs.stopOnce.Do(func() {
+		close(cs.stop)
+		if cs.port != nil {
+			cs.port.Close()
+		}
+		cs.wg.Wait()
+	})
+}
+
+// handleMessage presses on the configured note and releases on its note off (or a note on
+// with zero velocity)
+func (cs *CoughSwitch) handleMessage(msg MIDIMessage) {
+	if int(msg.Channel()) != cs.config.Channel || int(msg.Data1) != cs.config.Note {
+		return
+	}
+	switch msg.Type() {
+	case midiNoteOn:
+		if msg.Data2 > 0 {
+			cs.Press()
+		} else {
+			cs.Release()
+		}
+	case midiNoteOff:
+		cs.Release()
+	}
+}
+
+// update advances the fade one step towards the held state
+func (cs *CoughSwitch) update() {
+	cs.mu.Lock()
+	held := cs.held
+	gangs := cs.gangs
+	cs.mu.Unlock()
+
+	if cs.gang == nil {
+		if !held {
+			return
+		}
+		gang := findGang(gangs, cs.config.Gang)
+		if gang == nil || gang.IsLocked() {
+			return
+		}
+		cs.gang = gang
+		cs.base = gang.GetCurrentValue()
+		cs.lastSet = cs.base
+		cs.level = 0
+	}
+
+	if cs.gang.GetCurrentValue() != cs.lastSet {
+		// Someone else moved the fader; stop managing this gang
+		cs.gang = nil
+		return
+	}
+
+	step := float64(coughInterval) / float64(cs.fade)
+	if held {
+		cs.level = math.Min(1.0, cs.level+step)
+	} else {
+		cs.level = math.Max(0.0, cs.level-step)
+	}
+
+	switch cs.level {
+	case 0:
+		cs.set(cs.base)
+		cs.gang = nil
+	case 1:
+		cs.set(cs.gang.GetMin())
+	default:
+		// Fade in dB space so the curve sounds even across the gang's range
+		baseDb := math.Max(cs.gang.ValueToDb(cs.base), floorDb)
+		cs.set(cs.gang.DbToValue(baseDb + (floorDb-baseDb)*cs.level))
+	}
+}
+
+// restore immediately returns a closed or fading gang to its captured value
+func (cs *CoughSwitch) restore() {
+	if cs.gang == nil {
+		return
+	}
+	if cs.gang.GetCurrentValue() == cs.lastSet {
+		cs.set(cs.base)
+	}
+	cs.gang = nil
+}
+
+// set writes a value to the faded gang
+func (cs *CoughSwitch) set(value int64) {
+	if value == cs.lastSet {
+		return
+	}
+	if err := cs.gang.HandleUIChange(value); err != nil {
+		log.Printf("Cough %s: failed to write %s: %v", cs.config.Name, cs.gang.GetName(), err)
+	}
+	cs.lastSet = value
+}
+
+// closeCoughs closes the MIDI devices of switches that were never started
+func closeCoughs(coughs []*CoughSwitch) {
+	for _, cough := range coughs {
+		if cough.port != nil {
+			cough.port.Close()
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package sessionmixer
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	runtimepprof "runtime/pprof"
+)
+
+// DebugServer is an optional HTTP listener exposing pprof profiles, goroutine dumps and the
+// runtime statistics, for diagnosing performance issues
+//
+//	/debug/pprof/     pprof index (profile, heap, trace...)
+//	/debug/goroutines full goroutine dump
+//	/debug/stats      runtime statistics as JSON
+type DebugServer struct {
+	listener net.Listener
+	server   *http.Server
+}
+
+// StartDebugServer starts the debug listener on addr (e.g. "127.0.0.1:6060")
+func StartDebugServer(addr string) (*DebugServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/goroutines", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		runtimepprof.Lookup("goroutine").WriteTo(w, 2)
+	})
+	mux.HandleFunc("/debug/stats", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(Stats.Snapshot())
+	})
+
+	ds := &DebugServer{
+		listener: listener,
+		server:   &http.Server{Handler: mux},
+	}
+	go func() {
+		if err := ds.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("Debug server error: %v", err)
+		}
+	}()
+	return ds, nil
+}
+
+// GetAddr returns the address the debug server is listening on
+func (ds *DebugServer) GetAddr() string {
+	return ds.listener.Addr().String()
+}
+
+// Stop closes the debug listener
+func (ds *DebugServer) Stop() {
+	ds.server.Close()
+}
@@ -0,0 +1,84 @@
+package sessionmixer
+
+import (
+	"slices"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+// defaultDetachedSize is the size of a newly detached tag window
+var defaultDetachedSize = imgui.Vec2{X: 420, Y: 520}
+
+// isDetached reports whether a tag view has its own window
+func (sm *SessionMixer) isDetached(tag string) bool {
+	return slices.ContainsFunc(sm.detached, func(d DetachedGeometry) bool { return d.Tag == tag })
+}
+
+// inDetached reports whether a gang is shown in a detached window (it has a detached tag)
+func (sm *SessionMixer) inDetached(gang *GangedFader) bool {
+	for _, d := range sm.detached {
+		if gang.HasTag(d.Tag) && slices.Contains(sm.tags, d.Tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// drawTagMenu renders the context menu of a tag tab
+func (sm *SessionMixer) drawTagMenu(tag string) {
+	if !imgui.BeginPopupContextItemV("tag_menu_"+tag, imgui.PopupFlagsMouseButtonRight) {
+		return
+	}
+	if imgui.MenuItemBool(tr("Detach to a window")) {
+		sm.detached = append(sm.detached, DetachedGeometry{Tag: tag})
+		if sm.viewTag == tag {
+			sm.viewTag = ""
+		}
+	}
+	imgui.EndPopup()
+}
+
+// drawDetached renders each detached tag view as its own window; the cimgui-go GLFW backend
+// enables multi-viewports, so a window dragged out of the main one becomes an OS window that
+// can go to another monitor. Closing it puts the tag back in the tab bar
+func (sm *SessionMixer) drawDetached() {
+	place := sm.placeDetached
+	sm.placeDetached = false
+	for n := 0; n < len(sm.detached); n++ {
+		d := &sm.detached[n]
+		if !slices.Contains(sm.tags, d.Tag) {
+			continue // Not a tag of the current profile's gangs
+		}
+
+		cond := imgui.CondFirstUseEver
+		if place {
+			cond = imgui.CondAlways
+		}
+		if d.Width > 0 && d.Height > 0 {
+			imgui.SetNextWindowPosV(imgui.Vec2{X: float32(d.X), Y: float32(d.Y)}, cond, imgui.Vec2{})
+			imgui.SetNextWindowSizeV(imgui.Vec2{X: float32(d.Width), Y: float32(d.Height)}, cond)
+		} else {
+			imgui.SetNextWindowSizeV(scaledVec2(defaultDetachedSize), imgui.CondFirstUseEver)
+		}
+
+		open := true
+		if imgui.BeginV(d.Tag+"##detached", &open, imgui.WindowFlagsNone) {
+			pos, size := imgui.WindowPos(), imgui.WindowSize()
+			d.X, d.Y, d.Width, d.Height = int(pos.X), int(pos.Y), int(size.X), int(size.Y)
+
+			var gangs []int
+			for i, gang := range sm.gangs {
+				if gang.HasTag(d.Tag) {
+					gangs = append(gangs, i)
+				}
+			}
+			sm.drawFaderBank(gangs, 0)
+		}
+		imgui.End()
+
+		if !open {
+			sm.detached = slices.Delete(sm.detached, n, n+1)
+			n--
+		}
+	}
+}
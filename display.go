@@ -0,0 +1,104 @@
+package sessionmixer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/michaelquigley/df/dd"
+)
+
+// Value display modes
+const (
+	DisplayDb  = "db"
+	DisplayRaw = "raw"
+)
+
+// DisplayPrefs are the remembered value display choices (dB or raw) for "db" gangs
+// The global mode applies to every gang without a per-gang override
+type DisplayPrefs struct {
+	Mode    string            // Global display mode ("" = dB)
+	Gangs   map[string]string // Gang name -> display mode override
+	Offsets bool              // Show each gang's offset from the current scene instead of its value
+}
+
+// LoadDisplayPrefs reads the display preferences from path; a missing file yields defaults
+func LoadDisplayPrefs(path string) (*DisplayPrefs, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return &DisplayPrefs{Gangs: make(map[string]string)}, nil
+	}
+	prefs, err := dd.NewFromYAML[DisplayPrefs](path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load display preferences: %w", err)
+	}
+	if prefs.Gangs == nil {
+		prefs.Gangs = make(map[string]string)
+	}
+	return prefs, nil
+}
+
+// Save writes the display preferences to path
+func (dp *DisplayPrefs) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := dd.UnbindToYAML(dp, path); err != nil {
+		return fmt.Errorf("failed to save display preferences: %w", err)
+	}
+	return nil
+}
+
+// IsRaw returns true if the gang's values are shown raw
+func (dp *DisplayPrefs) IsRaw(gang *GangedFader) bool {
+	mode, ok := dp.Gangs[gang.GetName()]
+	if !ok {
+		mode = dp.Mode
+	}
+	return mode == DisplayRaw
+}
+
+// SetGlobal sets the global display mode, clearing the per-gang overrides
+func (dp *DisplayPrefs) SetGlobal(mode string, gangs []*GangedFader) {
+	dp.Mode = mode
+	clear(dp.Gangs)
+	dp.Apply(gangs)
+}
+
+// SetGang overrides the display mode of one gang; "" returns it to the global mode
+func (dp *DisplayPrefs) SetGang(gang *GangedFader, mode string) {
+	if mode == "" {
+		delete(dp.Gangs, gang.GetName())
+	} else {
+		dp.Gangs[gang.GetName()] = mode
+	}
+	gang.SetShowRaw(dp.IsRaw(gang))
+}
+
+// Apply sets the display mode of every gang
+func (dp *DisplayPrefs) Apply(gangs []*GangedFader) {
+	for _, gang := range gangs {
+		gang.SetShowRaw(dp.IsRaw(gang))
+	}
+}
+
+// formatOffset describes how far a gang has moved from a reference value: in dB for "db" gangs
+// shown in dB, in raw steps otherwise
+func formatOffset(gang *GangedFader, value, reference int64) string {
+	if value == reference {
+		return "±0"
+	}
+	if gang.GetUnit() != "db" || gang.IsShowRaw() {
+		return fmt.Sprintf("%+d", value-reference)
+	}
+	db, referenceDb := gang.ValueToDb(value), gang.ValueToDb(reference)
+	switch {
+	case math.IsInf(db, -1):
+		return "-∞ dB"
+	case math.IsInf(referenceDb, -1):
+		return "+∞ dB"
+	}
+	return fmt.Sprintf("%+.2f dB", db-referenceDb)
+}
@@ -0,0 +1,28 @@
+package sessionmixer
+
+import "testing"
+
+func TestFormatOffset(t *testing.T) {
+	// newFakeGang creates "db" gangs; 160 is +12 dB, 80 is about +6 dB
+	gang, _ := newFakeGang(t, "Vocal", 0, 160, 80, 1)
+	for _, tc := range []struct {
+		value, reference int64
+		want             string
+	}{
+		{80, 80, "±0"},
+		{160, 80, "+6.02 dB"},
+		{80, 160, "-6.02 dB"},
+		{0, 80, "-∞ dB"},
+		{80, 0, "+∞ dB"},
+	} {
+		if got := formatOffset(gang, tc.value, tc.reference); got != tc.want {
+			t.Errorf("formatOffset(%d, %d) = %q, want %q", tc.value, tc.reference, got, tc.want)
+		}
+	}
+
+	// Raw display shows the change in steps
+	gang.SetShowRaw(true)
+	if got := formatOffset(gang, 70, 80); got != "-10" {
+		t.Errorf("raw formatOffset = %q, want -10", got)
+	}
+}
@@ -0,0 +1,237 @@
+package sessionmixer
+
+import (
+	"log"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// duckerInterval is how often duckers sample their trigger level
+const duckerInterval = 20 * time.Millisecond
+
+// Ducker reduces a set of gangs while a trigger level stays above a threshold
+// Ducking engages once the trigger has exceeded the threshold for the hold time, and the
+// gangs ramp back to their previous values over the release time once it falls below
+type Ducker struct {
+	config  DuckerControl
+	trigger *scarlettctl.Control
+	gangs   []*GangedFader
+
+	// Runtime state (owned by the ducker goroutine, guarded against SetGangs by mu)
+	mu           sync.Mutex
+	aboveSince   time.Time
+	ducked       atomic.Bool
+	releaseStart time.Time
+	base         map[*GangedFader]int64 // Gang values captured before ducking
+	lastSet      map[*GangedFader]int64 // Values most recently written by the ducker
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewDucker creates a ducker for a trigger level control and the gangs it reduces
+func NewDucker(config DuckerControl, trigger *scarlettctl.Control, gangs []*GangedFader) *Ducker {
+	return &Ducker{
+		config:  config,
+		trigger: trigger,
+		gangs:   gangs,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// GetName returns the ducker name
+func (d *Ducker) GetName() string {
+	return d.config.Name
+}
+
+// IsDucked returns true while the gangs are reduced or releasing (thread-safe)
+func (d *Ducker) IsDucked() bool {
+	return d.ducked.Load()
+}
+
+// Start begins sampling the trigger level in a background goroutine
+func (d *Ducker) Start() {
+	go func() {
+		defer close(d.done)
+		ticker := time.NewTicker(duckerInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.stop:
+				d.mu.Lock()
+				d.restore()
+				d.mu.Unlock()
+				return
+			case now := <-ticker.C:
+				d.Update(now)
+			}
+		}
+	}()
+}
+
+// Stop stops the ducker, restoring any reduced gangs immediately
+// Must only be called after Start; blocks until the goroutine has exited
+func (d *Ducker) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stop)
+		<-d.done
+	})
+}
+
+// SetGangs resolves the ducked gangs by name among gangs (e.g. after a profile switch)
+// A ducked gang carries on with its new instance; gangs missing from gangs are restored and
+// dropped
+func (d *Ducker) SetGangs(gangs []*GangedFader) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.ducked.Load() {
+		for _, gang := range rebindGangs(d.gangs, gangs, d.base, d.lastSet) {
+			d.set(gang, d.base[gang])
+			delete(d.base, gang)
+		}
+	}
+	d.gangs = resolveGangs(gangs, d.config.Gangs)
+}
+
+// Update samples the trigger level and advances the ducker state
+func (d *Ducker) Update(now time.Time) {
+	level, err := d.trigger.GetValue()
+	if err != nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	above := levelToDb(level, d.trigger.Max) > float64(d.config.ThresholdDb)
+
+	if above {
+		if d.aboveSince.IsZero() {
+			d.aboveSince = now
+		}
+		if now.Sub(d.aboveSince) >= d.config.Hold {
+			d.duck()
+		}
+		return
+	}
+
+	d.aboveSince = time.Time{}
+	if d.ducked.Load() {
+		d.release(now)
+	}
+}
+
+// duck captures the current gang values (on the first call) and reduces the gangs
+func (d *Ducker) duck() {
+	if !d.ducked.Load() {
+		d.base = make(map[*GangedFader]int64)
+		d.lastSet = make(map[*GangedFader]int64)
+		for _, gang := range d.gangs {
+			d.base[gang] = gang.GetCurrentValue()
+		}
+		d.ducked.Store(true)
+	}
+	d.releaseStart = time.Time{}
+
+	for _, gang := range d.gangs {
+		base, ok := d.base[gang]
+		if !ok {
+			continue
+		}
+		d.set(gang, gang.DbToValue(gang.ValueToDb(base)-float64(d.config.DepthDb)))
+	}
+}
+
+// release ramps the gangs back to their captured values in dB space
+func (d *Ducker) release(now time.Time) {
+	if d.releaseStart.IsZero() {
+		d.releaseStart = now
+	}
+
+	t := 1.0
+	if d.config.Release > 0 {
+		t = math.Min(1.0, float64(now.Sub(d.releaseStart))/float64(d.config.Release))
+	}
+
+	for _, gang := range d.gangs {
+		base, ok := d.base[gang]
+		if !ok {
+			continue
+		}
+		baseDb := gang.ValueToDb(base)
+		if t >= 1.0 || math.IsInf(baseDb, -1) {
+			d.set(gang, base)
+			continue
+		}
+		d.set(gang, gang.DbToValue(baseDb-float64(d.config.DepthDb)*(1.0-t)))
+	}
+
+	if t >= 1.0 {
+		d.ducked.Store(false)
+		d.base = nil
+		d.lastSet = nil
+	}
+}
+
+// restore immediately returns any reduced gangs to their captured values
+func (d *Ducker) restore() {
+	if !d.ducked.Load() {
+		return
+	}
+	for gang, base := range d.base {
+		d.set(gang, base)
+	}
+	d.ducked.Store(false)
+}
+
+// set writes a value to a gang unless the user has taken over the gang since the last write
+func (d *Ducker) set(gang *GangedFader, value int64) {
+	if last, ok := d.lastSet[gang]; ok && gang.GetCurrentValue() != last {
+		// Someone else moved the fader; stop managing this gang
+		delete(d.base, gang)
+		return
+	}
+	if err := gang.HandleUIChange(value); err != nil {
+		log.Printf("Ducker %s: failed to write %s: %v", d.config.Name, gang.GetName(), err)
+	}
+	d.lastSet[gang] = value
+}
+
+// resolveGangs returns the gangs named in names, in order, skipping names gangs lacks
+func resolveGangs(gangs []*GangedFader, names []string) []*GangedFader {
+	var resolved []*GangedFader
+	for _, name := range names {
+		if gang := findGang(gangs, name); gang != nil {
+			resolved = append(resolved, gang)
+		}
+	}
+	return resolved
+}
+
+// rebindGangs moves the captured and last written values of gangs to the gangs of the same name
+// in next, and returns the gangs next lacks (their entries are left for the caller to restore)
+func rebindGangs(gangs, next []*GangedFader, base, lastSet map[*GangedFader]int64) []*GangedFader {
+	var dropped []*GangedFader
+	for _, gang := range gangs {
+		value, ok := base[gang]
+		if !ok {
+			continue
+		}
+		replacement := findGang(next, gang.GetName())
+		if replacement == nil {
+			dropped = append(dropped, gang)
+			continue
+		}
+		delete(base, gang)
+		base[replacement] = value
+		if last, ok := lastSet[gang]; ok {
+			delete(lastSet, gang)
+			lastSet[replacement] = last
+		}
+	}
+	return dropped
+}
@@ -0,0 +1,139 @@
+package sessionmixer
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// ErrControlNotFound is wrapped by errors for configured controls missing from the card
+var ErrControlNotFound = errors.New("control not found")
+
+// ErrReadOnly is returned when writing to a read-only display channel
+var ErrReadOnly = errors.New("read-only display channel")
+
+// ErrObserver is returned when writing to the hardware in read-only observer mode
+var ErrObserver = errors.New("read-only observer mode")
+
+// ErrInvalidConfig is wrapped by configuration loading errors
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// ErrNoInstance is wrapped by control socket errors when no mixer is running
+var ErrNoInstance = errors.New("no running instance")
+
+// ErrWriteFailed is returned when writing a value to a hardware control fails
+type ErrWriteFailed struct {
+	Control string
+	Cause   error
+}
+
+func (e *ErrWriteFailed) Error() string {
+	return fmt.Sprintf("failed to write %s: %v", e.Control, e.Cause)
+}
+
+func (e *ErrWriteFailed) Unwrap() error {
+	return e.Cause
+}
+
+// ErrOutOfRange is returned when a value outside a gang's or control's range was requested;
+// the write went ahead with the value clamped to the range
+type ErrOutOfRange struct {
+	Name  string // Gang or control
+	Value int64  // Requested value
+	Min   int64
+	Max   int64
+}
+
+func (e *ErrOutOfRange) Error() string {
+	return fmt.Sprintf("%s: value %d out of range [%d..%d], clamped", e.Name, e.Value, e.Min, e.Max)
+}
+
+// ErrNoGraphics is returned when the mixer window cannot be opened (no display, no usable
+// OpenGL context)
+type ErrNoGraphics struct {
+	Cause error
+}
+
+func (e *ErrNoGraphics) Error() string {
+	return fmt.Sprintf("cannot open the mixer window: %v", e.Cause)
+}
+
+func (e *ErrNoGraphics) Unwrap() error {
+	return e.Cause
+}
+
+// Process exit codes for the CLI
+const (
+	ExitFailure         = 1 // Any other error
+	ExitInvalidConfig   = 2
+	ExitControlNotFound = 3
+	ExitWriteFailed     = 4
+	ExitNoGraphics      = 5
+)
+
+// ExitCode maps an error to the CLI exit code for its type
+func ExitCode(err error) int {
+	var writeErr *ErrWriteFailed
+	var graphicsErr *ErrNoGraphics
+	switch {
+	case errors.Is(err, ErrInvalidConfig):
+		return ExitInvalidConfig
+	case errors.Is(err, ErrControlNotFound):
+		return ExitControlNotFound
+	case errors.As(err, &writeErr):
+		return ExitWriteFailed
+	case errors.As(err, &graphicsErr):
+		return ExitNoGraphics
+	default:
+		return ExitFailure
+	}
+}
+
+// findControl looks up a control by name or alias, wrapping lookup failures in ErrControlNotFound
+func findControl(card *scarlettctl.Card, name string) (*scarlettctl.Control, error) {
+	resolved := ResolveControlName(name)
+	control, err := card.FindControl(resolved)
+	if err != nil {
+		if resolved != name {
+			return nil, fmt.Errorf("%w: '%s' (alias of '%s') (%v)", ErrControlNotFound, resolved, name, err)
+		}
+		return nil, fmt.Errorf("%w: '%s' (%v)", ErrControlNotFound, name, err)
+	}
+	return control, nil
+}
+
+// uiErrorDuration is how long a failed UI action stays in the error banner
+const uiErrorDuration = 5 * time.Second
+
+// Last error from a UI action, shown in the mixer's error banner
+var uiError struct {
+	mu  sync.Mutex
+	err error
+	at  time.Time
+}
+
+// logError logs a non-nil error from a UI action and shows it in the error banner
+func logError(err error) {
+	if err == nil {
+		return
+	}
+	log.Printf("%v", err)
+	uiError.mu.Lock()
+	uiError.err = err
+	uiError.at = time.Now()
+	uiError.mu.Unlock()
+}
+
+// recentUIError returns the last UI error if it is still within the banner duration
+func recentUIError() error {
+	uiError.mu.Lock()
+	defer uiError.mu.Unlock()
+	if uiError.err == nil || time.Since(uiError.at) > uiErrorDuration {
+		return nil
+	}
+	return uiError.err
+}
@@ -0,0 +1,253 @@
+package sessionmixer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// evdev event types and ioctls (uapi/linux/input.h)
+const (
+	evKey = 0x01
+	evAbs = 0x03
+
+	absInfoSize = 24
+)
+
+var evdevIoctlGrab = ioc(iocWrite, 'E', 0x90, 4)
+
+// evdevIoctlAbsInfo returns the request reading the range of an axis
+func evdevIoctlAbsInfo(axis int) uintptr {
+	return ioc(iocRead, 'E', 0x40+uintptr(axis), absInfoSize)
+}
+
+// evdevEventSize is the size of struct input_event: a timeval of two longs, then type, code
+// and value
+var evdevEventSize = int(2*unsafe.Sizeof(int(0))) + 8
+
+// evdevKeys names the common gamepad buttons and footswitch keys
+var evdevKeys = map[string]int{
+	"BTN_0": 0x100, "BTN_1": 0x101, "BTN_2": 0x102, "BTN_3": 0x103, "BTN_4": 0x104,
+	"BTN_5": 0x105, "BTN_6": 0x106, "BTN_7": 0x107, "BTN_8": 0x108, "BTN_9": 0x109,
+	"BTN_LEFT": 0x110, "BTN_RIGHT": 0x111, "BTN_MIDDLE": 0x112,
+	"BTN_TRIGGER": 0x120, "BTN_THUMB": 0x121, "BTN_THUMB2": 0x122, "BTN_TOP": 0x123,
+	"BTN_TOP2": 0x124, "BTN_PINKIE": 0x125, "BTN_BASE": 0x126, "BTN_BASE2": 0x127,
+	"BTN_SOUTH": 0x130, "BTN_A": 0x130, "BTN_EAST": 0x131, "BTN_B": 0x131, "BTN_C": 0x132,
+	"BTN_NORTH": 0x133, "BTN_X": 0x133, "BTN_WEST": 0x134, "BTN_Y": 0x134, "BTN_Z": 0x135,
+	"BTN_TL": 0x136, "BTN_TR": 0x137, "BTN_TL2": 0x138, "BTN_TR2": 0x139,
+	"BTN_SELECT": 0x13A, "BTN_START": 0x13B, "BTN_MODE": 0x13C, "BTN_THUMBL": 0x13D, "BTN_THUMBR": 0x13E,
+	"BTN_DPAD_UP": 0x220, "BTN_DPAD_DOWN": 0x221, "BTN_DPAD_LEFT": 0x222, "BTN_DPAD_RIGHT": 0x223,
+	"KEY_1": 2, "KEY_2": 3, "KEY_3": 4, "KEY_4": 5, "KEY_5": 6, "KEY_6": 7, "KEY_7": 8, "KEY_8": 9, "KEY_9": 10, "KEY_0": 11,
+	"KEY_Q": 16, "KEY_W": 17, "KEY_E": 18, "KEY_R": 19, "KEY_T": 20, "KEY_Y": 21, "KEY_U": 22, "KEY_I": 23, "KEY_O": 24, "KEY_P": 25,
+	"KEY_A": 30, "KEY_S": 31, "KEY_D": 32, "KEY_F": 33, "KEY_G": 34, "KEY_H": 35, "KEY_J": 36, "KEY_K": 37, "KEY_L": 38,
+	"KEY_Z": 44, "KEY_X": 45, "KEY_C": 46, "KEY_V": 47, "KEY_B": 48, "KEY_N": 49, "KEY_M": 50,
+	"KEY_ENTER": 28, "KEY_SPACE": 57, "KEY_TAB": 15, "KEY_ESC": 1,
+	"KEY_F1": 59, "KEY_F2": 60, "KEY_F3": 61, "KEY_F4": 62, "KEY_F5": 63, "KEY_F6": 64,
+	"KEY_F7": 65, "KEY_F8": 66, "KEY_F9": 67, "KEY_F10": 68, "KEY_F11": 87, "KEY_F12": 88,
+	"KEY_UP": 103, "KEY_PAGEUP": 104, "KEY_LEFT": 105, "KEY_RIGHT": 106, "KEY_DOWN": 108, "KEY_PAGEDOWN": 109,
+	"KEY_MUTE": 113, "KEY_VOLUMEDOWN": 114, "KEY_VOLUMEUP": 115,
+	"KEY_NEXTSONG": 163, "KEY_PLAYPAUSE": 164, "KEY_PREVIOUSSONG": 165,
+}
+
+// evdevAxes names the common gamepad axes
+var evdevAxes = map[string]int{
+	"ABS_X": 0x00, "ABS_Y": 0x01, "ABS_Z": 0x02, "ABS_RX": 0x03, "ABS_RY": 0x04, "ABS_RZ": 0x05,
+	"ABS_THROTTLE": 0x06, "ABS_RUDDER": 0x07, "ABS_WHEEL": 0x08, "ABS_GAS": 0x09, "ABS_BRAKE": 0x0A,
+	"ABS_HAT0X": 0x10, "ABS_HAT0Y": 0x11, "ABS_HAT1X": 0x12, "ABS_HAT1Y": 0x13,
+}
+
+// evdevInput is a parsed binding input: a key, or one direction of an axis
+type evdevInput struct {
+	axis bool
+	code int
+	up   bool // Axis direction: toward the maximum
+}
+
+// parseEvdevInput parses a key name or event code, or an axis name with a + or - direction
+func parseEvdevInput(input string) (evdevInput, error) {
+	input = strings.ToUpper(strings.TrimSpace(input))
+	if strings.HasPrefix(input, "ABS_") {
+		name, up := strings.TrimSuffix(input, "+"), strings.HasSuffix(input, "+")
+		if !up {
+			if !strings.HasSuffix(input, "-") {
+				return evdevInput{}, fmt.Errorf("axis '%s' needs a direction (+ or -)", input)
+			}
+			name = strings.TrimSuffix(input, "-")
+		}
+		code, ok := evdevAxes[name]
+		if !ok {
+			return evdevInput{}, fmt.Errorf("unknown axis '%s'", name)
+		}
+		return evdevInput{axis: true, code: code, up: up}, nil
+	}
+	if code, ok := evdevKeys[input]; ok {
+		return evdevInput{code: code}, nil
+	}
+	code, err := strconv.Atoi(input)
+	if err != nil || code <= 0 || code > 0x2FF {
+		return evdevInput{}, fmt.Errorf("unknown key or button '%s'", input)
+	}
+	return evdevInput{code: code}, nil
+}
+
+// evdevBinding is a configured input with its resolved action and state
+type evdevBinding struct {
+	config EvdevBinding
+	input  evdevInput
+	action *buttonAction
+
+	// Axis threshold: pressed beyond it in the binding's direction
+	threshold int32
+	pressed   bool
+}
+
+// EvdevDevice maps the buttons and axes of a Linux input device (a gamepad, a USB footswitch)
+// onto button actions, for musicians whose hands are busy
+type EvdevDevice struct {
+	config   EvdevConfig
+	file     *os.File
+	bindings []*evdevBinding
+
+	mu    sync.Mutex
+	gangs []*GangedFader
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewEvdevDevice opens an input device and resolves its bindings against gangs and targets
+func NewEvdevDevice(config EvdevConfig, gangs []*GangedFader, targets ButtonTargets) (*EvdevDevice, error) {
+	file, err := os.OpenFile(config.Device, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input device '%s': %w", config.Device, err)
+	}
+	ed := &EvdevDevice{
+		config: config,
+		file:   file,
+		gangs:  gangs,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	for i, bc := range config.Bindings {
+		binding, err := ed.bind(bc, gangs, targets)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("input binding %d (%s): %w", i, bc.Input, err)
+		}
+		ed.bindings = append(ed.bindings, binding)
+	}
+	if config.Grab {
+		if err := ioctlValue(file, evdevIoctlGrab, 1); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to grab input device '%s': %w", config.Device, err)
+		}
+	}
+	return ed, nil
+}
+
+// bind resolves one binding; an axis binding reads the axis range for its threshold
+func (ed *EvdevDevice) bind(config EvdevBinding, gangs []*GangedFader, targets ButtonTargets) (*evdevBinding, error) {
+	input, err := parseEvdevInput(config.Input)
+	if err != nil {
+		return nil, err
+	}
+	action, err := newButtonAction(config.Action, config.Gang, config.Scene, config.Switch, config.Profile, gangs, targets, WriteSourceInput)
+	if err != nil {
+		return nil, err
+	}
+	binding := &evdevBinding{config: config, input: input, action: action}
+	if input.axis {
+		info := make([]byte, absInfoSize)
+		if err := ioctl(ed.file, evdevIoctlAbsInfo(input.code), info); err != nil {
+			return nil, fmt.Errorf("device has no axis '%s': %w", config.Input, err)
+		}
+		binding.threshold = axisThreshold(int32(binary.NativeEndian.Uint32(info[4:8])), int32(binary.NativeEndian.Uint32(info[8:12])), input.up)
+	}
+	return binding, nil
+}
+
+// axisThreshold returns the value past which an axis counts as pushed in a direction: three
+// quarters of the way from the center, so a hat (-1 to 1) and a stick both need a clear push
+func axisThreshold(lo, hi int32, up bool) int32 {
+	center := lo + (hi-lo)/2
+	if up {
+		return center + max(1, (hi-center)*3/4)
+	}
+	return center - max(1, (center-lo)*3/4)
+}
+
+// SetGangs replaces the gangs mute bindings act on (e.g. after a profile switch)
+func (ed *EvdevDevice) SetGangs(gangs []*GangedFader) {
+	ed.mu.Lock()
+	defer ed.mu.Unlock()
+	ed.gangs = gangs
+}
+
+// Start reads the device in a background goroutine
+func (ed *EvdevDevice) Start() {
+	go func() {
+		defer close(ed.done)
+		buf := make([]byte, 64*evdevEventSize)
+		for {
+			n, err := ed.file.Read(buf)
+			if err != nil {
+				select {
+				case <-ed.stop:
+				default:
+					log.Printf("Input device %s: read failed: %v", ed.config.Device, err)
+				}
+				return
+			}
+			for ev := buf[:n]; len(ev) >= evdevEventSize; ev = ev[evdevEventSize:] {
+				typ := binary.NativeEndian.Uint16(ev[evdevEventSize-8:])
+				code := binary.NativeEndian.Uint16(ev[evdevEventSize-6:])
+				value := int32(binary.NativeEndian.Uint32(ev[evdevEventSize-4:]))
+				ed.handleEvent(typ, int(code), value)
+			}
+		}
+	}()
+}
+
+// Stop closes the device; blocks until the reader has exited
+func (ed *EvdevDevice) Stop() {
+	ed.stopOnce.Do(func() {
+		close(ed.stop)
+		ed.file.Close()
+		<-ed.done
+	})
+}
+
+// handleEvent acts on the bindings of a key or axis event; key repeats are ignored
+func (ed *EvdevDevice) handleEvent(typ uint16, code int, value int32) {
+	if (typ != evKey || value == 2) && typ != evAbs {
+		return
+	}
+	ed.mu.Lock()
+	gangs := ed.gangs
+	ed.mu.Unlock()
+	for _, binding := range ed.bindings {
+		if binding.input.code != code || binding.input.axis != (typ == evAbs) {
+			continue
+		}
+		pressed := value != 0
+		if binding.input.axis {
+			pressed = value >= binding.threshold
+			if !binding.input.up {
+				pressed = value <= binding.threshold
+			}
+		}
+		if pressed == binding.pressed {
+			continue
+		}
+		binding.pressed = pressed
+		if err := binding.action.handle(gangs, pressed); err != nil {
+			log.Printf("Input device %s: %s (%s) failed: %v", ed.config.Device, binding.config.Input, binding.config.Action, err)
+		}
+	}
+}
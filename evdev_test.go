@@ -0,0 +1,44 @@
+package sessionmixer
+
+import "testing"
+
+func TestParseEvdevInput(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  evdevInput
+	}{
+		{"BTN_SOUTH", evdevInput{code: 0x130}},
+		{"key_pagedown", evdevInput{code: 109}},
+		{"304", evdevInput{code: 304}},
+		{"ABS_HAT0X+", evdevInput{axis: true, code: 0x10, up: true}},
+		{"ABS_Y-", evdevInput{axis: true, code: 0x01}},
+	} {
+		got, err := parseEvdevInput(tc.input)
+		if err != nil || got != tc.want {
+			t.Errorf("parseEvdevInput(%s) = %+v, %v, want %+v", tc.input, got, err, tc.want)
+		}
+	}
+	for _, input := range []string{"ABS_X", "ABS_NOPE+", "BTN_NOPE", "0"} {
+		if _, err := parseEvdevInput(input); err == nil {
+			t.Errorf("parseEvdevInput(%s) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestAxisThreshold(t *testing.T) {
+	// A hat needs a full push; a stick three quarters of the way out
+	for _, tc := range []struct {
+		lo, hi int32
+		up     bool
+		want   int32
+	}{
+		{-1, 1, true, 1},
+		{-1, 1, false, -1},
+		{0, 255, true, 223},
+		{0, 255, false, 32},
+	} {
+		if got := axisThreshold(tc.lo, tc.hi, tc.up); got != tc.want {
+			t.Errorf("axisThreshold(%d, %d, %v) = %d, want %d", tc.lo, tc.hi, tc.up, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+const (
+	// ExternalFollow - the gang follows changes made outside sessionmixer (default)
+	ExternalFollow = "follow"
+
+	// ExternalIgnore - changes made outside sessionmixer are overwritten with the gang's value
+	ExternalIgnore = "ignore"
+
+	// ExternalPrompt - changes made outside sessionmixer are held until followed or restored
+	// in the UI
+	ExternalPrompt = "prompt"
+)
+
+// externalEchoWindow is how long after a write events are taken as its echo rather than an
+// external change; it also limits how often an ignored change is overwritten when another
+// application keeps setting the control
+const externalEchoWindow = 250 * time.Millisecond
+
+// SetExternal sets what the gang does when its controls are changed outside sessionmixer:
+// ExternalFollow (or ""), ExternalIgnore or ExternalPrompt
+func (gf *GangedFader) SetExternal(policy string) {
+	gf.external = policy
+}
+
+// GetExternal returns what the gang does when its controls are changed outside sessionmixer
+func (gf *GangedFader) GetExternal() string {
+	if gf.external == "" {
+		return ExternalFollow
+	}
+	return gf.external
+}
+
+// holdExternal applies the external policy to a change of ch to value; false if the gang
+// follows it. Echoes of our own writes and changes in observer mode are always followed
+func (gf *GangedFader) holdExternal(ch *MixerChannel, value int64) bool {
+	if gf.GetExternal() == ExternalFollow || gf.display != "" || observer.Load() || !ch.isExternal(value) {
+		gf.externalMu.Lock()
+		delete(gf.externalHeld, ch)
+		gf.externalMu.Unlock()
+		return false
+	}
+	atomic.StoreInt64(&ch.lastHWValue, value)
+
+	if gf.external == ExternalIgnore {
+		if err := ch.restore(); err != nil {
+			log.Printf("%s: failed to restore after an external change: %v", gf.name, err)
+		}
+		return true
+	}
+	gf.externalMu.Lock()
+	defer gf.externalMu.Unlock()
+	if gf.externalHeld == nil {
+		gf.externalHeld = make(map[*MixerChannel]int64)
+	}
+	gf.externalHeld[ch] = value
+	return true
+}
+
+// GetExternalChange returns the value of an external change waiting to be followed or restored
+func (gf *GangedFader) GetExternalChange() (int64, bool) {
+	gf.externalMu.Lock()
+	defer gf.externalMu.Unlock()
+	for _, ch := range gf.channels {
+		if value, ok := gf.externalHeld[ch]; ok {
+			return gf.fromChannelValue(ch, value), true
+		}
+	}
+	return 0, false
+}
+
+// FollowExternal takes over the held external changes, as if the gang followed them
+func (gf *GangedFader) FollowExternal() {
+	gf.externalMu.Lock()
+	held := gf.externalHeld
+	gf.externalHeld = nil
+	gf.externalMu.Unlock()
+	for _, ch := range gf.channels {
+		if value, ok := held[ch]; ok {
+			gf.followHWChange(ch, value)
+		}
+	}
+}
+
+// RestoreExternal overwrites the held external changes with the gang's value
+func (gf *GangedFader) RestoreExternal() error {
+	gf.externalMu.Lock()
+	held := gf.externalHeld
+	gf.externalHeld = nil
+	gf.externalMu.Unlock()
+	var lastErr error
+	for _, ch := range gf.channels {
+		if _, ok := held[ch]; ok {
+			if err := ch.restore(); err != nil {
+				lastErr = err
+			}
+		}
+	}
+	return lastErr
+}
+
+// isExternal returns true if a hardware change to value was made outside sessionmixer rather
+// than echoing one of our writes
+func (ch *MixerChannel) isExternal(value int64) bool {
+	return value != atomic.LoadInt64(&ch.lastUIValue) && time.Since(time.Unix(0, ch.wroteAt.Load())) > externalEchoWindow
+}
+
+// restore writes the last UI value back to the hardware, even though it is unchanged
+func (ch *MixerChannel) restore() error {
+	if observer.Load() {
+		return fmt.Errorf("%s: %w", ch.control.Name, ErrObserver)
+	}
+	value := atomic.LoadInt64(&ch.lastUIValue)
+	started := time.Now()
+	ch.wroteAt.Store(started.UnixNano())
+	err := ch.io.SetValue(value)
+	Stats.RecordWrite(ch.control, value, started)
+	captureWrite(ch.control, value, err)
+	if err != nil {
+		return &ErrWriteFailed{Control: ch.control.Name, Cause: err}
+	}
+	return nil
+}
+
+// drawExternalConfirm renders the choice for the first gang with a held external change
+func (sm *SessionMixer) drawExternalConfirm() {
+	if sm.externalGang == nil && sm.jumping == nil {
+		for _, gang := range sm.gangs {
+			if _, ok := gang.GetExternalChange(); ok {
+				sm.externalGang = gang
+				imgui.OpenPopupStr(tr("External Change"))
+				break
+			}
+		}
+	}
+	if !imgui.BeginPopupModalV(tr("External Change"), nil, imgui.WindowFlagsAlwaysAutoResize) {
+		return
+	}
+	gang := sm.externalGang
+	var value int64
+	held := false
+	if gang != nil {
+		value, held = gang.GetExternalChange()
+	}
+	if held {
+		imgui.Text(trf("%s was changed outside sessionmixer, from %s to %s", gang.GetName(), gang.FormatValue(gang.GetCurrentValue()), gang.FormatValue(value)))
+		imgui.TextDisabled(tr("By another application or on the interface"))
+		if imgui.Button(tr("Follow")) {
+			gang.FollowExternal()
+			sm.externalGang = nil
+			imgui.CloseCurrentPopup()
+		}
+		imgui.SameLine()
+		if imgui.Button(tr("Restore")) {
+			logError(gang.RestoreExternal())
+			sm.externalGang = nil
+			imgui.CloseCurrentPopup()
+		}
+	} else {
+		// Resolved elsewhere, e.g. the control was set back to the gang's value
+		sm.externalGang = nil
+		imgui.CloseCurrentPopup()
+	}
+	imgui.EndPopup()
+}
@@ -0,0 +1,59 @@
+package sessionmixer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExternalIgnoreRestoresValue(t *testing.T) {
+	gang, fakes := newFakeGang(t, "Vocal", 0, 160, 80, 1)
+	gang.SetExternal(ExternalIgnore)
+
+	gang.HandleHWChange(1, 40)
+	if got := gang.GetCurrentValue(); got != 80 {
+		t.Errorf("gang followed an ignored change to %d", got)
+	}
+	if got := fakes[0].getWrites(); !slices.Equal(got, []int64{80}) {
+		t.Errorf("writes = %v, want the gang's value restored", got)
+	}
+
+	// The echo of our own write is not an external change
+	if err := gang.HandleUIChange(100); err != nil {
+		t.Fatal(err)
+	}
+	gang.HandleHWChange(1, 100)
+	if got := fakes[0].getWrites(); len(got) != 2 {
+		t.Errorf("writes = %v, the echo was overwritten", got)
+	}
+}
+
+func TestExternalPromptHoldsChange(t *testing.T) {
+	gang, fakes := newFakeGang(t, "Vocal", 0, 160, 80, 1, 2)
+	gang.SetExternal(ExternalPrompt)
+
+	gang.HandleHWChange(2, 40)
+	if got := gang.GetCurrentValue(); got != 80 {
+		t.Errorf("gang followed a held change to %d", got)
+	}
+	if value, ok := gang.GetExternalChange(); !ok || value != 40 {
+		t.Fatalf("held change = %d, %v; want 40", value, ok)
+	}
+	gang.FollowExternal()
+	if got := gang.GetCurrentValue(); got != 40 {
+		t.Errorf("gang = %d after following, want 40", got)
+	}
+	if _, ok := gang.GetExternalChange(); ok {
+		t.Error("change still held after following it")
+	}
+
+	gang.HandleHWChange(1, 20)
+	if err := gang.RestoreExternal(); err != nil {
+		t.Fatal(err)
+	}
+	if got := fakes[0].getWrites(); !slices.Equal(got, []int64{80}) {
+		t.Errorf("writes = %v, want the held channel restored", got)
+	}
+	if got := fakes[1].getWrites(); len(got) != 0 {
+		t.Errorf("writes = %v to a channel that was not changed", got)
+	}
+}
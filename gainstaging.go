@@ -0,0 +1,312 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/michaelquigley/scarlettctl"
+)
+
+const (
+	// gainStagingInterval is how often input levels are sampled during calibration
+	gainStagingInterval = 20 * time.Millisecond
+
+	defaultGainTargetMinDb = -18.0
+	defaultGainTargetMaxDb = -10.0
+	defaultGainDuration    = 10 * time.Second
+	defaultGainStepDb      = 1.0
+)
+
+// GainSuggestion is the outcome of a calibration for one input
+type GainSuggestion struct {
+	Input         int
+	PeakDb        float64 // -Inf if no signal was seen
+	CurrentGain   int64
+	SuggestedGain int64
+}
+
+// HasSignal returns true if the input produced any signal during calibration
+func (s GainSuggestion) HasSignal() bool {
+	return !math.IsInf(s.PeakDb, -1)
+}
+
+// NeedsChange returns true if the suggested gain differs from the current gain
+func (s GainSuggestion) NeedsChange() bool {
+	return s.SuggestedGain != s.CurrentGain
+}
+
+// stagedInput pairs an input's gain control with the level control metering it
+type stagedInput struct {
+	input *InputChannel
+	level *scarlettctl.Control
+}
+
+// GainStager watches input levels over a calibration period and suggests preamp gain
+// changes that bring each input's peak into a target range
+type GainStager struct {
+	inputs   []stagedInput
+	minDb    float64
+	maxDb    float64
+	duration time.Duration
+	stepDb   float64
+
+	mu          sync.Mutex
+	running     bool
+	started     time.Time
+	peaks       map[int]int64
+	suggestions []GainSuggestion
+	stop        chan struct{}
+	confirm     bool // Open the apply confirmation on the next frame
+}
+
+// NewGainStager pairs the configured level controls with the inputs' gain controls
+func NewGainStager(config *GainStagingConfig, card *scarlettctl.Card, inputs *InputPanel) (*GainStager, error) {
+	gs := &GainStager{
+		minDb:    float64(config.TargetMinDb),
+		maxDb:    float64(config.TargetMaxDb),
+		duration: config.Duration,
+		stepDb:   float64(config.StepDb),
+	}
+	if gs.minDb == 0 && gs.maxDb == 0 {
+		gs.minDb, gs.maxDb = defaultGainTargetMinDb, defaultGainTargetMaxDb
+	}
+	if gs.minDb > gs.maxDb {
+		return nil, fmt.Errorf("gain staging target_min_db must not exceed target_max_db")
+	}
+	if gs.duration <= 0 {
+		gs.duration = defaultGainDuration
+	}
+	if gs.stepDb <= 0 {
+		gs.stepDb = defaultGainStepDb
+	}
+
+	levels := make(map[int]string)
+	for _, level := range config.Levels {
+		if _, ok := levels[level.Input]; ok {
+			return nil, fmt.Errorf("gain staging: input %d is listed more than once", level.Input)
+		}
+		levels[level.Input] = level.Level
+	}
+	for _, in := range inputs.GetInputs() {
+		name, ok := levels[in.GetNumber()]
+		if !ok {
+			continue
+		}
+		if in.GetGain() == nil {
+			return nil, fmt.Errorf("input %d has no gain control", in.GetNumber())
+		}
+		level, err := findControl(card, name)
+		if err != nil {
+			return nil, fmt.Errorf("input %d level: %w", in.GetNumber(), err)
+		}
+		gs.inputs = append(gs.inputs, stagedInput{input: in, level: level})
+	}
+	if len(gs.inputs) == 0 {
+		return nil, fmt.Errorf("gain staging: none of the configured inputs exist on this device")
+	}
+	sort.Slice(gs.inputs, func(i, j int) bool { return gs.inputs[i].input.GetNumber() < gs.inputs[j].input.GetNumber() })
+
+	return gs, nil
+}
+
+// Start begins a calibration period; ignored while one is running
+func (gs *GainStager) Start() {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if gs.running {
+		return
+	}
+	gs.running = true
+	gs.started = time.Now()
+	gs.peaks = make(map[int]int64)
+	gs.suggestions = nil
+	gs.stop = make(chan struct{})
+
+	go gs.calibrate(gs.stop)
+}
+
+// Cancel aborts a running calibration without producing suggestions
+func (gs *GainStager) Cancel() {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if gs.running {
+		close(gs.stop)
+		gs.running = false
+	}
+}
+
+// calibrate samples the input levels until the period ends or stop is closed
+func (gs *GainStager) calibrate(stop chan struct{}) {
+	ticker := time.NewTicker(gainStagingInterval)
+	defer ticker.Stop()
+	deadline := time.After(gs.duration)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-deadline:
+			gs.finish()
+			return
+		case <-ticker.C:
+			for _, si := range gs.inputs {
+				value, err := si.level.GetValue()
+				if err != nil {
+					continue
+				}
+				gs.mu.Lock()
+				if value > gs.peaks[si.input.GetNumber()] {
+					gs.peaks[si.input.GetNumber()] = value
+				}
+				gs.mu.Unlock()
+			}
+		}
+	}
+}
+
+// finish computes the suggestions from the observed peaks
+func (gs *GainStager) finish() {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	center := (gs.minDb + gs.maxDb) / 2
+	gs.suggestions = nil
+	for _, si := range gs.inputs {
+		gain := si.input.GetGain()
+		control := gain.GetControl()
+		current := gain.GetCurrentValue()
+		s := GainSuggestion{
+			Input:         si.input.GetNumber(),
+			PeakDb:        levelToDb(gs.peaks[si.input.GetNumber()], si.level.Max),
+			CurrentGain:   current,
+			SuggestedGain: current,
+		}
+		if s.HasSignal() && (s.PeakDb < gs.minDb || s.PeakDb > gs.maxDb) {
+			steps := int64(math.Round((center - s.PeakDb) / gs.stepDb))
+			s.SuggestedGain = max(control.Min, min(control.Max, current+steps))
+		}
+		gs.suggestions = append(gs.suggestions, s)
+	}
+	gs.running = false
+}
+
+// IsRunning returns true during a calibration period
+func (gs *GainStager) IsRunning() bool {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return gs.running
+}
+
+// Progress returns the fraction (0.0-1.0) of the calibration period elapsed
+func (gs *GainStager) Progress() float32 {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if !gs.running {
+		return 0
+	}
+	return float32(math.Min(1, float64(time.Since(gs.started))/float64(gs.duration)))
+}
+
+// GetSuggestions returns the suggestions from the last completed calibration
+func (gs *GainStager) GetSuggestions() []GainSuggestion {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return append([]GainSuggestion(nil), gs.suggestions...)
+}
+
+// Apply writes every suggested gain change to the hardware; the last error is returned
+func (gs *GainStager) Apply() error {
+	var lastErr error
+	for _, s := range gs.GetSuggestions() {
+		if !s.NeedsChange() {
+			continue
+		}
+		for _, si := range gs.inputs {
+			if si.input.GetNumber() != s.Input {
+				continue
+			}
+			if err := si.input.GetGain().HandleUIChange(s.SuggestedGain); err != nil {
+				log.Printf("Gain staging: failed to set input %d gain: %v", s.Input, err)
+				lastErr = err
+			}
+		}
+	}
+	return lastErr
+}
+
+// Draw renders the calibration controls, suggestions and apply confirmation
+func (gs *GainStager) Draw() {
+	imgui.Text(trf("Target peak: %.0f to %.0f dBFS over %s", gs.minDb, gs.maxDb, gs.duration))
+
+	if gs.IsRunning() {
+		imgui.ProgressBarV(gs.Progress(), scaledVec2(imgui.Vec2{X: 300, Y: 0}), "Listening...")
+		imgui.SameLine()
+		if imgui.Button(tr("Cancel##gain_staging")) {
+			gs.Cancel()
+		}
+		return
+	}
+	if imgui.Button(tr("Start Calibration")) {
+		gs.Start()
+	}
+
+	suggestions := gs.GetSuggestions()
+	if len(suggestions) == 0 {
+		return
+	}
+
+	changes := 0
+	if imgui.BeginTableV("gain_staging_table", 4, imgui.TableFlagsNone, scaledVec2(imgui.Vec2{X: 400, Y: 0}), 0.0) {
+		imgui.TableSetupColumn(tr("Input"))
+		imgui.TableSetupColumn(tr("Peak"))
+		imgui.TableSetupColumn(tr("Gain"))
+		imgui.TableSetupColumn(tr("Suggested"))
+		imgui.TableHeadersRow()
+		for _, s := range suggestions {
+			imgui.TableNextRow()
+			imgui.TableNextColumn()
+			imgui.Text(trf("%d", s.Input))
+			imgui.TableNextColumn()
+			imgui.Text(formatDb(s.PeakDb))
+			imgui.TableNextColumn()
+			imgui.Text(trf("%d", s.CurrentGain))
+			imgui.TableNextColumn()
+			switch {
+			case !s.HasSignal():
+				imgui.TextDisabled(tr("no signal"))
+			case s.NeedsChange():
+				imgui.Text(trf("%d (%+d)", s.SuggestedGain, s.SuggestedGain-s.CurrentGain))
+				changes++
+			default:
+				imgui.TextDisabled(tr("ok"))
+			}
+		}
+		imgui.EndTable()
+	}
+
+	if changes > 0 && imgui.Button(tr("Apply Suggestions")) {
+		gs.confirm = true
+	}
+	if gs.confirm {
+		imgui.OpenPopupStr(tr("Apply Gain Changes"))
+		gs.confirm = false
+	}
+	if imgui.BeginPopupModalV(tr("Apply Gain Changes"), nil, imgui.WindowFlagsAlwaysAutoResize) {
+		imgui.Text(trf("Change the preamp gain on %d input(s)?", changes))
+		if imgui.Button(tr("Confirm")) {
+			if err := gs.Apply(); err != nil {
+				log.Printf("Gain staging: %v", err)
+			}
+			imgui.CloseCurrentPopup()
+		}
+		imgui.SameLine()
+		if imgui.Button(tr("Cancel")) {
+			imgui.CloseCurrentPopup()
+		}
+		imgui.EndPopup()
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"slices"
+	"sync"
 	"sync/atomic"
+	"time"
 
-	"github.com/AllenDang/cimgui-go/imgui"
 	"github.com/michaelquigley/dfx"
 	"github.com/michaelquigley/scarlettctl"
 )
@@ -21,7 +23,8 @@ const (
 	// GangModeRelative - maintains relative offsets between controls (future)
 	GangModeRelative GangMode = "relative"
 
-	// GangModeScaled - scales to each control's range (future)
+	// GangModeScaled - scales to each control's range; used for gangs whose members
+	// have different ranges
 	GangModeScaled GangMode = "scaled"
 )
 
@@ -29,9 +32,11 @@ const (
 // This implements "ganging" where one UI fader controls multiple channels
 type GangedFader struct {
 	// Display properties
-	name string
-	unit string
-	mode GangMode
+	name        string
+	unit        string
+	mode        GangMode
+	description string
+	tags        []string
 
 	// The channels being ganged together
 	channels []*MixerChannel
@@ -51,10 +56,55 @@ type GangedFader struct {
 	// Taper configuration
 	taperDb float32 // If > 0, use DecibelTaper; otherwise LinearTaper
 
+	// Boolean gangs (mute switches, Air on a stereo pair...) are drawn as a single toggle
+	toggle bool
+
+	// Show raw values instead of dB for "db" gangs
+	showRaw atomic.Bool
+
+	// Read-only display channels (meter or readout) have no fader and never write
+	display string
+
+	// Context menu state
+	defaultValue int64        // Value restored by "reset to default" (unity unless configured; startup value for raw gangs)
+	muted        atomic.Bool  // Set by Mute; cleared by Unmute
+	unmuteValue  atomic.Int64 // Value to restore on Unmute
+	locked       atomic.Bool  // Locked gangs ignore fader changes in the UI
+	safe         atomic.Bool  // Recall-safe gangs are skipped by scene recalls and mass operations
+
+	// Output protection ceiling (see protection.go)
+	ceiling    int64        // Writes above this raw value are capped
+	hasCeiling bool         // The gang is protected
+	cappedAt   atomic.Int64 // When a write was last capped (UnixNano; 0 = never)
+
+	// Large jump guard (see jump.go)
+	jumpMaxDb   float64       // Largest change (dB) applied in one action; 0 = unguarded
+	jumpRamp    time.Duration // Ramp time for guarded jumps; 0 = confirm in the UI
+	jumpPending atomic.Bool   // A jump is waiting for confirmation
+	jumpTarget  atomic.Int64  // Value of the pending jump
+	jumpGen     atomic.Int64  // Bumped to cancel a ramp in progress
+
+	// Write policy (see policy.go)
+	writerMu  sync.Mutex
+	writer    string    // Source of the last claimed write
+	writtenAt time.Time // When it claimed the gang
+
+	// External change policy (see external.go)
+	external     string // ExternalFollow (""), ExternalIgnore or ExternalPrompt
+	externalMu   sync.Mutex
+	externalHeld map[*MixerChannel]int64 // External changes held for a prompt, per channel
+
 	// Level controls for signal indication (read-only)
 	levelControls []*scarlettctl.Control
+	levelPostFrom int            // Index of the first post (mix output) level control; len(levelControls) without any
+	levelPost     atomic.Bool    // Meter the post levels instead of the pre levels
+	levelValues   []atomic.Int64 // Last level per control, fed by hardware events (nil when polled)
 	levelMin      int64
 	levelMax      int64
+	levelGains    []float64      // Linear calibration gain per level control (nil = uncalibrated)
+	levelHistory  *LevelHistory  // Rolling history of normalized levels (nil without levels)
+	meterScale    MeterScale     // Meter range and color transitions the levels are normalized to
+	loudness      *LoudnessMeter // Loudness estimate fed by the history sampler (nil without levels)
 }
 
 // NewGangedFader creates a new ganged fader from multiple channels
@@ -65,11 +115,21 @@ func NewGangedFader(name, unit string, mode GangMode, channels []*MixerChannel,
 		return nil, fmt.Errorf("ganged fader must have at least 1 channels")
 	}
 
-	// For mirror mode, use the first channel's range
-	// Assume all channels have the same range (typical for mixer controls)
+	// The gang uses the first channel's range; members with a different range would receive
+	// out-of-range values in mirror mode, so such gangs are scaled instead
 	firstControl := channels[0].GetControl()
-	min := firstControl.Min
-	max := firstControl.Max
+	min, max := controlRange(firstControl)
+	for _, ch := range channels[1:] {
+		control := ch.GetControl()
+		if chMin, chMax := controlRange(control); chMin != min || chMax != max {
+			if mode == GangModeMirror {
+				log.Printf("Gang '%s': %s has range [%d..%d] but %s has [%d..%d]; scaling to each control's range",
+					name, control.Name, chMin, chMax, firstControl.Name, min, max)
+				mode = GangModeScaled
+			}
+			break
+		}
+	}
 
 	// Read initial value from first channel
 	initialValue := channels[0].GetCurrentValue()
@@ -84,12 +144,25 @@ func NewGangedFader(name, unit string, mode GangMode, channels []*MixerChannel,
 		max:           max,
 		taperDb:       taperDb,
 		levelControls: levelControls,
+		levelPostFrom: len(levelControls),
+		meterScale:    DefaultMeterScale,
 	}
 
 	// Get level control range from first level control (if any)
 	if len(levelControls) > 0 {
 		gf.levelMin = levelControls[0].Min
 		gf.levelMax = levelControls[0].Max
+		gf.levelHistory = NewLevelHistory(historySize)
+		gf.loudness = NewLoudnessMeter(historyInterval)
+	}
+
+	gf.toggle = firstControl.Type == scarlettctl.ControlTypeBoolean
+	gf.defaultValue = gf.DbToValue(0)
+	switch {
+	case gf.toggle:
+		gf.defaultValue = gf.min
+	case unit == "raw":
+		gf.defaultValue = initialValue // Unity means nothing on a raw scale; reset to the startup value
 	}
 
 	// Configure fader parameters
@@ -114,34 +187,10 @@ func (gf *GangedFader) createFaderParams() dfx.FaderParams {
 		Taper:       taper,
 	}
 
-	// Configure display format based on unit
-	switch gf.unit {
-	case "db":
-		// Scarlett mixer control dB conversion: logarithmic scale from -∞ to +12 dB
-		// This matches the formula used in alsa-scarlett-gui for mixer volumes
-		params.Format = func(normalized float32) string {
-			min := float32(gf.min)
-			max := float32(gf.max)
-			rawValue := normalized*(max-min) + min
-
-			// Handle mute/zero case
-			if rawValue <= min {
-				return "-∞ dB"
-			}
-
-			// Logarithmic conversion: 0 to max maps to -∞ to +12 dB
-			db := 20.0*math.Log10(float64(rawValue)/float64(gf.max)) + 12.0
-			return fmt.Sprintf("%.2f dB", db)
-		}
-	case "raw":
-		fallthrough
-	default:
-		params.Format = func(normalized float32) string {
-			min := float32(gf.min)
-			max := float32(gf.max)
-			value := int(normalized*(max-min) + min)
-			return fmt.Sprintf("%d", value)
-		}
+	// Display format follows the unit and the dB/raw display toggle
+	params.Format = func(normalized float32) string {
+		rawValue := float64(normalized)*float64(gf.max-gf.min) + float64(gf.min)
+		return gf.FormatValue(int64(math.Round(rawValue)))
 	}
 
 	return params
@@ -150,33 +199,49 @@ func (gf *GangedFader) createFaderParams() dfx.FaderParams {
 // HandleUIChange is called when the user changes the ganged fader
 // Writes to all ganged channels based on the gang mode
 func (gf *GangedFader) HandleUIChange(newValue int64) error {
-	// Value equality check
+	if gf.display != "" {
+		return fmt.Errorf("gang '%s': %w", gf.name, ErrReadOnly)
+	}
+	if observer.Load() {
+		return fmt.Errorf("gang '%s': %w", gf.name, ErrObserver)
+	}
+	captureChange(gf, newValue)
+
+	// Out-of-range values are clamped and reported once written; the ceiling is a soft limit
+	newValue, rangeErr := gf.ClampValue(newValue)
+	newValue = gf.capValue(newValue)
+
+	// Value equality check; diverged channels are always rewritten so the gang reconverges
 	oldValue := atomic.LoadInt64(&gf.lastValue)
-	if oldValue == newValue {
-		return nil
+	if oldValue == newValue && !gf.IsDiverged() {
+		return rangeErr
 	}
 
 	// Update cached value
 	atomic.StoreInt64(&gf.lastValue, newValue)
+	auditGangChange(gf, oldValue, newValue, gf.writeSource())
 
 	// Write to all ganged channels based on mode
+	var err error
 	switch gf.mode {
 	case GangModeMirror:
-		return gf.handleMirrorMode(newValue)
+		err = gf.handleMirrorMode(newValue)
 
 	case GangModeRelative:
 		// Future: implement relative mode (maintains offsets)
 		log.Printf("Relative gang mode not yet implemented, using mirror mode")
-		return gf.handleMirrorMode(newValue)
+		err = gf.handleMirrorMode(newValue)
 
 	case GangModeScaled:
-		// Future: implement scaled mode
-		log.Printf("Scaled gang mode not yet implemented, using mirror mode")
-		return gf.handleMirrorMode(newValue)
+		err = gf.handleScaledMode(newValue)
 
 	default:
 		return fmt.Errorf("unknown gang mode: %s", gf.mode)
 	}
+	if err != nil {
+		return err
+	}
+	return rangeErr
 }
 
 // handleMirrorMode writes the same value to all ganged channels
@@ -186,7 +251,22 @@ func (gf *GangedFader) handleMirrorMode(value int64) error {
 	for _, ch := range gf.channels {
 		// Write to each channel - HandleUIChange has its own equality check
 		if err := ch.HandleUIChange(value); err != nil {
-			log.Printf("Failed to write to %s: %v", ch.GetDisplayName(), err)
+			log.Printf("%s: %v", ch.GetDisplayName(), err)
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+// handleScaledMode writes the value to each ganged channel, mapped proportionally from the
+// gang's range to the channel's own range
+func (gf *GangedFader) handleScaledMode(value int64) error {
+	var lastErr error
+
+	for _, ch := range gf.channels {
+		if err := ch.HandleUIChange(gf.toChannelValue(ch, value)); err != nil {
+			log.Printf("%s: %v", ch.GetDisplayName(), err)
 			lastErr = err
 		}
 	}
@@ -194,26 +274,95 @@ func (gf *GangedFader) handleMirrorMode(value int64) error {
 	return lastErr
 }
 
+// toChannelValue maps a gang value to a channel's range (unchanged unless the gang is scaled)
+func (gf *GangedFader) toChannelValue(ch *MixerChannel, value int64) int64 {
+	chMin, chMax := controlRange(ch.GetControl())
+	if gf.mode != GangModeScaled || gf.max <= gf.min || (chMin == gf.min && chMax == gf.max) {
+		return value
+	}
+	scaled := float64(value-gf.min) * float64(chMax-chMin) / float64(gf.max-gf.min)
+	return max(chMin, min(chMax, chMin+int64(math.Round(scaled))))
+}
+
+// fromChannelValue maps a channel value back to the gang's range; the inverse of toChannelValue
+func (gf *GangedFader) fromChannelValue(ch *MixerChannel, value int64) int64 {
+	chMin, chMax := controlRange(ch.GetControl())
+	if gf.mode != GangModeScaled || chMax <= chMin || (chMin == gf.min && chMax == gf.max) {
+		return value
+	}
+	scaled := float64(value-chMin) * float64(gf.max-gf.min) / float64(chMax-chMin)
+	return max(gf.min, min(gf.max, gf.min+int64(math.Round(scaled))))
+}
+
 // HandleHWChange is called when one of the ganged hardware controls changes
 // This is called by the event monitor when a ganged control changes externally
 func (gf *GangedFader) HandleHWChange(numID uint, newValue int64) {
 	// Find which channel changed
 	for _, ch := range gf.channels {
 		if ch.GetControl().NumID == numID {
-			// Update that channel's cached value
-			ch.HandleHWChange(newValue)
-
-			// For mirror mode, also update our ganged fader value
-			// Use the new value from the changed channel
-			if gf.mode == GangModeMirror {
-				atomic.StoreInt64(&gf.lastValue, newValue)
+			// Changes made outside sessionmixer may be ignored or held (see external.go)
+			if !gf.holdExternal(ch, newValue) {
+				gf.followHWChange(ch, newValue)
 			}
-
 			break
 		}
 	}
 }
 
+// followHWChange updates the cached values after ch changed to newValue on the hardware
+func (gf *GangedFader) followHWChange(ch *MixerChannel, newValue int64) {
+	// Update that channel's cached value
+	ch.HandleHWChange(newValue)
+
+	// For mirror mode, also update our ganged fader value
+	// Use the new value from the changed channel; display channels show the highest value
+	if gf.display != "" {
+		atomic.StoreInt64(&gf.lastValue, gf.maxChannelValue())
+	} else if gf.mode == GangModeMirror {
+		auditGangChange(gf, atomic.SwapInt64(&gf.lastValue, newValue), newValue, AuditSourceHardware)
+	} else if gf.mode == GangModeScaled {
+		value := gf.fromChannelValue(ch, newValue)
+		auditGangChange(gf, atomic.SwapInt64(&gf.lastValue, value), value, AuditSourceHardware)
+	}
+}
+
+// FormatValue formats a raw value for display with the unit's formatter, shared by the
+// fader tooltip and the value row; showing raw values bypasses the formatter
+func (gf *GangedFader) FormatValue(value int64) string {
+	if gf.showRaw.Load() {
+		return formatRaw(gf, value)
+	}
+	return unitFormatter(gf.unit)(gf, value)
+}
+
+// SetShowRaw switches a "db" gang between dB and raw value display
+func (gf *GangedFader) SetShowRaw(showRaw bool) {
+	gf.showRaw.Store(showRaw)
+}
+
+// IsShowRaw returns true if the gang shows raw values
+func (gf *GangedFader) IsShowRaw() bool {
+	return gf.showRaw.Load()
+}
+
+// IsToggle returns true if the gang controls boolean switches rather than faders
+func (gf *GangedFader) IsToggle() bool {
+	return gf.toggle
+}
+
+// IsDiverged returns true if the ganged channels no longer share a value
+// (e.g. one member of a toggle gang was switched on the hardware); members of a scaled gang
+// are compared in their own range
+func (gf *GangedFader) IsDiverged() bool {
+	first := gf.channels[0].GetCurrentValue()
+	for _, ch := range gf.channels[1:] {
+		if ch.GetCurrentValue() != gf.toChannelValue(ch, first) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetCurrentValue returns the current cached value
 func (gf *GangedFader) GetCurrentValue() int64 {
 	return atomic.LoadInt64(&gf.lastValue)
@@ -224,9 +373,52 @@ func (gf *GangedFader) GetName() string {
 	return gf.name
 }
 
-// GetParams returns the fader parameters
+// GetDescription returns the optional gang notes
+func (gf *GangedFader) GetDescription() string {
+	return gf.description
+}
+
+// SetDescription sets the notes shown in the gang tooltip and details popup
+func (gf *GangedFader) SetDescription(description string) {
+	gf.description = description
+}
+
+// GetTags returns the gang tags
+func (gf *GangedFader) GetTags() []string {
+	return gf.tags
+}
+
+// SetTags sets the tags used for filtering and tag views
+func (gf *GangedFader) SetTags(tags []string) {
+	gf.tags = tags
+}
+
+// HasTag returns true if the gang carries tag
+func (gf *GangedFader) HasTag(tag string) bool {
+	return slices.Contains(gf.tags, tag)
+}
+
+// GetMode returns the gang mode (scaled when the members' ranges differ)
+func (gf *GangedFader) GetMode() GangMode {
+	return gf.mode
+}
+
+// GetUnit returns the display unit
+func (gf *GangedFader) GetUnit() string {
+	return gf.unit
+}
+
+// GetTaperDb returns the DecibelTaper range, or 0 for a linear taper
+func (gf *GangedFader) GetTaperDb() float32 {
+	return gf.taperDb
+}
+
+// GetParams returns the fader parameters, sized for the UI scale
 func (gf *GangedFader) GetParams() dfx.FaderParams {
-	return gf.params
+	params := gf.params
+	params.Width = scaled(params.Width)
+	params.Height = scaled(params.Height)
+	return params
 }
 
 // GetMin returns the minimum value
@@ -244,12 +436,311 @@ func (gf *GangedFader) GetChannels() []*MixerChannel {
 	return gf.channels
 }
 
+// ValueToDb converts a raw fader value to dB using the Scarlett mixer law
+// (the same formula as the "db" display format); returns -Inf at or below the minimum
+func (gf *GangedFader) ValueToDb(value int64) float64 {
+	if value <= gf.min || gf.max <= 0 {
+		return math.Inf(-1)
+	}
+	return 20.0*math.Log10(float64(value)/float64(gf.max)) + 12.0
+}
+
+// DbToValue converts dB to a raw fader value, clamped to the fader range
+func (gf *GangedFader) DbToValue(db float64) int64 {
+	if math.IsInf(db, -1) || math.IsNaN(db) {
+		return gf.min
+	}
+	value := int64(math.Round(float64(gf.max) * math.Pow(10, (db-12.0)/20.0)))
+	if value < gf.min {
+		return gf.min
+	}
+	if value > gf.max {
+		return gf.max
+	}
+	return value
+}
+
+// GetDefaultValue returns the value restored by ResetToDefault
+func (gf *GangedFader) GetDefaultValue() int64 {
+	return gf.defaultValue
+}
+
+// SetDefaultDb sets the default value (in dB) restored by ResetToDefault
+func (gf *GangedFader) SetDefaultDb(db float64) {
+	gf.defaultValue = gf.DbToValue(db)
+}
+
+// ResetToDefault writes the default value to all ganged channels
+func (gf *GangedFader) ResetToDefault() error {
+	return gf.HandleGuardedChange(gf.defaultValue)
+}
+
+// nudgeStepDb is the keyboard adjustment step for "db" gangs
+const nudgeStepDb = 1.0
+
+// Nudge moves the gang by a number of steps: 1 dB per step for "db" gangs,
+// 1% of the range otherwise
+func (gf *GangedFader) Nudge(steps int) error {
+	return gf.HandleUIChange(gf.NudgeValue(gf.GetCurrentValue(), steps))
+}
+
+// NudgeValue returns the value a number of Nudge steps away from value
+func (gf *GangedFader) NudgeValue(value int64, steps int) int64 {
+	if gf.unit != "db" {
+		step := max(1, (gf.max-gf.min)/100)
+		return max(gf.min, min(gf.max, value+int64(steps)*step))
+	}
+
+	db := math.Max(gf.ValueToDb(value), floorDb)
+	db += float64(steps) * nudgeStepDb
+	if db <= floorDb {
+		return gf.min
+	}
+	return gf.DbToValue(db)
+}
+
+// Mute sets the gang to its minimum, remembering the current value for Unmute
+func (gf *GangedFader) Mute() error {
+	if gf.IsMuted() {
+		return nil
+	}
+	gf.unmuteValue.Store(gf.GetCurrentValue())
+	gf.muted.Store(true)
+	return gf.HandleUIChange(gf.min)
+}
+
+// Unmute restores the value the gang had before Mute
+func (gf *GangedFader) Unmute() error {
+	if !gf.muted.Swap(false) {
+		return nil
+	}
+	return gf.HandleUIChange(gf.unmuteValue.Load())
+}
+
+// IsMuted returns true if the gang was muted and has not been moved since
+func (gf *GangedFader) IsMuted() bool {
+	return gf.muted.Load() && gf.GetCurrentValue() == gf.min
+}
+
+// SetLocked locks or unlocks the gang against changes from the UI fader
+func (gf *GangedFader) SetLocked(locked bool) {
+	gf.locked.Store(locked)
+}
+
+// IsLocked returns true if the gang is locked against UI changes
+// Read-only display channels are always locked
+func (gf *GangedFader) IsLocked() bool {
+	return gf.locked.Load() || gf.display != ""
+}
+
+// SetDisplay makes the gang a read-only display channel drawn as a "meter" or numeric "readout"
+func (gf *GangedFader) SetDisplay(display string) {
+	gf.display = display
+}
+
+// GetDisplay returns the display channel kind ("" for faders)
+func (gf *GangedFader) GetDisplay() string {
+	return gf.display
+}
+
+// IsReadOnly returns true for display channels
+func (gf *GangedFader) IsReadOnly() bool {
+	return gf.display != ""
+}
+
+// Refresh re-reads every channel of a display channel from the hardware
+// Display controls such as meters change without hardware events, so they are polled
+func (gf *GangedFader) Refresh() error {
+	var lastErr error
+	for _, ch := range gf.channels {
+		value, err := ch.ReadValue()
+		if err != nil {
+			lastErr = fmt.Errorf("failed to read %s: %w", ch.GetControl().Name, err)
+			continue
+		}
+		ch.HandleHWChange(value)
+	}
+	atomic.StoreInt64(&gf.lastValue, gf.maxChannelValue())
+	return lastErr
+}
+
+// maxChannelValue returns the highest cached channel value
+func (gf *GangedFader) maxChannelValue() int64 {
+	value := gf.channels[0].GetCurrentValue()
+	for _, ch := range gf.channels[1:] {
+		value = max(value, ch.GetCurrentValue())
+	}
+	return value
+}
+
+// SetSafe marks the gang as recall-safe, excluding it from scene recalls and mass operations
+func (gf *GangedFader) SetSafe(safe bool) {
+	gf.safe.Store(safe)
+}
+
+// IsSafe returns true if the gang is recall-safe
+func (gf *GangedFader) IsSafe() bool {
+	return gf.safe.Load()
+}
+
+// Resync re-reads every ganged channel from the hardware and rewrites the gang value
+// (taken from the first channel) to any channel that has drifted
+func (gf *GangedFader) Resync() error {
+	for _, ch := range gf.channels {
+		value, err := ch.ReadValue()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", ch.GetControl().Name, err)
+		}
+		ch.HandleHWChange(value)
+	}
+
+	value := gf.channels[0].GetCurrentValue()
+	atomic.StoreInt64(&gf.lastValue, value)
+	if gf.mode == GangModeScaled {
+		return gf.handleScaledMode(value)
+	}
+	return gf.handleMirrorMode(value)
+}
+
+// PositionToValue maps a normalized fader position (0.0-1.0) to a raw value
+// With a decibel taper the position spans taperDb dB up to +12 dB (0.0 is the minimum);
+// otherwise the mapping is linear over the raw range
+func (gf *GangedFader) PositionToValue(pos float64) int64 {
+	pos = math.Max(0, math.Min(1, pos))
+	if gf.taperDb <= 0 {
+		return gf.min + int64(math.Round(pos*float64(gf.max-gf.min)))
+	}
+	if pos <= 0 {
+		return gf.min
+	}
+	return gf.DbToValue(12.0 - float64(gf.taperDb)*(1-pos))
+}
+
+// ValueToPosition maps a raw value to a normalized fader position; the inverse of PositionToValue
+func (gf *GangedFader) ValueToPosition(value int64) float64 {
+	if gf.max <= gf.min {
+		return 0
+	}
+	if gf.taperDb <= 0 {
+		return math.Max(0, math.Min(1, float64(value-gf.min)/float64(gf.max-gf.min)))
+	}
+	db := gf.ValueToDb(value)
+	if math.IsInf(db, -1) {
+		return 0
+	}
+	return math.Max(0, math.Min(1, 1-(12.0-db)/float64(gf.taperDb)))
+}
+
+// GetLevelControls returns the read-only level controls
+func (gf *GangedFader) GetLevelControls() []*scarlettctl.Control {
+	return gf.levelControls
+}
+
+// GetLevelHistory returns the level history, or nil if no level controls are configured
+func (gf *GangedFader) GetLevelHistory() *LevelHistory {
+	return gf.levelHistory
+}
+
+// GetLoudness returns the loudness meter, or nil if no level controls are configured
+func (gf *GangedFader) GetLoudness() *LoudnessMeter {
+	return gf.loudness
+}
+
+// GetRecentPeakDb returns the highest level (dBFS) recorded in the level history over window
+// Returns false if no level controls are configured
+func (gf *GangedFader) GetRecentPeakDb(window time.Duration) (float64, bool) {
+	if gf.levelHistory == nil {
+		return 0, false
+	}
+	var peak float32
+	for _, level := range gf.levelHistory.Last(max(1, int(window/historyInterval))) {
+		peak = max(peak, level)
+	}
+	return gf.meterScale.Db(peak), true
+}
+
+// SetMeterScale sets the meter range and color transitions of the gang's levels
+func (gf *GangedFader) SetMeterScale(scale MeterScale) {
+	gf.meterScale = scale
+}
+
+// GetMeterScale returns the meter range and color transitions of the gang's levels
+func (gf *GangedFader) GetMeterScale() MeterScale {
+	return gf.meterScale
+}
+
+// SetPostLevels adds the mix output (post) level controls, which the meter shows instead of the
+// input (pre) levels when selected; must be called before SetLevelOffsets and SetLevelEvents
+func (gf *GangedFader) SetPostLevels(controls []*scarlettctl.Control) {
+	gf.levelControls = append(gf.levelControls[:gf.levelPostFrom:gf.levelPostFrom], controls...)
+}
+
+// HasPostLevels returns true if the gang has post level controls to select
+func (gf *GangedFader) HasPostLevels() bool {
+	return gf.levelPostFrom < len(gf.levelControls)
+}
+
+// SetMeterPost selects the post (true) or pre (false) level controls for the meter
+func (gf *GangedFader) SetMeterPost(post bool) {
+	gf.levelPost.Store(post && gf.HasPostLevels())
+}
+
+// IsMeterPost returns true if the meter shows the post level controls
+func (gf *GangedFader) IsMeterPost() bool {
+	return gf.levelPost.Load()
+}
+
+// meteredLevels returns the index range of the level controls the meter shows
+func (gf *GangedFader) meteredLevels() (int, int) {
+	if gf.levelPost.Load() {
+		return gf.levelPostFrom, len(gf.levelControls)
+	}
+	return 0, gf.levelPostFrom
+}
+
 // HasLevels returns true if this gang has level controls configured
 func (gf *GangedFader) HasLevels() bool {
 	return len(gf.levelControls) > 0
 }
 
-// GetMaxLevel reads all level controls and returns the maximum value
+// SetLevelEvents switches the level controls from polling to values fed by HandleLevelChange,
+// seeded with their current values; must be called before the event monitor is created
+func (gf *GangedFader) SetLevelEvents(enabled bool) {
+	if !enabled || len(gf.levelControls) == 0 {
+		gf.levelValues = nil
+		return
+	}
+	gf.levelValues = make([]atomic.Int64, len(gf.levelControls))
+	for i, ctl := range gf.levelControls {
+		if val, err := ctl.GetValue(); err == nil {
+			gf.levelValues[i].Store(val)
+		}
+	}
+}
+
+// HasLevelEvents returns true if the level controls are fed by hardware events
+func (gf *GangedFader) HasLevelEvents() bool {
+	return gf.levelValues != nil
+}
+
+// HandleLevelChange is called by the event monitor when one of the gang's level controls changes
+func (gf *GangedFader) HandleLevelChange(numID uint, value int64) {
+	for i, ctl := range gf.levelControls {
+		if ctl.NumID == numID && gf.levelValues != nil {
+			gf.levelValues[i].Store(value)
+		}
+	}
+}
+
+// readLevel returns level control i: the last event-fed value, or a hardware read
+func (gf *GangedFader) readLevel(i int) (int64, error) {
+	if gf.levelValues != nil {
+		return gf.levelValues[i].Load(), nil
+	}
+	return gf.levelControls[i].GetValue()
+}
+
+// GetMaxLevel reads the metered level controls (pre or post) and returns the maximum value
 // Returns the level value and true if successful, or 0 and false if no levels configured
 func (gf *GangedFader) GetMaxLevel() (int64, bool) {
 	if len(gf.levelControls) == 0 {
@@ -257,82 +748,129 @@ func (gf *GangedFader) GetMaxLevel() (int64, bool) {
 	}
 
 	var maxLevel int64
-	for _, ctl := range gf.levelControls {
-		val, err := ctl.GetValue()
-		if err == nil && val > maxLevel {
+	from, to := gf.meteredLevels()
+	for i := from; i < to; i++ {
+		val, err := gf.readLevel(i)
+		if err != nil {
+			continue
+		}
+		if val = gf.calibrateLevel(i, val); val > maxLevel {
 			maxLevel = val
 		}
 	}
 	return maxLevel, true
 }
 
-// GetLevelColor computes the track color based on current signal level
-// Returns nil if no level controls are configured
-// Color gradient: black (zero) -> dark green (low) -> bright green -> yellow -> red (high)
+// SetLevelOffsets sets a calibration offset (dB) per level control, in level control order
+// Offsets align the displayed meters with a DAW or external reference
+func (gf *GangedFader) SetLevelOffsets(offsetsDb []float32) {
+	gf.levelGains = make([]float64, len(gf.levelControls))
+	for i := range gf.levelGains {
+		gf.levelGains[i] = 1.0
+		if i < len(offsetsDb) {
+			gf.levelGains[i] = math.Pow(10, float64(offsetsDb[i])/20.0)
+		}
+	}
+}
+
+// calibrateLevel applies the calibration offset for level control i to a raw level
+func (gf *GangedFader) calibrateLevel(i int, level int64) int64 {
+	if i >= len(gf.levelGains) {
+		return level
+	}
+	return int64(math.Round(float64(level) * gf.levelGains[i]))
+}
+
+// levelToDb converts a raw level meter value to dBFS relative to the meter's maximum
+// Returns -Inf for zero or negative levels
+func levelToDb(level, max int64) float64 {
+	if level <= 0 || max <= 0 {
+		return math.Inf(-1)
+	}
+	return 20.0 * math.Log10(float64(level)/float64(max))
+}
+
+// dbToMixerValue converts dB to a raw mixer value with the Scarlett mixer law, clamped to 0..max
+func dbToMixerValue(db float64, max int64) int64 {
+	if math.IsInf(db, -1) || math.IsNaN(db) {
+		return 0
+	}
+	value := int64(math.Round(float64(max) * math.Pow(10, (db-12.0)/20.0)))
+	return min(max, value)
+}
+
+// sampleLevels reads every metered level control once, returning the maximum raw level and the
+// summed energy (sum of squared linear amplitudes) across controls
+func (gf *GangedFader) sampleLevels() (int64, float64) {
+	var maxLevel int64
+	var energy float64
+	from, to := gf.meteredLevels()
+	for i := from; i < to; i++ {
+		val, err := gf.readLevel(i)
+		if err != nil {
+			continue
+		}
+		val = gf.calibrateLevel(i, val)
+		if val > maxLevel {
+			maxLevel = val
+		}
+		if gf.levelMax > 0 {
+			amplitude := float64(val) / float64(gf.levelMax)
+			energy += amplitude * amplitude
+		}
+	}
+	return maxLevel, energy
+}
+
+// GetNormalizedLevel reads the level controls and normalizes the maximum to 0.0-1.0
 // Uses logarithmic (dB) scale for more sensitivity at lower levels
-func (gf *GangedFader) GetLevelColor() *imgui.Vec4 {
+// Returns the level and true if successful, or 0 and false if no levels configured
+func (gf *GangedFader) GetNormalizedLevel() (float32, bool) {
 	level, ok := gf.GetMaxLevel()
 	if !ok {
-		return nil
+		return 0, false
 	}
+	return gf.normalizeLevel(level), true
+}
 
-	// When level is 0, don't set a color (use theme default)
-	if level == 0 {
-		return nil
+// GetNormalizedLevels reads each metered level control and normalizes it to 0.0-1.0, in level control
+// order (e.g. left and right of a stereo pair)
+// Returns nil and false if no levels configured
+func (gf *GangedFader) GetNormalizedLevels() ([]float32, bool) {
+	if len(gf.levelControls) == 0 {
+		return nil, false
+	}
+	from, to := gf.meteredLevels()
+	levels := make([]float32, to-from)
+	for i := from; i < to; i++ {
+		val, err := gf.readLevel(i)
+		if err != nil {
+			continue
+		}
+		levels[i-from] = gf.normalizeLevel(gf.calibrateLevel(i, val))
 	}
+	return levels, true
+}
+
+// IsStereoMetered returns true if the metered level controls are a pair (left and right), which
+// are shown side by side
+func (gf *GangedFader) IsStereoMetered() bool {
+	from, to := gf.meteredLevels()
+	return to-from == 2
+}
 
-	// Normalize to 0.0-1.0 using logarithmic (dB) scale
-	// This provides much more sensitivity at lower signal levels
-	var normalized float32
+// normalizeLevel maps a raw level to 0.0-1.0 using logarithmic (dB) scale
+// This provides much more sensitivity at lower signal levels
+func (gf *GangedFader) normalizeLevel(level int64) float32 {
 	if level <= gf.levelMin || gf.levelMax <= 0 {
-		normalized = 0
-	} else {
-		// Convert to dB scale: 20 * log10(level / max)
-		// This gives us 0 dB at max, negative values below
-		ratio := float64(level) / float64(gf.levelMax)
-		db := 20.0 * math.Log10(ratio)
-
-		// Use 96 dB range (16-bit dynamic range) for more sensitivity at low levels
-		// -96 dB -> 0.0, 0 dB -> 1.0
-		const dbRange = 96.0
-		if db < -dbRange {
-			db = -dbRange
-		}
-		normalized = float32((db + dbRange) / dbRange)
-	}
-
-	if normalized < 0 {
-		normalized = 0
-	} else if normalized > 1 {
-		normalized = 1
-	}
-
-	// Compute color using HSV
-	// 0%: dark green (H=120, S=1, V=0.3)
-	// 50%: bright green (H=120, S=1, V=0.6)
-	// 80%: yellow (H=60, S=1, V=0.8)
-	// 100%: red (H=0, S=1, V=1.0)
-	var h, s, v float32
-	s = 1.0
-
-	if normalized <= 0.5 {
-		// 0-50%: dark green to bright green (increase V)
-		h = 120.0 / 360.0
-		v = 0.3 + (normalized/0.5)*0.3 // 0.3 to 0.6
-	} else if normalized <= 0.8 {
-		// 50-80%: green to yellow (H from 120 to 60)
-		t := (normalized - 0.5) / 0.3
-		h = (120.0 - t*60.0) / 360.0 // 120 to 60
-		v = 0.6 + t*0.2              // 0.6 to 0.8
-	} else {
-		// 80-100%: yellow to red (H from 60 to 0)
-		t := (normalized - 0.8) / 0.2
-		h = (60.0 - t*60.0) / 360.0 // 60 to 0
-		v = 0.8 + t*0.2             // 0.8 to 1.0
+		return 0
 	}
 
-	var r, g, b float32
-	imgui.ColorConvertHSVtoRGB(h, s, v, &r, &g, &b)
+	// Convert to dB scale: 20 * log10(level / max)
+	// This gives us 0 dB at max, negative values below
+	ratio := float64(level) / float64(gf.levelMax)
+	db := 20.0 * math.Log10(ratio)
 
-	return &imgui.Vec4{X: r, Y: g, Z: b, W: 1.0}
+	// -range dB -> 0.0, 0 dB -> 1.0
+	return gf.meterScale.Position(db)
 }
@@ -0,0 +1,289 @@
+package sessionmixer
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// newFakeGang creates a mirror gang over fake channels, all sharing the given range and value
+func newFakeGang(t *testing.T, name string, min, max, value int64, numIDs ...uint) (*GangedFader, []*fakeControl) {
+	t.Helper()
+	var channels []*MixerChannel
+	var fakes []*fakeControl
+	for _, numID := range numIDs {
+		ch, fake := newFakeChannel(t, numID, name, min, max, value)
+		channels = append(channels, ch)
+		fakes = append(fakes, fake)
+	}
+	gang, err := NewGangedFader(name, "db", GangModeMirror, channels, nil, 0)
+	if err != nil {
+		t.Fatalf("NewGangedFader: %v", err)
+	}
+	return gang, fakes
+}
+
+func TestGangedFaderRequiresChannels(t *testing.T) {
+	if _, err := NewGangedFader("empty", "db", GangModeMirror, nil, nil, 0); err == nil {
+		t.Fatal("expected an error for a gang without channels")
+	}
+}
+
+func TestGangedFaderMirrorWritesEveryChannel(t *testing.T) {
+	gang, fakes := newFakeGang(t, "Vocal", 0, 160, 80, 1, 2)
+	if err := gang.HandleUIChange(120); err != nil {
+		t.Fatal(err)
+	}
+	for i, fake := range fakes {
+		if got := fake.getWrites(); !slices.Equal(got, []int64{120}) {
+			t.Errorf("channel %d writes = %v, want [120]", i, got)
+		}
+	}
+	if got := gang.GetCurrentValue(); got != 120 {
+		t.Errorf("GetCurrentValue() = %d, want 120", got)
+	}
+
+	// Dragging over the same value writes nothing more
+	if err := gang.HandleUIChange(120); err != nil {
+		t.Fatal(err)
+	}
+	if got := fakes[0].getWrites(); len(got) != 1 {
+		t.Errorf("writes = %v, want a single write", got)
+	}
+}
+
+func TestGangedFaderMirrorHWChange(t *testing.T) {
+	gang, fakes := newFakeGang(t, "Vocal", 0, 160, 80, 1, 2)
+	gang.HandleHWChange(2, 60)
+	if got := gang.GetCurrentValue(); got != 60 {
+		t.Errorf("GetCurrentValue() = %d, want 60", got)
+	}
+	if got := gang.GetChannels()[1].GetCurrentValue(); got != 60 {
+		t.Errorf("channel value = %d, want 60", got)
+	}
+
+	// Unknown controls are ignored
+	gang.HandleHWChange(99, 10)
+	if got := gang.GetCurrentValue(); got != 60 {
+		t.Errorf("GetCurrentValue() = %d after an unknown control, want 60", got)
+	}
+	for i, fake := range fakes {
+		if got := fake.getWrites(); len(got) != 0 {
+			t.Errorf("channel %d writes = %v, want none", i, got)
+		}
+	}
+}
+
+func TestGangedFaderReconvergesDivergedChannels(t *testing.T) {
+	gang, fakes := newFakeGang(t, "Vocal", 0, 160, 80, 1, 2)
+	if gang.IsDiverged() {
+		t.Fatal("a new gang should not be diverged")
+	}
+
+	// One member moved on the hardware; the gang follows it but the members now differ
+	gang.HandleHWChange(2, 60)
+	if !gang.IsDiverged() {
+		t.Fatal("expected the gang to be diverged")
+	}
+
+	// Writing the gang's current value rewrites the member left behind
+	if err := gang.HandleUIChange(60); err != nil {
+		t.Fatal(err)
+	}
+	if got := fakes[0].getWrites(); !slices.Equal(got, []int64{60}) {
+		t.Errorf("channel 0 writes = %v, want [60]", got)
+	}
+	if got := fakes[1].getWrites(); len(got) != 0 {
+		t.Errorf("channel 1 writes = %v, want none", got)
+	}
+	if gang.IsDiverged() {
+		t.Error("expected the gang to have reconverged")
+	}
+}
+
+func TestGangedFaderResync(t *testing.T) {
+	gang, fakes := newFakeGang(t, "Vocal", 0, 160, 80, 1, 2)
+
+	// The second member drifted without a hardware event
+	fakes[1].set(30)
+	if err := gang.Resync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := fakes[0].getWrites(); len(got) != 0 {
+		t.Errorf("channel 0 writes = %v, want none", got)
+	}
+	if got := fakes[1].getWrites(); !slices.Equal(got, []int64{80}) {
+		t.Errorf("channel 1 writes = %v, want [80]", got)
+	}
+	if got := gang.GetCurrentValue(); got != 80 {
+		t.Errorf("GetCurrentValue() = %d, want 80", got)
+	}
+}
+
+func TestGangedFaderResyncReadError(t *testing.T) {
+	gang, fakes := newFakeGang(t, "Vocal", 0, 160, 80, 1, 2)
+	fakes[1].getErr = errors.New("device gone")
+	if err := gang.Resync(); err == nil {
+		t.Fatal("expected a read error")
+	}
+}
+
+func TestGangedFaderScalesMismatchedRanges(t *testing.T) {
+	first, firstFake := newFakeChannel(t, 1, "Mix A Input 01", 0, 100, 0)
+	second, secondFake := newFakeChannel(t, 2, "Mix A Input 02", 0, 200, 0)
+	gang, err := NewGangedFader("Pair", "db", GangModeMirror, []*MixerChannel{first, second}, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gang.GetMode() != GangModeScaled {
+		t.Fatalf("GetMode() = %s, want %s", gang.GetMode(), GangModeScaled)
+	}
+	if gang.GetMin() != 0 || gang.GetMax() != 100 {
+		t.Errorf("range = [%d..%d], want the first channel's [0..100]", gang.GetMin(), gang.GetMax())
+	}
+
+	if err := gang.HandleUIChange(50); err != nil {
+		t.Fatal(err)
+	}
+	if got := firstFake.getWrites(); !slices.Equal(got, []int64{50}) {
+		t.Errorf("first writes = %v, want [50]", got)
+	}
+	if got := secondFake.getWrites(); !slices.Equal(got, []int64{100}) {
+		t.Errorf("second writes = %v, want [100]", got)
+	}
+	if gang.IsDiverged() {
+		t.Error("scaled members at corresponding values should not be diverged")
+	}
+
+	// A hardware change to the second member is mapped back to the gang's range
+	gang.HandleHWChange(2, 150)
+	if got := gang.GetCurrentValue(); got != 75 {
+		t.Errorf("GetCurrentValue() = %d, want 75", got)
+	}
+	if !gang.IsDiverged() {
+		t.Error("expected the gang to be diverged")
+	}
+}
+
+func TestGangedFaderKeepsMirrorForMatchingRanges(t *testing.T) {
+	gang, _ := newFakeGang(t, "Vocal", 0, 160, 80, 1, 2, 3)
+	if gang.GetMode() != GangModeMirror {
+		t.Errorf("GetMode() = %s, want %s", gang.GetMode(), GangModeMirror)
+	}
+}
+
+func TestGangedFaderMuteUnmute(t *testing.T) {
+	gang, fakes := newFakeGang(t, "Vocal", 0, 160, 80, 1, 2)
+	if err := gang.Mute(); err != nil {
+		t.Fatal(err)
+	}
+	if !gang.IsMuted() || gang.GetCurrentValue() != 0 {
+		t.Fatalf("after Mute: muted = %v, value = %d", gang.IsMuted(), gang.GetCurrentValue())
+	}
+	if err := gang.Unmute(); err != nil {
+		t.Fatal(err)
+	}
+	if gang.IsMuted() || gang.GetCurrentValue() != 80 {
+		t.Errorf("after Unmute: muted = %v, value = %d", gang.IsMuted(), gang.GetCurrentValue())
+	}
+	for i, fake := range fakes {
+		if got := fake.getWrites(); !slices.Equal(got, []int64{0, 80}) {
+			t.Errorf("channel %d writes = %v, want [0 80]", i, got)
+		}
+	}
+}
+
+func TestGangedFaderDisplayChannelIsReadOnly(t *testing.T) {
+	gang, fakes := newFakeGang(t, "Meter", 0, 160, 80, 1)
+	gang.SetDisplay("meter")
+	if err := gang.HandleUIChange(100); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("HandleUIChange error = %v, want ErrReadOnly", err)
+	}
+	if !gang.IsLocked() {
+		t.Error("display channels should be locked")
+	}
+	if got := fakes[0].getWrites(); len(got) != 0 {
+		t.Errorf("writes = %v, want none", got)
+	}
+}
+
+func TestGangedFaderObserverRefusesWrites(t *testing.T) {
+	SetObserver(true)
+	defer SetObserver(false)
+
+	gang, fakes := newFakeGang(t, "Vocal", 0, 160, 80, 1, 2)
+	if err := gang.HandleUIChange(100); !errors.Is(err, ErrObserver) {
+		t.Errorf("HandleUIChange error = %v, want ErrObserver", err)
+	}
+	if got := gang.GetCurrentValue(); got != 80 {
+		t.Errorf("GetCurrentValue() = %d, want 80", got)
+	}
+	for i, fake := range fakes {
+		if got := fake.getWrites(); len(got) != 0 {
+			t.Errorf("channel %d writes = %v, want none", i, got)
+		}
+	}
+}
+
+func TestGangedFaderDbConversion(t *testing.T) {
+	gang, _ := newFakeGang(t, "Vocal", 0, 160, 80, 1)
+	for _, value := range []int64{1, 40, 80, 160} {
+		if got := gang.DbToValue(gang.ValueToDb(value)); got != value {
+			t.Errorf("DbToValue(ValueToDb(%d)) = %d", value, got)
+		}
+	}
+	if got := gang.DbToValue(12); got != 160 {
+		t.Errorf("DbToValue(+12) = %d, want the maximum", got)
+	}
+	if got := gang.DbToValue(100); got != 160 {
+		t.Errorf("DbToValue(+100) = %d, want the clamped maximum", got)
+	}
+}
+
+func TestGangedFaderClampsOutOfRangeValues(t *testing.T) {
+	gang, fakes := newFakeGang(t, "Vocal", 0, 160, 80, 1, 2)
+	err := gang.HandleUIChange(500)
+	var rangeErr *ErrOutOfRange
+	if !errors.As(err, &rangeErr) || rangeErr.Value != 500 || rangeErr.Max != 160 {
+		t.Fatalf("HandleUIChange error = %v, want ErrOutOfRange", err)
+	}
+	for i, fake := range fakes {
+		if got := fake.getWrites(); !slices.Equal(got, []int64{160}) {
+			t.Errorf("channel %d writes = %v, want [160]", i, got)
+		}
+	}
+	if err := gang.HandleUIChange(-5); !errors.As(err, &rangeErr) || gang.GetCurrentValue() != 0 {
+		t.Errorf("HandleUIChange(-5) = %v, value %d; want ErrOutOfRange and 0", err, gang.GetCurrentValue())
+	}
+}
+
+func TestGangedFaderToggleRange(t *testing.T) {
+	control := &scarlettctl.Control{NumID: 1, Name: "Line In 1 Air", Type: scarlettctl.ControlTypeBoolean}
+	ch, fake := newFakeChannelFor(t, control, 0)
+	gang, err := NewGangedFader("Air", "", GangModeMirror, []*MixerChannel{ch}, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gang.IsToggle() || gang.GetMin() != 0 || gang.GetMax() != 1 {
+		t.Fatalf("toggle gang range = [%d..%d]", gang.GetMin(), gang.GetMax())
+	}
+	if err := gang.HandleUIChange(1); err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.getWrites(); !slices.Equal(got, []int64{1}) {
+		t.Errorf("writes = %v, want [1]", got)
+	}
+}
+
+func TestRawGangDefaultsToStartupValue(t *testing.T) {
+	ch, _ := newFakeChannel(t, 1, "Sync Delay", 0, 500, 120)
+	gang, err := NewGangedFader("Sync Delay", "raw", GangModeMirror, []*MixerChannel{ch}, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gang.GetDefaultValue(); got != 120 {
+		t.Errorf("GetDefaultValue() = %d, want the startup value 120", got)
+	}
+}
@@ -0,0 +1,180 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// GangPicker is the "add gang" dialog: it lists the card's fader and switch controls with
+// search, multi-select and a live value preview, and appends the resulting GangControl to
+// the config file
+type GangPicker struct {
+	config   *Config
+	path     string
+	controls []*scarlettctl.Control // Integer and boolean controls, in card order
+
+	open     bool // Open the dialog on the next frame
+	search   string
+	selected []*scarlettctl.Control // In selection order
+	name     string
+	unit     string
+	taperDb  float32
+	message  string
+	added    []string // Gangs added this session (loaded on the next start)
+}
+
+// NewGangPicker lists the card's gangable controls; the config is saved to path on add
+func NewGangPicker(card *scarlettctl.Card, config *Config, path string) (*GangPicker, error) {
+	controls, err := card.GetControls()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list controls: %w", err)
+	}
+	gp := &GangPicker{
+		config: config,
+		path:   path,
+		unit:   "db",
+	}
+	for _, control := range controls {
+		switch control.Type {
+		case scarlettctl.ControlTypeInteger, scarlettctl.ControlTypeInteger64, scarlettctl.ControlTypeBoolean:
+			gp.controls = append(gp.controls, control)
+		}
+	}
+	return gp, nil
+}
+
+// Open shows the dialog on the next frame with a fresh selection
+func (gp *GangPicker) Open() {
+	gp.open = true
+	gp.search = ""
+	gp.selected = nil
+	gp.name = ""
+	gp.taperDb = 0
+	gp.message = ""
+}
+
+// GetAdded returns the names of the gangs added this session
+func (gp *GangPicker) GetAdded() []string {
+	return gp.added
+}
+
+// Draw renders the dialog when open
+func (gp *GangPicker) Draw() {
+	if gp.open {
+		imgui.OpenPopupStr(tr("Add Gang"))
+		gp.open = false
+	}
+	if !imgui.BeginPopupModalV(tr("Add Gang"), nil, imgui.WindowFlagsAlwaysAutoResize) {
+		return
+	}
+
+	imgui.SetNextItemWidth(250)
+	imgui.InputTextWithHint(tr("Name"), tr("gang name"), &gp.name, imgui.InputTextFlagsNone, nil)
+	imgui.SetNextItemWidth(250)
+	if imgui.BeginCombo(tr("Unit"), gp.unit) {
+		for _, unit := range configUnits(gp.config) {
+			if imgui.SelectableBoolV(unit, unit == gp.unit, imgui.SelectableFlagsNone, imgui.Vec2{}) {
+				gp.unit = unit
+			}
+		}
+		imgui.EndCombo()
+	}
+	imgui.SetNextItemWidth(250)
+	imgui.InputFloatV(tr("Taper (dB)"), &gp.taperDb, 6, 12, "%.0f", imgui.InputTextFlagsNone)
+
+	imgui.SeparatorText(tr("Controls"))
+	imgui.SetNextItemWidth(250)
+	imgui.InputTextWithHint("##control_search", tr("search controls"), &gp.search, imgui.InputTextFlagsNone, nil)
+	imgui.SameLine()
+	imgui.TextDisabled(trf("%d selected", len(gp.selected)))
+
+	imgui.BeginChildStrV("control_list", scaledVec2(imgui.Vec2{X: 600, Y: 300}), imgui.ChildFlagsBorders, imgui.WindowFlagsNone)
+	search := strings.ToLower(gp.search)
+	for _, control := range gp.controls {
+		if search != "" && !strings.Contains(strings.ToLower(control.Name), search) {
+			continue
+		}
+		selected := slices.Contains(gp.selected, control)
+		if imgui.Checkbox(trf("%s##pick_%d", control.Name, control.NumID), &selected) {
+			if selected {
+				gp.selected = append(gp.selected, control)
+			} else {
+				gp.selected = slices.DeleteFunc(gp.selected, func(c *scarlettctl.Control) bool { return c == control })
+			}
+		}
+		imgui.SameLine()
+		imgui.TextDisabled(controlPreview(control))
+	}
+	imgui.EndChild()
+
+	if gp.message != "" {
+		imgui.TextColored(errorColor, gp.message)
+	}
+	if imgui.Button(tr("Add")) {
+		if err := gp.add(); err != nil {
+			gp.message = err.Error()
+		} else {
+			imgui.CloseCurrentPopup()
+		}
+	}
+	imgui.SameLine()
+	if imgui.Button(tr("Cancel")) {
+		imgui.CloseCurrentPopup()
+	}
+	imgui.EndPopup()
+}
+
+// add validates the selection against the same rules as ControlMapper.LoadGangs, then
+// appends the gang to the config and saves it
+func (gp *GangPicker) add() error {
+	name := strings.TrimSpace(gp.name)
+	if name == "" {
+		return fmt.Errorf("a name is required")
+	}
+	for _, gc := range gp.config.GangControls {
+		if gc.Name == name {
+			return fmt.Errorf("a gang named '%s' already exists", name)
+		}
+	}
+	if len(gp.selected) == 0 {
+		return fmt.Errorf("select at least one control")
+	}
+	toggle := gp.selected[0].Type == scarlettctl.ControlTypeBoolean
+	for _, control := range gp.selected[1:] {
+		if (control.Type == scarlettctl.ControlTypeBoolean) != toggle {
+			return fmt.Errorf("cannot mix boolean and integer controls")
+		}
+	}
+
+	gc := GangControl{Name: name, Unit: gp.unit, TaperDb: gp.taperDb}
+	if toggle {
+		gc.Unit, gc.TaperDb = "raw", 0
+	}
+	for _, control := range gp.selected {
+		gc.Controls = append(gc.Controls, control.Name)
+	}
+
+	gp.config.GangControls = append(gp.config.GangControls, gc)
+	if err := SaveConfig(gp.config, gp.path); err != nil {
+		gp.config.GangControls = gp.config.GangControls[:len(gp.config.GangControls)-1]
+		return err
+	}
+	gp.added = append(gp.added, name)
+	return nil
+}
+
+// controlPreview describes a control's type, range and live value
+func controlPreview(control *scarlettctl.Control) string {
+	value, err := control.GetValue()
+	if err != nil {
+		return "(unreadable)"
+	}
+	if control.Type == scarlettctl.ControlTypeBoolean {
+		return fmt.Sprintf("switch = %d", value)
+	}
+	return fmt.Sprintf("%d..%d = %d", control.Min, control.Max, value)
+}
@@ -0,0 +1,179 @@
+package sessionmixer
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/michaelquigley/scarlettctl"
+)
+
+const (
+	// gateInterval is how often gates sample their trigger level
+	gateInterval = 20 * time.Millisecond
+
+	// defaultGateHold is how long the trigger must stay below the threshold before the gate
+	// closes when the gate does not set a hold time
+	defaultGateHold = 2 * time.Second
+)
+
+// Gate mutes a set of gangs (e.g. the sends of an open mic to the cue mixes) while a trigger
+// level stays below a threshold
+// The gate closes once the trigger has stayed below the threshold for the hold time, and opens
+// as soon as it rises above it again, restoring the gangs to their previous values
+type Gate struct {
+	config  GateControl
+	hold    time.Duration
+	trigger *scarlettctl.Control
+	gangs   []*GangedFader
+
+	// Runtime state (owned by the gate goroutine, guarded against SetGangs by mu)
+	mu         sync.Mutex
+	belowSince time.Time
+	closed     atomic.Bool
+	base       map[*GangedFader]int64 // Gang values captured before closing
+	lastSet    map[*GangedFader]int64 // Values most recently written by the gate
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewGate creates a gate for a trigger level control and the gangs it mutes
+func NewGate(config GateControl, trigger *scarlettctl.Control, gangs []*GangedFader) *Gate {
+	hold := config.Hold
+	if hold <= 0 {
+		hold = defaultGateHold
+	}
+	return &Gate{
+		config:  config,
+		hold:    hold,
+		trigger: trigger,
+		gangs:   gangs,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// GetName returns the gate name
+func (g *Gate) GetName() string {
+	return g.config.Name
+}
+
+// IsClosed returns true while the gangs are muted by the gate (thread-safe)
+func (g *Gate) IsClosed() bool {
+	return g.closed.Load()
+}
+
+// Start begins sampling the trigger level in a background goroutine
+func (g *Gate) Start() {
+	go func() {
+		defer close(g.done)
+		ticker := time.NewTicker(gateInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-g.stop:
+				g.mu.Lock()
+				g.open()
+				g.mu.Unlock()
+				return
+			case now := <-ticker.C:
+				g.Update(now)
+			}
+		}
+	}()
+}
+
+// Stop stops the gate, opening it if closed
+// Must only be called after Start; blocks until the goroutine has exited
+func (g *Gate) Stop() {
+	g.stopOnce.Do(func() {
+		close(g.stop)
+		<-g.done
+	})
+}
+
+// SetGangs resolves the gated gangs by name among gangs (e.g. after a profile switch)
+// A closed gate keeps its new gang instances muted; gangs missing from gangs are restored and
+// dropped
+func (g *Gate) SetGangs(gangs []*GangedFader) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed.Load() {
+		for _, gang := range rebindGangs(g.gangs, gangs, g.base, g.lastSet) {
+			g.set(gang, g.base[gang])
+			delete(g.base, gang)
+		}
+	}
+	g.gangs = resolveGangs(gangs, g.config.Gangs)
+}
+
+// Update samples the trigger level and advances the gate state
+func (g *Gate) Update(now time.Time) {
+	level, err := g.trigger.GetValue()
+	if err != nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.advance(now, levelToDb(level, g.trigger.Max))
+}
+
+// advance opens the gate on a trigger level above the threshold, and closes it once the level
+// has stayed below the threshold for the hold time
+func (g *Gate) advance(now time.Time, levelDb float64) {
+	if levelDb > float64(g.config.ThresholdDb) {
+		g.belowSince = time.Time{}
+		g.open()
+		return
+	}
+	if g.belowSince.IsZero() {
+		g.belowSince = now
+	}
+	if !g.closed.Load() && now.Sub(g.belowSince) >= g.hold {
+		g.close()
+	}
+}
+
+// close captures the current gang values and mutes the gangs
+func (g *Gate) close() {
+	g.base = make(map[*GangedFader]int64)
+	g.lastSet = make(map[*GangedFader]int64)
+	for _, gang := range g.gangs {
+		g.base[gang] = gang.GetCurrentValue()
+	}
+	g.closed.Store(true)
+	for _, gang := range g.gangs {
+		g.set(gang, gang.GetMin())
+	}
+}
+
+// open returns the muted gangs to their captured values
+func (g *Gate) open() {
+	if !g.closed.Load() {
+		return
+	}
+	for _, gang := range g.gangs {
+		if base, ok := g.base[gang]; ok {
+			g.set(gang, base)
+		}
+	}
+	g.closed.Store(false)
+	g.base = nil
+	g.lastSet = nil
+}
+
+// set writes a value to a gang unless the user has taken over the gang since the last write
+func (g *Gate) set(gang *GangedFader, value int64) {
+	if last, ok := g.lastSet[gang]; ok && gang.GetCurrentValue() != last {
+		// Someone else moved the fader while the gate was closed; leave it where it is
+		delete(g.base, gang)
+		return
+	}
+	if err := gang.HandleUIChange(value); err != nil {
+		log.Printf("Gate %s: failed to write %s: %v", g.config.Name, gang.GetName(), err)
+	}
+	g.lastSet[gang] = value
+}
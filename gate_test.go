@@ -0,0 +1,80 @@
+package sessionmixer
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGateClosesAfterHoldAndOpensOnSignal(t *testing.T) {
+	cue1, fakes1 := newFakeGang(t, "Vocal → Cue 1", 0, 160, 80, 1)
+	cue2, fakes2 := newFakeGang(t, "Vocal → Cue 2", 0, 160, 120, 2)
+	gate := NewGate(GateControl{Name: "Vocal", ThresholdDb: -50, Hold: time.Second}, nil, []*GangedFader{cue1, cue2})
+
+	start := time.Now()
+	gate.advance(start, -60)
+	gate.advance(start.Add(500*time.Millisecond), -70)
+	if gate.IsClosed() {
+		t.Fatal("gate closed before the hold time")
+	}
+	gate.advance(start.Add(time.Second), -70)
+	if !gate.IsClosed() || cue1.GetCurrentValue() != 0 || cue2.GetCurrentValue() != 0 {
+		t.Fatalf("gate closed = %v, gangs at %d and %d; want closed and muted", gate.IsClosed(), cue1.GetCurrentValue(), cue2.GetCurrentValue())
+	}
+
+	// Signal opens the gate at once, restoring each gang's own value
+	gate.advance(start.Add(1100*time.Millisecond), -20)
+	if gate.IsClosed() {
+		t.Error("gate still closed on signal")
+	}
+	if got := fakes1[0].getWrites(); !slices.Equal(got, []int64{0, 80}) {
+		t.Errorf("cue 1 writes = %v, want [0 80]", got)
+	}
+	if got := fakes2[0].getWrites(); !slices.Equal(got, []int64{0, 120}) {
+		t.Errorf("cue 2 writes = %v, want [0 120]", got)
+	}
+}
+
+func TestGateLeavesGangMovedWhileClosed(t *testing.T) {
+	cue, fakes := newFakeGang(t, "Vocal → Cue 1", 0, 160, 80, 1)
+	gate := NewGate(GateControl{Name: "Vocal", ThresholdDb: -50}, nil, []*GangedFader{cue})
+
+	start := time.Now()
+	gate.advance(start, -60)
+	gate.advance(start.Add(defaultGateHold), -60)
+	if !gate.IsClosed() {
+		t.Fatal("gate did not close after the default hold")
+	}
+	if err := cue.HandleUIChange(100); err != nil {
+		t.Fatal(err)
+	}
+	gate.advance(start.Add(defaultGateHold+time.Second), -20)
+	if got := fakes[0].getWrites(); !slices.Equal(got, []int64{0, 100}) {
+		t.Errorf("writes = %v, want the user's move kept", got)
+	}
+}
+
+func TestGateSetGangsCarriesClosedGang(t *testing.T) {
+	cue, _ := newFakeGang(t, "Vocal → Cue 1", 0, 160, 80, 1)
+	gone, goneFakes := newFakeGang(t, "Vocal → Cue 2", 0, 160, 120, 2)
+	gate := NewGate(GateControl{Name: "Vocal", ThresholdDb: -50, Gangs: []string{cue.GetName(), gone.GetName()}}, nil, []*GangedFader{cue, gone})
+
+	start := time.Now()
+	gate.advance(start, -60)
+	gate.advance(start.Add(defaultGateHold), -60)
+	if !gate.IsClosed() {
+		t.Fatal("gate did not close")
+	}
+
+	// The new profile loads the muted cue send from the hardware and lacks the second send
+	next, nextFakes := newFakeGang(t, "Vocal → Cue 1", 0, 160, 0, 1)
+	gate.SetGangs([]*GangedFader{next})
+	if got := goneFakes[0].getWrites(); !slices.Equal(got, []int64{0, 120}) {
+		t.Errorf("dropped gang writes = %v, want [0 120]", got)
+	}
+
+	gate.advance(start.Add(defaultGateHold+time.Second), -20)
+	if got := nextFakes[0].getWrites(); !slices.Equal(got, []int64{80}) {
+		t.Errorf("new gang writes = %v, want [80]", got)
+	}
+}
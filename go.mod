@@ -10,6 +10,7 @@ require (
 	github.com/michaelquigley/scarlettctl v0.0.0-20251204203324-0ac833b9560b
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/cobra v1.10.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -38,7 +39,6 @@ require (
 	golang.org/x/sync v0.17.0 // indirect
 	golang.org/x/sys v0.37.0 // indirect
 	golang.org/x/text v0.24.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 replace (
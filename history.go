@@ -0,0 +1,179 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+const (
+	// historyInterval is how often gang levels are sampled into their history
+	historyInterval = 50 * time.Millisecond
+
+	// historySize is the number of samples kept per gang (5 minutes)
+	historySize = int(5 * time.Minute / historyInterval)
+
+	// sparklineWindow is the span shown in the sparkline under each fader
+	sparklineWindow = 10 * time.Second
+)
+
+// LevelHistory is a fixed-size ring buffer of normalized (0.0-1.0) levels
+type LevelHistory struct {
+	mu      sync.Mutex
+	samples []float32
+	next    int
+	count   int
+}
+
+// NewLevelHistory creates a history holding up to size samples
+func NewLevelHistory(size int) *LevelHistory {
+	return &LevelHistory{samples: make([]float32, size)}
+}
+
+// Add appends a sample, overwriting the oldest once full
+func (lh *LevelHistory) Add(level float32) {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+
+	lh.samples[lh.next] = level
+	lh.next = (lh.next + 1) % len(lh.samples)
+	if lh.count < len(lh.samples) {
+		lh.count++
+	}
+}
+
+// Last returns up to n of the most recent samples in chronological order
+func (lh *LevelHistory) Last(n int) []float32 {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+
+	if n > lh.count {
+		n = lh.count
+	}
+	out := make([]float32, n)
+	start := lh.next - n
+	if start < 0 {
+		start += len(lh.samples)
+	}
+	for i := 0; i < n; i++ {
+		out[i] = lh.samples[(start+i)%len(lh.samples)]
+	}
+	return out
+}
+
+// HistorySampler periodically records the level of every gang with level controls
+// into its level history and loudness meter
+type HistorySampler struct {
+	mu    sync.Mutex
+	gangs []*GangedFader
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewHistorySampler creates a sampler for the gangs' level histories
+func NewHistorySampler(gangs []*GangedFader) *HistorySampler {
+	return &HistorySampler{
+		gangs: gangs,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// Start begins sampling in a background goroutine
+func (hs *HistorySampler) Start() {
+	go func() {
+		defer close(hs.done)
+		ticker := time.NewTicker(historyInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-hs.stop:
+				return
+			case <-ticker.C:
+				hs.mu.Lock()
+				gangs := hs.gangs
+				hs.mu.Unlock()
+				for _, gang := range gangs {
+					if !gang.HasLevels() {
+						continue
+					}
+					level, energy := gang.sampleLevels()
+					gang.GetLevelHistory().Add(gang.normalizeLevel(level))
+					gang.GetLoudness().Add(energy)
+				}
+			}
+		}
+	}()
+}
+
+// SetGangs replaces the sampled gangs (e.g. after a profile switch)
+func (hs *HistorySampler) SetGangs(gangs []*GangedFader) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.gangs = gangs
+}
+
+// Stop stops sampling; blocks until the goroutine has exited
+func (hs *HistorySampler) Stop() {
+	hs.stopOnce.Do(func() {
+		close(hs.stop)
+		<-hs.done
+	})
+}
+
+// drawSparkline renders the recent level history of a gang as a small plot
+func drawSparkline(label string, history *LevelHistory, size imgui.Vec2) {
+	samples := history.Last(int(sparklineWindow / historyInterval))
+	if len(samples) == 0 {
+		imgui.Dummy(size)
+		return
+	}
+	imgui.PlotLinesFloatPtrV(label, &samples[0], int32(len(samples)), 0, "", 0.0, 1.0, size, 4)
+}
+
+// HistoryView renders a larger, zoomable level history for every gang with levels
+type HistoryView struct {
+	gangs   []*GangedFader
+	seconds int32 // Visible window
+}
+
+// NewHistoryView creates a history view for the gangs
+func NewHistoryView(gangs []*GangedFader) *HistoryView {
+	return &HistoryView{
+		gangs:   gangs,
+		seconds: 60,
+	}
+}
+
+// Draw renders the zoom control and one plot per gang
+func (hv *HistoryView) Draw() {
+	maxSeconds := int32(historySize) * int32(historyInterval/time.Millisecond) / 1000
+	imgui.SetNextItemWidth(300)
+	imgui.SliderIntV(tr("Window##history"), &hv.seconds, 5, maxSeconds, "%d s", imgui.SliderFlagsLogarithmic)
+	imgui.SameLine()
+	if imgui.Button(tr("Reset Loudness")) {
+		for _, gang := range hv.gangs {
+			if loudness := gang.GetLoudness(); loudness != nil {
+				loudness.Reset()
+			}
+		}
+	}
+
+	n := int(time.Duration(hv.seconds) * time.Second / historyInterval)
+	for i, gang := range hv.gangs {
+		history := gang.GetLevelHistory()
+		if history == nil {
+			continue
+		}
+		samples := history.Last(n)
+		if len(samples) == 0 {
+			continue
+		}
+		imgui.PlotLinesFloatPtrV(fmt.Sprintf("%s##history_%d", gang.GetName(), i),
+			&samples[0], int32(len(samples)), 0, "", 0.0, 1.0, scaledVec2(imgui.Vec2{X: 600, Y: 60}), 4)
+	}
+}
@@ -0,0 +1,151 @@
+package sessionmixer
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UI strings are written in English in the code and translated at draw time with tr and trf
+// The English text is the catalog key, so an untranslated string falls back to itself and no
+// key list has to be kept in sync; ImGui ID suffixes ("Label##id") are never translated
+
+// bundledLocales holds the translations shipped with sessionmixer (locales/<lang>.yaml)
+//
+//go:embed locales/*.yaml
+var bundledLocales embed.FS
+
+// translations maps English UI strings to the current locale (nil = English)
+var translations map[string]string
+
+// currentLocale is the locale selected by SetLocale ("" = English)
+var currentLocale string
+
+// SetLocale selects the UI language: locale (e.g. "de" or "de_AT"), or the environment
+// (LC_ALL, LC_MESSAGES, LANG) when empty. Bundled catalogs are extended and overridden by
+// $XDG_CONFIG_HOME/sessionmixer/locales/<lang>.yaml; a regional catalog extends its language
+func SetLocale(locale string) {
+	if locale == "" {
+		locale = DetectLocale()
+	}
+	currentLocale = ""
+	translations = nil
+
+	candidates := localeCandidates(locale)
+	if len(candidates) == 0 {
+		return
+	}
+	userDir := ""
+	if dir, err := LocalesDir(); err == nil {
+		userDir = dir
+	}
+
+	catalog := make(map[string]string)
+	for _, name := range candidates {
+		if err := mergeCatalog(catalog, bundledLocales, "locales/"+name+".yaml"); err != nil {
+			log.Printf("Locale %s: %v", name, err)
+		}
+		if userDir != "" {
+			if err := mergeCatalog(catalog, os.DirFS(userDir), name+".yaml"); err != nil {
+				log.Printf("Locale %s: %v", name, err)
+			}
+		}
+	}
+	currentLocale = candidates[len(candidates)-1]
+	if len(catalog) > 0 {
+		translations = catalog
+	}
+}
+
+// GetLocale returns the selected locale ("" for English)
+func GetLocale() string {
+	return currentLocale
+}
+
+// DetectLocale returns the message locale from the environment, as setlocale would
+func DetectLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(env); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// LocalesDir returns the directory of user translation catalogs (<lang>.yaml)
+func LocalesDir() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "locales"), nil
+}
+
+// localeCandidates returns the catalogs to load for a POSIX locale, language first
+// ("de_AT.UTF-8@euro" -> de, de_AT); none for English and the C locale
+func localeCandidates(locale string) []string {
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	locale = strings.ReplaceAll(locale, "-", "_")
+	language, _, hasRegion := strings.Cut(locale, "_")
+	language = strings.ToLower(language)
+	switch language {
+	case "", "c", "posix", "en":
+		return nil
+	}
+	candidates := []string{language}
+	if hasRegion {
+		candidates = append(candidates, language+locale[len(language):])
+	}
+	return candidates
+}
+
+// mergeCatalog adds the translations of a catalog file to catalog; a missing file is not an error
+func mergeCatalog(catalog map[string]string, fsys fs.FS, name string) error {
+	data, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var entries map[string]string
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("invalid catalog %s: %w", name, err)
+	}
+	for msg, translated := range entries {
+		if translated != "" {
+			catalog[msg] = translated
+		}
+	}
+	return nil
+}
+
+// tr translates an English UI string, keeping any ImGui ID suffix ("##id") as is
+func tr(msg string) string {
+	if translations == nil {
+		return msg
+	}
+	label, id, hasID := strings.Cut(msg, "##")
+	translated, ok := translations[label]
+	if !ok {
+		return msg
+	}
+	if hasID {
+		return translated + "##" + id
+	}
+	return translated
+}
+
+// trf translates an English format string and formats it
+func trf(format string, args ...any) string {
+	return fmt.Sprintf(tr(format), args...)
+}
@@ -0,0 +1,54 @@
+package sessionmixer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLocaleCandidates(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   []string
+	}{
+		{"", nil},
+		{"C", nil},
+		{"POSIX", nil},
+		{"en_US.UTF-8", nil},
+		{"de", []string{"de"}},
+		{"de_AT.UTF-8@euro", []string{"de", "de_AT"}},
+		{"pt-BR", []string{"pt", "pt_BR"}},
+	}
+	for _, test := range tests {
+		if got := localeCandidates(test.locale); !slices.Equal(got, test.want) {
+			t.Errorf("localeCandidates(%q) = %v, want %v", test.locale, got, test.want)
+		}
+	}
+}
+
+func TestTranslateKeepsImGuiIDs(t *testing.T) {
+	translations = map[string]string{"Mute": "Stumm", "Input %d": "Eingang %d"}
+	defer func() { translations = nil }()
+
+	if got := tr("Mute##gang_3"); got != "Stumm##gang_3" {
+		t.Errorf("tr = %q, want the ID kept", got)
+	}
+	if got := tr("Lock"); got != "Lock" {
+		t.Errorf("tr = %q, want the English fallback", got)
+	}
+	if got := trf("Input %d", 2); got != "Eingang 2" {
+		t.Errorf("trf = %q", got)
+	}
+}
+
+func TestBundledCatalogsParse(t *testing.T) {
+	entries, err := bundledLocales.ReadDir("locales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		catalog := make(map[string]string)
+		if err := mergeCatalog(catalog, bundledLocales, "locales/"+entry.Name()); err != nil {
+			t.Errorf("%s: %v", entry.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+const (
+	// idleInterval is how often the idle dimmer checks for activity
+	idleInterval = 250 * time.Millisecond
+
+	// defaultIdleDimDb is the dim depth used when the config does not set one
+	defaultIdleDimDb = 20.0
+)
+
+// IdleDimmer is the session timer protecting speakers left running: after a configured time
+// without interaction it dims the monitor gangs, and restores them on the next interaction
+// Interaction is any gang change not made by the dimmer itself (from the UI, keys, a control
+// surface, remote clients or the hardware) and any input to the mixer window (Touch)
+// A dimmed gang moved by someone else is left where it was put
+type IdleDimmer struct {
+	config IdleDimConfig
+	dimDb  float64
+
+	mu           sync.Mutex
+	gangs        []*GangedFader
+	lastActivity time.Time
+	seen         map[*GangedFader]int64 // Values at the last check
+	base         map[string]int64       // Gang name -> value before dimming (nil when not dimmed)
+	lastSet      map[string]int64       // Gang name -> value written by the dimmer
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewIdleDimmer creates an idle dimmer for the configured gangs
+func NewIdleDimmer(config IdleDimConfig, gangs []*GangedFader) (*IdleDimmer, error) {
+	if config.After <= 0 {
+		return nil, fmt.Errorf("idle dim: after must be positive")
+	}
+	for _, name := range config.Gangs {
+		if findGang(gangs, name) == nil {
+			return nil, fmt.Errorf("idle dim: unknown gang '%s'", name)
+		}
+	}
+	dimDb := float64(config.DimDb)
+	if dimDb <= 0 {
+		dimDb = defaultIdleDimDb
+	}
+	return &IdleDimmer{
+		config:       config,
+		dimDb:        dimDb,
+		gangs:        gangs,
+		lastActivity: time.Now(),
+		seen:         make(map[*GangedFader]int64),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}, nil
+}
+
+// SetGangs replaces the watched gangs (e.g. after a profile switch); the switch counts as
+// interaction
+func (id *IdleDimmer) SetGangs(gangs []*GangedFader) {
+	id.mu.Lock()
+	defer id.mu.Unlock()
+	id.gangs = gangs
+	id.seen = make(map[*GangedFader]int64)
+	id.lastActivity = time.Now()
+}
+
+// Touch records interaction with the mixer window (thread-safe)
+func (id *IdleDimmer) Touch() {
+	id.mu.Lock()
+	defer id.mu.Unlock()
+	id.lastActivity = time.Now()
+}
+
+// IsDimmed returns true while the monitor gangs are dimmed
+func (id *IdleDimmer) IsDimmed() bool {
+	id.mu.Lock()
+	defer id.mu.Unlock()
+	return id.base != nil
+}
+
+// GetIdle returns the time since the last interaction
+func (id *IdleDimmer) GetIdle() time.Duration {
+	id.mu.Lock()
+	defer id.mu.Unlock()
+	return time.Since(id.lastActivity)
+}
+
+// Start begins checking for activity in a background goroutine
+func (id *IdleDimmer) Start() {
+	go func() {
+		defer close(id.done)
+		ticker := time.NewTicker(idleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-id.stop:
+				return
+			case <-ticker.C:
+				id.update()
+			}
+		}
+	}()
+}
+
+// Stop stops checking, restoring dimmed gangs; blocks until the goroutine has exited
+// Must only be called after Start
+func (id *IdleDimmer) Stop() {
+	id.stopOnce.Do(func() {
+		close(id.stop)
+		<-id.done
+		id.mu.Lock()
+		defer id.mu.Unlock()
+		id.restore()
+	})
+}
+
+// update records gang changes as activity, then dims after the idle time or restores on
+// activity since dimming
+func (id *IdleDimmer) update() {
+	id.mu.Lock()
+	defer id.mu.Unlock()
+
+	now := time.Now()
+	for _, gang := range id.gangs {
+		value := gang.GetCurrentValue()
+		last, ok := id.seen[gang]
+		id.seen[gang] = value
+		if !ok || value == last {
+			continue
+		}
+		if set, ok := id.lastSet[gang.GetName()]; ok && value == set {
+			continue // The dimmer's own write
+		}
+		id.lastActivity = now
+	}
+
+	idle := now.Sub(id.lastActivity)
+	switch {
+	case id.base == nil && idle >= id.config.After:
+		id.dim()
+	case id.base != nil && idle < id.config.After:
+		id.restore()
+	}
+}
+
+// dim lowers the monitor gangs by the dim depth, capturing their values
+func (id *IdleDimmer) dim() {
+	id.base = make(map[string]int64)
+	id.lastSet = make(map[string]int64)
+	for _, name := range id.config.Gangs {
+		gang := findGang(id.gangs, name)
+		if gang == nil {
+			continue
+		}
+		value := gang.GetCurrentValue()
+		dimmed := gang.GetMin()
+		if db := gang.ValueToDb(value); !math.IsInf(db, -1) {
+			dimmed = gang.DbToValue(db - id.dimDb)
+		}
+		if err := gang.HandleUIChange(dimmed); err != nil {
+			log.Printf("Idle dim: failed to dim %s: %v", name, err)
+			continue
+		}
+		id.base[name] = value
+		id.lastSet[name] = dimmed
+		id.seen[gang] = dimmed
+	}
+	log.Printf("Idle dim: dimmed monitors by %.0f dB after %s without activity", id.dimDb, id.config.After)
+}
+
+// restore returns dimmed gangs that nobody has moved since to their captured values
+func (id *IdleDimmer) restore() {
+	if id.base == nil {
+		return
+	}
+	for name, value := range id.base {
+		gang := findGang(id.gangs, name)
+		if gang == nil || gang.GetCurrentValue() != id.lastSet[name] {
+			continue
+		}
+		if err := gang.HandleUIChange(value); err != nil {
+			log.Printf("Idle dim: failed to restore %s: %v", name, err)
+		}
+		id.seen[gang] = value
+	}
+	id.base = nil
+	id.lastSet = nil
+}
+
+// SetIdleDimmer enables the idle dimmer status line; input to the window counts as activity
+func (sm *SessionMixer) SetIdleDimmer(idle *IdleDimmer) {
+	sm.idle = idle
+}
+
+// trackActivity reports input to the window to the idle dimmer and shows when it has dimmed
+func (sm *SessionMixer) trackActivity() {
+	io := imgui.CurrentIO()
+	delta := io.MouseDelta()
+	if delta.X != 0 || delta.Y != 0 || io.MouseWheel() != 0 || imgui.IsAnyMouseDown() ||
+		io.KeyMods() != 0 || io.InputQueueCharacters().Size > 0 {
+		sm.idle.Touch()
+	}
+	if sm.idle.IsDimmed() {
+		imgui.TextColored(warningColor, trf("Monitors dimmed after %s without activity; any input restores them", sm.idle.config.After))
+	}
+}
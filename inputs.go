@@ -0,0 +1,277 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// InputChannel groups the preamp settings for a single physical input
+// Any of the settings may be nil when the device does not provide them
+type InputChannel struct {
+	number int
+
+	gain      *MixerChannel
+	pad       *MixerChannel
+	air       *MixerChannel
+	autogain  *MixerChannel
+	phantom   *MixerChannel
+	impedance *MixerChannel
+	link      *MixerChannel // Hardware stereo link switch (on the first input of a pair)
+}
+
+// inputSetting describes one row of the input panel
+type inputSetting struct {
+	label string
+	get   func(in *InputChannel) *MixerChannel
+}
+
+// inputSettings lists the rows of the input panel in display order
+var inputSettings = []inputSetting{
+	{"Gain", func(in *InputChannel) *MixerChannel { return in.gain }},
+	{"Link", func(in *InputChannel) *MixerChannel { return in.link }},
+	{"Pad", func(in *InputChannel) *MixerChannel { return in.pad }},
+	{"Air", func(in *InputChannel) *MixerChannel { return in.air }},
+	{"Autogain", func(in *InputChannel) *MixerChannel { return in.autogain }},
+	{"Phantom", func(in *InputChannel) *MixerChannel { return in.phantom }},
+	{"Impedance", func(in *InputChannel) *MixerChannel { return in.impedance }},
+}
+
+// NewInputChannel creates an input channel from the preamp controls discovered by scarlettctl
+func NewInputChannel(preamp scarlettctl.PreampChannel) (*InputChannel, error) {
+	in := &InputChannel{number: preamp.ChannelNum}
+
+	var err error
+	bind := func(control *scarlettctl.Control, setting string) *MixerChannel {
+		if control == nil || err != nil {
+			return nil
+		}
+		var ch *MixerChannel
+		ch, err = NewMixerChannel(control, fmt.Sprintf("Input %d %s", preamp.ChannelNum, setting), "raw")
+		return ch
+	}
+
+	in.gain = bind(preamp.Gain, "Gain")
+	in.pad = bind(preamp.Pad, "Pad")
+	in.air = bind(preamp.Air, "Air")
+	in.autogain = bind(preamp.Autogain, "Autogain")
+	in.phantom = bind(preamp.Phantom, "Phantom")
+	in.impedance = bind(preamp.Impedance, "Impedance")
+	in.link = bind(preamp.Link, "Link")
+	if err != nil {
+		return nil, fmt.Errorf("input %d: %w", preamp.ChannelNum, err)
+	}
+
+	return in, nil
+}
+
+// GetNumber returns the physical input number
+func (in *InputChannel) GetNumber() int {
+	return in.number
+}
+
+// GetGain returns the preamp gain channel, or nil if the device has none
+func (in *InputChannel) GetGain() *MixerChannel {
+	return in.gain
+}
+
+// GetAutogain returns the autogain switch channel, or nil if the device has none
+func (in *InputChannel) GetAutogain() *MixerChannel {
+	return in.autogain
+}
+
+// GetChannels returns all settings provided by the device for this input
+func (in *InputChannel) GetChannels() []*MixerChannel {
+	var channels []*MixerChannel
+	for _, setting := range inputSettings {
+		if ch := setting.get(in); ch != nil {
+			channels = append(channels, ch)
+		}
+	}
+	return channels
+}
+
+// InputPanel renders the preamp settings of every physical input, grouped by input
+type InputPanel struct {
+	inputs    []*InputChannel
+	interlock *PhantomInterlock                 // Optional phantom power safety interlock
+	links     map[*InputChannel][]*InputChannel // Software gain links: input -> linked partners
+}
+
+// NewInputPanel discovers the preamp controls on the card and creates an input panel
+// The panel is empty if the device exposes no preamp controls
+func NewInputPanel(card *scarlettctl.Card) (*InputPanel, error) {
+	preamps, err := card.GetPreampChannels()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover preamp controls: %w", err)
+	}
+
+	panel := &InputPanel{}
+	for _, preamp := range preamps {
+		in, err := NewInputChannel(preamp)
+		if err != nil {
+			return nil, err
+		}
+		panel.inputs = append(panel.inputs, in)
+	}
+
+	return panel, nil
+}
+
+// HasInputs returns true if the device provides any preamp controls
+func (ip *InputPanel) HasInputs() bool {
+	return len(ip.inputs) > 0
+}
+
+// GetInputs returns the input channels
+func (ip *InputPanel) GetInputs() []*InputChannel {
+	return ip.inputs
+}
+
+// GetChannels returns every setting channel in the panel, for event monitor registration
+func (ip *InputPanel) GetChannels() []*MixerChannel {
+	var channels []*MixerChannel
+	for _, in := range ip.inputs {
+		channels = append(channels, in.GetChannels()...)
+	}
+	return channels
+}
+
+// Draw renders the input settings as a table with one column per physical input
+// Rows for settings the device does not provide on any input are skipped
+func (ip *InputPanel) Draw() {
+	if len(ip.inputs) == 0 {
+		return
+	}
+
+	columnWidth := float32(90.0)
+	imgui.BeginTableV("inputs_table", int32(len(ip.inputs)+1),
+		imgui.TableFlagsNone,
+		imgui.Vec2{X: float32(len(ip.inputs)+1) * columnWidth, Y: 0}, 0.0)
+
+	imgui.TableSetupColumnV("##setting", imgui.TableColumnFlagsWidthFixed, columnWidth, 0)
+	for _, in := range ip.inputs {
+		imgui.TableSetupColumnV(trf("Input %d", in.number), imgui.TableColumnFlagsWidthFixed, columnWidth, 0)
+	}
+	imgui.TableHeadersRow()
+
+	for _, setting := range inputSettings {
+		if !ip.hasSetting(setting) {
+			continue
+		}
+
+		imgui.TableNextRow()
+		imgui.TableNextColumn()
+		imgui.Text(setting.label)
+
+		for _, in := range ip.inputs {
+			imgui.TableNextColumn()
+			ch := setting.get(in)
+			if ch == nil {
+				imgui.TextDisabled(tr("-"))
+				continue
+			}
+			imgui.SetNextItemWidth(-1)
+			label := fmt.Sprintf("##%s_%d", setting.label, in.number)
+			if ch == in.phantom && ip.interlock != nil {
+				ip.drawPhantom(label, in)
+				continue
+			}
+			if drawControlWidget(label, ch) && ch == in.gain {
+				ip.followLinkedGains(in)
+			}
+		}
+	}
+
+	imgui.EndTable()
+
+	if ip.interlock != nil {
+		ip.interlock.Draw()
+	}
+}
+
+// drawPhantom renders a phantom power switch routed through the safety interlock
+func (ip *InputPanel) drawPhantom(label string, in *InputChannel) {
+	enabled := in.phantom.GetCurrentValue() != 0
+	if imgui.Checkbox(label, &enabled) {
+		if err := ip.interlock.Request(in, enabled); err != nil {
+			log.Printf("Failed to switch phantom power on input %d: %v", in.number, err)
+		}
+	}
+}
+
+// SetInputLinks links the preamp gains of groups of inputs (e.g. stereo pairs)
+// A pair of consecutive inputs with a hardware link switch is linked in hardware; other
+// groups are linked in software, so a gain change in the UI is copied to the partners
+func (ip *InputPanel) SetInputLinks(links []InputLink) error {
+	ip.links = make(map[*InputChannel][]*InputChannel)
+	for i, link := range links {
+		if len(link.Inputs) < 2 {
+			return fmt.Errorf("input link %d: at least 2 inputs required", i)
+		}
+		var group []*InputChannel
+		for _, number := range link.Inputs {
+			in := ip.findInput(number)
+			if in == nil {
+				return fmt.Errorf("input link %d: input %d not found", i, number)
+			}
+			if in.gain == nil {
+				return fmt.Errorf("input link %d: input %d has no gain control", i, number)
+			}
+			group = append(group, in)
+		}
+
+		first := group[0]
+		if len(group) == 2 && first.link != nil && group[1].number == first.number+1 {
+			if err := first.link.HandleUIChange(1); err != nil {
+				return fmt.Errorf("input link %d: failed to enable hardware link: %w", i, err)
+			}
+			continue
+		}
+		for _, in := range group {
+			for _, partner := range group {
+				if partner != in {
+					ip.links[in] = append(ip.links[in], partner)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// followLinkedGains copies an input's gain to its software-linked partners
+func (ip *InputPanel) followLinkedGains(in *InputChannel) {
+	value := in.gain.GetCurrentValue()
+	for _, partner := range ip.links[in] {
+		if err := partner.gain.HandleUIChange(value); err != nil {
+			log.Printf("Failed to follow gain on input %d: %v", partner.number, err)
+		}
+	}
+}
+
+// findInput returns the input with the given physical number, or nil
+func (ip *InputPanel) findInput(number int) *InputChannel {
+	for _, in := range ip.inputs {
+		if in.number == number {
+			return in
+		}
+	}
+	return nil
+}
+
+// SetPhantomInterlock routes phantom power switches through a safety interlock
+func (ip *InputPanel) SetPhantomInterlock(interlock *PhantomInterlock) {
+	ip.interlock = interlock
+}
+
+// hasSetting returns true if any input provides the given setting
+func (ip *InputPanel) hasSetting(setting inputSetting) bool {
+	for _, in := range ip.inputs {
+		if setting.get(in) != nil {
+			return true
+		}
+	}
+	return false
+}
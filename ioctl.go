@@ -0,0 +1,61 @@
+package sessionmixer
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// ioctl directions
+const (
+	iocWrite = 1
+	iocRead  = 2
+)
+
+// ioc builds an ioctl request number from its direction, type, number and argument size
+func ioc(dir, typ, nr, size uintptr) uintptr {
+	return dir<<30 | size<<16 | typ<<8 | nr
+}
+
+// ioctl issues an ioctl with a pointer to arg, without taking the file out of non-blocking
+// mode, so Close still interrupts a pending read
+func ioctl(file *os.File, request uintptr, arg []byte) error {
+	return control(file, func(fd uintptr) syscall.Errno {
+		_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(unsafe.Pointer(&arg[0])))
+		return errno
+	})
+}
+
+// ioctlValue issues an ioctl taking its argument by value
+func ioctlValue(file *os.File, request, arg uintptr) error {
+	return control(file, func(fd uintptr) syscall.Errno {
+		_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, arg)
+		return errno
+	})
+}
+
+// control runs fn on the file's descriptor
+func control(file *os.File, fn func(fd uintptr) syscall.Errno) error {
+	conn, err := file.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errno syscall.Errno
+	if err := conn.Control(func(fd uintptr) { errno = fn(fd) }); err != nil {
+		return err
+	}
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// ioctlFd issues an ioctl with a pointer to arg on a descriptor owned by someone else (e.g. the
+// ALSA control handle of scarlettctl), which must not be wrapped in an *os.File
+func ioctlFd(fd int, request uintptr, arg []byte) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), request, uintptr(unsafe.Pointer(&arg[0])))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
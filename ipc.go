@@ -0,0 +1,188 @@
+package sessionmixer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ipcTimeout bounds a single IPC request, on both ends
+const ipcTimeout = 5 * time.Second
+
+// IPCRequest is one command sent to a running instance, as a single JSON line
+type IPCRequest struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// IPCResponse is the reply to an IPCRequest, as a single JSON line
+type IPCResponse struct {
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// IPCHandler handles one command; it runs on the connection's goroutine
+type IPCHandler func(args []string) (string, error)
+
+// IPCServer accepts commands from the CLI (e.g. `sessionmixer profile`) on a unix socket
+type IPCServer struct {
+	path     string
+	listener net.Listener
+	handlers map[string]IPCHandler
+
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// IPCSocketPath returns the path of the control socket: $XDG_RUNTIME_DIR/sessionmixer.sock,
+// or sessionmixer.sock in the state directory when there is no runtime directory
+func IPCSocketPath() (string, error) {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "sessionmixer.sock"), nil
+	}
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sessionmixer.sock"), nil
+}
+
+// NewIPCServer creates a server; register handlers with Handle before Start
+func NewIPCServer(path string) *IPCServer {
+	return &IPCServer{
+		path:     path,
+		handlers: make(map[string]IPCHandler),
+		done:     make(chan struct{}),
+	}
+}
+
+// Handle registers the handler for a command
+func (s *IPCServer) Handle(command string, handler IPCHandler) {
+	s.handlers[command] = handler
+}
+
+// Start listens on the socket and serves connections in the background
+// A stale socket left by a crashed instance is replaced; a live one is an error
+func (s *IPCServer) Start() error {
+	if conn, err := net.DialTimeout("unix", s.path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("another instance is listening on '%s'", s.path)
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	listener, err := net.Listen("unix", s.path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on '%s': %w", s.path, err)
+	}
+	if err := os.Chmod(s.path, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	s.listener = listener
+	go s.serve()
+	return nil
+}
+
+// Stop closes the socket; blocks until the accept loop has exited
+func (s *IPCServer) Stop() {
+	s.stopOnce.Do(func() {
+		if s.listener == nil {
+			return
+		}
+		s.listener.Close()
+		<-s.done
+		os.Remove(s.path)
+	})
+}
+
+// GetPath returns the socket path
+func (s *IPCServer) GetPath() string {
+	return s.path
+}
+
+func (s *IPCServer) serve() {
+	defer close(s.done)
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("IPC: accept failed: %v", err)
+			}
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle serves a single request on a connection
+// The handler itself is not bounded, as some commands (e.g. a scene fade) take a while; the
+// client sets its own deadline
+func (s *IPCServer) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(ipcTimeout))
+
+	var resp IPCResponse
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	var req IPCRequest
+	if err == nil {
+		err = json.Unmarshal(line, &req)
+	}
+	if err != nil {
+		resp.Error = fmt.Sprintf("invalid request: %v", err)
+	} else if handler, ok := s.handlers[req.Command]; !ok {
+		resp.Error = fmt.Sprintf("unknown command '%s'", req.Command)
+	} else if result, err := handler(req.Args); err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.Result = result
+	}
+
+	data, _ := json.Marshal(resp)
+	conn.SetWriteDeadline(time.Now().Add(ipcTimeout))
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		log.Printf("IPC: reply failed: %v", err)
+	}
+}
+
+// SendIPC sends a command to the instance listening on path and returns its result
+func SendIPC(path, command string, args ...string) (string, error) {
+	return SendIPCTimeout(path, ipcTimeout, command, args...)
+}
+
+// SendIPCTimeout is SendIPC for commands that take longer than the default timeout to complete
+func SendIPCTimeout(path string, timeout time.Duration, command string, args ...string) (string, error) {
+	conn, err := net.DialTimeout("unix", path, ipcTimeout)
+	if err != nil {
+		return "", fmt.Errorf("%w on '%s': %v", ErrNoInstance, path, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	data, _ := json.Marshal(IPCRequest{Command: command, Args: args})
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read reply: %w", err)
+	}
+	var resp IPCResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return "", fmt.Errorf("invalid reply: %w", err)
+	}
+	if resp.Error != "" {
+		return "", errors.New(resp.Error)
+	}
+	return resp.Result, nil
+}
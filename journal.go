@@ -0,0 +1,174 @@
+package sessionmixer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/michaelquigley/df/dd"
+)
+
+const (
+	// journalInterval is how often the mix is checked for changes to journal
+	journalInterval = 250 * time.Millisecond
+
+	// RecoveredScene is the scene the journaled mix is saved as after a session that did not end
+	// cleanly, so it can be restored later with `sessionmixer apply recovered`
+	RecoveredScene = "recovered"
+)
+
+// journalState is the on-disk journal: the last mix and whether the session ended cleanly
+type journalState struct {
+	Clean   bool
+	Gangs   map[string]int64
+	Routing map[string]string
+}
+
+// StateJournal writes the current mix to a small state file whenever it changes, replacing the
+// file atomically, and marks it clean on Stop; a journal left unclean means the application or
+// machine crashed, and LoadJournal returns the mix it held
+type StateJournal struct {
+	path   string
+	scenes *SceneManager
+	last   *journalState
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+	err      error // Error of the final write
+}
+
+// NewStateJournal creates a journal of the scene manager's gangs and routing, written to path
+func NewStateJournal(path string, scenes *SceneManager) *StateJournal {
+	return &StateJournal{
+		path:   path,
+		scenes: scenes,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// LoadJournal returns the mix journaled by a session that did not end cleanly, or nil if the
+// last session ended cleanly or there is no journal
+func LoadJournal(path string) (*Scene, error) {
+	state, err := dd.NewFromYAML[journalState](path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state journal: %w", err)
+	}
+	if state.Clean || len(state.Gangs) == 0 {
+		return nil, nil
+	}
+	return &Scene{Name: RecoveredScene, Gangs: state.Gangs, Routing: state.Routing}, nil
+}
+
+// Start writes the current mix and then journals every change in a background goroutine
+func (sj *StateJournal) Start() {
+	go func() {
+		defer close(sj.done)
+		ticker := time.NewTicker(journalInterval)
+		defer ticker.Stop()
+		for {
+			if err := sj.update(false); err != nil {
+				log.Printf("State journal: %v", err)
+			}
+			select {
+			case <-sj.stop:
+				sj.err = sj.update(true)
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop journals the final mix, marked clean; blocks until the goroutine has exited
+// Must only be called after Start
+func (sj *StateJournal) Stop() error {
+	sj.stopOnce.Do(func() {
+		close(sj.stop)
+		<-sj.done
+	})
+	return sj.err
+}
+
+// update writes the current mix if it differs from the last one written
+func (sj *StateJournal) update(clean bool) error {
+	scene := sj.scenes.Capture("")
+	state := &journalState{Clean: clean, Gangs: scene.Gangs, Routing: scene.Routing}
+	if last := sj.last; last != nil && last.Clean == clean &&
+		maps.Equal(last.Gangs, state.Gangs) && maps.Equal(last.Routing, state.Routing) {
+		return nil
+	}
+	if err := sj.write(state); err != nil {
+		return err
+	}
+	sj.last = state
+	return nil
+}
+
+// write replaces the journal file, syncing it before the rename so a crash leaves either the
+// previous or the new journal, never a partial one
+func (sj *StateJournal) write(state *journalState) error {
+	if err := os.MkdirAll(filepath.Dir(sj.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	tmp := sj.path + ".tmp"
+	if err := dd.UnbindToYAML(state, tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write state journal: %w", err)
+	}
+	f, err := os.Open(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to write state journal: %w", err)
+	}
+	err = f.Sync()
+	f.Close()
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to sync state journal: %w", err)
+	}
+	if err := os.Rename(tmp, sj.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write state journal: %w", err)
+	}
+	return nil
+}
+
+// SetRecovered offers to restore the mix of a session that did not end cleanly (nil for none)
+func (sm *SessionMixer) SetRecovered(scene *Scene) {
+	sm.recovered = scene
+	sm.openRecovery = scene != nil
+}
+
+// drawRecovery renders the dialog offering to restore the recovered mix
+func (sm *SessionMixer) drawRecovery() {
+	if sm.openRecovery {
+		imgui.OpenPopupStr(tr("Restore Last Mix"))
+		sm.openRecovery = false
+	}
+	if imgui.BeginPopupModalV(tr("Restore Last Mix"), nil, imgui.WindowFlagsAlwaysAutoResize) {
+		imgui.Text(tr("The last session did not end cleanly and the hardware holds a different mix."))
+		imgui.TextDisabled(trf("The last mix is kept as the scene '%s'.", RecoveredScene))
+		if imgui.Button(tr("Restore")) {
+			logError(sm.scenes.Apply(sm.recovered))
+			sm.recovered = nil
+			imgui.CloseCurrentPopup()
+		}
+		imgui.SameLine()
+		if imgui.Button(tr("Keep Hardware Mix")) {
+			sm.recovered = nil
+			imgui.CloseCurrentPopup()
+		}
+		imgui.EndPopup()
+	}
+}
@@ -0,0 +1,175 @@
+package sessionmixer
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+const (
+	// jumpRampInterval is the step of a jump ramp
+	jumpRampInterval = 10 * time.Millisecond
+
+	// defaultJumpRamp is the ramp time for guarded jumps that cannot be confirmed (remote
+	// clients) when the gang does not set one
+	defaultJumpRamp = time.Second
+)
+
+// SetJumpGuard guards the gang against single actions that would move it by more than maxDb
+// (0 disables the guard); such changes are ramped over ramp, or held for confirmation in the
+// UI if ramp is 0
+func (gf *GangedFader) SetJumpGuard(maxDb float64, ramp time.Duration) {
+	gf.jumpMaxDb = maxDb
+	gf.jumpRamp = ramp
+}
+
+// GetJumpGuard returns the jump guard threshold (0 = no guard) and ramp time
+func (gf *GangedFader) GetJumpGuard() (float64, time.Duration) {
+	return gf.jumpMaxDb, gf.jumpRamp
+}
+
+// IsJump returns true if changing the gang to value is guarded as a large jump
+func (gf *GangedFader) IsJump(value int64) bool {
+	if gf.jumpMaxDb <= 0 || gf.toggle || gf.display != "" {
+		return false
+	}
+	from := math.Max(gf.ValueToDb(gf.GetCurrentValue()), floorDb)
+	to := math.Max(gf.ValueToDb(value), floorDb)
+	return math.Abs(to-from) > gf.jumpMaxDb
+}
+
+// HandleGuardedChange is HandleUIChange for single UI actions: a guarded jump is ramped, or,
+// without a ramp time, held until ConfirmJump or CancelJump
+func (gf *GangedFader) HandleGuardedChange(value int64) error {
+	value, rangeErr := gf.ClampValue(value)
+	if !gf.IsJump(value) {
+		gf.cancelRamp()
+		if err := gf.HandleUIChange(value); err != nil {
+			return err
+		}
+		return rangeErr
+	}
+	if gf.jumpRamp > 0 {
+		gf.ramp(value, gf.jumpRamp)
+		return rangeErr
+	}
+	gf.jumpTarget.Store(value)
+	gf.jumpPending.Store(true)
+	return rangeErr
+}
+
+// HandleRampedChange is HandleUIChange for changes that cannot be confirmed (e.g. from a
+// remote client): a guarded jump is always ramped
+func (gf *GangedFader) HandleRampedChange(value int64) error {
+	value, rangeErr := gf.ClampValue(value)
+	if !gf.IsJump(value) {
+		gf.cancelRamp()
+		if err := gf.HandleUIChange(value); err != nil {
+			return err
+		}
+		return rangeErr
+	}
+	ramp := gf.jumpRamp
+	if ramp <= 0 {
+		ramp = defaultJumpRamp
+	}
+	gf.ramp(value, ramp)
+	return rangeErr
+}
+
+// GetPendingJump returns the value of a jump waiting for confirmation
+func (gf *GangedFader) GetPendingJump() (int64, bool) {
+	return gf.jumpTarget.Load(), gf.jumpPending.Load()
+}
+
+// ConfirmJump applies the jump waiting for confirmation
+func (gf *GangedFader) ConfirmJump() error {
+	if !gf.jumpPending.CompareAndSwap(true, false) {
+		return nil
+	}
+	gf.cancelRamp()
+	return gf.HandleUIChange(gf.jumpTarget.Load())
+}
+
+// CancelJump discards the jump waiting for confirmation
+func (gf *GangedFader) CancelJump() {
+	gf.jumpPending.Store(false)
+}
+
+// ramp moves the gang to value over the ramp time in dB space in a background goroutine,
+// replacing any ramp in progress; the ramp stops if someone else moves the gang
+func (gf *GangedFader) ramp(value int64, ramp time.Duration) {
+	gen := gf.jumpGen.Add(1)
+	from := gf.GetCurrentValue()
+	fromDb := math.Max(gf.ValueToDb(from), floorDb)
+	toDb := math.Max(gf.ValueToDb(value), floorDb)
+	go func() {
+		lastSet := from
+		started := time.Now()
+		for {
+			time.Sleep(jumpRampInterval)
+			if gf.jumpGen.Load() != gen || gf.GetCurrentValue() != lastSet {
+				return
+			}
+			t := float64(time.Since(started)) / float64(ramp)
+			next := value
+			if t < 1 {
+				next = gf.DbToValue(fromDb + (toDb-fromDb)*t)
+				if fromDb+(toDb-fromDb)*t <= floorDb {
+					next = gf.GetMin()
+				}
+			}
+			if err := gf.HandleUIChange(next); err != nil {
+				log.Printf("Failed to ramp %s: %v", gf.name, err)
+				return
+			}
+			lastSet = next
+			if t >= 1 {
+				return
+			}
+		}
+	}()
+}
+
+// cancelRamp stops a ramp in progress
+func (gf *GangedFader) cancelRamp() {
+	gf.jumpGen.Add(1)
+}
+
+// drawJumpConfirm renders the confirmation dialog for the first gang with a pending jump
+func (sm *SessionMixer) drawJumpConfirm() {
+	if sm.jumping == nil {
+		for _, gang := range sm.gangs {
+			if _, ok := gang.GetPendingJump(); ok {
+				sm.jumping = gang
+				imgui.OpenPopupStr(tr("Large Change"))
+				break
+			}
+		}
+	}
+	if !imgui.BeginPopupModalV(tr("Large Change"), nil, imgui.WindowFlagsAlwaysAutoResize) {
+		return
+	}
+	if gang := sm.jumping; gang != nil {
+		value, _ := gang.GetPendingJump()
+		current := gang.GetCurrentValue()
+		imgui.Text(trf("Change %s from %s to %s?", gang.GetName(), gang.FormatValue(current), gang.FormatValue(value)))
+		imgui.TextDisabled(trf("More than %.0f dB in one step", gang.jumpMaxDb))
+		if imgui.Button(tr("Apply")) {
+			logError(gang.ConfirmJump())
+			sm.jumping = nil
+			imgui.CloseCurrentPopup()
+		}
+		imgui.SameLine()
+		if imgui.Button(tr("Cancel")) {
+			gang.CancelJump()
+			sm.jumping = nil
+			imgui.CloseCurrentPopup()
+		}
+	} else {
+		imgui.CloseCurrentPopup()
+	}
+	imgui.EndPopup()
+}
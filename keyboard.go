@@ -0,0 +1,380 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/michaelquigley/dfx"
+)
+
+const (
+	// coarseSteps is the number of nudge steps applied by PageUp/PageDown
+	coarseSteps = 6
+
+	// defaultDimDb is the dim depth used when a dim binding does not set one
+	defaultDimDb = 20.0
+)
+
+// builtinKeys are the shortcuts used by the fader bank navigation actions
+var builtinKeys = []string{"Left", "Right", "Up", "Down", "PageUp", "PageDown", "Home"}
+
+// focusColor highlights the keyboard-focused gang
+var focusColor = imgui.Vec4{X: 0.26, Y: 0.59, Z: 0.98, W: 0.35}
+
+// buildActions registers the keyboard actions for navigating and adjusting the fader bank
+func (sm *SessionMixer) buildActions() *dfx.ActionRegistry {
+	actions := dfx.NewActionRegistry()
+	actions.MustRegister("mixer.focus_prev", "Left", func() { sm.moveFocus(-1) })
+	actions.MustRegister("mixer.focus_next", "Right", func() { sm.moveFocus(1) })
+	actions.MustRegister("mixer.up", "Up", func() { sm.nudgeFocused(1) })
+	actions.MustRegister("mixer.down", "Down", func() { sm.nudgeFocused(-1) })
+	actions.MustRegister("mixer.page_up", "PageUp", func() { sm.nudgeFocused(coarseSteps) })
+	actions.MustRegister("mixer.page_down", "PageDown", func() { sm.nudgeFocused(-coarseSteps) })
+	actions.MustRegister("mixer.reset", "Home", func() {
+		if gang := sm.focusedGang(); gang != nil && !gang.IsLocked() && gang.Claim(WriteSourceUI) {
+			logError(gang.ResetToDefault())
+		}
+	})
+	return actions
+}
+
+// SetKeybindings validates the configured keybindings and registers them as actions
+// Momentary bindings fire when the chord is pressed and again when it is released; they are
+// polled every frame rather than registered, as actions only report presses
+// Cough bindings are always momentary
+// Must be called after SetSceneManager when any binding recalls a scene, after SetProfiles
+// when any binding switches profiles, after SetCoughs when any binding holds a cough switch, and
+// after SetPanic when any binding engages or restores the panic
+func (sm *SessionMixer) SetKeybindings(bindings []Keybinding) error {
+	used := make(map[keyChord]bool)
+	for _, key := range builtinKeys {
+		chord, err := parseKeyChord(key)
+		if err != nil {
+			return err
+		}
+		used[chord] = true
+	}
+
+	for i, binding := range bindings {
+		// Every chord is parsed here, so a bad key is an error rather than a panic in MustRegister
+		chord, err := parseKeyChord(binding.Keys)
+		if err != nil {
+			return fmt.Errorf("keybinding %d: %w", i, err)
+		}
+		if used[chord] {
+			return fmt.Errorf("keybinding %d: '%s' is already bound", i, binding.Keys)
+		}
+		used[chord] = true
+
+		handler, err := sm.bindingHandler(binding)
+		if err != nil {
+			return fmt.Errorf("keybinding %d (%s): %w", i, binding.Keys, err)
+		}
+
+		mode := binding.Mode
+		if binding.Action == "cough" {
+			if mode == "latching" {
+				return fmt.Errorf("keybinding %d (%s): cough cannot be latching", i, binding.Keys)
+			}
+			mode = "momentary"
+		}
+		switch mode {
+		case "", "latching":
+			sm.actions.MustRegister(fmt.Sprintf("binding.%d.%s", i, binding.Action), binding.Keys, handler)
+		case "momentary":
+			if slices.Contains([]string{"recall", "profile", "panic", "restore"}, binding.Action) {
+				return fmt.Errorf("keybinding %d (%s): %s cannot be momentary", i, binding.Keys, binding.Action)
+			}
+			sm.momentary = append(sm.momentary, &momentaryBinding{chord: chord, toggle: handler})
+		default:
+			return fmt.Errorf("keybinding %d (%s): unknown mode '%s'", i, binding.Keys, binding.Mode)
+		}
+	}
+	return nil
+}
+
+// momentaryBinding is a keybinding active only while its chord is held
+type momentaryBinding struct {
+	chord  keyChord
+	toggle func()
+	held   bool
+}
+
+// pollMomentary toggles momentary bindings on chord press and release
+// New presses are ignored while a text field has keyboard focus
+func (sm *SessionMixer) pollMomentary() {
+	io := imgui.CurrentIO()
+	for _, binding := range sm.momentary {
+		down := binding.chord.isDown(io)
+		if down == binding.held || (down && io.WantTextInput()) {
+			continue
+		}
+		binding.held = down
+		binding.toggle()
+	}
+}
+
+// keyChord is a key with its required modifiers
+type keyChord struct {
+	key                     imgui.Key
+	ctrl, shift, alt, super bool
+}
+
+// namedKeys maps key names (lowercase) to imgui keys, beyond letters, digits and F1-F12
+var namedKeys = map[string]imgui.Key{
+	"space":     imgui.KeySpace,
+	"enter":     imgui.KeyEnter,
+	"tab":       imgui.KeyTab,
+	"escape":    imgui.KeyEscape,
+	"backspace": imgui.KeyBackspace,
+	"insert":    imgui.KeyInsert,
+	"delete":    imgui.KeyDelete,
+	"left":      imgui.KeyLeftArrow,
+	"right":     imgui.KeyRightArrow,
+	"up":        imgui.KeyUpArrow,
+	"down":      imgui.KeyDownArrow,
+	"pageup":    imgui.KeyPageUp,
+	"pagedown":  imgui.KeyPageDown,
+	"home":      imgui.KeyHome,
+	"end":       imgui.KeyEnd,
+}
+
+// parseKeyChord parses a chord such as "Ctrl+Shift+T" or "F5"
+func parseKeyChord(keys string) (keyChord, error) {
+	var chord keyChord
+	parts := strings.Split(normalizeKeys(keys), "+")
+	for _, part := range parts[:len(parts)-1] {
+		switch part {
+		case "ctrl":
+			chord.ctrl = true
+		case "shift":
+			chord.shift = true
+		case "alt":
+			chord.alt = true
+		case "super":
+			chord.super = true
+		default:
+			return chord, fmt.Errorf("unknown modifier '%s' in '%s'", part, keys)
+		}
+	}
+
+	name := parts[len(parts)-1]
+	switch {
+	case len(name) == 1 && name[0] >= 'a' && name[0] <= 'z':
+		chord.key = imgui.KeyA + imgui.Key(name[0]-'a')
+	case len(name) == 1 && name[0] >= '0' && name[0] <= '9':
+		chord.key = imgui.Key0 + imgui.Key(name[0]-'0')
+	case len(name) >= 2 && name[0] == 'f':
+		var n int
+		if _, err := fmt.Sscanf(name[1:], "%d", &n); err != nil || n < 1 || n > 12 {
+			return chord, fmt.Errorf("unknown key '%s' in '%s'", name, keys)
+		}
+		chord.key = imgui.KeyF1 + imgui.Key(n-1)
+	default:
+		key, ok := namedKeys[name]
+		if !ok {
+			return chord, fmt.Errorf("unknown key '%s' in '%s'", name, keys)
+		}
+		chord.key = key
+	}
+	return chord, nil
+}
+
+// isDown returns true while the key is held with exactly the chord's modifiers
+func (kc keyChord) isDown(io *imgui.IO) bool {
+	return imgui.IsKeyDown(kc.key) &&
+		io.KeyCtrl() == kc.ctrl && io.KeyShift() == kc.shift && io.KeyAlt() == kc.alt && io.KeySuper() == kc.super
+}
+
+// bindingHandler creates the action handler for a configured keybinding
+func (sm *SessionMixer) bindingHandler(binding Keybinding) (func(), error) {
+	switch binding.Action {
+	case "mute":
+		if findGang(sm.gangs, binding.Gang) == nil {
+			return nil, fmt.Errorf("unknown gang '%s'", binding.Gang)
+		}
+		// Resolved on each press, as a profile switch replaces the gangs
+		return func() {
+			gang := findGang(sm.gangs, binding.Gang)
+			if gang == nil || gang.IsLocked() || !gang.Claim(WriteSourceUI) {
+				return
+			}
+			if gang.IsMuted() {
+				logError(gang.Unmute())
+			} else {
+				logError(gang.Mute())
+			}
+		}, nil
+
+	case "lock":
+		if findGang(sm.gangs, binding.Gang) == nil {
+			return nil, fmt.Errorf("unknown gang '%s'", binding.Gang)
+		}
+		return func() {
+			if gang := findGang(sm.gangs, binding.Gang); gang != nil {
+				gang.SetLocked(!gang.IsLocked())
+			}
+		}, nil
+
+	case "profile":
+		if sm.profiles == nil {
+			return nil, fmt.Errorf("profiles are not available")
+		}
+		if binding.Profile == "" {
+			return sm.profiles.Next, nil
+		}
+		if !slices.Contains(sm.profiles.GetNames(), binding.Profile) {
+			return nil, fmt.Errorf("unknown profile '%s'", binding.Profile)
+		}
+		return func() { logError(sm.profiles.Request(binding.Profile)) }, nil
+
+	case "cough":
+		var cough *CoughSwitch
+		for _, c := range sm.coughs {
+			if c.GetName() == binding.Cough {
+				cough = c
+			}
+		}
+		if cough == nil {
+			return nil, fmt.Errorf("unknown cough switch '%s'", binding.Cough)
+		}
+		return func() {
+			if cough.IsHeld() {
+				cough.Release()
+			} else {
+				cough.Press()
+			}
+		}, nil
+
+	case "panic", "restore":
+		if sm.panic == nil {
+			return nil, fmt.Errorf("panic is not configured")
+		}
+		// The fades run in the background so the window keeps drawing
+		if binding.Action == "panic" {
+			return func() { go func() { logError(sm.panic.Engage()) }() }, nil
+		}
+		return func() { go func() { logError(sm.panic.Restore()) }() }, nil
+
+	case "recall":
+		if sm.scenes == nil {
+			return nil, fmt.Errorf("scenes are not available")
+		}
+		if binding.Scene == "" {
+			return nil, fmt.Errorf("recall requires a scene")
+		}
+		return func() {
+			if err := sm.scenes.Recall(binding.Scene); err != nil {
+				log.Printf("Failed to recall scene '%s': %v", binding.Scene, err)
+			}
+		}, nil
+
+	case "dim":
+		gangs := sm.gangs
+		if binding.Gang != "" {
+			gang := findGang(sm.gangs, binding.Gang)
+			if gang == nil {
+				return nil, fmt.Errorf("unknown gang '%s'", binding.Gang)
+			}
+			gangs = []*GangedFader{gang}
+		}
+		depth := float64(binding.DimDb)
+		if depth <= 0 {
+			depth = defaultDimDb
+		}
+		return (&dimmer{gangs: gangs, depthDb: depth}).toggle, nil
+
+	default:
+		return nil, fmt.Errorf("unknown action '%s'", binding.Action)
+	}
+}
+
+// normalizeKeys canonicalizes a key chord before parsing ("ctrl + m" == "Ctrl+M")
+func normalizeKeys(keys string) string {
+	parts := strings.Split(keys, "+")
+	for i, part := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(part))
+	}
+	return strings.Join(parts, "+")
+}
+
+// dimmer toggles a set of gangs between their current values and a reduced level
+type dimmer struct {
+	gangs   []*GangedFader
+	depthDb float64
+	base    map[*GangedFader]int64 // Values before dimming (nil when not dimmed)
+}
+
+// toggle dims the gangs, or restores them if already dimmed
+// Locked and recall-safe gangs are left untouched
+func (d *dimmer) toggle() {
+	if d.base != nil {
+		for gang, value := range d.base {
+			logError(gang.HandleUIChange(value))
+		}
+		d.base = nil
+		return
+	}
+
+	d.base = make(map[*GangedFader]int64)
+	for _, gang := range d.gangs {
+		if gang.IsLocked() || gang.IsSafe() {
+			continue
+		}
+		value := gang.GetCurrentValue()
+		d.base[gang] = value
+		logError(gang.HandleUIChange(gang.DbToValue(gang.ValueToDb(value) - d.depthDb)))
+	}
+}
+
+// moveFocus moves the keyboard focus by delta visible gangs, wrapping at either end
+// The first move (or a move from a gang hidden by the filter) focuses the first or last visible gang
+func (sm *SessionMixer) moveFocus(delta int) {
+	visible := sm.visibleGangs()
+	n := len(visible)
+	if n == 0 {
+		return
+	}
+	pos := slices.Index(visible, sm.focused)
+	if pos < 0 {
+		if delta > 0 {
+			sm.focused = visible[0]
+		} else {
+			sm.focused = visible[n-1]
+		}
+		return
+	}
+	sm.focused = visible[((pos+delta)%n+n)%n]
+}
+
+// focusedGang returns the keyboard-focused gang, or nil if none is focused or it is hidden by the filter
+func (sm *SessionMixer) focusedGang() *GangedFader {
+	if !slices.Contains(sm.visibleGangs(), sm.focused) {
+		return nil
+	}
+	return sm.gangs[sm.focused]
+}
+
+// nudgeFocused adjusts the focused gang by steps, unless it is locked or held by another source;
+// the nudge is staged when changes are staged
+func (sm *SessionMixer) nudgeFocused(steps int) {
+	if gang := sm.focusedGang(); gang != nil && !gang.IsLocked() {
+		if sm.staging.IsEnabled() && !gang.IsToggle() {
+			logError(sm.staging.Stage(gang, gang.NudgeValue(sm.staging.Value(gang), steps)))
+			return
+		}
+		if gang.Claim(WriteSourceUI) {
+			logError(gang.Nudge(steps))
+		}
+	}
+}
+
+// highlightFocused colors the current table cell if column i holds the focused gang
+func (sm *SessionMixer) highlightFocused(i int) {
+	if i == sm.focused {
+		imgui.TableSetBgColor(imgui.TableBgTargetCellBg, imgui.ColorConvertFloat4ToU32(focusColor))
+	}
+}
@@ -0,0 +1,186 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// ListenBus emulates PFL/AFL listening on hardware without a solo bus
+// Listening to a gang captures the sends of a designated monitoring mix, solos the mixer
+// inputs the gang carries into that mix, and restores the captured sends when cleared
+type ListenBus struct {
+	mix     string
+	afl     bool
+	levelDb float64
+
+	sends        map[int]*scarlettctl.Control // Monitoring mix input number -> send control
+	inputByNumID map[uint]int                 // Mixer input control -> input number
+	maxSendsRaw  int64
+
+	mu         sync.Mutex
+	gangInputs map[*GangedFader][]int // Gang -> mixer input numbers it carries
+	active     *GangedFader
+	captured   map[int]int64 // Send values before listening (nil when not listening)
+}
+
+// NewListenBus discovers the mixer send controls and maps each gang to the mixer inputs it carries
+// Gangs whose controls are not mixer inputs cannot be listened to
+func NewListenBus(card *scarlettctl.Card, config *ListenConfig, gangs []*GangedFader) (*ListenBus, error) {
+	mixerInputs, err := card.GetMixerInputs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover mixer inputs: %w", err)
+	}
+
+	lb := &ListenBus{
+		mix:          config.Mix,
+		levelDb:      float64(config.LevelDb),
+		sends:        make(map[int]*scarlettctl.Control),
+		inputByNumID: make(map[uint]int),
+	}
+	switch config.Mode {
+	case "", "pfl":
+	case "afl":
+		lb.afl = true
+	default:
+		return nil, fmt.Errorf("unknown listen mode '%s'", config.Mode)
+	}
+
+	for _, mi := range mixerInputs {
+		lb.inputByNumID[mi.Control.NumID] = mi.InputNum
+		if mi.MixName == config.Mix {
+			lb.sends[mi.InputNum] = mi.Control
+			lb.maxSendsRaw = mi.Control.Max
+		}
+	}
+	if len(lb.sends) == 0 {
+		return nil, fmt.Errorf("monitoring mix '%s' not found", config.Mix)
+	}
+
+	lb.SetGangs(gangs)
+	return lb, nil
+}
+
+// SetGangs maps the gangs to the mixer inputs they carry (e.g. after a profile switch)
+// A listen in progress follows the gang of the same name, or keeps its sends until cleared
+func (lb *ListenBus) SetGangs(gangs []*GangedFader) {
+	gangInputs := make(map[*GangedFader][]int)
+	for _, gang := range gangs {
+		for _, ch := range gang.GetChannels() {
+			input, ok := lb.inputByNumID[ch.GetControl().NumID]
+			if ok && !slices.Contains(gangInputs[gang], input) {
+				gangInputs[gang] = append(gangInputs[gang], input)
+			}
+		}
+	}
+
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	lb.gangInputs = gangInputs
+	if lb.active != nil {
+		lb.active = findGang(gangs, lb.active.GetName())
+	}
+}
+
+// GetMix returns the name of the monitoring mix
+func (lb *ListenBus) GetMix() string {
+	return lb.mix
+}
+
+// CanListen returns true if the gang carries any mixer input
+func (lb *ListenBus) CanListen(gang *GangedFader) bool {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return len(lb.gangInputs[gang]) > 0
+}
+
+// GetActive returns the gang being listened to, or nil
+func (lb *ListenBus) GetActive() *GangedFader {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	return lb.active
+}
+
+// Toggle listens to the gang, or clears the listen if the gang is already active
+func (lb *ListenBus) Toggle(gang *GangedFader) error {
+	if lb.GetActive() == gang {
+		return lb.Clear()
+	}
+	return lb.Listen(gang)
+}
+
+// Listen solos the gang's mixer inputs into the monitoring mix
+// In PFL mode the inputs are sent at the configured level; in AFL mode at the gang's level
+// Switching directly between gangs keeps the sends captured before the first listen
+func (lb *ListenBus) Listen(gang *GangedFader) error {
+	if IsObserver() {
+		return ErrObserver
+	}
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	inputs := lb.gangInputs[gang]
+	if len(inputs) == 0 {
+		return fmt.Errorf("gang '%s' carries no mixer inputs", gang.GetName())
+	}
+
+	if lb.captured == nil {
+		lb.captured = make(map[int]int64)
+		for input, control := range lb.sends {
+			value, err := control.GetValue()
+			if err != nil {
+				lb.captured = nil
+				return fmt.Errorf("failed to capture %s: %w", control.Name, err)
+			}
+			lb.captured[input] = value
+		}
+	}
+
+	level := lb.soloLevel(gang)
+	var lastErr error
+	for input, control := range lb.sends {
+		value := control.Min
+		if slices.Contains(inputs, input) {
+			value = level
+		}
+		if err := control.SetValue(value); err != nil {
+			log.Printf("Listen: failed to set %s: %v", control.Name, err)
+			lastErr = err
+		}
+	}
+	lb.active = gang
+	return lastErr
+}
+
+// Clear restores the monitoring mix sends captured before listening
+func (lb *ListenBus) Clear() error {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	if lb.captured == nil {
+		return nil
+	}
+	var lastErr error
+	for input, value := range lb.captured {
+		control := lb.sends[input]
+		if err := control.SetValue(value); err != nil {
+			log.Printf("Listen: failed to restore %s: %v", control.Name, err)
+			lastErr = err
+		}
+	}
+	lb.captured = nil
+	lb.active = nil
+	return lastErr
+}
+
+// soloLevel returns the raw send level for a soloed input
+func (lb *ListenBus) soloLevel(gang *GangedFader) int64 {
+	db := lb.levelDb
+	if lb.afl {
+		db = gang.ValueToDb(gang.GetCurrentValue())
+	}
+	return dbToMixerValue(db, lb.maxSendsRaw)
+}
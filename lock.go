@@ -0,0 +1,129 @@
+package sessionmixer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"syscall"
+
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// ALSA control element lock (struct snd_ctl_elem_id; the element is looked up by its numid)
+const ctlElemIDSize = 64
+
+var (
+	ctlIoctlElemLock   = ioc(iocWrite, 'U', 0x14, ctlElemIDSize)
+	ctlIoctlElemUnlock = ioc(iocWrite, 'U', 0x15, ctlElemIDSize)
+)
+
+// ControlLocks locks the controls sessionmixer manages (gangs and switches) against writes
+// from other applications while it runs. An ALSA element lock belongs to the open control
+// handle, so writes through the card sessionmixer opened still apply while everyone else's
+// are refused (EBUSY); the kernel drops the locks if sessionmixer exits without Release
+type ControlLocks struct {
+	lock     func(numID uint, lock bool) error
+	switches []*Switch
+
+	mu     sync.Mutex
+	locked map[uint]string // Locked controls by NumID, with their names
+}
+
+// NewControlLocks locks the controls of gangs and switches on card; controls the driver does
+// not let us lock (or another application holds) are logged and left unlocked
+func NewControlLocks(card *scarlettctl.Card, gangs []*GangedFader, switches []*Switch) (*ControlLocks, error) {
+	fds := card.GetPollFds()
+	if len(fds) == 0 {
+		return nil, fmt.Errorf("no control handle to lock the controls with")
+	}
+	fd := fds[0]
+	cl := newControlLocks(func(numID uint, lock bool) error {
+		id := make([]byte, ctlElemIDSize)
+		binary.NativeEndian.PutUint32(id[0:4], uint32(numID))
+		if lock {
+			return ioctlFd(fd, ctlIoctlElemLock, id)
+		}
+		return ioctlFd(fd, ctlIoctlElemUnlock, id)
+	}, switches)
+	cl.SetGangs(gangs)
+	return cl, nil
+}
+
+// newControlLocks creates control locks taken and released with lock
+func newControlLocks(lock func(numID uint, lock bool) error, switches []*Switch) *ControlLocks {
+	return &ControlLocks{lock: lock, switches: switches, locked: make(map[uint]string)}
+}
+
+// SetGangs locks the controls of gangs and the switches, and releases the controls of gangs
+// no longer loaded (e.g. after a profile switch); display channels are never written, so
+// they are not locked
+func (cl *ControlLocks) SetGangs(gangs []*GangedFader) {
+	wanted := make(map[uint]string)
+	for _, gang := range gangs {
+		if gang.IsReadOnly() {
+			continue
+		}
+		for _, ch := range gang.GetChannels() {
+			wanted[ch.GetControl().NumID] = ch.GetControl().Name
+		}
+	}
+	for _, sw := range cl.switches {
+		control := sw.GetChannel().GetControl()
+		wanted[control.NumID] = control.Name
+	}
+
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	for numID, name := range cl.locked {
+		if _, ok := wanted[numID]; !ok {
+			cl.unlock(numID, name)
+		}
+	}
+	for numID, name := range wanted {
+		if _, ok := cl.locked[numID]; ok {
+			continue
+		}
+		if err := cl.lock(numID, true); err != nil {
+			log.Printf("Control lock: %s stays unlocked: %v", name, lockError(err))
+			continue
+		}
+		cl.locked[numID] = name
+	}
+}
+
+// Release unlocks every locked control
+func (cl *ControlLocks) Release() {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	for numID, name := range cl.locked {
+		cl.unlock(numID, name)
+	}
+}
+
+// Count returns the number of locked controls
+func (cl *ControlLocks) Count() int {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	return len(cl.locked)
+}
+
+// unlock releases the lock on one control; must be called with mu held
+func (cl *ControlLocks) unlock(numID uint, name string) {
+	if err := cl.lock(numID, false); err != nil {
+		log.Printf("Control lock: failed to unlock %s: %v", name, err)
+	}
+	delete(cl.locked, numID)
+}
+
+// lockError explains the errors the kernel returns for element locks
+func lockError(err error) error {
+	switch {
+	case errors.Is(err, syscall.EBUSY):
+		return fmt.Errorf("locked by another application")
+	case errors.Is(err, syscall.ENOTTY), errors.Is(err, syscall.EINVAL), errors.Is(err, syscall.EPERM):
+		return fmt.Errorf("not supported by the driver (%w)", err)
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package sessionmixer
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestControlLocksFollowGangs(t *testing.T) {
+	held := make(map[uint]bool)
+	cl := newControlLocks(func(numID uint, lock bool) error {
+		if numID == 9 {
+			return syscall.EBUSY // Held by another application
+		}
+		held[numID] = lock
+		return nil
+	}, nil)
+
+	vocal, _ := newFakeGang(t, "Vocal", 0, 160, 80, 1, 2)
+	guitar, _ := newFakeGang(t, "Guitar", 0, 160, 80, 3)
+	busy, _ := newFakeGang(t, "Busy", 0, 160, 80, 9)
+	meter, _ := newFakeGang(t, "Meter", 0, 160, 80, 4)
+	meter.SetDisplay(DisplayMeter)
+
+	cl.SetGangs([]*GangedFader{vocal, guitar, busy, meter})
+	if cl.Count() != 3 {
+		t.Errorf("locked %d controls, want 3", cl.Count())
+	}
+	if held[4] {
+		t.Error("a display channel was locked")
+	}
+
+	// A profile switch releases the controls of gangs no longer loaded
+	cl.SetGangs([]*GangedFader{guitar})
+	if held[1] || held[2] || !held[3] {
+		t.Errorf("locks after the switch = %v, want only control 3", held)
+	}
+
+	cl.Release()
+	if held[3] || cl.Count() != 0 {
+		t.Errorf("locks left after Release: %v", held)
+	}
+}
@@ -0,0 +1,142 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// loudnessShortTerm is the window for the short-term loudness estimate
+	loudnessShortTerm = 3 * time.Second
+
+	// loudnessBlock and loudnessStep define the overlapping gating blocks (400ms, 75% overlap)
+	loudnessBlock = 400 * time.Millisecond
+	loudnessStep  = 100 * time.Millisecond
+
+	// Gating thresholds per ITU-R BS.1770
+	loudnessAbsoluteGate = -70.0
+	loudnessRelativeGate = -10.0
+)
+
+// LoudnessMeter estimates short-term and integrated loudness (LUFS-style) from level samples
+// The device meters report peak rather than K-weighted power, so values are an approximation
+// useful for consistency rather than compliance measurement
+type LoudnessMeter struct {
+	interval time.Duration // Sample interval
+
+	mu      sync.Mutex
+	recent  []float64 // Ring buffer of energies covering the short-term window
+	next    int
+	count   int
+	samples int       // Total samples, for block stepping
+	blocks  []float64 // Mean energy of each gating block since the last reset
+}
+
+// NewLoudnessMeter creates a meter fed at the given sample interval
+func NewLoudnessMeter(interval time.Duration) *LoudnessMeter {
+	return &LoudnessMeter{
+		interval: interval,
+		recent:   make([]float64, int(loudnessShortTerm/interval)),
+	}
+}
+
+// Add records one sample of summed channel energy (sum of squared linear amplitudes)
+func (lm *LoudnessMeter) Add(energy float64) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lm.recent[lm.next] = energy
+	lm.next = (lm.next + 1) % len(lm.recent)
+	if lm.count < len(lm.recent) {
+		lm.count++
+	}
+	lm.samples++
+
+	blockSamples := int(loudnessBlock / lm.interval)
+	stepSamples := max(1, int(loudnessStep/lm.interval))
+	if lm.count >= blockSamples && lm.samples%stepSamples == 0 {
+		lm.blocks = append(lm.blocks, lm.meanLocked(blockSamples))
+	}
+}
+
+// ShortTerm returns the short-term loudness estimate over the last 3 seconds
+func (lm *LoudnessMeter) ShortTerm() float64 {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	return energyToLoudness(lm.meanLocked(lm.count))
+}
+
+// Integrated returns the gated integrated loudness estimate since the last reset
+func (lm *LoudnessMeter) Integrated() float64 {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	// Absolute gate
+	var gated []float64
+	for _, e := range lm.blocks {
+		if energyToLoudness(e) > loudnessAbsoluteGate {
+			gated = append(gated, e)
+		}
+	}
+	if len(gated) == 0 {
+		return math.Inf(-1)
+	}
+
+	// Relative gate
+	relative := energyToLoudness(mean(gated)) + loudnessRelativeGate
+	var final []float64
+	for _, e := range gated {
+		if energyToLoudness(e) > relative {
+			final = append(final, e)
+		}
+	}
+	return energyToLoudness(mean(final))
+}
+
+// Reset discards the integrated measurement
+func (lm *LoudnessMeter) Reset() {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	lm.blocks = nil
+}
+
+// meanLocked returns the mean energy of the last n samples; caller holds mu
+func (lm *LoudnessMeter) meanLocked(n int) float64 {
+	if n <= 0 {
+		return 0
+	}
+	sum := 0.0
+	for i := 1; i <= n; i++ {
+		sum += lm.recent[(lm.next-i+len(lm.recent))%len(lm.recent)]
+	}
+	return sum / float64(n)
+}
+
+// energyToLoudness converts mean energy to loudness units per BS.1770
+func energyToLoudness(energy float64) float64 {
+	if energy <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10.0*math.Log10(energy)
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// formatLoudness formats a loudness value for display
+func formatLoudness(lufs float64) string {
+	if math.IsInf(lufs, -1) {
+		return "-∞"
+	}
+	return fmt.Sprintf("%.1f", lufs)
+}
@@ -2,6 +2,8 @@ package sessionmixer
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/michaelquigley/scarlettctl"
 )
@@ -24,18 +26,50 @@ func NewControlMapper(card *scarlettctl.Card, config *Config) *ControlMapper {
 func (cm *ControlMapper) LoadGangs() ([]*GangedFader, error) {
 	var gangs []*GangedFader
 
-	for i, gangControl := range cm.config.GangControls {
+	if err := RegisterUnits(cm.config.Units); err != nil {
+		return nil, err
+	}
+
+	generated, err := ExpandGangTemplates(cm.config.GangTemplates)
+	if err != nil {
+		return nil, err
+	}
+	gangControls := append(slices.Clone(cm.config.GangControls), generated...)
+
+	for i, gangControl := range gangControls {
 		// Find all hardware controls for this gang
 		var gangChannels []*MixerChannel
+		var toggle bool
+
+		switch gangControl.Display {
+		case "", DisplayMeter, DisplayReadout:
+		default:
+			return nil, fmt.Errorf("gang %d (%s): unknown display '%s'", i, gangControl.Name, gangControl.Display)
+		}
+		switch gangControl.External {
+		case "", ExternalFollow, ExternalIgnore, ExternalPrompt:
+		default:
+			return nil, fmt.Errorf("gang %d (%s): unknown external '%s' (expected '%s', '%s' or '%s')", i, gangControl.Name, gangControl.External, ExternalFollow, ExternalIgnore, ExternalPrompt)
+		}
 
 		for j, ctrlName := range gangControl.Controls {
-			control, err := cm.card.FindControl(ctrlName)
+			control, err := findControl(cm.card, ctrlName)
 			if err != nil {
-				return nil, fmt.Errorf("gang %d (%s), control %d (%s): not found on hardware: %w", i, gangControl.Name, j, ctrlName, err)
+				return nil, fmt.Errorf("gang %d (%s), control %d: %w", i, gangControl.Name, j, err)
 			}
 
-			// Validate control type
-			if control.Type != scarlettctl.ControlTypeInteger && control.Type != scarlettctl.ControlTypeInteger64 {
+			// Validate control type; a gang is either all faders or all boolean switches
+			switch control.Type {
+			case scarlettctl.ControlTypeInteger, scarlettctl.ControlTypeInteger64:
+				if j > 0 && toggle {
+					return nil, fmt.Errorf("gang %d (%s), control %d (%s): cannot mix boolean and integer controls", i, gangControl.Name, j, ctrlName)
+				}
+			case scarlettctl.ControlTypeBoolean:
+				if j > 0 && !toggle {
+					return nil, fmt.Errorf("gang %d (%s), control %d (%s): cannot mix boolean and integer controls", i, gangControl.Name, j, ctrlName)
+				}
+				toggle = true
+			default:
 				return nil, fmt.Errorf("gang %d (%s), control %d (%s): type %d not supported", i, gangControl.Name, j, ctrlName, control.Type)
 			}
 
@@ -53,13 +87,44 @@ func (cm *ControlMapper) LoadGangs() ([]*GangedFader, error) {
 
 		// Find level controls for this gang (optional)
 		var levelControls []*scarlettctl.Control
+		var levelOffsets []float32
+		calibrated := false
 		for j, levelName := range gangControl.Levels {
-			levelCtl, err := cm.card.FindControl(levelName)
+			levelCtl, err := findControl(cm.card, levelName)
 			if err != nil {
-				return nil, fmt.Errorf("gang %d (%s), level %d (%s): not found on hardware: %w",
-					i, gangControl.Name, j, levelName, err)
+				return nil, fmt.Errorf("gang %d (%s), level %d: %w", i, gangControl.Name, j, err)
 			}
 			levelControls = append(levelControls, levelCtl)
+
+			offset, ok := cm.config.LevelOffsets[levelName]
+			calibrated = calibrated || ok
+			levelOffsets = append(levelOffsets, offset)
+		}
+
+		// Mix output (post) level controls the meter can switch to (optional)
+		var postControls []*scarlettctl.Control
+		for j, levelName := range gangControl.PostLevels {
+			levelCtl, err := findControl(cm.card, levelName)
+			if err != nil {
+				return nil, fmt.Errorf("gang %d (%s), post level %d: %w", i, gangControl.Name, j, err)
+			}
+			postControls = append(postControls, levelCtl)
+
+			offset, ok := cm.config.LevelOffsets[levelName]
+			calibrated = calibrated || ok
+			levelOffsets = append(levelOffsets, offset)
+		}
+		if len(postControls) > 0 && len(levelControls) == 0 {
+			return nil, fmt.Errorf("gang %d (%s): post_levels need levels to switch from", i, gangControl.Name)
+		}
+		switch gangControl.MeterSource {
+		case "", MeterPre:
+		case MeterPost:
+			if len(postControls) == 0 {
+				return nil, fmt.Errorf("gang %d (%s): meter_source '%s' needs post_levels", i, gangControl.Name, MeterPost)
+			}
+		default:
+			return nil, fmt.Errorf("gang %d (%s): unknown meter_source '%s' (expected '%s' or '%s')", i, gangControl.Name, gangControl.MeterSource, MeterPre, MeterPost)
 		}
 
 		// Create ganged fader (mirror mode only for now)
@@ -67,9 +132,151 @@ func (cm *ControlMapper) LoadGangs() ([]*GangedFader, error) {
 		if err != nil {
 			return nil, fmt.Errorf("gang %d (%s): failed to create ganged fader: %w", i, gangControl.Name, err)
 		}
+		gang.SetPostLevels(postControls)
+		gang.SetMeterPost(gangControl.MeterSource == MeterPost)
+		if calibrated {
+			gang.SetLevelOffsets(levelOffsets)
+		}
+		meterScale, err := NewMeterScale(gangControl.Meter)
+		if err != nil {
+			return nil, fmt.Errorf("gang %d (%s), meter: %w", i, gangControl.Name, err)
+		}
+		gang.SetMeterScale(meterScale)
+		gang.SetLevelEvents(cm.config.LevelEvents)
+		gang.SetDescription(gangControl.Description)
+		gang.SetTags(gangControl.Tags)
+		gang.SetSafe(gangControl.Safe)
+		gang.SetDisplay(gangControl.Display)
+		gang.SetJumpGuard(float64(gangControl.MaxJumpDb), gangControl.JumpRamp)
+		gang.SetExternal(gangControl.External)
+		if gangControl.DefaultDb != nil {
+			if gangControl.Unit == "raw" {
+				return nil, fmt.Errorf("gang %d (%s): default_db does not apply to raw gangs", i, gangControl.Name)
+			}
+			gang.SetDefaultDb(float64(*gangControl.DefaultDb))
+		}
 
 		gangs = append(gangs, gang)
 	}
+	applyProtection(cm.config.Protection, gangs)
 
 	return gangs, nil
 }
+
+// LoadSwitches creates Switch instances from the config
+// Switches restricted to a different device variant are skipped
+func (cm *ControlMapper) LoadSwitches() ([]*Switch, error) {
+	var switches []*Switch
+
+	for i, switchControl := range cm.config.Switches {
+		if switchControl.Device != "" && !strings.Contains(strings.ToLower(cm.card.Name), strings.ToLower(switchControl.Device)) {
+			continue
+		}
+
+		control, err := findControl(cm.card, switchControl.Control)
+		if err != nil {
+			return nil, fmt.Errorf("switch %d (%s): %w", i, switchControl.Name, err)
+		}
+
+		// Validate control type
+		if control.Type != scarlettctl.ControlTypeBoolean && control.Type != scarlettctl.ControlTypeEnumerated {
+			return nil, fmt.Errorf("switch %d (%s), control (%s): type %d not supported", i, switchControl.Name, switchControl.Control, control.Type)
+		}
+
+		var momentary bool
+		switch switchControl.Mode {
+		case "", "latching":
+		case "momentary":
+			if control.Type != scarlettctl.ControlTypeBoolean {
+				return nil, fmt.Errorf("switch %d (%s): momentary mode requires a boolean control", i, switchControl.Name)
+			}
+			momentary = true
+		default:
+			return nil, fmt.Errorf("switch %d (%s): unknown mode '%s'", i, switchControl.Name, switchControl.Mode)
+		}
+
+		ch, err := NewMixerChannel(control, switchControl.Name, "raw")
+		if err != nil {
+			return nil, fmt.Errorf("switch %d (%s), control (%s): failed to create channel: %w", i, switchControl.Name, switchControl.Control, err)
+		}
+
+		sw := NewSwitch(switchControl.Name, ch)
+		sw.SetMomentary(momentary)
+		switches = append(switches, sw)
+	}
+
+	return switches, nil
+}
+
+// LoadDuckers creates Ducker instances from the config, resolving gang names against gangs
+func (cm *ControlMapper) LoadDuckers(gangs []*GangedFader) ([]*Ducker, error) {
+	var duckers []*Ducker
+
+	for i, duckerControl := range cm.config.Duckers {
+		trigger, err := findControl(cm.card, duckerControl.Trigger)
+		if err != nil {
+			return nil, fmt.Errorf("ducker %d (%s), trigger: %w", i, duckerControl.Name, err)
+		}
+
+		var duckGangs []*GangedFader
+		for _, name := range duckerControl.Gangs {
+			gang := findGang(gangs, name)
+			if gang == nil {
+				return nil, fmt.Errorf("ducker %d (%s): unknown gang '%s'", i, duckerControl.Name, name)
+			}
+			duckGangs = append(duckGangs, gang)
+		}
+
+		duckers = append(duckers, NewDucker(duckerControl, trigger, duckGangs))
+	}
+
+	return duckers, nil
+}
+
+// LoadGates creates Gate instances from the config, resolving gang names against gangs
+func (cm *ControlMapper) LoadGates(gangs []*GangedFader) ([]*Gate, error) {
+	var gates []*Gate
+
+	for i, gateControl := range cm.config.Gates {
+		trigger, err := findControl(cm.card, gateControl.Trigger)
+		if err != nil {
+			return nil, fmt.Errorf("gate %d (%s), trigger: %w", i, gateControl.Name, err)
+		}
+
+		var gateGangs []*GangedFader
+		for _, name := range gateControl.Gangs {
+			gang := findGang(gangs, name)
+			if gang == nil {
+				return nil, fmt.Errorf("gate %d (%s): unknown gang '%s'", i, gateControl.Name, name)
+			}
+			gateGangs = append(gateGangs, gang)
+		}
+
+		gates = append(gates, NewGate(gateControl, trigger, gateGangs))
+	}
+
+	return gates, nil
+}
+
+// LoadCoughs creates the configured cough switches for the gangs
+// On error, switches already opened are closed
+func (cm *ControlMapper) LoadCoughs(gangs []*GangedFader) ([]*CoughSwitch, error) {
+	var coughs []*CoughSwitch
+
+	for i, coughControl := range cm.config.Coughs {
+		for _, other := range coughs {
+			if other.GetName() == coughControl.Name {
+				closeCoughs(coughs)
+				return nil, fmt.Errorf("cough %d: duplicate name '%s'", i, coughControl.Name)
+			}
+		}
+		cough, err := NewCoughSwitch(coughControl, gangs)
+		if err != nil {
+			closeCoughs(coughs)
+			return nil, fmt.Errorf("cough %d (%s): %w", i, coughControl.Name, err)
+		}
+		coughs = append(coughs, cough)
+	}
+
+	return coughs, nil
+}
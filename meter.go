@@ -0,0 +1,269 @@
+package sessionmixer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+// Meter geometry (at scale 1) and ballistics
+const (
+	meterWidth       = 8                       // Width of a gang meter beside its fader
+	meterStereoWidth = 5                       // Width of each meter of a stereo pair
+	meterSegments    = 24                      // Segments along the bar
+	meterGap         = 1                       // Gap between segments, before the clip LED and between a stereo pair
+	meterPeakHold    = 1500 * time.Millisecond // How long the peak tick holds before following the level
+)
+
+var (
+	meterBackground = imgui.Vec4{X: 0.1, Y: 0.1, Z: 0.1, W: 1.0}
+	meterBorder     = imgui.Vec4{X: 0.4, Y: 0.4, Z: 0.4, W: 1.0}
+	meterClipOff    = imgui.Vec4{X: 0.25, Y: 0.05, Z: 0.05, W: 1.0}
+	meterClipOn     = imgui.Vec4{X: 1.0, Y: 0.1, Z: 0.1, W: 1.0}
+)
+
+// Meter sources of gangs with both input and mix output meters
+const (
+	MeterPre  = "pre"  // Input (pre) levels
+	MeterPost = "post" // Mix output (post) levels
+)
+
+// meterUnlit is the alpha of segments above the level
+const meterUnlit = 0.15
+
+// MeterScale maps levels onto a meter: the dB range below full scale it spans and where the
+// color gradient turns from green to yellow and from yellow to red
+type MeterScale struct {
+	RangeDb  float32 // Levels this far below 0 dBFS sit at the bottom of the meter
+	YellowDb float32 // Level (dBFS) where green turns yellow
+	RedDb    float32 // Level (dBFS) where yellow turns red
+}
+
+// DefaultMeterScale spans 96 dB (16-bit dynamic range) for sensitivity at low levels, turning
+// yellow halfway up and red at 80%
+var DefaultMeterScale = MeterScale{RangeDb: 96, YellowDb: -48, RedDb: -19.2}
+
+// NewMeterScale builds a gang's meter scale from its config; unset transitions are placed at
+// the default proportions of the range
+func NewMeterScale(config *MeterConfig) (MeterScale, error) {
+	if config == nil {
+		return DefaultMeterScale, nil
+	}
+	scale := MeterScale{RangeDb: config.RangeDb, YellowDb: config.YellowDb, RedDb: config.RedDb}
+	if scale.RangeDb == 0 {
+		scale.RangeDb = DefaultMeterScale.RangeDb
+	}
+	if scale.RangeDb < 0 {
+		return MeterScale{}, fmt.Errorf("range_db must be positive (got %.1f)", scale.RangeDb)
+	}
+	if scale.YellowDb == 0 {
+		scale.YellowDb = -scale.RangeDb * 0.5
+	}
+	if scale.RedDb == 0 {
+		scale.RedDb = -scale.RangeDb * 0.2
+	}
+	if scale.YellowDb <= -scale.RangeDb || scale.RedDb > 0 || scale.YellowDb >= scale.RedDb {
+		return MeterScale{}, errors.New("expected -range_db < yellow_db < red_db <= 0")
+	}
+	return scale, nil
+}
+
+// Position maps a level (dBFS) to 0.0-1.0 along the meter
+func (ms MeterScale) Position(db float64) float32 {
+	if math.IsNaN(db) || math.IsInf(db, -1) {
+		return 0
+	}
+	return float32(max(0, min(1, 1+db/float64(ms.RangeDb))))
+}
+
+// Db maps a position along the meter back to a level (dBFS); the bottom is -Inf
+func (ms MeterScale) Db(position float32) float64 {
+	if position <= 0 {
+		return math.Inf(-1)
+	}
+	return float64((position - 1) * ms.RangeDb)
+}
+
+// Color maps a position along the meter to the meter color gradient
+func (ms MeterScale) Color(position float32) imgui.Vec4 {
+	yellow, red := ms.Position(float64(ms.YellowDb)), ms.Position(float64(ms.RedDb))
+
+	// Compute color using HSV
+	// 0: dark green (H=120, S=1, V=0.3)
+	// yellow_db: bright green (H=120, S=1, V=0.6)
+	// red_db: yellow (H=60, S=1, V=0.8)
+	// 0 dBFS: red (H=0, S=1, V=1.0)
+	var h, s, v float32
+	s = 1.0
+
+	if position <= yellow {
+		// Dark green to bright green (increase V)
+		h = 120.0 / 360.0
+		v = 0.3 + (position/yellow)*0.3 // 0.3 to 0.6
+	} else if position <= red {
+		// Green to yellow (H from 120 to 60)
+		t := (position - yellow) / (red - yellow)
+		h = (120.0 - t*60.0) / 360.0 // 120 to 60
+		v = 0.6 + t*0.2              // 0.6 to 0.8
+	} else {
+		// Yellow to red (H from 60 to 0)
+		t := (position - red) / (1 - red)
+		h = (60.0 - t*60.0) / 360.0 // 60 to 0
+		v = 0.8 + t*0.2             // 0.8 to 1.0
+	}
+
+	var r, g, b float32
+	imgui.ColorConvertHSVtoRGB(h, s, v, &r, &g, &b)
+
+	return imgui.Vec4{X: r, Y: g, Z: b, W: 1.0}
+}
+
+// MeterState holds what a meter remembers between frames: the held peak and the clip LED,
+// which stays lit until the meter is clicked
+type MeterState struct {
+	peak    float32
+	peakAt  time.Time
+	clipped bool
+}
+
+// drawMeter renders a segmented level meter at the cursor with the window's draw list: segments
+// follow the color gradient of scale, and with a state a peak tick and a clip LED (lit when level
+// reaches clip) are added. Levels are normalized (0.0-1.0); the meter runs bottom to top when
+// size is taller than wide, left to right otherwise. Clicking the meter resets the peak and clip
+func drawMeter(id string, state *MeterState, scale MeterScale, level, clip float32, size imgui.Vec2) {
+	level = max(0, min(1, level))
+	topLeft := imgui.CursorScreenPos()
+	vertical := size.Y >= size.X
+	length, thickness := size.X, size.Y
+	if vertical {
+		length, thickness = size.Y, size.X
+	}
+
+	// The clip LED takes a square at the top (right) end
+	bar := length
+	if state != nil {
+		bar -= thickness + scaled(meterGap)
+	}
+	if bar <= 0 {
+		imgui.Dummy(size)
+		return
+	}
+
+	// span returns the corners of the bar between normalized positions a and b
+	span := func(a, b float32) (imgui.Vec2, imgui.Vec2) {
+		if vertical {
+			bottom := topLeft.Y + size.Y
+			return imgui.Vec2{X: topLeft.X, Y: bottom - b*bar}, imgui.Vec2{X: topLeft.X + size.X, Y: bottom - a*bar}
+		}
+		return imgui.Vec2{X: topLeft.X + a*bar, Y: topLeft.Y}, imgui.Vec2{X: topLeft.X + b*bar, Y: topLeft.Y + size.Y}
+	}
+
+	drawList := imgui.WindowDrawList()
+	from, to := span(0, 1)
+	drawList.AddRectFilled(from, to, imgui.ColorConvertFloat4ToU32(meterBackground))
+	gap := scaled(meterGap) / bar
+	for s := range meterSegments {
+		a, b := float32(s)/meterSegments, float32(s+1)/meterSegments
+		color := scale.Color(b)
+		if level <= a {
+			color.W = meterUnlit
+		}
+		from, to := span(a, b-gap)
+		drawList.AddRectFilled(from, to, imgui.ColorConvertFloat4ToU32(color))
+	}
+	from, to = span(0, 1)
+	drawList.AddRect(from, to, imgui.ColorConvertFloat4ToU32(meterBorder))
+
+	if state != nil {
+		now := time.Now()
+		if level >= state.peak || now.Sub(state.peakAt) > meterPeakHold {
+			state.peak, state.peakAt = level, now
+		}
+		if level > 0 && level >= clip {
+			state.clipped = true
+		}
+		if state.peak > 0 {
+			from, to := span(state.peak, state.peak)
+			drawList.AddLineV(from, to, imgui.ColorConvertFloat4ToU32(scale.Color(state.peak)), scaled(2))
+		}
+
+		led := imgui.Vec2{X: topLeft.X + size.X - thickness, Y: topLeft.Y}
+		if vertical {
+			led = topLeft
+		}
+		color := meterClipOff
+		if state.clipped {
+			color = meterClipOn
+		}
+		drawList.AddRectFilled(led, imgui.Vec2{X: led.X + thickness, Y: led.Y + thickness}, imgui.ColorConvertFloat4ToU32(color))
+	}
+
+	if imgui.InvisibleButton(id, size) && state != nil {
+		*state = MeterState{}
+	}
+}
+
+// clipLevel returns the configured clip threshold as a position on scale
+func (sm *SessionMixer) clipLevel(scale MeterScale) float32 {
+	var thresholdDb float32
+	if sm.config != nil {
+		thresholdDb = sm.config.ClipThresholdDb
+	}
+	return scale.Position(float64(thresholdDb))
+}
+
+// drawGangMeter renders gang i's level meter beside its fader, a left/right pair for stereo
+// gangs so imbalance shows, with a tooltip giving the held peaks
+func (sm *SessionMixer) drawGangMeter(i int, gang *GangedFader, height float32) {
+	scale := gang.GetMeterScale()
+	clip := sm.clipLevel(scale)
+	width := scaled(meterWidth)
+	var levels []float32
+	if gang.IsStereoMetered() {
+		levels, _ = gang.GetNormalizedLevels()
+		width = scaled(meterStereoWidth)
+	} else {
+		level, _ := gang.GetNormalizedLevel()
+		levels = []float32{level}
+	}
+
+	states := &sm.meters[i]
+	hovered := false
+	for c, level := range levels {
+		if c > 0 {
+			imgui.SameLineV(0, scaled(meterGap))
+		}
+		drawMeter(fmt.Sprintf("##meter_gang_%d_%d", i, c), &states[c], scale, level, clip, imgui.Vec2{X: width, Y: height})
+		hovered = hovered || imgui.IsItemHoveredV(imgui.HoveredFlagsForTooltip)
+	}
+	if !hovered || !imgui.BeginTooltip() {
+		return
+	}
+	if gang.HasPostLevels() {
+		if gang.IsMeterPost() {
+			imgui.TextDisabled(tr("Post (mix output)"))
+		} else {
+			imgui.TextDisabled(tr("Pre (input)"))
+		}
+	}
+	clipped := false
+	for c := range levels {
+		label := ""
+		if len(levels) == 2 {
+			label = []string{"L  ", "R  "}[c]
+		}
+		if states[c].peak > 0 {
+			imgui.TextUnformatted(label + trf("Peak %.1f dBFS", scale.Db(states[c].peak)))
+		} else {
+			imgui.TextUnformatted(label + tr("No signal"))
+		}
+		clipped = clipped || states[c].clipped
+	}
+	if clipped {
+		imgui.TextUnformatted(tr("Clipped (click to reset)"))
+	}
+	imgui.EndTooltip()
+}
@@ -0,0 +1,60 @@
+package sessionmixer
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewMeterScaleDefaults(t *testing.T) {
+	scale, err := NewMeterScale(nil)
+	if err != nil || scale != DefaultMeterScale {
+		t.Fatalf("NewMeterScale(nil) = %+v, %v; want the default scale", scale, err)
+	}
+
+	// Unset transitions keep the default proportions of the range
+	scale, err = NewMeterScale(&MeterConfig{RangeDb: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scale.YellowDb != -30 || scale.RedDb != -12 {
+		t.Errorf("transitions = %.1f, %.1f; want -30, -12", scale.YellowDb, scale.RedDb)
+	}
+}
+
+func TestNewMeterScaleRejectsBadTransitions(t *testing.T) {
+	for _, config := range []MeterConfig{
+		{RangeDb: -10},
+		{RangeDb: 40, YellowDb: -40},
+		{YellowDb: -10, RedDb: -20},
+		{RedDb: 3},
+	} {
+		if _, err := NewMeterScale(&config); err == nil {
+			t.Errorf("NewMeterScale(%+v): expected an error", config)
+		}
+	}
+}
+
+func TestMeterScalePosition(t *testing.T) {
+	scale := MeterScale{RangeDb: 60, YellowDb: -30, RedDb: -12}
+	for _, tc := range []struct {
+		db   float64
+		want float32
+	}{
+		{0, 1},
+		{6, 1},
+		{-30, 0.5},
+		{-60, 0},
+		{-90, 0},
+		{math.Inf(-1), 0},
+	} {
+		if got := scale.Position(tc.db); got != tc.want {
+			t.Errorf("Position(%.0f) = %.2f, want %.2f", tc.db, got, tc.want)
+		}
+	}
+	if got := scale.Db(0.5); got != -30 {
+		t.Errorf("Db(0.5) = %.1f, want -30", got)
+	}
+	if got := scale.Db(0); !math.IsInf(got, -1) {
+		t.Errorf("Db(0) = %.1f, want -Inf", got)
+	}
+}
@@ -0,0 +1,172 @@
+package sessionmixer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MIDI status types (upper nibble of the status byte)
+const (
+	midiNoteOff       = 0x80
+	midiNoteOn        = 0x90
+	midiControlChange = 0xB0
+	midiPitchBend     = 0xE0
+)
+
+// MIDIMessage is a channel voice message
+type MIDIMessage struct {
+	Status byte
+	Data1  byte
+	Data2  byte
+}
+
+// Type returns the message type (status with the channel masked out)
+func (m MIDIMessage) Type() byte {
+	return m.Status & 0xF0
+}
+
+// Channel returns the message channel (0-15)
+func (m MIDIMessage) Channel() byte {
+	return m.Status & 0x0F
+}
+
+// MIDIPort is an ALSA rawmidi device opened for reading and writing, or a pair of virtual
+// sequencer ports (see MIDIVirtualDevice)
+type MIDIPort struct {
+	path    string
+	file    *os.File
+	mu      sync.Mutex // Serializes writes
+	virtual bool       // Sequencer ports rather than a rawmidi device
+	seqOut  byte       // Sequencer port messages are sent from
+}
+
+// MIDIDevicePath resolves an ALSA rawmidi device name ("hw:2,0") to its device node;
+// anything else is returned unchanged as a path
+func MIDIDevicePath(device string) (string, error) {
+	if !strings.HasPrefix(device, "hw:") {
+		return device, nil
+	}
+	card, dev, _ := strings.Cut(strings.TrimPrefix(device, "hw:"), ",")
+	if dev == "" {
+		dev = "0"
+	}
+	c, err := strconv.Atoi(card)
+	if err != nil {
+		return "", fmt.Errorf("invalid MIDI device '%s'", device)
+	}
+	d, err := strconv.Atoi(dev)
+	if err != nil {
+		return "", fmt.Errorf("invalid MIDI device '%s'", device)
+	}
+	return fmt.Sprintf("/dev/snd/midiC%dD%d", c, d), nil
+}
+
+// OpenMIDIPort opens a rawmidi device by name or path, or creates virtual sequencer ports for
+// "virtual" or "virtual:Name"
+func OpenMIDIPort(device string) (*MIDIPort, error) {
+	if device == MIDIVirtualDevice || strings.HasPrefix(device, MIDIVirtualDevice+":") {
+		return openVirtualMIDIPort(device)
+	}
+	path, err := MIDIDevicePath(device)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open MIDI device '%s': %w", path, err)
+	}
+	return &MIDIPort{path: path, file: file}, nil
+}
+
+// GetPath returns the device node path, or a description of the virtual ports
+func (p *MIDIPort) GetPath() string {
+	return p.path
+}
+
+// Send writes a channel voice message
+func (p *MIDIPort) Send(msg MIDIMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.virtual {
+		ev, ok := encodeSeqEvent(msg, p.seqOut)
+		if !ok {
+			return nil
+		}
+		_, err := p.file.Write(ev)
+		return err
+	}
+	data := []byte{msg.Status, msg.Data1, msg.Data2}
+	if t := msg.Type(); t == 0xC0 || t == 0xD0 {
+		data = data[:2] // Program change and channel pressure have one data byte
+	}
+	_, err := p.file.Write(data)
+	return err
+}
+
+// Read parses incoming channel voice messages and passes them to handler until the port
+// is closed or fails; system exclusive and real-time messages are skipped
+func (p *MIDIPort) Read(handler func(MIDIMessage)) error {
+	if p.virtual {
+		return p.readSeq(handler)
+	}
+	r := bufio.NewReader(p.file)
+	var status byte
+	var data []byte
+	inSysex := false
+
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case b >= 0xF8:
+			continue // Real-time messages may appear anywhere
+		case b == 0xF0:
+			inSysex = true
+			continue
+		case b == 0xF7:
+			inSysex = false
+			continue
+		case b >= 0xF1:
+			status = 0 // System common messages cancel running status
+			continue
+		case b >= 0x80:
+			inSysex = false
+			status = b
+			data = data[:0]
+			continue
+		}
+
+		if inSysex || status == 0 {
+			continue
+		}
+		data = append(data, b)
+
+		need := 2
+		if t := status & 0xF0; t == 0xC0 || t == 0xD0 {
+			need = 1
+		}
+		if len(data) < need {
+			continue
+		}
+
+		msg := MIDIMessage{Status: status, Data1: data[0]}
+		if need == 2 {
+			msg.Data2 = data[1]
+		}
+		data = data[:0] // Running status: keep status for the next message
+		handler(msg)
+	}
+}
+
+// Close closes the device, unblocking any Read
+func (p *MIDIPort) Close() error {
+	return p.file.Close()
+}
@@ -0,0 +1,178 @@
+package sessionmixer
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigVersion is the configuration schema version written by this build
+const ConfigVersion = 2
+
+// configMigration upgrades a raw configuration document by one version, in place; changed
+// reports whether anything besides the version moved (only then is the file rewritten, since
+// rewriting drops comments)
+type configMigration func(doc map[string]any) (changed bool, err error)
+
+// configMigrations[v] upgrades a version v document to version v+1; append a migration and
+// bump ConfigVersion whenever a change would break existing configs (renamed or restructured
+// keys). Migrations work on the raw document, before binding, so they can read keys that no
+// longer exist in Config
+var configMigrations = []configMigration{
+	// 0 -> 1: unversioned configs; the version field is introduced, nothing else changes
+	func(doc map[string]any) (bool, error) { return false, nil },
+
+	// 1 -> 2: maps keyed by input number (which YAML cannot bind with unquoted numbers) become
+	// lists of entries
+	func(doc map[string]any) (bool, error) {
+		sends, err := inputMapToList(doc, "phantom_safety", "sends", "gangs")
+		if err != nil {
+			return false, err
+		}
+		levels, err := inputMapToList(doc, "gain_staging", "levels", "level")
+		return sends || levels, err
+	},
+}
+
+// inputMapToList rewrites doc[section][key], a map of physical input number to value, as a list
+// of {input, <valueKey>} entries ordered by input; false if there is no such map
+func inputMapToList(doc map[string]any, section, key, valueKey string) (bool, error) {
+	sectionDoc, ok := doc[section].(map[string]any)
+	if !ok {
+		return false, nil
+	}
+	entries := make(map[int]any)
+	switch m := sectionDoc[key].(type) {
+	case map[string]any:
+		for k, v := range m {
+			input, err := strconv.Atoi(k)
+			if err != nil {
+				return false, fmt.Errorf("%s.%s: input '%s' is not a number", section, key, k)
+			}
+			entries[input] = v
+		}
+	case map[any]any:
+		for k, v := range m {
+			input, ok := k.(int)
+			if !ok {
+				parsed, err := strconv.Atoi(fmt.Sprint(k))
+				if err != nil {
+					return false, fmt.Errorf("%s.%s: input '%v' is not a number", section, key, k)
+				}
+				input = parsed
+			}
+			entries[input] = v
+		}
+	default:
+		return false, nil
+	}
+	list := make([]any, 0, len(entries))
+	for _, input := range slices.Sorted(maps.Keys(entries)) {
+		list = append(list, map[string]any{"input": input, valueKey: entries[input]})
+	}
+	sectionDoc[key] = list
+	return true, nil
+}
+
+// readConfigDocument reads a configuration file into a raw document
+func readConfigDocument(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	switch configFormat(path) {
+	case "json":
+		err = json.Unmarshal(data, &doc)
+	case "toml":
+		return nil, errTOMLConfig
+	default:
+		err = yaml.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = make(map[string]any)
+	}
+	return doc, nil
+}
+
+// documentVersion returns the version of a raw document (0 if absent)
+func documentVersion(doc map[string]any) (int, error) {
+	raw, ok := doc["version"]
+	if !ok {
+		return 0, nil
+	}
+	switch v := raw.(type) {
+	case int:
+		return v, nil
+	case float64: // JSON numbers
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	}
+	return 0, fmt.Errorf("version must be an integer, got %v", raw)
+}
+
+// migrateConfigDocument upgrades a raw document to ConfigVersion; returns the original version
+// and whether any migration changed the document
+func migrateConfigDocument(doc map[string]any) (version int, changed bool, err error) {
+	version, err = documentVersion(doc)
+	if err != nil {
+		return 0, false, err
+	}
+	if version > ConfigVersion {
+		return version, false, fmt.Errorf("config version %d is newer than this build supports (%d); upgrade sessionmixer", version, ConfigVersion)
+	}
+	if version < 0 {
+		return version, false, fmt.Errorf("invalid config version %d", version)
+	}
+	for v := version; v < ConfigVersion; v++ {
+		migrated, err := configMigrations[v](doc)
+		if err != nil {
+			return version, false, fmt.Errorf("migrating config from version %d to %d: %w", v, v+1, err)
+		}
+		changed = changed || migrated
+		doc["version"] = v + 1
+	}
+	return version, changed, nil
+}
+
+// backupConfig copies the configuration file to path.v<version>.bak before a migration
+// rewrites it; an existing backup of the same version is kept
+func backupConfig(path string, version int) (string, error) {
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if _, err := os.Stat(backup); err == nil {
+		return backup, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(backup, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to back up config: %w", err)
+	}
+	return backup, nil
+}
+
+// upgradeConfigFile rewrites a migrated configuration in place after backing up the original
+// Failure to write is logged, not fatal: the migrated configuration is still used this run
+func upgradeConfigFile(cfg *Config, path string, from int) {
+	backup, err := backupConfig(path, from)
+	if err != nil {
+		log.Printf("Config upgraded from version %d to %d but not saved: %v", from, ConfigVersion, err)
+		return
+	}
+	if err := SaveConfig(cfg, path); err != nil {
+		log.Printf("Config upgraded from version %d to %d but not saved: %v", from, ConfigVersion, err)
+		return
+	}
+	log.Printf("Config upgraded from version %d to %d (original saved as %s)", from, ConfigVersion, backup)
+}
@@ -2,6 +2,11 @@ package sessionmixer
 
 import (
 	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/AllenDang/cimgui-go/imgui"
 	"github.com/michaelquigley/dfx"
@@ -11,44 +16,432 @@ import (
 // SessionMixer is the main mixer component
 // Implements dfx.Component interface for immediate-mode GUI rendering
 type SessionMixer struct {
-	card    *scarlettctl.Card
-	config  *Config
-	gangs   []*GangedFader
-	monitor *EventMonitor
+	card     *scarlettctl.Card
+	config   *Config
+	gangs    []*GangedFader
+	monitor  *EventMonitor
+	inputs   *InputPanel
+	routing  *RoutingPanel
+	scenes   *SceneManager
+	status   *StatusBar
+	clips    *ClipLog
+	history  *HistoryView
+	surface  *Surface
+	stager   *GainStager
+	autogain *AutogainRunner
+	listen   *ListenBus
+
+	switches []*Switch
+	coughs   []*CoughSwitch
+	panic    *Panic      // Emergency mute (nil if not configured)
+	idle     *IdleDimmer // Session timer dimming the monitors (nil if not configured)
+
+	// Scene UI state
+	sceneNames    []string
+	selectedScene string
+	sceneName     string
+
+	// Periodic backups (nil if off) and the backup picker state
+	backups        *Backups
+	backupNames    []string
+	selectedBackup string
+
+	// Scene morph UI state
+	morphA, morphB *Scene
+	morphT         float32
+
+	// Gang whose large jump is being confirmed (nil = none)
+	jumping *GangedFader
+
+	// Gang whose external change is being followed or restored (nil = none)
+	externalGang *GangedFader
+
+	// Fader changes held until applied
+	staging *Staging
+
+	// Mix journaled by a session that did not end cleanly, offered for restore (nil = none)
+	recovered    *Scene
+	openRecovery bool
+
+	// Gang context menu state
+	showDetails int     // Gang whose details popup opens on the next frame (-1 = none)
+	exactValue  float32 // "Set exact value" input (dB for "db" gangs, raw otherwise)
+	clipboard   GangClipboard
+	pasteTo     map[int]bool // Gangs selected in the "paste to multiple" menu
+
+	// Peak hold and clip LED of each gang's level meters (left and right for stereo gangs)
+	meters [][2]MeterState
+
+	// Fader bank filter (matches gang names and tags, case-insensitive) and tag views
+	filter  string
+	tags    []string // Distinct gang tags in config order, one view tab each
+	viewTag string   // Tag of the selected view ("" = all gangs)
+
+	// Restricted page (nil = full mixer)
+	page *Page
+
+	// Gang profiles (nil if not switchable) and the window geometry remembered per profile
+	profiles *ProfileManager
+	window   *windowTracker
+
+	// Window presentation
+	strip  bool // Single row of small faders and mutes
+	docked bool // Always a strip (panel widget docked at a screen edge)
+
+	// Tag views detached into their own windows, and whether to move them to their geometry
+	detached      []DetachedGeometry
+	placeDetached bool
+	onTop         bool // Always on top (needs a WindowHost)
+
+	// "Add gang" and "edit gangs" dialogs (nil if the config cannot be saved)
+	picker *GangPicker
+	editor *ConfigEditor
+
+	// Remembered dB/raw value display choices (nil = not persisted)
+	display     *DisplayPrefs
+	displayPath string
+
+	// Keyboard navigation
+	actions   *dfx.ActionRegistry
+	focused   int                 // Keyboard-focused gang (-1 = none)
+	momentary []*momentaryBinding // Keybindings polled for press and release
+
+	// Screen reader announcements (nil = off)
+	announcer *Announcer
+	hovered   *GangedFader // Gang under the pointer this frame
+	announced announceState
 }
 
 // NewSessionMixer creates a new session mixer
 func NewSessionMixer(card *scarlettctl.Card, config *Config, gangs []*GangedFader) *SessionMixer {
-	return &SessionMixer{
-		card:   card,
-		config: config,
-		gangs:  gangs,
+	sm := &SessionMixer{
+		card:        card,
+		config:      config,
+		showDetails: -1,
+		pasteTo:     make(map[int]bool),
+		focused:     -1,
+		staging:     NewStaging(config != nil && config.StageChanges),
+	}
+	sm.actions = sm.buildActions()
+	sm.SetGangs(gangs)
+	return sm
+}
+
+// SetGangs replaces the fader bank (e.g. after a profile switch), resetting the per-gang UI
+// state; must be called on the UI thread
+func (sm *SessionMixer) SetGangs(gangs []*GangedFader) {
+	sm.gangs = gangs
+	sm.meters = make([][2]MeterState, len(gangs))
+	sm.history = nil
+	for _, gang := range gangs {
+		if gang.HasLevels() {
+			sm.history = NewHistoryView(gangs)
+			break
+		}
+	}
+	sm.tags = nil
+	for _, gang := range gangs {
+		for _, tag := range gang.GetTags() {
+			if !slices.Contains(sm.tags, tag) {
+				sm.tags = append(sm.tags, tag)
+			}
+		}
+	}
+	if !slices.Contains(sm.tags, sm.viewTag) {
+		sm.viewTag = ""
+	}
+	sm.staging.Discard()
+	sm.showDetails = -1
+	clear(sm.pasteTo)
+	sm.focused = -1
+	sm.hovered = nil
+	sm.announced = announceState{}
+	if sm.display != nil {
+		sm.display.Apply(gangs)
+	}
+	if sm.page != nil {
+		sm.page.Restrict(gangs)
 	}
 }
 
 // Draw renders the mixer UI using dfx immediate mode
 // This is called every frame by the dfx application
 func (sm *SessionMixer) Draw(_ *dfx.State) {
-	// Calculate total number of faders (individual channels + gangs)
-	totalFaders := len(sm.gangs)
+	defer limitFrame()
+	started := time.Now()
+	defer func() { Stats.RecordFrame(time.Since(started)) }()
+
+	applyUIScale()
+
+	if sm.window != nil {
+		if !sm.window.restored {
+			sm.window.restored = true
+			sm.applyWindow(sm.window.restore(sm.currentProfile()))
+		}
+		sm.window.track(sm.strip, sm.onTop, sm.detached)
+	}
+
+	sm.pollMomentary()
+
+	// Announced once the frame has found the gang under the pointer
+	if sm.announcer != nil {
+		sm.hovered = nil
+		defer sm.announceFocus()
+	}
+
+	// Device status (clock source, sync, sample rate)
+	if sm.status != nil && sm.status.HasStatus() && sm.page == nil {
+		sm.status.Draw()
+	}
+
+	// Hardware event watcher status
+	if sm.monitor != nil && sm.page == nil {
+		sm.drawMonitorStatus()
+	}
+
+	// Session timer: window input counts as activity
+	if sm.idle != nil {
+		sm.trackActivity()
+	}
+
+	// Most recent failed UI action
+	if err := recentUIError(); err != nil {
+		imgui.TextColored(errorColor, err.Error())
+	}
+
+	// Gang profiles; a requested switch is applied here, on the UI thread
+	if sm.profiles != nil {
+		from := sm.profiles.GetCurrent()
+		logError(sm.profiles.Apply())
+		if to := sm.profiles.GetCurrent(); to != from && sm.window != nil {
+			sm.applyWindow(sm.window.switched(from, to))
+		}
+		if sm.profiles.HasProfiles() && sm.page == nil {
+			sm.drawProfiles()
+		}
+	}
+
+	// An observer shows values and meters but the rest of the window does not react to input
+	if IsObserver() {
+		imgui.TextDisabled(tr("Read-only: changes are not written to the hardware"))
+		imgui.PushStyleVarFloat(imgui.StyleVarDisabledAlpha, 1.0)
+		imgui.BeginDisabled()
+		defer func() {
+			imgui.EndDisabled()
+			imgui.PopStyleVar()
+		}()
+	}
+
+	// Large jumps held for confirmation
+	sm.drawJumpConfirm()
+
+	// External changes held for a decision
+	sm.drawExternalConfirm()
+
+	// A restricted page replaces the whole UI with its own gangs
+	if sm.page != nil {
+		sm.drawPage()
+		return
+	}
+
+	if sm.recovered != nil && sm.scenes != nil {
+		sm.drawRecovery()
+	}
+
+	if len(sm.gangs) == 0 {
+		imgui.Text(tr("No controls configured"))
+		return
+	}
 
-	if totalFaders == 0 {
-		imgui.Text("No controls configured")
+	// Strip mode replaces the whole UI with a single row of small faders and mutes
+	if sm.strip {
+		sm.drawStrip()
 		return
 	}
 
-	imgui.Dummy(imgui.Vec2{X: 25, Y: 100})
+	// Tag views
+	if len(sm.tags) > 0 {
+		sm.drawTagViews()
+	}
+
+	// Filter box narrowing the visible gangs
+	imgui.SetNextItemWidth(200)
+	imgui.InputTextWithHint("##gang_filter", tr("filter gangs"), &sm.filter, imgui.InputTextFlagsNone, nil)
+
+	// Add a gang from the hardware controls
+	if sm.picker != nil {
+		imgui.SameLine()
+		if imgui.Button(tr("Add Gang...")) {
+			sm.picker.Open()
+		}
+		sm.picker.Draw()
+		if added := sm.picker.GetAdded(); len(added) > 0 {
+			imgui.SameLine()
+			imgui.TextDisabled(trf("%d gang(s) added; restart to load", len(added)))
+		}
+	}
+	if sm.editor != nil {
+		imgui.SameLine()
+		if imgui.Button(tr("Edit Gangs...")) {
+			sm.editor.Open()
+		}
+		sm.editor.Draw()
+		if sm.editor.IsSaved() {
+			imgui.SameLine()
+			imgui.TextDisabled(tr("gangs saved; restart to apply"))
+		}
+	}
+
+	// Global dB/raw value display toggle, and offsets from the current scene
+	if sm.display != nil {
+		imgui.SameLine()
+		raw := sm.display.Mode == DisplayRaw
+		if imgui.Checkbox(tr("Raw values"), &raw) {
+			mode := DisplayDb
+			if raw {
+				mode = DisplayRaw
+			}
+			sm.display.SetGlobal(mode, sm.gangs)
+			sm.saveDisplay()
+		}
+		if sm.scenes != nil {
+			imgui.SameLine()
+			if imgui.Checkbox(tr("Scene offsets"), &sm.display.Offsets) {
+				sm.saveDisplay()
+			}
+			imgui.SetItemTooltip(tr("Show each gang's change since the current scene was recalled or saved"))
+		}
+	}
+
+	// Staged fader changes
+	imgui.SameLine()
+	sm.drawStaging()
+
+	// Strip mode and always on top
+	imgui.SameLine()
+	sm.drawWindowToggles()
+
+	// Emergency mute
+	if sm.panic != nil {
+		imgui.SameLine()
+		sm.drawPanicButton()
+	}
+
+	// Control surface bank
+	if sm.surface != nil {
+		imgui.SameLine()
+		sm.drawSurfaceBank()
+	}
+	// Tag views detached into their own windows
+	sm.drawDetached()
+
+	visible := sm.visibleGangs()
+	if len(visible) == 0 {
+		imgui.TextDisabled(tr("No gangs match the filter"))
+		return
+	}
+	sm.drawFaderBank(visible, scaled(450))
+
+	// Device switches (Direct Monitor, loopback...)
+	if len(sm.switches) > 0 {
+		sm.drawSwitches()
+	}
+
+	// Input channel hardware settings (preamp gain, pad, air, phantom...)
+	if sm.inputs != nil && sm.inputs.HasInputs() {
+		if imgui.CollapsingHeaderTreeNodeFlags(tr("Inputs")) {
+			sm.inputs.Draw()
+		}
+	}
+
+	// Autogain across multiple inputs
+	if sm.autogain != nil {
+		if imgui.CollapsingHeaderTreeNodeFlags(tr("Autogain")) {
+			sm.autogain.Draw()
+		}
+	}
+
+	// Gain staging assistant
+	if sm.stager != nil {
+		if imgui.CollapsingHeaderTreeNodeFlags(tr("Gain Staging")) {
+			sm.stager.Draw()
+		}
+	}
+
+	// Routing/patchbay editor
+	if sm.routing != nil && sm.routing.HasSinks() {
+		if imgui.CollapsingHeaderTreeNodeFlags(tr("Routing")) {
+			sm.routing.Draw()
+		}
+	}
+
+	// Zoomable level history
+	if sm.history != nil {
+		if imgui.CollapsingHeaderTreeNodeFlags(tr("Level History")) {
+			sm.history.Draw()
+		}
+	}
+
+	// Clip event summary
+	if sm.clips != nil {
+		if imgui.CollapsingHeaderTreeNodeFlags(tr("Clips")) {
+			sm.clips.Draw()
+		}
+	}
+
+	// Scene save/recall
+	if sm.scenes != nil {
+		if imgui.CollapsingHeaderTreeNodeFlags(tr("Scenes")) {
+			sm.drawScenes()
+		}
+	}
+
+	// Write latency and event statistics
+	if imgui.CollapsingHeaderTreeNodeFlags(tr("Performance")) {
+		drawStats()
+	}
+
+	// Live list of hardware control changes, for finding a knob's control
+	if imgui.CollapsingHeaderTreeNodeFlags(tr("Control Changes")) {
+		drawControlLog()
+	}
+}
+
+// drawGangToggle renders a toggle gang as a checkbox, returning the new value when clicked
+// Clicking a mixed toggle switches every member on
+func drawGangToggle(label string, gang *GangedFader) (int, bool) {
+	diverged := gang.IsDiverged()
+	on := gang.GetCurrentValue() != 0 && !diverged
+	if diverged {
+		imgui.PushItemFlag(imgui.ItemFlags(imgui.ItemFlagsMixedValue), true)
+	}
+	clicked := imgui.Checkbox(label, &on)
+	if diverged {
+		imgui.PopItemFlag()
+	}
+	if clicked && diverged {
+		on = true
+	}
+	return int(boolToValue(on)), clicked
+}
+
+// drawFaderBank renders the gangs at the given indexes as a scrollable table of labels, faders,
+// values, listen buttons, sparklines and loudness estimates (height 0 fills the window)
+func (sm *SessionMixer) drawFaderBank(visible []int, height float32) {
+	totalFaders := len(visible)
+
+	imgui.Dummy(scaledVec2(imgui.Vec2{X: 25, Y: 100}))
 	imgui.SameLine()
 
 	// Create scrollable child window for fader bank
 	// Similar to dfx_example_mixer layout
-	childSize := imgui.Vec2{X: 0, Y: 450} // X=0 fills available width
+	childSize := imgui.Vec2{X: 0, Y: height} // X=0 fills available width
 	imgui.BeginChildStrV("FaderBank", childSize,
 		imgui.ChildFlagsNone,
 		imgui.WindowFlagsHorizontalScrollbar)
 
 	// Use table layout for stable column widths
-	faderWidth := float32(80.0) // Width per fader column
+	faderWidth := scaled(80) // Width per fader column
 	contentWidth := float32(totalFaders) * faderWidth
 
 	imgui.BeginTableV("mixer_table", int32(totalFaders),
@@ -61,57 +454,722 @@ func (sm *SessionMixer) Draw(_ *dfx.State) {
 			imgui.TableColumnFlagsWidthFixed, faderWidth, 0)
 	}
 
-	// Row 1: Channel labels
+	// Row 1: Channel labels (hover for notes, click for details), marked when modified since the
+	// current scene
+	var scene *Scene
+	if sm.scenes != nil {
+		scene = sm.scenes.GetCurrent()
+	}
 	imgui.TableNextRow()
-	for _, gang := range sm.gangs {
+	for _, i := range visible {
+		gang := sm.gangs[i]
 		imgui.TableNextColumn()
+		sm.highlightFocused(i)
 		imgui.Text(gang.GetName())
+		if gang.GetDescription() != "" && imgui.BeginItemTooltip() {
+			imgui.PushTextWrapPosV(300)
+			imgui.TextUnformatted(gang.GetDescription())
+			imgui.PopTextWrapPos()
+			imgui.EndTooltip()
+		}
+		popupID := fmt.Sprintf("gang_details_%d", i)
+		if imgui.IsItemClicked() || sm.showDetails == i {
+			imgui.OpenPopupStr(popupID)
+			sm.showDetails = -1
+		}
+		if imgui.BeginPopup(popupID) {
+			drawGangDetails(gang)
+			imgui.EndPopup()
+		}
+		if scene != nil {
+			sm.drawModified(i, gang, scene)
+		}
 	}
 
 	// Row 2: Faders
 	imgui.TableNextRow()
 
 	// Draw ganged faders
-	for i, gang := range sm.gangs {
+	for _, i := range visible {
+		gang := sm.gangs[i]
 		imgui.TableNextColumn()
+		sm.highlightFocused(i)
+
+		// Display channels are read-only: a meter or numeric readout instead of a fader
+		if gang.IsReadOnly() {
+			drawReadout(gang, imgui.Vec2{X: scaled(20), Y: gang.GetParams().Height})
+			sm.trackHovered(gang)
+			continue
+		}
 
-		currentValue := int(gang.GetCurrentValue())
+		currentValue := int(sm.staging.Value(gang))
 
-		// Get params and set TrackColor if gang has level controls
 		params := gang.GetParams()
-		if gang.HasLevels() {
-			params.TrackColor = gang.GetLevelColor()
+
+		// Locked gangs are drawn disabled and ignore fader changes
+		locked := gang.IsLocked()
+		if locked {
+			imgui.BeginDisabled()
 		}
 
-		// Use dfx.FaderI for ganged fader
-		newValue, changed := dfx.FaderI(
-			fmt.Sprintf("##fader_gang_%d", i),
-			currentValue,
-			int(gang.GetMin()),
-			int(gang.GetMax()),
-			params)
+		var newValue int
+		var changed bool
+		if gang.IsToggle() {
+			// Boolean gangs are a single toggle; shown mixed when members diverge on the hardware
+			newValue, changed = drawGangToggle(fmt.Sprintf("##toggle_gang_%d", i), gang)
+		} else {
+			// Use dfx.FaderI for ganged fader
+			newValue, changed = dfx.FaderI(
+				fmt.Sprintf("##fader_gang_%d", i),
+				currentValue,
+				int(gang.GetMin()),
+				int(gang.GetMax()),
+				params)
+		}
 
-		if changed {
-			// IMMEDIATE write to all ganged channels
-			gang.HandleUIChange(int64(newValue))
+		if locked {
+			imgui.EndDisabled()
+		} else if changed {
+			// IMMEDIATE write to all ganged channels, unless changes are staged
+			logError(sm.changeGang(gang, int64(newValue)))
+		}
+
+		// Clicking a fader moves the keyboard focus to it
+		if imgui.IsItemActivated() {
+			sm.focused = i
+		}
+		sm.trackHovered(gang)
+
+		// Right-click context menu
+		menuID := fmt.Sprintf("gang_menu_%d", i)
+		if imgui.IsItemHoveredV(imgui.HoveredFlagsAllowWhenDisabled) && imgui.IsMouseClickedBool(imgui.MouseButtonRight) {
+			sm.exactValue = displayValue(gang, sm.staging.Value(gang))
+			imgui.OpenPopupStr(menuID)
+		}
+		if imgui.BeginPopup(menuID) {
+			sm.drawGangMenu(i, gang)
+			imgui.EndPopup()
+		}
+
+		// Level meter beside the fader
+		if gang.HasLevels() {
+			imgui.SameLine()
+			sm.drawGangMeter(i, gang, params.Height)
 		}
 	}
 
-	// Row 3: Value displays
+	// Row 3: Value displays (M = muted, L = locked, S = recall safe, CAP = held at the protection ceiling),
+	// staged values waiting for Apply, or offsets from the current scene
+	reference := sm.offsetScene()
 	imgui.TableNextRow()
-	for _, gang := range sm.gangs {
+	for _, i := range visible {
+		gang := sm.gangs[i]
 		imgui.TableNextColumn()
+		sm.highlightSurface(i)
+		if gang.GetDisplay() == DisplayReadout {
+			continue // The readout already shows the value
+		}
 		currentValue := gang.GetCurrentValue()
-		imgui.Text(fmt.Sprintf("%d", currentValue))
+		flags := ""
+		if gang.IsMuted() {
+			flags += " M"
+		}
+		if gang.IsLocked() && !gang.IsReadOnly() {
+			flags += " L"
+		}
+		if gang.IsSafe() {
+			flags += " S"
+		}
+		if writer, held := gang.GetWriter(); held && writer != WriteSourceUI {
+			flags += " " + strings.ToUpper(writer) // Held by another source (see the write policy)
+		}
+		referenceValue, hasReference := int64(0), false
+		if reference != nil && !gang.IsToggle() && !gang.IsReadOnly() {
+			referenceValue, hasReference = reference.Gangs[gang.GetName()]
+		}
+		if staged, ok := sm.staging.Get(gang); ok {
+			imgui.TextColored(stagedColor, "> "+gang.FormatValue(staged)+flags)
+			if imgui.BeginItemTooltip() {
+				imgui.TextUnformatted(trf("Staged: %s", gang.FormatValue(staged)))
+				imgui.TextUnformatted(trf("Hardware: %s", gang.FormatValue(currentValue)))
+				imgui.EndTooltip()
+			}
+		} else if gang.IsCapped() {
+			imgui.TextColored(warningColor, gang.FormatValue(currentValue)+flags+" CAP")
+		} else if gang.IsToggle() {
+			imgui.Text(toggleState(gang) + flags)
+		} else if hasReference {
+			offset := formatOffset(gang, currentValue, referenceValue) + flags
+			if currentValue != referenceValue {
+				imgui.TextColored(offsetColor, offset)
+			} else {
+				imgui.Text(offset)
+			}
+			if imgui.BeginItemTooltip() {
+				imgui.TextUnformatted(gang.FormatValue(currentValue))
+				imgui.TextUnformatted(trf("Scene '%s': %s", reference.Name, gang.FormatValue(referenceValue)))
+				imgui.EndTooltip()
+			}
+		} else {
+			imgui.Text(gang.FormatValue(currentValue) + flags)
+		}
+	}
+
+	// Row 3b: Listen (PFL/AFL) buttons
+	if sm.listen != nil {
+		imgui.TableNextRow()
+		active := sm.listen.GetActive()
+		for _, i := range visible {
+			gang := sm.gangs[i]
+			imgui.TableNextColumn()
+			if !sm.listen.CanListen(gang) {
+				continue
+			}
+			if gang == active {
+				imgui.PushStyleColorVec4(imgui.ColButton, listenColor)
+			}
+			if imgui.SmallButton(trf("Listen##listen_%d", i)) {
+				logError(sm.listen.Toggle(gang))
+			}
+			if gang == active {
+				imgui.PopStyleColor()
+			}
+		}
+	}
+
+	// Row 4: Level history sparklines
+	imgui.TableNextRow()
+	for _, i := range visible {
+		gang := sm.gangs[i]
+		imgui.TableNextColumn()
+		if history := gang.GetLevelHistory(); history != nil {
+			drawSparkline(fmt.Sprintf("##sparkline_%d", i), history, scaledVec2(imgui.Vec2{X: 60, Y: 24}))
+		}
+	}
+
+	// Row 5: Loudness estimates (short-term / integrated)
+	imgui.TableNextRow()
+	for _, i := range visible {
+		gang := sm.gangs[i]
+		imgui.TableNextColumn()
+		if loudness := gang.GetLoudness(); loudness != nil {
+			imgui.TextDisabled(trf("S %s", formatLoudness(loudness.ShortTerm())))
+			imgui.TextDisabled(trf("I %s", formatLoudness(loudness.Integrated())))
+		}
 	}
 
 	imgui.EndTable()
 	imgui.EndChild()
 }
 
+// drawModified marks a gang that has moved from its value in scene with a dot after its label;
+// clicking the dot reverts the gang
+func (sm *SessionMixer) drawModified(i int, gang *GangedFader, scene *Scene) {
+	value, modified := scene.Modified(gang)
+	if !modified {
+		return
+	}
+	imgui.SameLine()
+	height := imgui.TextLineHeight()
+	locked := gang.IsLocked()
+	if locked {
+		imgui.BeginDisabled()
+	}
+	if imgui.InvisibleButton(fmt.Sprintf("##revert_%d", i), imgui.Vec2{X: height, Y: height}) && gang.Claim(WriteSourceUI) {
+		logError(gang.HandleGuardedChange(value))
+	}
+	if locked {
+		imgui.EndDisabled()
+	}
+	center := imgui.Vec2{X: imgui.ItemRectMin().X + height/2, Y: imgui.ItemRectMin().Y + height/2}
+	imgui.WindowDrawList().AddCircleFilled(center, height/4, imgui.ColorConvertFloat4ToU32(offsetColor))
+	if imgui.IsItemHoveredV(imgui.HoveredFlagsForTooltip|imgui.HoveredFlagsAllowWhenDisabled) && imgui.BeginTooltip() {
+		imgui.TextUnformatted(trf("Modified since scene '%s'", scene.Name))
+		imgui.TextUnformatted(trf("Click to revert to %s", gang.FormatValue(value)))
+		imgui.EndTooltip()
+	}
+}
+
+// offsetScene returns the scene gang offsets are shown from, or nil when values are shown
+func (sm *SessionMixer) offsetScene() *Scene {
+	if sm.display == nil || !sm.display.Offsets || sm.scenes == nil {
+		return nil
+	}
+	return sm.scenes.GetCurrent()
+}
+
+// toggleState describes the state of a toggle gang
+func toggleState(gang *GangedFader) string {
+	switch {
+	case gang.IsDiverged():
+		return tr("Mixed")
+	case gang.GetCurrentValue() != 0:
+		return tr("On")
+	default:
+		return tr("Off")
+	}
+}
+
+// drawGangMenu renders the fader context menu for a gang
+func (sm *SessionMixer) drawGangMenu(i int, gang *GangedFader) {
+	imgui.SeparatorText(gang.GetName())
+	locked := gang.IsLocked()
+
+	if imgui.MenuItemBoolV(tr("Reset to default"), "", false, !locked) && gang.Claim(WriteSourceUI) {
+		logError(gang.ResetToDefault())
+	}
+	if gang.IsMuted() {
+		if imgui.MenuItemBoolV(tr("Unmute"), "", false, !locked) && gang.Claim(WriteSourceUI) {
+			logError(gang.Unmute())
+		}
+	} else if imgui.MenuItemBoolV(tr("Mute"), "", false, !locked) && gang.Claim(WriteSourceUI) {
+		logError(gang.Mute())
+	}
+	if imgui.MenuItemBoolV(tr("Lock"), "", locked, true) {
+		gang.SetLocked(!locked)
+	}
+	if imgui.MenuItemBoolV(tr("Recall safe"), "", gang.IsSafe(), true) {
+		gang.SetSafe(!gang.IsSafe())
+	}
+
+	// Set exact value
+	format := "%.0f"
+	if gang.GetUnit() == "db" {
+		format = "%.1f dB"
+	}
+	if locked {
+		imgui.BeginDisabled()
+	}
+	imgui.SetNextItemWidth(120)
+	if imgui.InputFloatV(tr("Set value"), &sm.exactValue, 0, 0, format, imgui.InputTextFlagsEnterReturnsTrue) {
+		logError(sm.changeGang(gang, rawValue(gang, sm.exactValue)))
+		imgui.CloseCurrentPopup()
+	}
+	if locked {
+		imgui.EndDisabled()
+	}
+
+	imgui.Separator()
+	if imgui.MenuItemBool(tr("Copy value")) {
+		sm.clipboard.Copy(gang)
+		imgui.SetClipboardText(sm.clipboard.String())
+	}
+	if imgui.MenuItemBoolV(tr("Paste value"), sm.clipboard.String(), false, sm.clipboard.CanPaste(gang)) {
+		logError(sm.clipboard.Paste(gang))
+	}
+	if imgui.BeginMenuV(tr("Paste to multiple"), sm.clipboard.HasValue()) {
+		sm.drawPasteTargets()
+		imgui.EndMenu()
+	}
+
+	if sm.display != nil && gang.GetUnit() == "db" {
+		sm.drawDisplayMenu(gang)
+	}
+	if gang.HasPostLevels() {
+		if imgui.MenuItemBoolV(tr("Meter post (mix output)"), "", gang.IsMeterPost(), true) {
+			gang.SetMeterPost(!gang.IsMeterPost())
+			sm.meters[i] = [2]MeterState{}
+		}
+	}
+
+	imgui.Separator()
+	if imgui.MenuItemBool(tr("Show controls")) {
+		sm.showDetails = i
+	}
+	if imgui.MenuItemBoolV(tr("Re-sync gang"), "", false, !locked) && gang.Claim(WriteSourceUI) {
+		logError(gang.Resync())
+	}
+}
+
+// drawDisplayMenu renders the per-gang dB/raw display submenu
+func (sm *SessionMixer) drawDisplayMenu(gang *GangedFader) {
+	if !imgui.BeginMenu(tr("Display")) {
+		return
+	}
+	override := sm.display.Gangs[gang.GetName()]
+	for _, item := range []struct{ label, mode string }{
+		{"Global setting", ""},
+		{"dB", DisplayDb},
+		{"Raw", DisplayRaw},
+	} {
+		if imgui.MenuItemBoolV(tr(item.label), "", override == item.mode, true) {
+			sm.display.SetGang(gang, item.mode)
+			sm.saveDisplay()
+		}
+	}
+	imgui.EndMenu()
+}
+
+// saveDisplay persists the display preferences
+func (sm *SessionMixer) saveDisplay() {
+	if sm.displayPath != "" {
+		logError(sm.display.Save(sm.displayPath))
+	}
+}
+
+// drawPasteTargets renders the "paste to multiple" submenu: a checklist of compatible gangs
+// Recall-safe gangs are excluded from multi-gang pastes
+func (sm *SessionMixer) drawPasteTargets() {
+	candidates := sm.pasteCandidates()
+	if len(candidates) == 0 {
+		imgui.TextDisabled(tr("No compatible gangs"))
+		return
+	}
+
+	// Keep the menu open while toggling targets
+	var targets []*GangedFader
+	imgui.PushItemFlag(imgui.ItemFlagsAutoClosePopups, false)
+	for _, i := range candidates {
+		gang := sm.gangs[i]
+		selected := sm.pasteTo[i]
+		if imgui.MenuItemBoolPtr(gang.GetName(), "", &selected) {
+			sm.pasteTo[i] = selected
+		}
+		if selected {
+			targets = append(targets, gang)
+		}
+	}
+	imgui.PopItemFlag()
+
+	imgui.Separator()
+	if imgui.MenuItemBoolV(tr("Paste to selected"), "", false, len(targets) > 0) {
+		logError(sm.clipboard.Paste(targets...))
+		clear(sm.pasteTo)
+	}
+	if imgui.MenuItemBool(tr("Paste to all compatible")) {
+		var all []*GangedFader
+		for _, i := range candidates {
+			all = append(all, sm.gangs[i])
+		}
+		logError(sm.clipboard.Paste(all...))
+		clear(sm.pasteTo)
+	}
+}
+
+// pasteCandidates returns the indexes of the gangs that can receive a multi-gang paste
+func (sm *SessionMixer) pasteCandidates() []int {
+	var candidates []int
+	for i, gang := range sm.gangs {
+		if sm.clipboard.CanPaste(gang) && !gang.IsSafe() {
+			candidates = append(candidates, i)
+		}
+	}
+	return candidates
+}
+
+// displayValue converts a raw gang value to the value edited in the context menu
+func displayValue(gang *GangedFader, value int64) float32 {
+	if gang.GetUnit() != "db" {
+		return float32(value)
+	}
+	db := gang.ValueToDb(value)
+	if math.IsInf(db, -1) {
+		return floorDb
+	}
+	return float32(db)
+}
+
+// rawValue converts a value edited in the context menu back to a raw gang value
+func rawValue(gang *GangedFader, value float32) int64 {
+	if gang.GetUnit() != "db" {
+		return max(gang.GetMin(), min(gang.GetMax(), int64(math.Round(float64(value)))))
+	}
+	if value <= floorDb {
+		return gang.GetMin()
+	}
+	return gang.DbToValue(float64(value))
+}
+
+// drawGangDetails renders a gang's notes, taper and underlying controls with their ranges
+// and current raw values
+func drawGangDetails(gang *GangedFader) {
+	imgui.SeparatorText(gang.GetName())
+	if gang.GetDescription() != "" {
+		imgui.PushTextWrapPosV(400)
+		imgui.TextUnformatted(gang.GetDescription())
+		imgui.PopTextWrapPos()
+		imgui.Spacing()
+	}
+
+	taper := "linear"
+	if gang.GetTaperDb() > 0 {
+		taper = fmt.Sprintf("%.0f dB", gang.GetTaperDb())
+	}
+	imgui.TextUnformatted(trf("Unit: %s   Taper: %s   Mode: %s   Value: %d", gang.GetUnit(), taper, gang.GetMode(), gang.GetCurrentValue()))
+	if len(gang.GetTags()) > 0 {
+		imgui.TextUnformatted(tr("Tags: ") + strings.Join(gang.GetTags(), ", "))
+	}
+
+	imgui.SeparatorText(tr("Controls"))
+	for _, ch := range gang.GetChannels() {
+		control := ch.GetControl()
+		imgui.TextUnformatted(trf("%s  [%d..%d]  = %d", control.Name, control.Min, control.Max, ch.GetCurrentValue()))
+	}
+
+	if gang.HasLevels() {
+		imgui.SeparatorText(tr("Levels"))
+		for _, control := range gang.GetLevelControls() {
+			value, err := control.GetValue()
+			if err != nil {
+				imgui.TextUnformatted(trf("%s  [%d..%d]  (unreadable)", control.Name, control.Min, control.Max))
+				continue
+			}
+			imgui.TextUnformatted(trf("%s  [%d..%d]  = %d", control.Name, control.Min, control.Max, value))
+		}
+	}
+}
+
+// drawTagViews renders a tab per tag, selecting which gangs the bank shows
+func (sm *SessionMixer) drawTagViews() {
+	if !imgui.BeginTabBar("tag_views") {
+		return
+	}
+	if imgui.BeginTabItem(tr("All")) {
+		sm.viewTag = ""
+		imgui.EndTabItem()
+	}
+	for _, tag := range sm.tags {
+		if sm.isDetached(tag) {
+			continue
+		}
+		selected := imgui.BeginTabItem(tag)
+		sm.drawTagMenu(tag)
+		if selected {
+			sm.viewTag = tag
+			imgui.EndTabItem()
+		}
+	}
+	imgui.EndTabBar()
+}
+
+// drawProfiles renders the profile selector
+func (sm *SessionMixer) drawProfiles() {
+	imgui.SetNextItemWidth(200)
+	current := sm.profiles.GetCurrent()
+	if imgui.BeginCombo(tr("Profile"), current) {
+		for _, name := range sm.profiles.GetNames() {
+			if imgui.SelectableBoolV(name, name == current, imgui.SelectableFlagsNone, imgui.Vec2{}) {
+				logError(sm.profiles.Request(name))
+			}
+		}
+		imgui.EndCombo()
+	}
+}
+
+// drawMonitorStatus warns while the hardware event watcher is down (the UI is polled or stale)
+// and notes past restarts
+func (sm *SessionMixer) drawMonitorStatus() {
+	if sm.monitor.HasFailed() {
+		status := tr("hardware changes are not shown")
+		if fallback := sm.monitor.GetFallback(); fallback > 0 {
+			status = trf("polling every %s", fallback)
+		}
+		imgui.TextColored(warningColor, trf("Hardware events unavailable (%v); restarting, %s", sm.monitor.GetLastError(), status))
+	} else if restarts := sm.monitor.GetRestarts(); restarts > 0 {
+		imgui.TextDisabled(trf("Event monitor restarted %d time(s); last error: %v", restarts, sm.monitor.GetLastError()))
+	}
+}
+
+// errorColor highlights failed UI actions
+var errorColor = imgui.Vec4{X: 1.0, Y: 0.2, Z: 0.2, W: 1.0}
+
+// warningColor highlights degraded hardware sync
+var warningColor = imgui.Vec4{X: 1.0, Y: 0.6, Z: 0.2, W: 1.0}
+
+// listenColor marks the listen button of the gang being listened to
+var listenColor = imgui.Vec4{X: 0.9, Y: 0.75, Z: 0.1, W: 1.0}
+
+// offsetColor marks gangs that have moved since the current scene
+var offsetColor = imgui.Vec4{X: 0.4, Y: 0.8, Z: 1.0, W: 1.0}
+
+// surfaceColor marks the gangs mapped to the control surface's current bank
+var surfaceColor = imgui.Vec4{X: 0.9, Y: 0.6, Z: 0.1, W: 0.35}
+
+// pickupColor marks gangs whose surface fader waits to pick them up (soft takeover)
+var pickupColor = imgui.Vec4{X: 0.9, Y: 0.6, Z: 0.1, W: 0.12}
+
+// drawSurfaceBank renders the control surface bank selector
+func (sm *SessionMixer) drawSurfaceBank() {
+	if imgui.ArrowButton("##bank_down", imgui.DirLeft) {
+		sm.surface.BankDown()
+	}
+	imgui.SameLine()
+	first, count := sm.surface.GetBankRange()
+	label := trf("Surface bank %d/%d", sm.surface.GetBank()+1, sm.surface.GetBankCount())
+	if count > 0 {
+		label += fmt.Sprintf(" (%s - %s)", sm.gangs[first].GetName(), sm.gangs[first+count-1].GetName())
+	}
+	imgui.Text(label)
+	imgui.SameLine()
+	if imgui.ArrowButton("##bank_up", imgui.DirRight) {
+		sm.surface.BankUp()
+	}
+}
+
+// highlightSurface colors the current table cell if gang i is mapped to the surface
+func (sm *SessionMixer) highlightSurface(i int) {
+	if sm.surface == nil {
+		return
+	}
+	first, count := sm.surface.GetBankRange()
+	if i >= first && i < first+count {
+		color := surfaceColor
+		if sm.surface.AwaitsPickup(i - first) {
+			color = pickupColor
+		}
+		imgui.TableSetBgColor(imgui.TableBgTargetCellBg, imgui.ColorConvertFloat4ToU32(color))
+	}
+}
+
+// visibleGangs returns the indexes of the gangs in the selected tag view that match the
+// filter, in bank order
+func (sm *SessionMixer) visibleGangs() []int {
+	filter := strings.ToLower(strings.TrimSpace(sm.filter))
+	visible := make([]int, 0, len(sm.gangs))
+	for i, gang := range sm.gangs {
+		if sm.viewTag != "" && !gang.HasTag(sm.viewTag) {
+			continue
+		}
+		if sm.viewTag == "" && sm.inDetached(gang) {
+			continue // Shown in its own window
+		}
+		if filter == "" || matchesFilter(gang, filter) {
+			visible = append(visible, i)
+		}
+	}
+	return visible
+}
+
+// matchesFilter returns true if the gang name or any tag contains the lowercase filter
+func matchesFilter(gang *GangedFader, filter string) bool {
+	if strings.Contains(strings.ToLower(gang.GetName()), filter) {
+		return true
+	}
+	for _, tag := range gang.GetTags() {
+		if strings.Contains(strings.ToLower(tag), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// drawSwitches renders the configured switches as a row of labeled toggles/selectors
+func (sm *SessionMixer) drawSwitches() {
+	switchWidth := float32(150.0)
+	imgui.BeginTableV("switches_table", int32(len(sm.switches)),
+		imgui.TableFlagsNone,
+		imgui.Vec2{X: float32(len(sm.switches)) * switchWidth, Y: 0}, 0.0)
+	for i := range sm.switches {
+		imgui.TableSetupColumnV(fmt.Sprintf("##switch_col%d", i),
+			imgui.TableColumnFlagsWidthFixed, switchWidth, 0)
+	}
+
+	imgui.TableNextRow()
+	for _, sw := range sm.switches {
+		imgui.TableNextColumn()
+		imgui.Text(sw.GetName())
+	}
+
+	imgui.TableNextRow()
+	for i, sw := range sm.switches {
+		imgui.TableNextColumn()
+		imgui.SetNextItemWidth(-1)
+		sw.Draw(fmt.Sprintf("##switch_%d", i))
+	}
+
+	imgui.EndTable()
+}
+
+// drawScenes renders the scene selector with recall and save controls
+func (sm *SessionMixer) drawScenes() {
+	imgui.SetNextItemWidth(200)
+	if imgui.BeginCombo("##scene_select", sm.selectedScene) {
+		for _, name := range sm.sceneNames {
+			if imgui.SelectableBoolV(name, name == sm.selectedScene, imgui.SelectableFlagsNone, imgui.Vec2{}) {
+				sm.selectedScene = name
+			}
+		}
+		imgui.EndCombo()
+	}
+	imgui.SameLine()
+	if imgui.Button(tr("Recall")) && sm.selectedScene != "" {
+		if err := sm.scenes.Recall(sm.selectedScene); err != nil {
+			log.Printf("Failed to recall scene '%s': %v", sm.selectedScene, err)
+		}
+	}
+
+	imgui.SetNextItemWidth(200)
+	imgui.InputTextWithHint("##scene_name", tr("scene name"), &sm.sceneName, imgui.InputTextFlagsNone, nil)
+	imgui.SameLine()
+	if imgui.Button(tr("Save")) && sm.sceneName != "" {
+		if err := sm.scenes.Save(sm.sceneName); err != nil {
+			log.Printf("Failed to save scene '%s': %v", sm.sceneName, err)
+		} else {
+			sm.selectedScene = sm.sceneName
+			sm.refreshSceneNames()
+		}
+	}
+
+	if current := sm.scenes.GetCurrent(); current != nil {
+		imgui.Text(trf("Current scene: %s", current.Name))
+	}
+
+	if sm.backups != nil {
+		sm.drawBackups()
+	}
+
+	// Morph between two scenes
+	imgui.SeparatorText(tr("Morph"))
+	sm.drawMorphSelector("##morph_a", &sm.morphA)
+	imgui.SameLine()
+	imgui.Text(tr("->"))
+	imgui.SameLine()
+	sm.drawMorphSelector("##morph_b", &sm.morphB)
+	if sm.morphA != nil && sm.morphB != nil {
+		imgui.SetNextItemWidth(420)
+		if imgui.SliderFloatV("##morph", &sm.morphT, 0.0, 1.0, "%.2f", imgui.SliderFlagsNone) {
+			if err := sm.scenes.Morph(sm.morphA, sm.morphB, float64(sm.morphT)); err != nil {
+				log.Printf("Failed to morph scenes: %v", err)
+			}
+		}
+	}
+}
+
+// drawMorphSelector renders a scene combo that loads the selected scene into target
+func (sm *SessionMixer) drawMorphSelector(label string, target **Scene) {
+	preview := ""
+	if *target != nil {
+		preview = (*target).Name
+	}
+	imgui.SetNextItemWidth(200)
+	if imgui.BeginCombo(label, preview) {
+		for _, name := range sm.sceneNames {
+			if imgui.SelectableBoolV(name, name == preview, imgui.SelectableFlagsNone, imgui.Vec2{}) {
+				scene, err := sm.scenes.Load(name)
+				if err != nil {
+					log.Printf("%v", err)
+				} else {
+					*target = scene
+				}
+			}
+		}
+		imgui.EndCombo()
+	}
+}
+
+// refreshSceneNames reloads the list of stored scenes
+func (sm *SessionMixer) refreshSceneNames() {
+	names, err := sm.scenes.List()
+	if err != nil {
+		log.Printf("Failed to list scenes: %v", err)
+		return
+	}
+	sm.sceneNames = names
+}
+
 // Actions returns the action registry for keyboard shortcuts
 func (sm *SessionMixer) Actions() *dfx.ActionRegistry {
-	return nil // No custom actions for now
+	return sm.actions
 }
 
 // SetMonitor sets the event monitor for hardware change notifications
@@ -119,6 +1177,113 @@ func (sm *SessionMixer) SetMonitor(monitor *EventMonitor) {
 	sm.monitor = monitor
 }
 
+// SetInputPanel sets the panel used to render input channel hardware settings
+func (sm *SessionMixer) SetInputPanel(inputs *InputPanel) {
+	sm.inputs = inputs
+}
+
+// SetRoutingPanel sets the panel used to render the routing/patchbay editor
+func (sm *SessionMixer) SetRoutingPanel(routing *RoutingPanel) {
+	sm.routing = routing
+}
+
+// SetSceneManager sets the scene manager used for scene save/recall
+func (sm *SessionMixer) SetSceneManager(scenes *SceneManager) {
+	sm.scenes = scenes
+	sm.refreshSceneNames()
+}
+
+// SetCoughs sets the cough switches keybindings can hold
+func (sm *SessionMixer) SetCoughs(coughs []*CoughSwitch) {
+	sm.coughs = coughs
+}
+
+// SetStatusBar sets the status bar used to display clock and sync status
+func (sm *SessionMixer) SetStatusBar(status *StatusBar) {
+	sm.status = status
+}
+
+// SetSwitches sets the configured device switches
+func (sm *SessionMixer) SetSwitches(switches []*Switch) {
+	sm.switches = switches
+}
+
+// SetGangPicker sets the "add gang" dialog
+func (sm *SessionMixer) SetGangPicker(picker *GangPicker) {
+	sm.picker = picker
+}
+
+// SetProfiles sets the profile manager; the mixer applies requested switches each frame
+func (sm *SessionMixer) SetProfiles(profiles *ProfileManager) {
+	sm.profiles = profiles
+}
+
+// SetWindowPrefs sets the window geometries remembered per profile, saved to path
+func (sm *SessionMixer) SetWindowPrefs(prefs *WindowPrefs, path string) {
+	sm.window = &windowTracker{prefs: prefs, path: path}
+}
+
+// SetWindowHost sets the host used to move and resize the window on profile switches; must be
+// called after SetWindowPrefs
+func (sm *SessionMixer) SetWindowHost(host WindowHost) {
+	if sm.window != nil {
+		sm.window.host = host
+	}
+}
+
+// SaveWindowGeometry remembers the last window geometry for the current profile
+func (sm *SessionMixer) SaveWindowGeometry() error {
+	if sm.window == nil {
+		return nil
+	}
+	return sm.window.save(sm.currentProfile())
+}
+
+// currentProfile returns the active profile name
+func (sm *SessionMixer) currentProfile() string {
+	if sm.profiles == nil {
+		return DefaultProfile
+	}
+	return sm.profiles.GetCurrent()
+}
+
+// SetConfigEditor sets the "edit gangs" dialog
+func (sm *SessionMixer) SetConfigEditor(editor *ConfigEditor) {
+	sm.editor = editor
+}
+
+// SetDisplayPrefs sets the dB/raw value display choices, saved to path when changed
+func (sm *SessionMixer) SetDisplayPrefs(display *DisplayPrefs, path string) {
+	sm.display = display
+	sm.displayPath = path
+	display.Apply(sm.gangs)
+}
+
+// SetSurface sets the control surface whose bank is shown above the fader bank (may be nil)
+func (sm *SessionMixer) SetSurface(surface *Surface) {
+	sm.surface = surface
+}
+
+// SetAutogainRunner sets the multi-input autogain runner (may be nil)
+func (sm *SessionMixer) SetAutogainRunner(autogain *AutogainRunner) {
+	sm.autogain = autogain
+}
+
+// SetListenBus sets the PFL/AFL listen bus used by the per-gang listen buttons (may be nil)
+func (sm *SessionMixer) SetListenBus(listen *ListenBus) {
+	sm.listen = listen
+}
+
+// SetGainStager sets the gain staging assistant shown below the input settings
+func (sm *SessionMixer) SetGainStager(stager *GainStager) {
+	sm.stager = stager
+}
+
+// SetClipLog sets the clip log shown in the clip summary panel
+func (sm *SessionMixer) SetClipLog(clips *ClipLog) {
+	sm.clips = clips
+}
+
 // GetCard returns the scarlettctl card
 func (sm *SessionMixer) GetCard() *scarlettctl.Card {
 	return sm.card
@@ -1,69 +1,345 @@
 package sessionmixer
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/michaelquigley/scarlettctl"
 )
 
+const (
+	// pollTick is the resolution of control polling
+	pollTick = 50 * time.Millisecond
+
+	// Event watcher restart backoff, doubling from min to max after each failure
+	minRestartBackoff = time.Second
+	maxRestartBackoff = 30 * time.Second
+
+	// stableWatch is how long the watcher must run before the restart backoff resets
+	stableWatch = time.Minute
+)
+
+// polledControl is a control re-read periodically because it emits no hardware events
+type polledControl struct {
+	control  *scarlettctl.Control
+	interval time.Duration
+	next     time.Time
+}
+
+// controlTarget is where a hardware change to a control is dispatched: the gang owning it,
+// or a standalone channel, and the gangs metering it with event-fed levels
+type controlTarget struct {
+	gang    *GangedFader
+	channel *MixerChannel
+	levels  []*GangedFader
+}
+
 // EventMonitor handles hardware change events from scarlettctl
 // Implements the Hardware → UI flow in the bidirectional update strategy
 type EventMonitor struct {
-	card    *scarlettctl.Card
-	gangs   []*GangedFader
+	card     *scarlettctl.Card
+	gangs    []*GangedFader
+	channels []*MixerChannel // Standalone channels not owned by a gang (e.g. input settings)
+
+	// Control NumID -> dispatch target; rebuilt when the gangs or channels change
+	indexMu sync.RWMutex
+	index   map[uint]controlTarget
+
+	// The current watcher; replaced on every supervised restart
+	mu      sync.Mutex
 	monitor *scarlettctl.EventMonitor
+	lastErr error // Error that stopped the last watcher (nil if none failed)
+
+	// Polling fallback
+	polled   []*polledControl
+	fallback time.Duration // Poll every monitored control at this interval once events fail (0 = off)
+	failed   atomic.Bool   // Set while the watcher is down after an error
+	restarts atomic.Int32  // Number of watcher restarts
+
+	started   atomic.Bool
+	stopOnce  sync.Once
+	stop      chan struct{}
+	watchDone chan struct{}
+	done      chan struct{}
 }
 
 // NewEventMonitor creates a new event monitor
 func NewEventMonitor(card *scarlettctl.Card, gangs []*GangedFader) *EventMonitor {
-	return &EventMonitor{
-		card:    card,
-		gangs:   gangs,
-		monitor: card.NewEventMonitor(),
+	em := &EventMonitor{
+		card:      card,
+		gangs:     gangs,
+		stop:      make(chan struct{}),
+		watchDone: make(chan struct{}),
+		done:      make(chan struct{}),
 	}
+	em.reindex()
+	return em
 }
 
-// Start begins monitoring hardware events in a background goroutine
-// This is event-driven, not polling (per BIDIRECTIONAL_UPDATE_STRATEGY.md)
-func (em *EventMonitor) Start() error {
-	// Start watching for control changes in a goroutine
-	// WatchControls is blocking, so we run it in the background
-	go func() {
-		err := em.monitor.WatchControls(em.handleControlChange)
+// SetPolling configures polling for controls without hardware events, and the fallback used
+// when the event monitor fails; must be called before Start
+func (em *EventMonitor) SetPolling(config *PollingConfig) error {
+	em.fallback = config.Fallback
+	for name, interval := range config.Controls {
+		if interval <= 0 {
+			return fmt.Errorf("poll interval for '%s' must be positive", name)
+		}
+		control, err := findControl(em.card, name)
 		if err != nil {
-			log.Printf("Event monitor error: %v", err)
+			return fmt.Errorf("polling: %w", err)
 		}
-	}()
+		em.polled = append(em.polled, &polledControl{control: control, interval: interval})
+	}
 	return nil
 }
 
-// Stop stops the event monitor
-func (em *EventMonitor) Stop() {
-	em.monitor.Stop()
+// AddChannels registers standalone channels for hardware change notifications
+// Must be called before Start
+func (em *EventMonitor) AddChannels(channels ...*MixerChannel) {
+	em.channels = append(em.channels, channels...)
+	em.reindex()
 }
 
-// handleControlChange is the callback invoked when a hardware control changes
-// This is called from the scarlettctl event monitor goroutine
-// It uses thread-safe atomic operations to update cached values
-func (em *EventMonitor) handleControlChange(control *scarlettctl.Control, value int64) error {
-	// Check if this control belongs to a ganged fader
+// SetGangs replaces the monitored gangs (e.g. after a configuration reload) and rebuilds the
+// dispatch index; safe to call while running
+func (em *EventMonitor) SetGangs(gangs []*GangedFader) {
+	em.indexMu.Lock()
+	em.gangs = gangs
+	em.indexMu.Unlock()
+	em.reindex()
+}
+
+// reindex rebuilds the NumID dispatch index; a control owned by several gangs, or by a gang
+// and a standalone channel, is dispatched to the first gang
+func (em *EventMonitor) reindex() {
+	em.indexMu.Lock()
+	defer em.indexMu.Unlock()
+	em.index = make(map[uint]controlTarget)
 	for _, gang := range em.gangs {
 		for _, ch := range gang.GetChannels() {
-			if ch.GetControl().NumID == control.NumID {
-				// Update the gang's cached value
-				// HandleHWChange has value equality check
-				gang.HandleHWChange(control.NumID, value)
-				return nil
+			if _, ok := em.index[ch.GetControl().NumID]; !ok {
+				em.index[ch.GetControl().NumID] = controlTarget{gang: gang}
+			}
+		}
+	}
+	for _, ch := range em.channels {
+		if _, ok := em.index[ch.GetControl().NumID]; !ok {
+			em.index[ch.GetControl().NumID] = controlTarget{channel: ch}
+		}
+	}
+	for _, gang := range em.gangs {
+		if !gang.HasLevelEvents() {
+			continue
+		}
+		for _, ctl := range gang.GetLevelControls() {
+			target := em.index[ctl.NumID]
+			target.levels = append(target.levels, gang)
+			em.index[ctl.NumID] = target
+		}
+	}
+}
+
+// Start begins monitoring hardware events in a background goroutine
+// This is event-driven, not polling (per BIDIRECTIONAL_UPDATE_STRATEGY.md); controls without
+// events, and every control while the watcher is down, are polled if configured
+func (em *EventMonitor) Start() error {
+	em.started.Store(true)
+	go em.watch()
+	go em.poll()
+	return nil
+}
+
+// Stop stops the watcher and polling; blocks until both have exited, so the card can be
+// closed or the configuration reloaded safely afterwards
+func (em *EventMonitor) Stop() {
+	em.stopOnce.Do(func() {
+		close(em.stop)
+		em.mu.Lock()
+		if em.monitor != nil {
+			em.monitor.Stop()
+		}
+		em.mu.Unlock()
+		if em.started.Load() {
+			<-em.watchDone
+			<-em.done
+		}
+	})
+}
+
+// watch runs the scarlettctl watcher, restarting it with exponential backoff whenever it fails
+// WatchControls is blocking, so this runs in the background
+func (em *EventMonitor) watch() {
+	defer close(em.watchDone)
+	backoff := minRestartBackoff
+	for {
+		em.mu.Lock()
+		select {
+		case <-em.stop:
+			em.mu.Unlock()
+			return
+		default:
+		}
+		monitor := em.card.NewEventMonitor()
+		em.monitor = monitor
+		em.mu.Unlock()
+
+		started := time.Now()
+		err := monitor.WatchControls(em.handleControlChange)
+		select {
+		case <-em.stop:
+			return
+		default:
+		}
+		if err == nil {
+			err = errors.New("watcher exited unexpectedly")
+		}
+		if time.Since(started) >= stableWatch {
+			backoff = minRestartBackoff
+		}
+
+		em.mu.Lock()
+		em.lastErr = err
+		em.mu.Unlock()
+		em.failed.Store(true)
+		if em.fallback > 0 {
+			log.Printf("Event monitor error: %v (restarting in %s, polling every %s)", err, backoff, em.fallback)
+		} else {
+			log.Printf("Event monitor error: %v (restarting in %s)", err, backoff)
+		}
+
+		select {
+		case <-em.stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxRestartBackoff)
+		em.restarts.Add(1)
+		em.failed.Store(false)
+	}
+}
+
+// HasFailed returns true while the watcher is down after an error
+func (em *EventMonitor) HasFailed() bool {
+	return em.failed.Load()
+}
+
+// GetLastError returns the error that stopped the last failed watcher, or nil
+func (em *EventMonitor) GetLastError() error {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	return em.lastErr
+}
+
+// GetRestarts returns the number of times the watcher has been restarted
+func (em *EventMonitor) GetRestarts() int {
+	return int(em.restarts.Load())
+}
+
+// GetFallback returns the fallback poll interval (0 = no fallback)
+func (em *EventMonitor) GetFallback() time.Duration {
+	return em.fallback
+}
+
+// poll re-reads the polled controls when due, and every monitored control at the fallback
+// interval once the event monitor has failed
+func (em *EventMonitor) poll() {
+	defer close(em.done)
+	if len(em.polled) == 0 && em.fallback <= 0 {
+		<-em.stop
+		return
+	}
+
+	ticker := time.NewTicker(pollTick)
+	defer ticker.Stop()
+	var nextFallback time.Time
+	for {
+		select {
+		case <-em.stop:
+			return
+		case now := <-ticker.C:
+			for _, pc := range em.polled {
+				if now.Before(pc.next) {
+					continue
+				}
+				pc.next = now.Add(pc.interval)
+				em.pollControl(pc.control)
+			}
+			if em.fallback > 0 && em.failed.Load() && !now.Before(nextFallback) {
+				nextFallback = now.Add(em.fallback)
+				for _, control := range em.monitoredControls() {
+					em.pollControl(control)
+				}
 			}
 		}
 	}
+}
+
+// pollControl reads a control and handles its value as a hardware change
+func (em *EventMonitor) pollControl(control *scarlettctl.Control) {
+	value, err := control.GetValue()
+	if err != nil {
+		return
+	}
+	em.handleControlChange(control, value)
+}
+
+// monitoredControls returns the controls of every gang and standalone channel, and the
+// event-fed level controls
+func (em *EventMonitor) monitoredControls() []*scarlettctl.Control {
+	em.indexMu.RLock()
+	defer em.indexMu.RUnlock()
+	var controls []*scarlettctl.Control
+	for _, gang := range em.gangs {
+		for _, ch := range gang.GetChannels() {
+			controls = append(controls, ch.GetControl())
+		}
+		if gang.HasLevelEvents() {
+			controls = append(controls, gang.GetLevelControls()...)
+		}
+	}
+	for _, ch := range em.channels {
+		controls = append(controls, ch.GetControl())
+	}
+	return controls
+}
+
+// handleControlChange is the callback invoked when a hardware control changes
+// This is called from the scarlettctl event monitor goroutine for every control on the card,
+// so dispatch is a single index lookup; it uses thread-safe atomic operations to update
+// cached values
+func (em *EventMonitor) handleControlChange(control *scarlettctl.Control, value int64) error {
+	Stats.RecordEvent(control, value)
+	em.indexMu.RLock()
+	target, ok := em.index[control.NumID]
+	em.indexMu.RUnlock()
+	changeLog.record(control, value, target.gang)
 
 	// Control not found in our configuration (this is okay - we might not be
 	// monitoring all controls on the card)
+	if !ok {
+		return nil
+	}
+	captureEvent(control, value)
+
+	// HandleHWChange has value equality check
+	if target.gang != nil {
+		target.gang.HandleHWChange(control.NumID, value)
+	} else if target.channel != nil {
+		target.channel.HandleHWChange(value)
+	}
+	for _, gang := range target.levels {
+		gang.HandleLevelChange(control.NumID, value)
+	}
 	return nil
 }
 
-// GetMonitor returns the underlying scarlettctl event monitor
+// GetMonitor returns the current underlying scarlettctl event monitor (nil before Start)
 func (em *EventMonitor) GetMonitor() *scarlettctl.EventMonitor {
+	em.mu.Lock()
+	defer em.mu.Unlock()
 	return em.monitor
 }
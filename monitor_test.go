@@ -0,0 +1,122 @@
+package sessionmixer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// event delivers a hardware change for numID to the monitor, as the scarlettctl watcher would
+func event(t *testing.T, em *EventMonitor, numID uint, value int64) {
+	t.Helper()
+	if err := em.handleControlChange(&scarlettctl.Control{NumID: numID, Name: "event"}, value); err != nil {
+		t.Fatalf("handleControlChange: %v", err)
+	}
+}
+
+func TestEventMonitorDispatchesToGang(t *testing.T) {
+	gang, fakes := newFakeGang(t, "Vocal", 0, 160, 80, 1, 2)
+	em := NewEventMonitor(nil, []*GangedFader{gang})
+
+	event(t, em, 2, 60)
+	if got := gang.GetCurrentValue(); got != 60 {
+		t.Errorf("gang value = %d, want 60", got)
+	}
+	if got := gang.GetChannels()[1].GetCurrentValue(); got != 60 {
+		t.Errorf("channel value = %d, want 60", got)
+	}
+
+	// Dispatch only updates caches; nothing is written back to the hardware
+	for i, fake := range fakes {
+		if got := fake.getWrites(); len(got) != 0 {
+			t.Errorf("channel %d writes = %v, want none", i, got)
+		}
+	}
+}
+
+func TestEventMonitorIgnoresUnknownControls(t *testing.T) {
+	gang, _ := newFakeGang(t, "Vocal", 0, 160, 80, 1)
+	em := NewEventMonitor(nil, []*GangedFader{gang})
+
+	event(t, em, 99, 10)
+	if got := gang.GetCurrentValue(); got != 80 {
+		t.Errorf("gang value = %d, want 80", got)
+	}
+}
+
+func TestEventMonitorDispatchesToStandaloneChannel(t *testing.T) {
+	ch, _ := newFakeChannel(t, 5, "Line In 1 Level", 0, 1, 0)
+	em := NewEventMonitor(nil, nil)
+	em.AddChannels(ch)
+
+	event(t, em, 5, 1)
+	if got := ch.GetCurrentValue(); got != 1 {
+		t.Errorf("channel value = %d, want 1", got)
+	}
+}
+
+func TestEventMonitorPrefersGangOverChannel(t *testing.T) {
+	gang, _ := newFakeGang(t, "Vocal", 0, 160, 80, 1)
+	standalone, _ := newFakeChannel(t, 1, "Mix A Input 01", 0, 160, 80)
+	em := NewEventMonitor(nil, []*GangedFader{gang})
+	em.AddChannels(standalone)
+
+	event(t, em, 1, 40)
+	if got := gang.GetCurrentValue(); got != 40 {
+		t.Errorf("gang value = %d, want 40", got)
+	}
+	if got := standalone.GetCurrentValue(); got != 80 {
+		t.Errorf("standalone channel value = %d, want 80 (dispatched to the gang only)", got)
+	}
+}
+
+func TestEventMonitorSetGangsReindexes(t *testing.T) {
+	oldGang, _ := newFakeGang(t, "Vocal", 0, 160, 80, 1)
+	newGang, _ := newFakeGang(t, "Guitar", 0, 160, 80, 2)
+	em := NewEventMonitor(nil, []*GangedFader{oldGang})
+
+	em.SetGangs([]*GangedFader{newGang})
+	event(t, em, 1, 10)
+	event(t, em, 2, 20)
+	if got := oldGang.GetCurrentValue(); got != 80 {
+		t.Errorf("old gang value = %d, want 80", got)
+	}
+	if got := newGang.GetCurrentValue(); got != 20 {
+		t.Errorf("new gang value = %d, want 20", got)
+	}
+}
+
+func TestEventMonitorDispatchesLevelEvents(t *testing.T) {
+	ch, _ := newFakeChannel(t, 1, "Mix A Input 01", 0, 160, 80)
+	level := &scarlettctl.Control{NumID: 10, Name: "Level Meter", Min: 0, Max: 4095}
+	gang, err := NewGangedFader("Vocal", "db", GangModeMirror, []*MixerChannel{ch}, []*scarlettctl.Control{level}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gang.SetLevelEvents(true)
+	em := NewEventMonitor(nil, []*GangedFader{gang})
+
+	event(t, em, 10, 2048)
+	if got, ok := gang.GetMaxLevel(); !ok || got != 2048 {
+		t.Errorf("GetMaxLevel() = %d, %v; want 2048", got, ok)
+	}
+	if got := gang.GetCurrentValue(); got != 80 {
+		t.Errorf("gang value = %d, want 80 (level events do not move the fader)", got)
+	}
+}
+
+func TestEventMonitorMonitoredControls(t *testing.T) {
+	gang, _ := newFakeGang(t, "Vocal", 0, 160, 80, 1, 2)
+	ch, _ := newFakeChannel(t, 5, "Line In 1 Level", 0, 1, 0)
+	em := NewEventMonitor(nil, []*GangedFader{gang})
+	em.AddChannels(ch)
+
+	var numIDs []uint
+	for _, control := range em.monitoredControls() {
+		numIDs = append(numIDs, control.NumID)
+	}
+	if !slices.Equal(numIDs, []uint{1, 2, 5}) {
+		t.Errorf("monitored NumIDs = %v, want [1 2 5]", numIDs)
+	}
+}
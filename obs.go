@@ -0,0 +1,335 @@
+package sessionmixer
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// obs-websocket v5 opcodes
+	obsOpHello           = 0
+	obsOpIdentify        = 1
+	obsOpIdentified      = 2
+	obsOpEvent           = 5
+	obsOpRequest         = 6
+	obsOpRequestResponse = 7
+
+	// obsEventScenes subscribes to scene events only
+	obsEventScenes = 1 << 2
+
+	// obsDialTimeout bounds connecting and identifying
+	obsDialTimeout = 5 * time.Second
+
+	// obsMuteInterval is how often gang mute states are compared and pushed to OBS
+	obsMuteInterval = 100 * time.Millisecond
+)
+
+// obsMessage is an obs-websocket v5 message envelope
+type obsMessage struct {
+	Op int             `json:"op"`
+	D  json.RawMessage `json:"d"`
+}
+
+// OBSClient links OBS to the mixer over obs-websocket (v5): switching the OBS program scene
+// recalls a mixer scene and mutes or unmutes gangs, and gang mute states are mirrored onto
+// OBS inputs. The connection is retried with backoff while OBS is not running
+type OBSClient struct {
+	config OBSConfig
+	scenes *SceneManager // nil if scenes are unavailable
+
+	mu    sync.Mutex
+	gangs []*GangedFader
+	ws    *wsConn
+
+	connected atomic.Bool
+	requestID atomic.Int64
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewOBSClient validates the OBS links against the gangs; scenes may be nil if no link recalls
+// a scene
+func NewOBSClient(config OBSConfig, gangs []*GangedFader, scenes *SceneManager) (*OBSClient, error) {
+	for i, link := range config.Scenes {
+		if link.Scene != "" && scenes == nil {
+			return nil, fmt.Errorf("obs scene %d (%s): scenes are not available", i, link.ObsScene)
+		}
+		for _, name := range append(append([]string{}, link.Mute...), link.Unmute...) {
+			if findGang(gangs, name) == nil {
+				return nil, fmt.Errorf("obs scene %d (%s): unknown gang '%s'", i, link.ObsScene, name)
+			}
+		}
+	}
+	for i, link := range config.Inputs {
+		if findGang(gangs, link.Gang) == nil {
+			return nil, fmt.Errorf("obs input %d (%s): unknown gang '%s'", i, link.Input, link.Gang)
+		}
+	}
+	return &OBSClient{
+		config: config,
+		scenes: scenes,
+		gangs:  gangs,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// SetGangs replaces the gangs links refer to by name (e.g. after a profile switch)
+func (oc *OBSClient) SetGangs(gangs []*GangedFader) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	oc.gangs = gangs
+}
+
+// IsConnected returns true while identified with OBS
+func (oc *OBSClient) IsConnected() bool {
+	return oc.connected.Load()
+}
+
+// Start connects in the background
+func (oc *OBSClient) Start() {
+	go oc.run()
+}
+
+// Stop disconnects; blocks until the background goroutine has exited
+func (oc *OBSClient) Stop() {
+	oc.stopOnce.Do(func() {
+		close(oc.stop)
+		oc.mu.Lock()
+		if oc.ws != nil {
+			oc.ws.Close()
+		}
+		oc.mu.Unlock()
+		<-oc.done
+	})
+}
+
+// run keeps a session open, reconnecting with exponential backoff
+func (oc *OBSClient) run() {
+	defer close(oc.done)
+	backoff := minRestartBackoff
+	for {
+		started := time.Now()
+		err := oc.session()
+		oc.connected.Store(false)
+		select {
+		case <-oc.stop:
+			return
+		default:
+		}
+		if time.Since(started) >= stableWatch {
+			backoff = minRestartBackoff
+		}
+		log.Printf("OBS: %v (retrying in %s)", err, backoff)
+		select {
+		case <-oc.stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxRestartBackoff)
+	}
+}
+
+// session connects, identifies and serves events until the connection fails
+func (oc *OBSClient) session() error {
+	ws, err := dialWebSocket(oc.config.Address, "/", "obswebsocket.json", obsDialTimeout)
+	if err != nil {
+		return err
+	}
+	oc.mu.Lock()
+	select {
+	case <-oc.stop:
+		oc.mu.Unlock()
+		ws.Close()
+		return nil
+	default:
+	}
+	oc.ws = ws
+	oc.mu.Unlock()
+	defer func() {
+		oc.mu.Lock()
+		oc.ws = nil
+		oc.mu.Unlock()
+		ws.Close()
+	}()
+
+	if err := oc.identify(ws); err != nil {
+		return err
+	}
+	oc.connected.Store(true)
+	log.Printf("OBS: connected to %s", oc.config.Address)
+
+	// Mirror mute states while events are read below
+	muteDone := make(chan struct{})
+	defer close(muteDone)
+	if len(oc.config.Inputs) > 0 {
+		go oc.mirrorMutes(ws, muteDone)
+	}
+
+	for {
+		data, err := ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+		var msg obsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return fmt.Errorf("invalid message: %w", err)
+		}
+		if msg.Op == obsOpEvent {
+			oc.handleEvent(msg.D)
+		}
+	}
+}
+
+// identify performs the Hello/Identify handshake, authenticating if OBS requires it
+func (oc *OBSClient) identify(ws *wsConn) error {
+	data, err := ws.ReadMessage()
+	if err != nil {
+		return err
+	}
+	var msg obsMessage
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Op != obsOpHello {
+		return errors.New("expected Hello from obs-websocket")
+	}
+	var hello struct {
+		Authentication *struct {
+			Challenge string `json:"challenge"`
+			Salt      string `json:"salt"`
+		} `json:"authentication"`
+	}
+	if err := json.Unmarshal(msg.D, &hello); err != nil {
+		return fmt.Errorf("invalid Hello: %w", err)
+	}
+
+	identify := map[string]any{
+		"rpcVersion":         1,
+		"eventSubscriptions": obsEventScenes,
+	}
+	if hello.Authentication != nil {
+		if oc.config.Password == "" {
+			return errors.New("OBS requires a password (obs.password)")
+		}
+		identify["authentication"] = obsAuth(oc.config.Password, hello.Authentication.Salt, hello.Authentication.Challenge)
+	}
+	if err := oc.send(ws, obsOpIdentify, identify); err != nil {
+		return err
+	}
+
+	data, err = ws.ReadMessage()
+	if err != nil {
+		return fmt.Errorf("identify failed (wrong password?): %w", err)
+	}
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Op != obsOpIdentified {
+		return errors.New("expected Identified from obs-websocket")
+	}
+	return nil
+}
+
+// obsAuth computes the obs-websocket authentication string
+func obsAuth(password, salt, challenge string) string {
+	secret := sha256.Sum256([]byte(password + salt))
+	auth := sha256.Sum256([]byte(base64.StdEncoding.EncodeToString(secret[:]) + challenge))
+	return base64.StdEncoding.EncodeToString(auth[:])
+}
+
+// send writes one message
+func (oc *OBSClient) send(ws *wsConn, op int, d any) error {
+	payload, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(obsMessage{Op: op, D: payload})
+	if err != nil {
+		return err
+	}
+	return ws.WriteMessage(data)
+}
+
+// request sends a request without waiting for its response; failures are reported by OBS in
+// a RequestResponse, which is ignored
+func (oc *OBSClient) request(ws *wsConn, requestType string, data any) error {
+	return oc.send(ws, obsOpRequest, map[string]any{
+		"requestType": requestType,
+		"requestId":   strconv.FormatInt(oc.requestID.Add(1), 10),
+		"requestData": data,
+	})
+}
+
+// handleEvent applies the links of the new OBS program scene
+func (oc *OBSClient) handleEvent(d json.RawMessage) {
+	var event struct {
+		EventType string `json:"eventType"`
+		EventData struct {
+			SceneName string `json:"sceneName"`
+		} `json:"eventData"`
+	}
+	if err := json.Unmarshal(d, &event); err != nil || event.EventType != "CurrentProgramSceneChanged" {
+		return
+	}
+
+	oc.mu.Lock()
+	gangs := oc.gangs
+	oc.mu.Unlock()
+	for _, link := range oc.config.Scenes {
+		if link.ObsScene != event.EventData.SceneName {
+			continue
+		}
+		if link.Scene != "" {
+			if err := oc.scenes.Recall(link.Scene); err != nil {
+				log.Printf("OBS: failed to recall scene '%s': %v", link.Scene, err)
+			}
+		}
+		for _, name := range link.Mute {
+			if gang := findGang(gangs, name); gang != nil && !gang.IsLocked() && !gang.IsMuted() {
+				logError(gang.Mute())
+			}
+		}
+		for _, name := range link.Unmute {
+			if gang := findGang(gangs, name); gang != nil && !gang.IsLocked() && gang.IsMuted() {
+				logError(gang.Unmute())
+			}
+		}
+	}
+}
+
+// mirrorMutes pushes the mute state of linked gangs to their OBS inputs, initially and on
+// every change
+func (oc *OBSClient) mirrorMutes(ws *wsConn, done <-chan struct{}) {
+	sent := make(map[string]bool) // OBS input -> mute state last sent
+	ticker := time.NewTicker(obsMuteInterval)
+	defer ticker.Stop()
+	for {
+		oc.mu.Lock()
+		gangs := oc.gangs
+		oc.mu.Unlock()
+		for _, link := range oc.config.Inputs {
+			gang := findGang(gangs, link.Gang)
+			if gang == nil {
+				continue
+			}
+			muted := gang.IsMuted()
+			if last, ok := sent[link.Input]; ok && last == muted {
+				continue
+			}
+			if err := oc.request(ws, "SetInputMute", map[string]any{"inputName": link.Input, "inputMuted": muted}); err != nil {
+				return // The read loop sees the failure and reconnects
+			}
+			sent[link.Input] = muted
+		}
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
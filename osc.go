@@ -0,0 +1,411 @@
+package sessionmixer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// oscFeedbackInterval is how often gang changes are sent back to OSC clients
+	oscFeedbackInterval = 50 * time.Millisecond
+
+	// oscClientTimeout is how long a client that has sent nothing keeps receiving feedback
+	oscClientTimeout = 10 * time.Minute
+)
+
+// OSC namespace of the mixer; gangs are numbered from 1 in bank order
+//
+//	/gang/<n>/fader f  fader position (0-1), in and out
+//	/gang/<n>/mute  i  1 muted, 0 unmuted, in and out
+//	/scene/recall   s  recall a scene by name
+const (
+	oscGangPrefix  = "/gang/"
+	oscSceneRecall = "/scene/recall"
+)
+
+// OSCGangAddress returns the address of a gang's fader or mute (n counts from 1)
+func OSCGangAddress(n int, control string) string {
+	return fmt.Sprintf("%s%d/%s", oscGangPrefix, n, control)
+}
+
+// OSCMessage is an OSC message with int32, float32 and string arguments
+type OSCMessage struct {
+	Address string
+	Args    []any
+}
+
+// encodeOSC encodes a message; arguments of other types are an error
+func encodeOSC(msg OSCMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	writeOSCString(&buf, msg.Address)
+	tags := ","
+	var args bytes.Buffer
+	for _, arg := range msg.Args {
+		switch v := arg.(type) {
+		case int32:
+			tags += "i"
+			binary.Write(&args, binary.BigEndian, v)
+		case float32:
+			tags += "f"
+			binary.Write(&args, binary.BigEndian, v)
+		case string:
+			tags += "s"
+			writeOSCString(&args, v)
+		default:
+			return nil, fmt.Errorf("unsupported OSC argument %T", arg)
+		}
+	}
+	writeOSCString(&buf, tags)
+	buf.Write(args.Bytes())
+	return buf.Bytes(), nil
+}
+
+// writeOSCString writes a null-terminated string padded to a multiple of 4 bytes
+func writeOSCString(buf *bytes.Buffer, s string) {
+	buf.WriteString(s)
+	buf.Write(make([]byte, 4-len(s)%4))
+}
+
+// decodeOSC decodes a packet, a message or a bundle of them, passing each message to handler;
+// unsupported argument types end the message
+func decodeOSC(packet []byte, handler func(OSCMessage)) error {
+	if bytes.HasPrefix(packet, []byte("#bundle\x00")) {
+		rest := packet[min(16, len(packet)):] // Skip the time tag
+		for len(rest) >= 4 {
+			size := int(binary.BigEndian.Uint32(rest))
+			if 4+size > len(rest) {
+				return errors.New("truncated OSC bundle")
+			}
+			if err := decodeOSC(rest[4:4+size], handler); err != nil {
+				return err
+			}
+			rest = rest[4+size:]
+		}
+		return nil
+	}
+
+	address, rest, err := readOSCString(packet)
+	if err != nil {
+		return err
+	}
+	msg := OSCMessage{Address: address}
+	if len(rest) == 0 {
+		handler(msg) // No type tag string (old senders)
+		return nil
+	}
+	tags, rest, err := readOSCString(rest)
+	if err != nil {
+		return err
+	}
+	for _, tag := range strings.TrimPrefix(tags, ",") {
+		switch tag {
+		case 'i', 'f':
+			if len(rest) < 4 {
+				return errors.New("truncated OSC argument")
+			}
+			bits := binary.BigEndian.Uint32(rest)
+			rest = rest[4:]
+			if tag == 'i' {
+				msg.Args = append(msg.Args, int32(bits))
+			} else {
+				msg.Args = append(msg.Args, math.Float32frombits(bits))
+			}
+		case 's':
+			var s string
+			if s, rest, err = readOSCString(rest); err != nil {
+				return err
+			}
+			msg.Args = append(msg.Args, s)
+		case 'T', 'F':
+			msg.Args = append(msg.Args, boolToInt32(tag == 'T'))
+		default:
+			handler(msg)
+			return nil
+		}
+	}
+	handler(msg)
+	return nil
+}
+
+// readOSCString reads a padded OSC string, returning it and the bytes after it
+func readOSCString(data []byte) (string, []byte, error) {
+	end := bytes.IndexByte(data, 0)
+	if end < 0 {
+		return "", nil, errors.New("unterminated OSC string")
+	}
+	next := min(len(data), (end/4+1)*4)
+	return string(data[:end]), data[next:], nil
+}
+
+func boolToInt32(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// oscNumber returns a message's first argument as a number
+func oscNumber(msg OSCMessage) (float64, bool) {
+	if len(msg.Args) == 0 {
+		return 0, false
+	}
+	switch v := msg.Args[0].(type) {
+	case int32:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// oscClient is an address that sent to the server and receives feedback
+type oscClient struct {
+	addr     *net.UDPAddr
+	lastSeen time.Time
+	sent     map[string]any // Value last sent (or received) per address
+}
+
+// OSCServer lets OSC controllers such as TouchOSC or Open Stage Control drive the gangs and
+// recall scenes over UDP; every client that sends a message receives the gang positions and
+// mutes back, so its faders follow changes made elsewhere
+type OSCServer struct {
+	listen   string
+	scenes   *SceneManager // nil if scene recalls are unavailable
+	smoother *Smoother     // nil without smoothing
+	conn     *net.UDPConn
+
+	mu      sync.Mutex
+	gangs   []*GangedFader
+	clients map[string]*oscClient
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewOSCServer creates an OSC server for the gangs on the configured address
+func NewOSCServer(config OSCConfig, gangs []*GangedFader, scenes *SceneManager) (*OSCServer, error) {
+	var smoother *Smoother
+	if config.Smoothing != nil {
+		var err error
+		smoother, err = NewSmoother(*config.Smoothing, WriteSourceOSC, (*GangedFader).HandleRampedChange)
+		if err != nil {
+			return nil, fmt.Errorf("OSC: %w", err)
+		}
+	}
+	return &OSCServer{
+		listen:   config.Listen,
+		scenes:   scenes,
+		smoother: smoother,
+		gangs:    gangs,
+		clients:  make(map[string]*oscClient),
+		stop:     make(chan struct{}),
+	}, nil
+}
+
+// Start listens for OSC messages and sends feedback in background goroutines
+func (osc *OSCServer) Start() error {
+	addr, err := net.ResolveUDPAddr("udp", osc.listen)
+	if err != nil {
+		return fmt.Errorf("invalid OSC listen address '%s': %w", osc.listen, err)
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", osc.listen, err)
+	}
+	osc.conn = conn
+	if osc.smoother != nil {
+		osc.smoother.Start()
+	}
+	osc.wg.Add(2)
+	go osc.read()
+	go func() {
+		defer osc.wg.Done()
+		ticker := time.NewTicker(oscFeedbackInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-osc.stop:
+				return
+			case <-ticker.C:
+				osc.sendFeedback()
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop closes the socket; blocks until the goroutines have exited
+func (osc *OSCServer) Stop() {
+	osc.stopOnce.Do(func() {
+		close(osc.stop)
+		if osc.conn != nil {
+			osc.conn.Close()
+		}
+		osc.wg.Wait()
+		if osc.smoother != nil {
+			osc.smoother.Stop()
+		}
+	})
+}
+
+// GetAddr returns the address the server is listening on
+func (osc *OSCServer) GetAddr() string {
+	return osc.conn.LocalAddr().String()
+}
+
+// SetGangs replaces the gangs (e.g. after a profile switch); clients get every value again
+func (osc *OSCServer) SetGangs(gangs []*GangedFader) {
+	osc.mu.Lock()
+	defer osc.mu.Unlock()
+	osc.gangs = gangs
+	if osc.smoother != nil {
+		osc.smoother.Clear()
+	}
+	for _, client := range osc.clients {
+		clear(client.sent)
+	}
+}
+
+// read handles incoming packets until the socket is closed
+func (osc *OSCServer) read() {
+	defer osc.wg.Done()
+	buf := make([]byte, 65536)
+	for {
+		n, from, err := osc.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-osc.stop:
+			default:
+				log.Printf("OSC: read failed: %v", err)
+			}
+			return
+		}
+		client := osc.touch(from)
+		err = decodeOSC(buf[:n], func(msg OSCMessage) {
+			if err := osc.handle(client, msg); err != nil {
+				log.Printf("OSC: %s %s: %v", from, msg.Address, err)
+			}
+		})
+		if err != nil {
+			log.Printf("OSC: %s: %v", from, err)
+		}
+	}
+}
+
+// touch registers a client as active, returning it
+func (osc *OSCServer) touch(addr *net.UDPAddr) *oscClient {
+	osc.mu.Lock()
+	defer osc.mu.Unlock()
+	client, ok := osc.clients[addr.String()]
+	if !ok {
+		client = &oscClient{addr: addr, sent: make(map[string]any)}
+		osc.clients[addr.String()] = client
+	}
+	client.lastSeen = time.Now()
+	return client
+}
+
+// handle applies a message from a client
+func (osc *OSCServer) handle(client *oscClient, msg OSCMessage) error {
+	if msg.Address == oscSceneRecall {
+		if osc.scenes == nil {
+			return errors.New("scenes are not available")
+		}
+		if len(msg.Args) == 0 {
+			return errors.New("missing scene name")
+		}
+		name, ok := msg.Args[0].(string)
+		if !ok {
+			return errors.New("scene name must be a string")
+		}
+		return osc.scenes.Recall(name)
+	}
+
+	index, control, ok := strings.Cut(strings.TrimPrefix(msg.Address, oscGangPrefix), "/")
+	if !strings.HasPrefix(msg.Address, oscGangPrefix) || !ok {
+		return errors.New("unknown address")
+	}
+	n, err := strconv.Atoi(index)
+	value, hasValue := oscNumber(msg)
+	osc.mu.Lock()
+	var gang *GangedFader
+	if err == nil && n >= 1 && n <= len(osc.gangs) {
+		gang = osc.gangs[n-1]
+	}
+	osc.mu.Unlock()
+	switch {
+	case gang == nil:
+		return fmt.Errorf("no gang %s", index)
+	case !hasValue:
+		return errors.New("missing value")
+	case gang.IsLocked() || gang.IsReadOnly() || !gang.Claim(WriteSourceOSC):
+		return nil
+	}
+
+	switch control {
+	case "fader":
+		if osc.smoother != nil && !osc.smoother.Set(gang, value) {
+			return nil // Jitter
+		}
+		osc.mu.Lock()
+		client.sent[msg.Address] = float32(value) // The client already shows this position
+		osc.mu.Unlock()
+		if osc.smoother != nil {
+			return nil // The smoother writes it
+		}
+		return gang.HandleRampedChange(gang.PositionToValue(value))
+	case "mute":
+		if value != 0 {
+			return gang.Mute()
+		}
+		return gang.Unmute()
+	}
+	return errors.New("unknown address")
+}
+
+// sendFeedback sends every gang position and mute that changed since it was last sent to each
+// active client, and forgets clients that have gone quiet
+func (osc *OSCServer) sendFeedback() {
+	osc.mu.Lock()
+	defer osc.mu.Unlock()
+
+	now := time.Now()
+	var msgs []OSCMessage
+	for i, gang := range osc.gangs {
+		// A gang still being smoothed is not sent, or clients would see it lag behind their faders
+		if osc.smoother == nil || !osc.smoother.Pending(gang) {
+			msgs = append(msgs, OSCMessage{Address: OSCGangAddress(i+1, "fader"), Args: []any{float32(gang.ValueToPosition(gang.GetCurrentValue()))}})
+		}
+		msgs = append(msgs, OSCMessage{Address: OSCGangAddress(i+1, "mute"), Args: []any{boolToInt32(gang.IsMuted())}})
+	}
+	for key, client := range osc.clients {
+		if now.Sub(client.lastSeen) > oscClientTimeout {
+			delete(osc.clients, key)
+			continue
+		}
+		for _, msg := range msgs {
+			if client.sent[msg.Address] == msg.Args[0] {
+				continue
+			}
+			data, err := encodeOSC(msg)
+			if err != nil {
+				continue
+			}
+			if _, err := osc.conn.WriteToUDP(data, client.addr); err != nil {
+				log.Printf("OSC: failed to send feedback to %s: %v", client.addr, err)
+				break
+			}
+			client.sent[msg.Address] = msg.Args[0]
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package sessionmixer
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestOSCRoundTrip(t *testing.T) {
+	msgs := []OSCMessage{
+		{Address: OSCGangAddress(1, "fader"), Args: []any{float32(0.75)}},
+		{Address: OSCGangAddress(12, "mute"), Args: []any{int32(1)}},
+		{Address: oscSceneRecall, Args: []any{"show"}},
+		{Address: "/ping"},
+	}
+	for _, msg := range msgs {
+		data, err := encodeOSC(msg)
+		if err != nil {
+			t.Fatalf("encodeOSC(%v): %v", msg, err)
+		}
+		if len(data)%4 != 0 {
+			t.Errorf("%s: packet length %d is not padded", msg.Address, len(data))
+		}
+		var got []OSCMessage
+		if err := decodeOSC(data, func(m OSCMessage) { got = append(got, m) }); err != nil {
+			t.Fatalf("decodeOSC(%s): %v", msg.Address, err)
+		}
+		if len(got) != 1 || !reflect.DeepEqual(got[0], msg) {
+			t.Errorf("%s: decoded %v", msg.Address, got)
+		}
+	}
+}
+
+func TestOSCBundle(t *testing.T) {
+	bundle := append([]byte("#bundle\x00"), make([]byte, 8)...)
+	for _, msg := range []OSCMessage{
+		{Address: "/gang/1/fader", Args: []any{float32(0.5)}},
+		{Address: "/gang/2/mute", Args: []any{int32(0)}},
+	} {
+		data, _ := encodeOSC(msg)
+		bundle = binary.BigEndian.AppendUint32(bundle, uint32(len(data)))
+		bundle = append(bundle, data...)
+	}
+	var addresses []string
+	if err := decodeOSC(bundle, func(m OSCMessage) { addresses = append(addresses, m.Address) }); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(addresses, []string{"/gang/1/fader", "/gang/2/mute"}) {
+		t.Errorf("bundle decoded to %v", addresses)
+	}
+
+	if err := decodeOSC(bundle[:len(bundle)-4], func(OSCMessage) {}); err == nil {
+		t.Error("truncated bundle decoded without error")
+	}
+}
+
+func TestOSCBooleanArguments(t *testing.T) {
+	// True and False carry no data; they arrive as 1 and 0
+	data := []byte("/gang/3/mute\x00\x00\x00\x00,T\x00\x00")
+	var got OSCMessage
+	if err := decodeOSC(data, func(m OSCMessage) { got = m }); err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := oscNumber(got); !ok || value != 1 {
+		t.Errorf("oscNumber = %v, %v; want 1", value, ok)
+	}
+}
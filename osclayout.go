@@ -0,0 +1,90 @@
+package sessionmixer
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Open Stage Control layout geometry (pixels)
+const (
+	oscLayoutStrip  = 90
+	oscLayoutFader  = 320
+	oscLayoutButton = 50
+	oscLayoutMargin = 10
+)
+
+// oscWidget is an Open Stage Control widget; unset properties take the editor defaults
+type oscWidget struct {
+	Type    string         `json:"type"`
+	ID      string         `json:"id"`
+	Label   string         `json:"label,omitempty"`
+	Left    int            `json:"left"`
+	Top     int            `json:"top"`
+	Width   int            `json:"width"`
+	Height  int            `json:"height"`
+	Mode    string         `json:"mode,omitempty"`
+	On      any            `json:"on,omitempty"`
+	Off     any            `json:"off,omitempty"`
+	Range   map[string]any `json:"range,omitempty"`
+	Address string         `json:"address,omitempty"`
+	Target  []string       `json:"target,omitempty"`
+	Widgets []oscWidget    `json:"widgets,omitempty"`
+}
+
+// OSCLayout generates an Open Stage Control session with a fader and a mute button per gang
+// (a toggle button for on/off gangs) and a button per scene, sending to the mixer's OSC server at
+// target (host:port); read-only gangs are left out
+func OSCLayout(gangs []*GangedFader, scenes []string, target string) ([]byte, error) {
+	targets := []string{target}
+	var widgets []oscWidget
+	left := oscLayoutMargin
+	for i, gang := range gangs {
+		if gang.IsReadOnly() {
+			continue
+		}
+		n := i + 1
+		label := gang.GetName()
+		if gang.IsToggle() {
+			widgets = append(widgets, oscWidget{
+				Type: "button", ID: fmt.Sprintf("gang_%d", n), Label: label, Mode: "toggle", On: 1, Off: 0,
+				Left: left, Top: oscLayoutMargin, Width: oscLayoutStrip - oscLayoutMargin, Height: oscLayoutButton,
+				Address: OSCGangAddress(n, "fader"), Target: targets,
+			})
+		} else {
+			widgets = append(widgets, oscWidget{
+				Type: "fader", ID: fmt.Sprintf("gang_%d", n), Label: label, Range: map[string]any{"min": 0, "max": 1},
+				Left: left, Top: oscLayoutMargin, Width: oscLayoutStrip - oscLayoutMargin, Height: oscLayoutFader,
+				Address: OSCGangAddress(n, "fader"), Target: targets,
+			})
+			widgets = append(widgets, oscWidget{
+				Type: "button", ID: fmt.Sprintf("mute_%d", n), Label: "Mute", Mode: "toggle", On: 1, Off: 0,
+				Left: left, Top: 2*oscLayoutMargin + oscLayoutFader, Width: oscLayoutStrip - oscLayoutMargin, Height: oscLayoutButton,
+				Address: OSCGangAddress(n, "mute"), Target: targets,
+			})
+		}
+		left += oscLayoutStrip
+	}
+
+	top := 4*oscLayoutMargin + oscLayoutFader + oscLayoutButton
+	left = oscLayoutMargin
+	for i, scene := range scenes {
+		// A tap button sends its "on" value on press and nothing on release
+		widgets = append(widgets, oscWidget{
+			Type: "button", ID: fmt.Sprintf("scene_%d", i+1), Label: scene, Mode: "tap", On: scene,
+			Left: left, Top: top, Width: 2*oscLayoutStrip - oscLayoutMargin, Height: oscLayoutButton,
+			Address: oscSceneRecall, Target: targets,
+		})
+		left += 2 * oscLayoutStrip
+	}
+
+	session := map[string]any{
+		"createdWith": "Open Stage Control",
+		"type":        "session",
+		"content": oscWidget{
+			Type:    "root",
+			ID:      "root",
+			Widgets: widgets,
+		},
+	}
+	return json.MarshalIndent(session, "", "  ")
+}
@@ -0,0 +1,137 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+// pageFaderWidth is the width of a restricted page fader, sized for touch screens
+const pageFaderWidth = 90
+
+// Page is a restricted view exposing only a subset of gangs (e.g. a musician's own headphone
+// mix); every other gang is hidden and locked, and the mixer's panels, scenes, profiles and
+// configuration dialogs are not shown
+type Page struct {
+	config PageConfig
+}
+
+// FindPage returns the configured page with the given name, validated against the gangs
+func FindPage(config *Config, name string, gangs []*GangedFader) (*Page, error) {
+	for _, pc := range config.Pages {
+		if pc.Name != name {
+			continue
+		}
+		for _, gang := range pc.Gangs {
+			if findGang(gangs, gang) == nil {
+				return nil, fmt.Errorf("page '%s': unknown gang '%s'", name, gang)
+			}
+		}
+		return &Page{config: pc}, nil
+	}
+	var names []string
+	for _, pc := range config.Pages {
+		names = append(names, pc.Name)
+	}
+	return nil, fmt.Errorf("unknown page '%s' (pages: %s)", name, strings.Join(names, ", "))
+}
+
+// GetName returns the page name
+func (p *Page) GetName() string {
+	return p.config.Name
+}
+
+// Allows returns true if the gang is exposed on the page
+func (p *Page) Allows(gang *GangedFader) bool {
+	return slices.Contains(p.config.Gangs, gang.GetName())
+}
+
+// Restrict locks every gang the page does not expose
+func (p *Page) Restrict(gangs []*GangedFader) {
+	for _, gang := range gangs {
+		if !p.Allows(gang) {
+			gang.SetLocked(true)
+		}
+	}
+}
+
+// SetPage restricts the mixer to a page (nil for the full mixer); profile switches restrict
+// the new gangs too
+func (sm *SessionMixer) SetPage(page *Page) {
+	sm.page = page
+	if page != nil {
+		page.Restrict(sm.gangs)
+	}
+}
+
+// drawPage renders the gangs of a restricted page as tall faders with mute buttons, in page
+// order, filling the window height
+func (sm *SessionMixer) drawPage() {
+	imgui.Text(sm.page.GetName())
+
+	height := imgui.ContentRegionAvail().Y - 3*imgui.FrameHeightWithSpacing()
+	if height < scaled(100) {
+		height = scaled(100)
+	}
+	first := true
+	for _, name := range sm.page.config.Gangs {
+		gang := findGang(sm.gangs, name)
+		if gang == nil {
+			continue // Not part of the current profile
+		}
+		if !first {
+			imgui.SameLine()
+		}
+		first = false
+		imgui.PushIDStr(name)
+		imgui.BeginGroup()
+		imgui.TextDisabled(name)
+
+		switch {
+		case gang.IsReadOnly():
+			drawReadout(gang, scaledVec2(imgui.Vec2{X: pageFaderWidth, Y: 10}))
+		case gang.IsToggle():
+			if value, changed := drawGangToggle("##page_toggle", gang); changed && !gang.IsLocked() && gang.Claim(WriteSourceUI) {
+				logError(gang.HandleUIChange(int64(value)))
+			}
+		default:
+			locked := gang.IsLocked()
+			if locked {
+				imgui.BeginDisabled()
+			}
+			value := int32(gang.GetCurrentValue())
+			if imgui.VSliderIntV("##page_fader", imgui.Vec2{X: scaled(pageFaderWidth), Y: height}, &value,
+				int32(gang.GetMin()), int32(gang.GetMax()), "", imgui.SliderFlagsNone) && !locked && gang.Claim(WriteSourceUI) {
+				logError(gang.HandleGuardedChange(int64(value)))
+			}
+			if gang.IsCapped() {
+				imgui.TextColored(warningColor, gang.FormatValue(gang.GetCurrentValue())+" CAP")
+			} else {
+				imgui.Text(gang.FormatValue(gang.GetCurrentValue()))
+			}
+			muted := gang.IsMuted()
+			if muted {
+				imgui.PushStyleColorVec4(imgui.ColButton, mutedColor)
+			}
+			if imgui.ButtonV(tr("Mute"), imgui.Vec2{X: scaled(pageFaderWidth), Y: 0}) && !locked && gang.Claim(WriteSourceUI) {
+				if muted {
+					logError(gang.Unmute())
+				} else {
+					logError(gang.Mute())
+				}
+			}
+			if muted {
+				imgui.PopStyleColor()
+			}
+			if locked {
+				imgui.EndDisabled()
+			}
+		}
+
+		imgui.EndGroup()
+		sm.trackHovered(gang)
+		imgui.PopID()
+	}
+}
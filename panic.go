@@ -0,0 +1,201 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+const (
+	// panicInterval is the step of a panic fade
+	panicInterval = 10 * time.Millisecond
+
+	// defaultPanicFade is how quickly the gangs are silenced when the config does not set it
+	defaultPanicFade = 100 * time.Millisecond
+
+	// defaultPanicRestore is how slowly the gangs come back when the config does not set it
+	defaultPanicRestore = time.Second
+)
+
+// Panic is the emergency mute: Engage quickly fades the configured output gangs to silence
+// (e.g. on feedback) and Restore fades them back to the values they had before
+// Locked gangs are silenced too; the captured values are kept by gang name, so a profile
+// switch in between restores the gangs of the same name
+type Panic struct {
+	config PanicConfig
+
+	fading sync.Mutex   // Serializes Engage and Restore
+	gen    atomic.Int64 // Bumped by each Engage and Restore; a fade stops when it changes
+
+	mu      sync.Mutex
+	gangs   []*GangedFader
+	saved   map[string]int64 // Gang name -> value before engaging (nil when not engaged)
+	engaged bool
+}
+
+// NewPanic creates the emergency mute for the configured gangs
+func NewPanic(config PanicConfig, gangs []*GangedFader) (*Panic, error) {
+	for _, name := range config.Gangs {
+		if findGang(gangs, name) == nil {
+			return nil, fmt.Errorf("panic: unknown gang '%s'", name)
+		}
+	}
+	return &Panic{config: config, gangs: gangs}, nil
+}
+
+// SetGangs replaces the gangs the configured names resolve to (e.g. after a profile switch)
+func (p *Panic) SetGangs(gangs []*GangedFader) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.gangs = gangs
+}
+
+// IsEngaged returns true between Engage and Restore
+func (p *Panic) IsEngaged() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.engaged
+}
+
+// Engage fades the configured gangs to silence, capturing their values for Restore; engaging
+// again (or during a restore) silences them again but keeps the values captured first
+// Blocks for the fade time; returns the last error of any step
+func (p *Panic) Engage() error {
+	gen := p.gen.Add(1) // Interrupts a restore in progress
+	p.fading.Lock()
+	defer p.fading.Unlock()
+
+	p.mu.Lock()
+	targets := make(map[*GangedFader]int64)
+	saved := p.saved
+	if saved == nil {
+		saved = make(map[string]int64)
+	}
+	for _, name := range p.config.Gangs {
+		gang := findGang(p.gangs, name)
+		if gang == nil {
+			continue
+		}
+		if _, ok := saved[name]; !ok {
+			saved[name] = gang.GetCurrentValue()
+		}
+		targets[gang] = gang.GetMin()
+	}
+	p.saved = saved
+	p.engaged = true
+	p.mu.Unlock()
+
+	fade := p.config.Fade
+	if fade <= 0 {
+		fade = defaultPanicFade
+	}
+	return p.fade(targets, fade, gen)
+}
+
+// Restore fades the gangs back to the values captured by Engage; does nothing if not engaged
+// The values are kept until the fade completes, so an Engage during the restore silences the
+// gangs without losing them
+// Blocks for the restore time; returns the last error of any step
+func (p *Panic) Restore() error {
+	gen := p.gen.Add(1)
+	p.fading.Lock()
+	defer p.fading.Unlock()
+
+	p.mu.Lock()
+	if !p.engaged {
+		p.mu.Unlock()
+		return nil
+	}
+	targets := make(map[*GangedFader]int64)
+	for name, value := range p.saved {
+		if gang := findGang(p.gangs, name); gang != nil {
+			targets[gang] = value
+		}
+	}
+	p.engaged = false
+	p.mu.Unlock()
+
+	restore := p.config.Restore
+	if restore <= 0 {
+		restore = defaultPanicRestore
+	}
+	err := p.fade(targets, restore, gen)
+
+	p.mu.Lock()
+	if p.gen.Load() == gen {
+		p.saved = nil
+	}
+	p.mu.Unlock()
+	return err
+}
+
+// fade moves each gang from its current value to its target over the fade time in dB space,
+// then writes the targets exactly; a later Engage or Restore (gen changed) stops it where it is
+// Returns the last error of any step
+func (p *Panic) fade(targets map[*GangedFader]int64, fade time.Duration, gen int64) error {
+	from := make(map[*GangedFader]float64)
+	to := make(map[*GangedFader]float64)
+	for gang, target := range targets {
+		from[gang] = math.Max(gang.ValueToDb(gang.GetCurrentValue()), floorDb)
+		to[gang] = math.Max(gang.ValueToDb(target), floorDb)
+	}
+
+	var lastErr error
+	started := time.Now()
+	for elapsed := time.Duration(0); elapsed < fade; elapsed = time.Since(started) {
+		if p.gen.Load() != gen {
+			return lastErr
+		}
+		t := float64(elapsed) / float64(fade)
+		for gang := range targets {
+			db := from[gang] + (to[gang]-from[gang])*t
+			value := gang.DbToValue(db)
+			if db <= floorDb {
+				value = gang.GetMin()
+			}
+			if err := gang.HandleUIChange(value); err != nil {
+				log.Printf("Panic: failed to fade %s: %v", gang.GetName(), err)
+				lastErr = err
+			}
+		}
+		time.Sleep(panicInterval)
+	}
+	for gang, target := range targets {
+		if err := gang.HandleUIChange(target); err != nil {
+			log.Printf("Panic: failed to set %s: %v", gang.GetName(), err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// panicColor marks the panic button, and the restore button while the panic is engaged
+var panicColor = imgui.Vec4{X: 0.8, Y: 0.1, Z: 0.1, W: 1.0}
+
+// SetPanic enables the panic button and the panic and restore keybindings
+func (sm *SessionMixer) SetPanic(p *Panic) {
+	sm.panic = p
+}
+
+// drawPanicButton renders the panic button, replaced by a restore button while engaged
+// The fades run in the background so the window keeps drawing
+func (sm *SessionMixer) drawPanicButton() {
+	imgui.PushStyleColorVec4(imgui.ColButton, panicColor)
+	if sm.panic.IsEngaged() {
+		if imgui.Button(tr("Restore##panic")) {
+			go func() { logError(sm.panic.Restore()) }()
+		}
+		imgui.SetItemTooltip(tr("Fade the output gangs back to their levels before the panic"))
+	} else {
+		if imgui.Button(tr("PANIC")) {
+			go func() { logError(sm.panic.Engage()) }()
+		}
+		imgui.SetItemTooltip(tr("Fade the output gangs to silence (e.g. feedback)"))
+	}
+	imgui.PopStyleColor()
+}
@@ -0,0 +1,220 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+const defaultPhantomSettle = 3 * time.Second
+
+// PhantomInterlock protects monitors and ribbon mics when phantom power is toggled from the UI
+// Optionally asks for confirmation, then mutes (or dims) the sends carrying the input for a
+// settle time around the switch before restoring them
+type PhantomInterlock struct {
+	config *PhantomSafety
+	sends  map[int][]string // Input number -> send gang names, resolved on each switch
+
+	mu        sync.Mutex
+	gangs     []*GangedFader
+	pending   *phantomRequest            // Awaiting confirmation
+	restores  map[string]*phantomRestore // Sends (by gang name) attenuated while the power settles
+	openPopup bool
+}
+
+// phantomRestore is an attenuated send waiting for the settle time to pass
+type phantomRestore struct {
+	saved   int64 // Value before the first switch
+	written int64 // Attenuated value written by the interlock
+	timer   *time.Timer
+}
+
+// phantomRequest is a phantom power switch awaiting confirmation
+type phantomRequest struct {
+	input   *InputChannel
+	enabled bool
+}
+
+// NewPhantomInterlock creates an interlock from config, checking the send gang names
+func NewPhantomInterlock(config *PhantomSafety, gangs []*GangedFader) (*PhantomInterlock, error) {
+	pi := &PhantomInterlock{
+		config:   config,
+		sends:    make(map[int][]string),
+		gangs:    gangs,
+		restores: make(map[string]*phantomRestore),
+	}
+
+	for _, send := range config.Sends {
+		for _, name := range send.Gangs {
+			if findGang(gangs, name) == nil {
+				return nil, fmt.Errorf("phantom safety: input %d: unknown gang '%s'", send.Input, name)
+			}
+			pi.sends[send.Input] = append(pi.sends[send.Input], name)
+		}
+	}
+
+	return pi, nil
+}
+
+// SetGangs replaces the gangs the send names resolve to (e.g. after a profile switch); sends
+// missing from the new gangs are not attenuated
+func (pi *PhantomInterlock) SetGangs(gangs []*GangedFader) {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	pi.gangs = gangs
+}
+
+// Request asks to switch phantom power on an input
+// If confirmation is required the switch is deferred until Confirm is called
+func (pi *PhantomInterlock) Request(input *InputChannel, enabled bool) error {
+	if pi.config.Confirm {
+		pi.mu.Lock()
+		pi.pending = &phantomRequest{input: input, enabled: enabled}
+		pi.openPopup = true
+		pi.mu.Unlock()
+		return nil
+	}
+	return pi.Switch(input, enabled)
+}
+
+// Confirm performs the pending phantom power switch
+func (pi *PhantomInterlock) Confirm() error {
+	pi.mu.Lock()
+	req := pi.pending
+	pi.pending = nil
+	pi.mu.Unlock()
+
+	if req == nil {
+		return nil
+	}
+	return pi.Switch(req.input, req.enabled)
+}
+
+// Cancel discards the pending phantom power switch
+func (pi *PhantomInterlock) Cancel() {
+	pi.mu.Lock()
+	pi.pending = nil
+	pi.mu.Unlock()
+}
+
+// Switch mutes/dims the input's sends, switches phantom power, and restores the sends
+// after the settle time
+// A switch while a send is still attenuated keeps the value saved by the first switch and
+// extends the settle time
+func (pi *PhantomInterlock) Switch(input *InputChannel, enabled bool) error {
+	if input.phantom == nil {
+		return fmt.Errorf("input %d has no phantom power control", input.number)
+	}
+	pi.attenuateSends(pi.sends[input.number])
+	return input.phantom.HandleUIChange(boolToValue(enabled))
+}
+
+// attenuateSends attenuates the sends and (re)starts their restore timers
+func (pi *PhantomInterlock) attenuateSends(sends []string) {
+	settle := pi.config.Settle
+	if settle <= 0 {
+		settle = defaultPhantomSettle
+	}
+
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	for _, name := range sends {
+		gang := findGang(pi.gangs, name)
+		if gang == nil {
+			continue
+		}
+		restore := pi.restores[name]
+		if restore != nil && !restore.timer.Stop() {
+			// The restore is already running; it will find itself replaced and do nothing
+			restore = &phantomRestore{saved: restore.saved}
+		} else if restore == nil {
+			restore = &phantomRestore{saved: gang.GetCurrentValue()}
+		}
+		if err := gang.HandleUIChange(pi.attenuate(gang, restore.saved)); err != nil {
+			log.Printf("Phantom safety: failed to attenuate %s: %v", gang.GetName(), err)
+		}
+		restore.written = gang.GetCurrentValue()
+		pi.restores[name] = restore
+
+		if restore.timer == nil {
+			restore.timer = time.AfterFunc(settle, func() { pi.restore(name, restore) })
+		} else {
+			restore.timer.Reset(settle)
+		}
+	}
+}
+
+// restore returns a send to its saved value, unless someone moved it while it was attenuated
+func (pi *PhantomInterlock) restore(name string, restore *phantomRestore) {
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	if pi.restores[name] != restore {
+		return
+	}
+	delete(pi.restores, name)
+
+	gang := findGang(pi.gangs, name)
+	if gang == nil || gang.GetCurrentValue() != restore.written {
+		return
+	}
+	if err := gang.HandleUIChange(restore.saved); err != nil {
+		log.Printf("Phantom safety: failed to restore %s: %v", gang.GetName(), err)
+	}
+}
+
+// attenuate returns the muted or dimmed value for a send
+func (pi *PhantomInterlock) attenuate(gang *GangedFader, value int64) int64 {
+	if pi.config.DimDb <= 0 {
+		return gang.GetMin()
+	}
+	return gang.DbToValue(gang.ValueToDb(value) - float64(pi.config.DimDb))
+}
+
+// Draw renders the confirmation dialog when a switch is pending
+func (pi *PhantomInterlock) Draw() {
+	pi.mu.Lock()
+	req := pi.pending
+	if pi.openPopup {
+		imgui.OpenPopupStr(tr("Phantom Power"))
+		pi.openPopup = false
+	}
+	pi.mu.Unlock()
+
+	if imgui.BeginPopupModalV(tr("Phantom Power"), nil, imgui.WindowFlagsAlwaysAutoResize) {
+		if req != nil {
+			state := tr("off")
+			if req.enabled {
+				state = tr("on")
+			}
+			imgui.Text(trf("Switch phantom power %s for input %d?", state, req.input.number))
+			if sends := pi.sends[req.input.number]; len(sends) > 0 {
+				imgui.TextDisabled(trf("%d send(s) will be attenuated while the power settles", len(sends)))
+			}
+		}
+		if imgui.Button(tr("Confirm")) {
+			if err := pi.Confirm(); err != nil {
+				log.Printf("Phantom safety: %v", err)
+			}
+			imgui.CloseCurrentPopup()
+		}
+		imgui.SameLine()
+		if imgui.Button(tr("Cancel")) {
+			pi.Cancel()
+			imgui.CloseCurrentPopup()
+		}
+		imgui.EndPopup()
+	}
+}
+
+// findGang returns the gang with the given name, or nil
+func findGang(gangs []*GangedFader, name string) *GangedFader {
+	for _, gang := range gangs {
+		if gang.GetName() == name {
+			return gang
+		}
+	}
+	return nil
+}
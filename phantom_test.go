@@ -0,0 +1,72 @@
+package sessionmixer
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func newPhantomInput(t *testing.T, number int) *InputChannel {
+	t.Helper()
+	phantom, _ := newFakeChannel(t, 90, "Phantom", 0, 1, 0)
+	return &InputChannel{number: number, phantom: phantom}
+}
+
+func waitPhantomRestored(t *testing.T, pi *PhantomInterlock) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		pi.mu.Lock()
+		n := len(pi.restores)
+		pi.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("sends not restored")
+}
+
+func TestPhantomSwitchKeepsFirstSavedValue(t *testing.T) {
+	send, fakes := newFakeGang(t, "Vocal → Cue 1", 0, 160, 80, 1)
+	settle := 50 * time.Millisecond
+	pi, err := NewPhantomInterlock(&PhantomSafety{Settle: settle, Sends: []PhantomSend{{Input: 1, Gangs: []string{send.GetName()}}}}, []*GangedFader{send})
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := newPhantomInput(t, 1)
+
+	if err := pi.Switch(input, true); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(settle / 2)
+	// Switching back before the restore must not save the muted value
+	if err := pi.Switch(input, false); err != nil {
+		t.Fatal(err)
+	}
+	waitPhantomRestored(t, pi)
+	if got := send.GetCurrentValue(); got != 80 {
+		t.Errorf("send restored to %d, want 80", got)
+	}
+	if got := fakes[0].getWrites(); !slices.Equal(got, []int64{0, 80}) {
+		t.Errorf("writes = %v, want [0 80]", got)
+	}
+}
+
+func TestPhantomRestoreLeavesMovedSend(t *testing.T) {
+	send, fakes := newFakeGang(t, "Vocal → Cue 1", 0, 160, 80, 1)
+	pi, err := NewPhantomInterlock(&PhantomSafety{Settle: 20 * time.Millisecond, Sends: []PhantomSend{{Input: 1, Gangs: []string{send.GetName()}}}}, []*GangedFader{send})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pi.Switch(newPhantomInput(t, 1), true); err != nil {
+		t.Fatal(err)
+	}
+	if err := send.HandleUIChange(100); err != nil {
+		t.Fatal(err)
+	}
+	waitPhantomRestored(t, pi)
+	if got := fakes[0].getWrites(); !slices.Equal(got, []int64{0, 100}) {
+		t.Errorf("writes = %v, want the user's move kept", got)
+	}
+}
@@ -0,0 +1,128 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// Write sources: where a gang change came from, for the write policy and the audit log
+const (
+	WriteSourceUI     = "ui"     // The mixer window, its strips and pages, and keyboard shortcuts
+	WriteSourceMIDI   = "midi"   // Control surface faders, encoders and buttons
+	WriteSourceInput  = "input"  // Gamepads and footswitches
+	WriteSourceOSC    = "osc"    // OSC clients
+	WriteSourceRemote = "remote" // Remote protocol clients (connect, other software over the websocket)
+)
+
+// Write policy modes
+const (
+	WritePolicyLastWriter = "last_writer" // Every write goes through (default)
+	WritePolicyPriority   = "priority"    // A source cannot take a gang from a higher-priority source holding it
+	WritePolicyLockout    = "lockout"     // No other source can take a gang while its writer holds it
+)
+
+// defaultWriteHold is how long a source holds a gang after its last write
+const defaultWriteHold = time.Second
+
+// defaultWritePriority ranks the sources from highest to lowest
+var defaultWritePriority = []string{WriteSourceUI, WriteSourceMIDI, WriteSourceInput, WriteSourceOSC, WriteSourceRemote}
+
+// writePolicy is the resolved policy applied by GangedFader.Claim
+type writePolicy struct {
+	mode     string
+	priority []string
+	hold     time.Duration
+}
+
+// activePolicy is the active write policy; nil is last writer wins with the default hold
+var activePolicy atomic.Pointer[writePolicy]
+
+// SetWritePolicy validates and activates the write policy; nil restores last writer wins
+func SetWritePolicy(config *WritePolicyConfig) error {
+	if config == nil {
+		activePolicy.Store(nil)
+		return nil
+	}
+	wp := &writePolicy{mode: config.Mode, priority: config.Priority, hold: config.Hold}
+	switch wp.mode {
+	case "":
+		wp.mode = WritePolicyLastWriter
+	case WritePolicyLastWriter, WritePolicyPriority, WritePolicyLockout:
+	default:
+		return fmt.Errorf("%w: unknown write policy mode '%s'", ErrInvalidConfig, config.Mode)
+	}
+	if len(wp.priority) == 0 {
+		wp.priority = defaultWritePriority
+	}
+	for i, source := range wp.priority {
+		if !slices.Contains(defaultWritePriority, source) {
+			return fmt.Errorf("%w: unknown write source '%s'", ErrInvalidConfig, source)
+		}
+		if slices.Contains(wp.priority[:i], source) {
+			return fmt.Errorf("%w: write source '%s' is listed twice", ErrInvalidConfig, source)
+		}
+	}
+	if wp.hold < 0 {
+		return fmt.Errorf("%w: write policy hold must not be negative", ErrInvalidConfig)
+	}
+	if wp.hold == 0 {
+		wp.hold = defaultWriteHold
+	}
+	activePolicy.Store(wp)
+	return nil
+}
+
+// rank returns a source's position in the priority list; unlisted sources rank below all others
+func (wp *writePolicy) rank(source string) int {
+	if i := slices.Index(wp.priority, source); i >= 0 {
+		return i
+	}
+	return len(wp.priority)
+}
+
+// Claim asks the write policy whether source may write the gang now, and if so makes it the
+// gang's writer; a refused write should be dropped silently (the writer that holds the gang
+// is mid-move, and the refused source sees its value come back as feedback). Writes from the
+// mixer's own automation (scenes, duckers, panic, idle dimming) do not claim and always apply.
+func (gf *GangedFader) Claim(source string) bool {
+	wp := activePolicy.Load()
+	now := time.Now()
+	gf.writerMu.Lock()
+	defer gf.writerMu.Unlock()
+	if wp != nil && gf.writer != "" && gf.writer != source && now.Sub(gf.writtenAt) < wp.hold {
+		switch {
+		case wp.mode == WritePolicyLockout:
+			return false
+		case wp.mode == WritePolicyPriority && wp.rank(source) > wp.rank(gf.writer):
+			return false
+		}
+	}
+	gf.writer = source
+	gf.writtenAt = now
+	return true
+}
+
+// GetWriter returns the source holding the gang under the write policy, if any
+func (gf *GangedFader) GetWriter() (string, bool) {
+	hold := defaultWriteHold
+	if wp := activePolicy.Load(); wp != nil {
+		hold = wp.hold
+	}
+	gf.writerMu.Lock()
+	defer gf.writerMu.Unlock()
+	if gf.writer == "" || time.Since(gf.writtenAt) >= hold {
+		return "", false
+	}
+	return gf.writer, true
+}
+
+// writeSource returns the source a change to the gang is attributed to: its writer while it holds
+// the gang, otherwise the mixer itself
+func (gf *GangedFader) writeSource() string {
+	if writer, ok := gf.GetWriter(); ok {
+		return writer
+	}
+	return AuditSourceMixer
+}
@@ -0,0 +1,79 @@
+package sessionmixer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWritePolicyPriority(t *testing.T) {
+	if err := SetWritePolicy(&WritePolicyConfig{Mode: WritePolicyPriority}); err != nil {
+		t.Fatal(err)
+	}
+	defer SetWritePolicy(nil)
+
+	gang := &GangedFader{}
+	if !gang.Claim(WriteSourceOSC) {
+		t.Fatal("first claim refused")
+	}
+	// The UI ranks above OSC and takes the gang over mid-drag; OSC is refused while the UI holds it
+	if !gang.Claim(WriteSourceUI) {
+		t.Error("UI could not take the gang from OSC")
+	}
+	if gang.Claim(WriteSourceOSC) {
+		t.Error("OSC took the gang from the UI")
+	}
+	if writer, held := gang.GetWriter(); !held || writer != WriteSourceUI {
+		t.Errorf("writer = %q, %v; want ui", writer, held)
+	}
+	if gang.writeSource() != WriteSourceUI {
+		t.Errorf("writes attributed to %q", gang.writeSource())
+	}
+
+	// Once the hold has passed, any source may claim it
+	gang.writtenAt = time.Now().Add(-2 * defaultWriteHold)
+	if !gang.Claim(WriteSourceOSC) {
+		t.Error("OSC refused after the hold")
+	}
+}
+
+func TestWritePolicyLockout(t *testing.T) {
+	if err := SetWritePolicy(&WritePolicyConfig{Mode: WritePolicyLockout, Hold: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	defer SetWritePolicy(nil)
+
+	gang := &GangedFader{}
+	gang.Claim(WriteSourceRemote)
+	if gang.Claim(WriteSourceUI) {
+		t.Error("lockout let the UI take a gang held by a remote client")
+	}
+	if !gang.Claim(WriteSourceRemote) {
+		t.Error("the holder was refused")
+	}
+}
+
+func TestWritePolicyLastWriter(t *testing.T) {
+	gang := &GangedFader{}
+	gang.Claim(WriteSourceUI)
+	if !gang.Claim(WriteSourceRemote) {
+		t.Error("last writer refused a write")
+	}
+	if gang.writeSource() != WriteSourceRemote {
+		t.Errorf("writes attributed to %q", gang.writeSource())
+	}
+}
+
+func TestWritePolicyConfig(t *testing.T) {
+	defer SetWritePolicy(nil)
+	for _, config := range []WritePolicyConfig{
+		{Mode: "loudest"},
+		{Priority: []string{"ui", "http"}},
+		{Priority: []string{"ui", "osc", "ui"}},
+		{Hold: -time.Second},
+	} {
+		if err := SetWritePolicy(&config); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%+v: err = %v", config, err)
+		}
+	}
+}
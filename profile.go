@@ -0,0 +1,152 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// DefaultProfile is the name of the profile made of the top-level gang_controls
+const DefaultProfile = "default"
+
+// ProfileManager switches between gang profiles at runtime
+// Switches are requested from any goroutine (IPC, keybindings) and applied by the owner of
+// the gangs (the UI thread, or the daemon loop) with Apply, which rebuilds the gangs through
+// the ControlMapper and hands them to the registered listeners (event monitor, mixer, scenes)
+type ProfileManager struct {
+	card      *scarlettctl.Card
+	config    *Config
+	listeners []func(gangs []*GangedFader)
+	requests  chan struct{}
+
+	mu      sync.Mutex
+	current string
+	pending string // Requested profile not yet applied ("" = none)
+}
+
+// NewProfileManager creates a profile manager starting on the default profile
+func NewProfileManager(card *scarlettctl.Card, config *Config) (*ProfileManager, error) {
+	seen := map[string]bool{DefaultProfile: true}
+	for i, profile := range config.Profiles {
+		if seen[profile.Name] {
+			return nil, fmt.Errorf("profile %d: duplicate profile name '%s'", i, profile.Name)
+		}
+		seen[profile.Name] = true
+	}
+	return &ProfileManager{
+		card:     card,
+		config:   config,
+		requests: make(chan struct{}, 1),
+		current:  DefaultProfile,
+	}, nil
+}
+
+// OnSwitch registers a function receiving the new gangs after each switch
+// Must be called before the first switch
+func (pm *ProfileManager) OnSwitch(fn func(gangs []*GangedFader)) {
+	pm.listeners = append(pm.listeners, fn)
+}
+
+// GetNames returns the profile names, starting with the default profile
+func (pm *ProfileManager) GetNames() []string {
+	names := []string{DefaultProfile}
+	for _, profile := range pm.config.Profiles {
+		names = append(names, profile.Name)
+	}
+	return names
+}
+
+// HasProfiles returns true if any profile besides the default is configured
+func (pm *ProfileManager) HasProfiles() bool {
+	return len(pm.config.Profiles) > 0
+}
+
+// GetCurrent returns the name of the active profile
+func (pm *ProfileManager) GetCurrent() string {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.current
+}
+
+// Request asks for a switch to the named profile on the next Apply; safe from any goroutine
+func (pm *ProfileManager) Request(name string) error {
+	if _, err := pm.gangControls(name); err != nil {
+		return err
+	}
+	pm.mu.Lock()
+	pm.pending = name
+	pm.mu.Unlock()
+	select {
+	case pm.requests <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Next requests a switch to the profile after the current one, wrapping around
+func (pm *ProfileManager) Next() {
+	names := pm.GetNames()
+	current := pm.GetCurrent()
+	for i, name := range names {
+		if name == current {
+			_ = pm.Request(names[(i+1)%len(names)])
+			return
+		}
+	}
+}
+
+// Requests returns a channel signalled when a switch is requested, for owners without a
+// frame loop
+func (pm *ProfileManager) Requests() <-chan struct{} {
+	return pm.requests
+}
+
+// Apply performs the pending switch, if any; the gangs of the previous profile stay active
+// when the new profile fails to load
+func (pm *ProfileManager) Apply() error {
+	pm.mu.Lock()
+	name := pm.pending
+	pm.pending = ""
+	pm.mu.Unlock()
+	if name == "" || name == pm.GetCurrent() {
+		return nil
+	}
+
+	gangControls, err := pm.gangControls(name)
+	if err != nil {
+		return err
+	}
+	cfg := *pm.config
+	cfg.GangControls = gangControls
+	if name != DefaultProfile {
+		cfg.GangTemplates = nil // Templates belong to the top-level (default) gangs
+	}
+	gangs, err := NewControlMapper(pm.card, &cfg).LoadGangs()
+	if err != nil {
+		return fmt.Errorf("profile '%s': %w", name, err)
+	}
+	for _, fn := range pm.listeners {
+		fn(gangs)
+	}
+
+	pm.mu.Lock()
+	pm.current = name
+	pm.mu.Unlock()
+	log.Printf("Switched to profile '%s' (%d gangs)", name, len(gangs))
+	return nil
+}
+
+// gangControls returns the gang configuration of the named profile
+func (pm *ProfileManager) gangControls(name string) ([]GangControl, error) {
+	if name == DefaultProfile {
+		return pm.config.GangControls, nil
+	}
+	for _, profile := range pm.config.Profiles {
+		if profile.Name == name {
+			return profile.GangControls, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown profile '%s'", name)
+}
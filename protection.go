@@ -0,0 +1,55 @@
+package sessionmixer
+
+import (
+	"slices"
+	"time"
+)
+
+// capIndication is how long a gang stays marked after a write was capped at its ceiling
+const capIndication = 2 * time.Second
+
+// SetCeilingDb caps every write to the gang at db, whatever its source (UI, keyboard, MIDI,
+// remote clients, scenes, duckers); the cap cannot be lifted at runtime
+func (gf *GangedFader) SetCeilingDb(db float64) {
+	gf.ceiling = gf.DbToValue(db)
+	gf.hasCeiling = true
+}
+
+// GetCeiling returns the raw value writes are capped at, if the gang is protected
+func (gf *GangedFader) GetCeiling() (int64, bool) {
+	return gf.ceiling, gf.hasCeiling
+}
+
+// IsCapped returns true if a write was recently capped at the ceiling, or if the hardware
+// holds a value above it (changed from outside sessionmixer)
+func (gf *GangedFader) IsCapped() bool {
+	if !gf.hasCeiling {
+		return false
+	}
+	if gf.GetCurrentValue() > gf.ceiling {
+		return true
+	}
+	at := gf.cappedAt.Load()
+	return at != 0 && time.Since(time.Unix(0, at)) < capIndication
+}
+
+// capValue limits a value about to be written to the ceiling, recording when the cap engaged
+func (gf *GangedFader) capValue(value int64) int64 {
+	if !gf.hasCeiling || value <= gf.ceiling {
+		return value
+	}
+	gf.cappedAt.Store(time.Now().UnixNano())
+	return gf.ceiling
+}
+
+// applyProtection caps the protected gangs at the configured ceiling
+func applyProtection(config *ProtectionConfig, gangs []*GangedFader) {
+	if config == nil {
+		return
+	}
+	for _, gang := range gangs {
+		if slices.Contains(config.Gangs, gang.GetName()) {
+			gang.SetCeilingDb(float64(config.MaxDb))
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package sessionmixer
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+// readoutInterval is how often display channels are re-read from the hardware
+const readoutInterval = 50 * time.Millisecond
+
+// Display channel kinds
+const (
+	DisplayMeter   = "meter"
+	DisplayReadout = "readout"
+)
+
+// ReadoutPoller periodically re-reads the read-only display channels (meters, gain
+// reduction, status values) that change without hardware events
+type ReadoutPoller struct {
+	mu    sync.Mutex
+	gangs []*GangedFader
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewReadoutPoller creates a poller for the display channels among gangs
+func NewReadoutPoller(gangs []*GangedFader) *ReadoutPoller {
+	rp := &ReadoutPoller{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	rp.SetGangs(gangs)
+	return rp
+}
+
+// SetGangs replaces the polled gangs with the display channels among gangs (e.g. after a
+// profile switch)
+func (rp *ReadoutPoller) SetGangs(gangs []*GangedFader) {
+	var readouts []*GangedFader
+	for _, gang := range gangs {
+		if gang.IsReadOnly() {
+			readouts = append(readouts, gang)
+		}
+	}
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.gangs = readouts
+}
+
+// HasReadouts returns true if any gang is a display channel
+func (rp *ReadoutPoller) HasReadouts() bool {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	return len(rp.gangs) > 0
+}
+
+// Start begins polling in a background goroutine
+func (rp *ReadoutPoller) Start() {
+	go func() {
+		defer close(rp.done)
+		ticker := time.NewTicker(readoutInterval)
+		defer ticker.Stop()
+		failed := make(map[*GangedFader]bool) // Log each failing gang once
+		for {
+			select {
+			case <-rp.stop:
+				return
+			case <-ticker.C:
+				rp.mu.Lock()
+				gangs := rp.gangs
+				rp.mu.Unlock()
+				for _, gang := range gangs {
+					err := gang.Refresh()
+					if err != nil && !failed[gang] {
+						log.Printf("Readout %s: %v", gang.GetName(), err)
+					}
+					failed[gang] = err != nil
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops polling; blocks until the goroutine has exited
+func (rp *ReadoutPoller) Stop() {
+	rp.stopOnce.Do(func() {
+		close(rp.stop)
+		<-rp.done
+	})
+}
+
+// drawReadout renders a display channel in place of a fader: a segmented meter following the
+// gang taper, or a large numeric readout
+func drawReadout(gang *GangedFader, size imgui.Vec2) {
+	value := gang.GetCurrentValue()
+	if gang.GetDisplay() != DisplayMeter {
+		imgui.Text(gang.FormatValue(value))
+		return
+	}
+
+	pos := float32(gang.ValueToPosition(value))
+	drawMeter("##readout_"+gang.GetName(), nil, DefaultMeterScale, pos, 1, size)
+	if imgui.BeginItemTooltip() {
+		imgui.Text(gang.FormatValue(value))
+		imgui.EndTooltip()
+	}
+}
@@ -0,0 +1,168 @@
+package sessionmixer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recorder logs all gang values and peak levels at a fixed interval for later analysis
+// Files ending in .json are written as line-delimited JSON; anything else is CSV
+type Recorder struct {
+	gangs    []*GangedFader
+	interval time.Duration
+	jsonMode bool
+
+	file *os.File
+	buf  *bufio.Writer
+	csv  *csv.Writer
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// recordedGang is the JSON representation of a gang in a recorded sample
+// Nil dB values represent -∞ (muted fader or silent meter)
+type recordedGang struct {
+	Value  int64    `json:"value"`
+	Db     *float64 `json:"db"`
+	PeakDb *float64 `json:"peak_db,omitempty"`
+}
+
+// NewRecorder creates a recorder writing to path every interval
+func NewRecorder(path string, interval time.Duration, gangs []*GangedFader) (*Recorder, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("record interval must be positive")
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	r := &Recorder{
+		gangs:    gangs,
+		interval: interval,
+		jsonMode: strings.EqualFold(filepath.Ext(path), ".json"),
+		file:     file,
+		buf:      bufio.NewWriter(file),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if !r.jsonMode {
+		r.csv = csv.NewWriter(r.buf)
+		header := []string{"time"}
+		for _, gang := range gangs {
+			header = append(header, gang.GetName(), gang.GetName()+" dB")
+			if gang.HasLevels() {
+				header = append(header, gang.GetName()+" peak dB")
+			}
+		}
+		if err := r.csv.Write(header); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to write recording header: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+// Start begins recording in a background goroutine
+func (r *Recorder) Start() {
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case now := <-ticker.C:
+				if err := r.record(now); err != nil {
+					log.Printf("Recorder: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops recording and closes the file; blocks until the goroutine has exited
+func (r *Recorder) Stop() error {
+	var err error
+	r.stopOnce.Do(func() {
+		close(r.stop)
+		<-r.done
+		if r.csv != nil {
+			r.csv.Flush()
+		}
+		if ferr := r.buf.Flush(); ferr != nil {
+			err = ferr
+		}
+		if cerr := r.file.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	})
+	return err
+}
+
+// record writes one sample of every gang
+func (r *Recorder) record(now time.Time) error {
+	if r.jsonMode {
+		sample := struct {
+			Time  time.Time               `json:"time"`
+			Gangs map[string]recordedGang `json:"gangs"`
+		}{Time: now, Gangs: make(map[string]recordedGang)}
+
+		for _, gang := range r.gangs {
+			value := gang.GetCurrentValue()
+			rg := recordedGang{Value: value, Db: finiteOrNil(gang.ValueToDb(value))}
+			if peak, ok := gang.GetRecentPeakDb(r.interval); ok {
+				rg.PeakDb = finiteOrNil(peak)
+			}
+			sample.Gangs[gang.GetName()] = rg
+		}
+
+		data, err := json.Marshal(sample)
+		if err != nil {
+			return err
+		}
+		_, err = r.buf.Write(append(data, '\n'))
+		return err
+	}
+
+	row := []string{now.Format(time.RFC3339Nano)}
+	for _, gang := range r.gangs {
+		value := gang.GetCurrentValue()
+		row = append(row, fmt.Sprintf("%d", value), formatDb(gang.ValueToDb(value)))
+		if peak, ok := gang.GetRecentPeakDb(r.interval); ok {
+			row = append(row, formatDb(peak))
+		}
+	}
+	return r.csv.Write(row)
+}
+
+// finiteOrNil returns a pointer to v, or nil if v is infinite
+func finiteOrNil(v float64) *float64 {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return nil
+	}
+	return &v
+}
+
+// formatDb formats a dB value with two decimals, or "-inf"
+func formatDb(db float64) string {
+	if math.IsInf(db, -1) {
+		return "-inf"
+	}
+	return fmt.Sprintf("%.2f", db)
+}
@@ -0,0 +1,748 @@
+package sessionmixer
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// RemotePath is the websocket endpoint of the remote server
+	RemotePath = "/remote"
+
+	// remoteProtocol is the websocket subprotocol of the remote protocol
+	remoteProtocol = "sessionmixer.v1"
+
+	// remoteSyncInterval is how often gang states are compared and pushed to clients
+	remoteSyncInterval = 50 * time.Millisecond
+
+	// remoteTimeout bounds connecting, the hello exchange and each write
+	remoteTimeout = 5 * time.Second
+)
+
+// RemoteGang is the state of a gang as shared with remote clients
+type RemoteGang struct {
+	Name    string  `json:"name"`
+	Unit    string  `json:"unit,omitempty"`
+	Min     int64   `json:"min"`
+	Max     int64   `json:"max"`
+	Toggle  bool    `json:"toggle,omitempty"`
+	Display string  `json:"display,omitempty"` // "meter" or "readout" for read-only display channels
+	Value   int64   `json:"value"`
+	Text    string  `json:"text"` // Value formatted in the gang's unit
+	Muted   bool    `json:"muted,omitempty"`
+	Locked  bool    `json:"locked,omitempty"`
+	Level   float32 `json:"level,omitempty"` // Normalized peak level (0-1) of gangs with levels
+}
+
+// remoteMessage is a message of the remote protocol, one per websocket text message (see
+// docs/remote-protocol.md)
+//
+//	client -> server: hello (token, page), set (gang, value), mute (gang, muted), list_gangs,
+//	                  get (gang), list_scenes, recall_scene (scene), save_scene (scene)
+//	server -> client: snapshot (gangs), update (changed gangs), result, error
+//
+// A request carrying an id is answered with a result (or error) carrying the same id; set and
+// mute without an id are fire-and-forget, failures are still reported as errors
+type remoteMessage struct {
+	Type   string       `json:"type"`
+	ID     int64        `json:"id,omitempty"`
+	Token  string       `json:"token,omitempty"`
+	Page   string       `json:"page,omitempty"`
+	Gang   string       `json:"gang,omitempty"`
+	Value  int64        `json:"value,omitempty"`
+	Muted  bool         `json:"muted,omitempty"`
+	Scene  string       `json:"scene,omitempty"`
+	Gangs  []RemoteGang `json:"gangs,omitempty"`
+	Scenes []string     `json:"scenes,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// RemoteServer shares the gangs of the instance that owns the hardware with remote clients
+// over a websocket: clients receive a snapshot and then every change (from the hardware, the
+// local UI or other clients), and their fader and mute changes are applied like UI changes
+// A client may ask for a restricted page, which hides and locks every other gang for it
+type RemoteServer struct {
+	config   *Config
+	scenes   *SceneManager // nil if scene requests are unavailable
+	listener net.Listener
+	server   *http.Server
+
+	mu         sync.Mutex
+	gangs      []*GangedFader
+	generation int // Bumped when the gangs are replaced, so clients get a new snapshot
+	clients    map[*remoteConn]bool
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// remoteConn is a connected client
+type remoteConn struct {
+	ws         *wsConn
+	page       *Page // nil = all gangs
+	generation int   // Generation of the last snapshot sent (-1 = none)
+	sent       map[string]RemoteGang
+}
+
+// NewRemoteServer creates a server for the gangs; the configuration supplies the listen address,
+// token and pages
+func NewRemoteServer(config *Config, gangs []*GangedFader) *RemoteServer {
+	return &RemoteServer{
+		config:  config,
+		gangs:   gangs,
+		clients: make(map[*remoteConn]bool),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// SetSceneManager enables the scene requests; must be called before Start
+func (rs *RemoteServer) SetSceneManager(scenes *SceneManager) {
+	rs.scenes = scenes
+}
+
+// Start listens for clients and syncs them in the background
+func (rs *RemoteServer) Start() error {
+	if err := checkRemoteTokens(rs.config); err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", rs.config.Remote.Listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", rs.config.Remote.Listen, err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(RemotePath, rs.handle)
+	rs.listener = listener
+	rs.server = &http.Server{Handler: mux}
+	go func() {
+		if err := rs.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("Remote server error: %v", err)
+		}
+	}()
+	go rs.sync()
+	return nil
+}
+
+// Stop closes the listener and all client connections; blocks until syncing has stopped
+func (rs *RemoteServer) Stop() {
+	rs.stopOnce.Do(func() {
+		close(rs.stop)
+		if rs.server == nil {
+			return
+		}
+		rs.server.Close()
+		<-rs.done
+		rs.mu.Lock()
+		for client := range rs.clients {
+			client.ws.Close()
+		}
+		rs.mu.Unlock()
+	})
+}
+
+// GetAddr returns the address the server is listening on
+func (rs *RemoteServer) GetAddr() string {
+	return rs.listener.Addr().String()
+}
+
+// SetGangs replaces the shared gangs (e.g. after a profile switch); clients get a new snapshot
+func (rs *RemoteServer) SetGangs(gangs []*GangedFader) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.gangs = gangs
+	rs.generation++
+}
+
+// handle serves one client: the hello, then its changes until it disconnects
+func (rs *RemoteServer) handle(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgradeWebSocket(w, r, remoteProtocol)
+	if err != nil {
+		log.Printf("Remote: %s: %v", r.RemoteAddr, err)
+		return
+	}
+	defer ws.Close()
+
+	client, err := rs.hello(ws)
+	if err != nil {
+		log.Printf("Remote: %s: %v", r.RemoteAddr, err)
+		rs.send(ws, remoteMessage{Type: "error", Error: err.Error()})
+		return
+	}
+	log.Printf("Remote: client %s connected", r.RemoteAddr)
+	rs.mu.Lock()
+	rs.clients[client] = true
+	rs.mu.Unlock()
+	defer func() {
+		rs.mu.Lock()
+		delete(rs.clients, client)
+		rs.mu.Unlock()
+		log.Printf("Remote: client %s disconnected", r.RemoteAddr)
+	}()
+
+	for {
+		data, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg remoteMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return
+		}
+		reply, err := rs.request(client, msg)
+		if err != nil {
+			rs.send(ws, remoteMessage{Type: "error", ID: msg.ID, Gang: msg.Gang, Error: err.Error()})
+		} else if msg.ID != 0 {
+			reply.Type, reply.ID = "result", msg.ID
+			rs.send(ws, reply)
+		}
+	}
+}
+
+// hello reads and checks the client's hello
+func (rs *RemoteServer) hello(ws *wsConn) (*remoteConn, error) {
+	ws.conn.SetReadDeadline(time.Now().Add(remoteTimeout))
+	data, err := ws.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	ws.conn.SetReadDeadline(time.Time{})
+	var msg remoteMessage
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Type != "hello" {
+		return nil, errors.New("expected hello")
+	}
+	name, err := remotePage(rs.config, msg.Token, msg.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &remoteConn{ws: ws, generation: -1}
+	if name != "" {
+		rs.mu.Lock()
+		gangs := rs.gangs
+		rs.mu.Unlock()
+		page, err := FindPage(rs.config, name, gangs)
+		if err != nil {
+			return nil, err
+		}
+		client.page = page
+	}
+	return client, nil
+}
+
+// remotePage returns the page a client presenting token may use ("" for every gang), given the
+// page it asked for. The page is decided by the server: a page token only opens its own page,
+// while the remote token opens every gang (or the page asked for)
+func remotePage(config *Config, token, requested string) (string, error) {
+	for _, pc := range config.Pages {
+		if pc.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(pc.Token)) != 1 {
+			continue
+		}
+		if requested != "" && requested != pc.Name {
+			return "", fmt.Errorf("token is not allowed to use page '%s'", requested)
+		}
+		return pc.Name, nil
+	}
+	if main := config.Remote.Token; main != "" && subtle.ConstantTimeCompare([]byte(token), []byte(main)) != 1 {
+		return "", errors.New("invalid token")
+	}
+	return requested, nil
+}
+
+// checkRemoteTokens rejects a listener reachable from other hosts without a remote token, page
+// tokens without a remote token (anyone could then connect to every gang) and tokens shared by
+// two pages or a page and the remote token
+func checkRemoteTokens(config *Config) error {
+	if config.Remote.Token == "" && !isLoopbackAddr(config.Remote.Listen) {
+		return fmt.Errorf("remote.token is required to listen on %s (only loopback addresses may go without)", config.Remote.Listen)
+	}
+	tokens := map[string]string{config.Remote.Token: "remote"}
+	for _, pc := range config.Pages {
+		if pc.Token == "" {
+			continue
+		}
+		if config.Remote.Token == "" {
+			return fmt.Errorf("page '%s' has a token, but remote.token is not set", pc.Name)
+		}
+		if other, ok := tokens[pc.Token]; ok {
+			return fmt.Errorf("page '%s' uses the same token as %s", pc.Name, other)
+		}
+		tokens[pc.Token] = fmt.Sprintf("page '%s'", pc.Name)
+	}
+	return nil
+}
+
+// isLoopbackAddr returns true if a listen address (host:port) only accepts local connections
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// request serves one client request; gang changes are applied as the local UI would
+func (rs *RemoteServer) request(client *remoteConn, msg remoteMessage) (remoteMessage, error) {
+	rs.mu.Lock()
+	gangs := rs.gangs
+	rs.mu.Unlock()
+
+	switch msg.Type {
+	case "list_gangs":
+		var reply remoteMessage
+		for _, gang := range gangs {
+			if client.allows(gang) {
+				reply.Gangs = append(reply.Gangs, remoteGangState(gang))
+			}
+		}
+		return reply, nil
+
+	case "get", "set", "mute":
+		gang := findGang(gangs, msg.Gang)
+		if gang == nil || !client.allows(gang) {
+			return remoteMessage{}, fmt.Errorf("unknown gang '%s'", msg.Gang)
+		}
+		var err error
+		switch {
+		case msg.Type != "get" && gang.IsLocked():
+			err = fmt.Errorf("gang '%s' is locked", msg.Gang)
+		case msg.Type != "get" && !gang.Claim(WriteSourceRemote):
+			// Held by a source the write policy puts first; the reply carries its value
+		case msg.Type == "set":
+			err = gang.HandleRampedChange(msg.Value)
+		case msg.Type == "mute" && msg.Muted:
+			err = gang.Mute()
+		case msg.Type == "mute":
+			err = gang.Unmute()
+		}
+		return remoteMessage{Gangs: []RemoteGang{remoteGangState(gang)}}, err
+
+	case "list_scenes", "recall_scene", "save_scene":
+		// Scenes cover every gang, so they are not available on a restricted page
+		if rs.scenes == nil || client.page != nil {
+			return remoteMessage{}, errors.New("scenes are not available")
+		}
+		switch msg.Type {
+		case "list_scenes":
+			names, err := rs.scenes.List()
+			return remoteMessage{Scenes: names}, err
+		case "recall_scene":
+			return remoteMessage{Scene: msg.Scene}, rs.scenes.Recall(msg.Scene)
+		default:
+			return remoteMessage{Scene: msg.Scene}, rs.scenes.Save(msg.Scene)
+		}
+
+	default:
+		return remoteMessage{}, fmt.Errorf("unknown request '%s'", msg.Type)
+	}
+}
+
+// allows returns true if the client may see and change the gang
+func (rc *remoteConn) allows(gang *GangedFader) bool {
+	return rc.page == nil || rc.page.Allows(gang)
+}
+
+// sync pushes gang states to the clients: a snapshot to new clients and after the gangs are
+// replaced, otherwise the gangs that changed
+func (rs *RemoteServer) sync() {
+	defer close(rs.done)
+	ticker := time.NewTicker(remoteSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-rs.stop:
+			return
+		case <-ticker.C:
+		}
+
+		rs.mu.Lock()
+		gangs := rs.gangs
+		generation := rs.generation
+		var clients []*remoteConn
+		for client := range rs.clients {
+			clients = append(clients, client)
+		}
+		rs.mu.Unlock()
+		if len(clients) == 0 {
+			continue
+		}
+
+		states := make([]RemoteGang, len(gangs))
+		for i, gang := range gangs {
+			states[i] = remoteGangState(gang)
+		}
+		for _, client := range clients {
+			if err := rs.syncClient(client, gangs, states, generation); err != nil {
+				client.ws.Close() // The client's read loop sees the failure and unregisters it
+			}
+		}
+	}
+}
+
+// syncClient sends a client the states it has not seen yet
+func (rs *RemoteServer) syncClient(client *remoteConn, gangs []*GangedFader, states []RemoteGang, generation int) error {
+	snapshot := client.generation != generation
+	msg := remoteMessage{Type: "update"}
+	if snapshot {
+		msg.Type = "snapshot"
+		if client.page != nil {
+			msg.Page = client.page.GetName()
+		}
+		msg.Gangs = []RemoteGang{}
+		client.sent = make(map[string]RemoteGang)
+		client.generation = generation
+	}
+	for i, state := range states {
+		if !client.allows(gangs[i]) {
+			continue
+		}
+		if last, ok := client.sent[state.Name]; ok && last == state {
+			continue
+		}
+		msg.Gangs = append(msg.Gangs, state)
+		client.sent[state.Name] = state
+	}
+	if !snapshot && len(msg.Gangs) == 0 {
+		return nil
+	}
+	return rs.send(client.ws, msg)
+}
+
+// send writes one message, bounded by the remote timeout
+func (rs *RemoteServer) send(ws *wsConn, msg remoteMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	ws.conn.SetWriteDeadline(time.Now().Add(remoteTimeout))
+	return ws.WriteMessage(data)
+}
+
+// remoteGangState captures the shared state of a gang
+func remoteGangState(gang *GangedFader) RemoteGang {
+	value := gang.GetCurrentValue()
+	state := RemoteGang{
+		Name:    gang.GetName(),
+		Unit:    gang.GetUnit(),
+		Min:     gang.GetMin(),
+		Max:     gang.GetMax(),
+		Toggle:  gang.IsToggle(),
+		Display: gang.GetDisplay(),
+		Value:   value,
+		Text:    gang.FormatValue(value),
+		Muted:   gang.IsMuted(),
+		Locked:  gang.IsLocked(),
+	}
+	if level, ok := gang.GetNormalizedLevel(); ok {
+		state.Level = level
+	}
+	return state
+}
+
+// RemoteClient mirrors the gangs of a remote server and sends changes back to it; the
+// connection is retried with backoff while the server is unreachable
+type RemoteClient struct {
+	addr  string
+	token string
+	page  string
+
+	mu        sync.Mutex
+	gangs     []RemoteGang
+	ws        *wsConn
+	lastError string
+	pending   map[int64]chan remoteMessage // Requests awaiting their result, by id
+
+	connected atomic.Bool
+	requestID atomic.Int64
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewRemoteClient creates a client for the server at addr (host:port), optionally restricted
+// to a page
+func NewRemoteClient(addr, token, page string) *RemoteClient {
+	return &RemoteClient{
+		addr:    addr,
+		token:   token,
+		page:    page,
+		pending: make(map[int64]chan remoteMessage),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// GetAddr returns the server address
+func (rc *RemoteClient) GetAddr() string {
+	return rc.addr
+}
+
+// GetPage returns the page the server restricts the client to, or the requested page before
+// connecting ("" for all gangs)
+func (rc *RemoteClient) GetPage() string {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.page
+}
+
+// IsConnected returns true while connected to the server
+func (rc *RemoteClient) IsConnected() bool {
+	return rc.connected.Load()
+}
+
+// GetError returns the most recent error reported by or about the server
+func (rc *RemoteClient) GetError() string {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.lastError
+}
+
+// GetGangs returns a copy of the mirrored gang states, in server order
+func (rc *RemoteClient) GetGangs() []RemoteGang {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]RemoteGang(nil), rc.gangs...)
+}
+
+// Set moves a gang; the mirrored value follows immediately so the fader does not jump back
+// before the server's update arrives
+func (rc *RemoteClient) Set(gang string, value int64) error {
+	rc.mu.Lock()
+	for i := range rc.gangs {
+		if rc.gangs[i].Name == gang {
+			rc.gangs[i].Value = value
+		}
+	}
+	rc.mu.Unlock()
+	return rc.send(remoteMessage{Type: "set", Gang: gang, Value: value})
+}
+
+// SetMuted mutes or unmutes a gang
+func (rc *RemoteClient) SetMuted(gang string, muted bool) error {
+	return rc.send(remoteMessage{Type: "mute", Gang: gang, Muted: muted})
+}
+
+// GetGang reads the current state of a gang from the server
+func (rc *RemoteClient) GetGang(gang string) (RemoteGang, error) {
+	reply, err := rc.call(remoteMessage{Type: "get", Gang: gang})
+	if err != nil {
+		return RemoteGang{}, err
+	}
+	if len(reply.Gangs) != 1 {
+		return RemoteGang{}, errors.New("invalid reply")
+	}
+	return reply.Gangs[0], nil
+}
+
+// ListScenes returns the names of the server's stored scenes
+func (rc *RemoteClient) ListScenes() ([]string, error) {
+	reply, err := rc.call(remoteMessage{Type: "list_scenes"})
+	return reply.Scenes, err
+}
+
+// RecallScene recalls a stored scene on the server
+func (rc *RemoteClient) RecallScene(name string) error {
+	_, err := rc.call(remoteMessage{Type: "recall_scene", Scene: name})
+	return err
+}
+
+// SaveScene saves the server's current gang values as a scene
+func (rc *RemoteClient) SaveScene(name string) error {
+	_, err := rc.call(remoteMessage{Type: "save_scene", Scene: name})
+	return err
+}
+
+// Start connects in the background
+func (rc *RemoteClient) Start() {
+	go rc.run()
+}
+
+// Stop disconnects; blocks until the background goroutine has exited
+func (rc *RemoteClient) Stop() {
+	rc.stopOnce.Do(func() {
+		close(rc.stop)
+		rc.mu.Lock()
+		if rc.ws != nil {
+			rc.ws.Close()
+		}
+		rc.mu.Unlock()
+		<-rc.done
+	})
+}
+
+// run keeps a session open, reconnecting with exponential backoff
+func (rc *RemoteClient) run() {
+	defer close(rc.done)
+	backoff := minRestartBackoff
+	for {
+		started := time.Now()
+		err := rc.session()
+		rc.connected.Store(false)
+		select {
+		case <-rc.stop:
+			return
+		default:
+		}
+		if time.Since(started) >= stableWatch {
+			backoff = minRestartBackoff
+		}
+		rc.setError(fmt.Sprintf("%v (retrying in %s)", err, backoff))
+		log.Printf("Remote: %v (retrying in %s)", err, backoff)
+		select {
+		case <-rc.stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxRestartBackoff)
+	}
+}
+
+// session connects, says hello and mirrors the server until the connection fails
+func (rc *RemoteClient) session() error {
+	ws, err := dialWebSocket(rc.addr, RemotePath, remoteProtocol, remoteTimeout)
+	if err != nil {
+		return err
+	}
+	rc.mu.Lock()
+	select {
+	case <-rc.stop:
+		rc.mu.Unlock()
+		ws.Close()
+		return nil
+	default:
+	}
+	rc.ws = ws
+	rc.mu.Unlock()
+	defer func() {
+		rc.mu.Lock()
+		rc.ws = nil
+		for id, reply := range rc.pending {
+			reply <- remoteMessage{Type: "error", Error: "connection lost"}
+			delete(rc.pending, id)
+		}
+		rc.mu.Unlock()
+		ws.Close()
+	}()
+
+	if err := rc.send(remoteMessage{Type: "hello", Token: rc.token, Page: rc.page}); err != nil {
+		return err
+	}
+	for {
+		data, err := ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+		var msg remoteMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return fmt.Errorf("invalid message: %w", err)
+		}
+		switch msg.Type {
+		case "snapshot":
+			rc.mu.Lock()
+			rc.gangs = msg.Gangs
+			if msg.Page != "" {
+				rc.page = msg.Page // A page token restricts the client whatever it asked for
+			}
+			rc.lastError = ""
+			rc.mu.Unlock()
+			if !rc.connected.Swap(true) {
+				log.Printf("Remote: connected to %s", rc.addr)
+			}
+		case "update":
+			rc.merge(msg.Gangs)
+		case "result", "error":
+			if msg.ID != 0 {
+				rc.deliver(msg)
+				continue
+			}
+			rc.setError(msg.Error)
+			if !rc.connected.Load() {
+				return errors.New(msg.Error) // Rejected hello
+			}
+		}
+	}
+}
+
+// merge applies changed gang states to the mirror
+func (rc *RemoteClient) merge(states []RemoteGang) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for _, state := range states {
+		for i := range rc.gangs {
+			if rc.gangs[i].Name == state.Name {
+				rc.gangs[i] = state
+			}
+		}
+	}
+}
+
+// call sends a request and waits for its result
+func (rc *RemoteClient) call(msg remoteMessage) (remoteMessage, error) {
+	msg.ID = rc.requestID.Add(1)
+	reply := make(chan remoteMessage, 1)
+	rc.mu.Lock()
+	rc.pending[msg.ID] = reply
+	rc.mu.Unlock()
+	defer func() {
+		rc.mu.Lock()
+		delete(rc.pending, msg.ID)
+		rc.mu.Unlock()
+	}()
+
+	if err := rc.send(msg); err != nil {
+		return remoteMessage{}, err
+	}
+	select {
+	case result := <-reply:
+		if result.Type == "error" {
+			return result, errors.New(result.Error)
+		}
+		return result, nil
+	case <-time.After(remoteTimeout):
+		return remoteMessage{}, fmt.Errorf("%s: no reply from %s", msg.Type, rc.addr)
+	}
+}
+
+// deliver passes a result to the request waiting for it
+func (rc *RemoteClient) deliver(msg remoteMessage) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if reply, ok := rc.pending[msg.ID]; ok {
+		reply <- msg
+		delete(rc.pending, msg.ID)
+	}
+}
+
+// send writes one message to the server
+func (rc *RemoteClient) send(msg remoteMessage) error {
+	rc.mu.Lock()
+	ws := rc.ws
+	rc.mu.Unlock()
+	if ws == nil {
+		return errors.New("not connected")
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return ws.WriteMessage(data)
+}
+
+func (rc *RemoteClient) setError(err string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.lastError = err
+}
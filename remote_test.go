@@ -0,0 +1,91 @@
+package sessionmixer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRemotePageFromToken(t *testing.T) {
+	config := &Config{
+		Remote: &RemoteConfig{Listen: "127.0.0.1:0", Token: "main"},
+		Pages: []PageConfig{
+			{Name: "alice", Gangs: []string{"Alice Phones"}, Token: "alice-secret"},
+			{Name: "bob", Gangs: []string{"Bob Phones"}},
+		},
+	}
+	tests := []struct {
+		token, requested string
+		want             string
+		wantErr          bool
+	}{
+		{token: "main", want: ""},
+		{token: "main", requested: "bob", want: "bob"},
+		{token: "alice-secret", want: "alice"},
+		{token: "alice-secret", requested: "alice", want: "alice"},
+		{token: "alice-secret", requested: "bob", wantErr: true},
+		{token: "wrong", wantErr: true},
+		{token: "", requested: "alice", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := remotePage(config, tt.token, tt.requested)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("remotePage(%q, %q) = %q, %v; want %q, error %v", tt.token, tt.requested, got, err, tt.want, tt.wantErr)
+		}
+	}
+	if err := checkRemoteTokens(config); err != nil {
+		t.Errorf("checkRemoteTokens: %v", err)
+	}
+}
+
+func TestCheckRemoteTokensRejectsUnsafeTokens(t *testing.T) {
+	noMain := &Config{
+		Remote: &RemoteConfig{Listen: "127.0.0.1:0"},
+		Pages:  []PageConfig{{Name: "alice", Gangs: []string{"Alice Phones"}, Token: "alice-secret"}},
+	}
+	if err := checkRemoteTokens(noMain); err == nil {
+		t.Error("page token without remote.token accepted")
+	}
+	shared := &Config{
+		Remote: &RemoteConfig{Listen: "127.0.0.1:0", Token: "main"},
+		Pages:  []PageConfig{{Name: "alice", Gangs: []string{"Alice Phones"}, Token: "main"}},
+	}
+	if err := checkRemoteTokens(shared); err == nil {
+		t.Error("page token equal to remote.token accepted")
+	}
+}
+
+func TestCheckRemoteTokensRequiresTokenOffLoopback(t *testing.T) {
+	for listen, wantErr := range map[string]bool{
+		"127.0.0.1:7070": false,
+		"localhost:7070": false,
+		"[::1]:7070":     false,
+		"0.0.0.0:7070":   true,
+		":7070":          true,
+		"10.0.0.5:7070":  true,
+	} {
+		config := &Config{Remote: &RemoteConfig{Listen: listen}}
+		if err := checkRemoteTokens(config); (err != nil) != wantErr {
+			t.Errorf("checkRemoteTokens(%s) = %v, want error %v", listen, err, wantErr)
+		}
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	for origin, want := range map[string]bool{
+		"":                      true,
+		"http://studio-pc:7070": true,
+		"http://STUDIO-PC:7070": true,
+		"https://evil.example":  false,
+		"http://studio-pc:8080": false,
+		"null":                  false,
+	} {
+		r := httptest.NewRequest(http.MethodGet, "http://studio-pc:7070/remote", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if got := sameOrigin(r); got != want {
+			t.Errorf("sameOrigin(%q) = %v, want %v", origin, got, want)
+		}
+	}
+}
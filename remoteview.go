@@ -0,0 +1,105 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/michaelquigley/dfx"
+)
+
+// RemoteMixer is the window of a client-mode instance: the gangs mirrored from a remote server
+// as tall faders with mute buttons, in the style of a restricted page
+type RemoteMixer struct {
+	client  *RemoteClient
+	actions *dfx.ActionRegistry
+}
+
+// NewRemoteMixer creates the window for a remote client
+func NewRemoteMixer(client *RemoteClient) *RemoteMixer {
+	return &RemoteMixer{
+		client:  client,
+		actions: dfx.NewActionRegistry(),
+	}
+}
+
+// Actions returns the (empty) action registry; keybindings belong to the server's own UI
+func (rm *RemoteMixer) Actions() *dfx.ActionRegistry {
+	return rm.actions
+}
+
+// Draw renders the connection status and the mirrored gangs
+func (rm *RemoteMixer) Draw(_ *dfx.State) {
+	defer limitFrame()
+	started := time.Now()
+	defer func() { Stats.RecordFrame(time.Since(started)) }()
+
+	applyUIScale()
+	title := rm.client.GetAddr()
+	if page := rm.client.GetPage(); page != "" {
+		title = fmt.Sprintf("%s (%s)", page, title)
+	}
+	imgui.Text(title)
+	if !rm.client.IsConnected() {
+		imgui.SameLine()
+		imgui.TextColored(errorColor, tr("disconnected"))
+	}
+	if err := rm.client.GetError(); err != "" {
+		imgui.TextColored(errorColor, err)
+	}
+
+	gangs := rm.client.GetGangs()
+	if len(gangs) == 0 {
+		imgui.TextDisabled(tr("No gangs"))
+		return
+	}
+
+	height := imgui.ContentRegionAvail().Y - 3*imgui.FrameHeightWithSpacing()
+	if height < scaled(100) {
+		height = scaled(100)
+	}
+	for i, gang := range gangs {
+		if i > 0 {
+			imgui.SameLine()
+		}
+		imgui.PushIDStr(gang.Name)
+		imgui.BeginGroup()
+		imgui.TextDisabled(gang.Name)
+
+		locked := gang.Locked || !rm.client.IsConnected()
+		if locked {
+			imgui.BeginDisabled()
+		}
+		switch {
+		case gang.Display != "":
+			imgui.ProgressBarV(gang.Level, scaledVec2(imgui.Vec2{X: pageFaderWidth, Y: 10}), gang.Text)
+		case gang.Toggle:
+			on := gang.Value != 0
+			if imgui.Checkbox("##remote_toggle", &on) {
+				logError(rm.client.Set(gang.Name, boolToValue(on)))
+			}
+		default:
+			value := int32(gang.Value)
+			if imgui.VSliderIntV("##remote_fader", imgui.Vec2{X: scaled(pageFaderWidth), Y: height}, &value,
+				int32(gang.Min), int32(gang.Max), "", imgui.SliderFlagsNone) {
+				logError(rm.client.Set(gang.Name, int64(value)))
+			}
+			imgui.Text(gang.Text)
+			if gang.Muted {
+				imgui.PushStyleColorVec4(imgui.ColButton, mutedColor)
+			}
+			if imgui.ButtonV(tr("Mute"), imgui.Vec2{X: scaled(pageFaderWidth), Y: 0}) {
+				logError(rm.client.SetMuted(gang.Name, !gang.Muted))
+			}
+			if gang.Muted {
+				imgui.PopStyleColor()
+			}
+		}
+		if locked {
+			imgui.EndDisabled()
+		}
+
+		imgui.EndGroup()
+		imgui.PopID()
+	}
+}
@@ -0,0 +1,107 @@
+package sessionmixer
+
+import (
+	"errors"
+	"log"
+	"os"
+	"runtime"
+	"time"
+)
+
+// frameInterval is the minimum time between frames (0 = unlimited)
+var frameInterval time.Duration
+
+// nextFrame is when the next frame may start
+var nextFrame time.Time
+
+// SetRendering applies the frame rate cap and vsync setting; vsync must be set before the window
+// is created, as the GL drivers read it when the context is made
+func SetRendering(config *RenderingConfig) {
+	frameInterval = 0
+	if config == nil {
+		return
+	}
+	if config.MaxFPS > 0 {
+		frameInterval = time.Second / time.Duration(config.MaxFPS)
+	}
+	if config.Vsync != nil {
+		setVsync(*config.Vsync)
+	}
+	if config.Software {
+		UseSoftwareRendering()
+	}
+}
+
+// Rendering fallbacks when the mixer window cannot be opened
+const (
+	FallbackNone     = ""         // Exit with diagnostics
+	FallbackTerminal = "terminal" // Keep running without a window, printing gang changes
+)
+
+// UseSoftwareRendering makes Mesa create the GL context on its software rasterizer (llvmpipe),
+// for old or missing GPU drivers; must be called before the window is created
+func UseSoftwareRendering() {
+	if err := os.Setenv("LIBGL_ALWAYS_SOFTWARE", "1"); err != nil {
+		log.Printf("software rendering: %v", err)
+	}
+}
+
+// CheckDisplay reports a missing display before the window is tried: on Linux the window needs
+// an X11 or Wayland session
+func CheckDisplay() error {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		return &ErrNoGraphics{Cause: errors.New("no display (neither DISPLAY nor WAYLAND_DISPLAY is set)")}
+	}
+	return nil
+}
+
+// GraphicsHints suggests ways around a window that could not be opened
+func GraphicsHints() []string {
+	hints := []string{
+		"run headless with 'sessionmixer daemon', or control a mixer elsewhere with 'sessionmixer connect'",
+		"keep running without a window: --fallback terminal (or rendering.fallback: terminal)",
+	}
+	if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		return append([]string{"start it from a desktop session, or over SSH with X forwarding (ssh -X)"}, hints...)
+	}
+	if os.Getenv("LIBGL_ALWAYS_SOFTWARE") == "" {
+		hints = append([]string{"OpenGL may be unavailable; try the software renderer: --software (or rendering.software: true)"}, hints...)
+	} else {
+		hints = append([]string{"the software renderer failed too; check that Mesa is installed (glxinfo -B)"}, hints...)
+	}
+	return hints
+}
+
+// setVsync asks the Mesa and NVIDIA GL drivers to sync buffer swaps to the display refresh (or
+// not), unless the user's environment already does
+func setVsync(vsync bool) {
+	mesa, nvidia := "0", "0"
+	if vsync {
+		mesa, nvidia = "3", "1"
+	}
+	for env, value := range map[string]string{"vblank_mode": mesa, "__GL_SYNC_TO_VBLANK": nvidia} {
+		if _, set := os.LookupEnv(env); set {
+			continue
+		}
+		if err := os.Setenv(env, value); err != nil {
+			log.Printf("vsync: %v", err)
+		}
+	}
+}
+
+// limitFrame sleeps until the next frame is due under the frame rate cap (UI thread, once per
+// frame, after drawing)
+func limitFrame() {
+	if frameInterval <= 0 {
+		return
+	}
+	now := time.Now()
+	if wait := nextFrame.Sub(now); wait > 0 {
+		time.Sleep(wait)
+		now = nextFrame
+	}
+	nextFrame = now.Add(frameInterval)
+}
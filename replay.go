@@ -0,0 +1,201 @@
+package sessionmixer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// maxCaptureLine bounds a capture line; gangs records of large configurations are the longest
+const maxCaptureLine = 16 * 1024 * 1024
+
+// ReplayStep is a capture record applied by Replay, with the writes the mixer logic made for it
+type ReplayStep struct {
+	Line   int
+	Record CaptureRecord
+	Writes []CaptureRecord // Writes made while applying the record
+	Err    error           // Error returned by the gang for a change record
+}
+
+// ReplayResult summarizes a replay
+type ReplayResult struct {
+	Events   int    // Hardware events replayed
+	Changes  int    // Gang changes replayed
+	Recorded int    // Writes to gang controls in the capture
+	Replayed int    // Writes made by the replay
+	Mismatch string // First difference between the recorded and replayed writes ("" if none)
+}
+
+// replayControl is the ControlIO of a replayed channel: the value is held in memory and every
+// write is reported to the replay
+type replayControl struct {
+	control *scarlettctl.Control
+	value   atomic.Int64
+	onWrite func(control *scarlettctl.Control, value int64)
+}
+
+func (rc *replayControl) GetValue() (int64, error) {
+	return rc.value.Load(), nil
+}
+
+func (rc *replayControl) SetValue(value int64) error {
+	rc.value.Store(value)
+	rc.onWrite(rc.control, value)
+	return nil
+}
+
+// Replay drives the mixer logic with a capture written by StartCapture, without the hardware:
+// gangs are rebuilt from each gangs record over in-memory controls, hardware events are fed to
+// an event monitor and gang changes are applied in capture order, ignoring the recorded timing
+// Each applied record is passed to emit (if not nil); the writes the replay makes to gang
+// controls are compared with the recorded ones and the first difference is reported
+func Replay(path string, emit func(ReplayStep)) (*ReplayResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open capture: %w", err)
+	}
+	defer file.Close()
+
+	var step *ReplayStep
+	var recorded []ReplayStep // Recorded writes, with the line they came from
+	var replayed []CaptureRecord
+	onWrite := func(control *scarlettctl.Control, value int64) {
+		write := CaptureRecord{Type: CaptureTypeWrite, NumID: control.NumID, Control: control.Name, Value: value}
+		replayed = append(replayed, write)
+		if step != nil {
+			step.Writes = append(step.Writes, write)
+		}
+	}
+
+	result := &ReplayResult{}
+	monitor := NewEventMonitor(nil, nil)
+	var gangs []*GangedFader
+	var controls map[uint]*replayControl
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxCaptureLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var record CaptureRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("capture line %d: %w", line, err)
+		}
+
+		step = &ReplayStep{Line: line, Record: record}
+		switch record.Type {
+		case CaptureTypeGangs:
+			gangs, controls, err = replayGangs(record.Gangs, onWrite)
+			if err != nil {
+				return nil, fmt.Errorf("capture line %d: %w", line, err)
+			}
+			monitor.SetGangs(gangs)
+
+		case CaptureTypeEvent:
+			if gangs == nil {
+				return nil, fmt.Errorf("capture line %d: event before the gang layout", line)
+			}
+			control := &scarlettctl.Control{NumID: record.NumID, Name: record.Control}
+			if rc, ok := controls[record.NumID]; ok {
+				rc.value.Store(record.Value)
+				control = rc.control
+			}
+			if err := monitor.handleControlChange(control, record.Value); err != nil {
+				return nil, fmt.Errorf("capture line %d: %w", line, err)
+			}
+			result.Events++
+
+		case CaptureTypeChange:
+			gang := findGang(gangs, record.Gang)
+			if gang == nil {
+				return nil, fmt.Errorf("capture line %d: unknown gang '%s'", line, record.Gang)
+			}
+			step.Err = gang.HandleUIChange(record.Value)
+			result.Changes++
+
+		case CaptureTypeWrite:
+			// Writes to controls outside the gangs (input settings, routing) cannot be replayed
+			if _, ok := controls[record.NumID]; ok {
+				recorded = append(recorded, ReplayStep{Line: line, Record: record})
+			}
+
+		default:
+			return nil, fmt.Errorf("capture line %d: unknown record type '%s'", line, record.Type)
+		}
+
+		if emit != nil {
+			emit(*step)
+		}
+		step = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read capture: %w", err)
+	}
+
+	result.Recorded = len(recorded)
+	result.Replayed = len(replayed)
+	for i := 0; i < len(recorded) && i < len(replayed); i++ {
+		want, got := recorded[i].Record, replayed[i]
+		if want.NumID != got.NumID || want.Value != got.Value {
+			result.Mismatch = fmt.Sprintf("write %d (line %d): recorded %s = %d, replayed %s = %d",
+				i+1, recorded[i].Line, want.Control, want.Value, got.Control, got.Value)
+			return result, nil
+		}
+	}
+	if len(recorded) != len(replayed) {
+		result.Mismatch = fmt.Sprintf("recorded %d writes to gang controls, replayed %d", len(recorded), len(replayed))
+	}
+	return result, nil
+}
+
+// replayGangs rebuilds captured gangs over in-memory controls; a control shared by several
+// gangs is backed by a single value, as on the hardware
+func replayGangs(captured []CapturedGang, onWrite func(*scarlettctl.Control, int64)) ([]*GangedFader, map[uint]*replayControl, error) {
+	var gangs []*GangedFader
+	controls := make(map[uint]*replayControl)
+	for _, cg := range captured {
+		var channels []*MixerChannel
+		for _, cc := range cg.Controls {
+			rc, ok := controls[cc.NumID]
+			if !ok {
+				rc = &replayControl{
+					control: &scarlettctl.Control{NumID: cc.NumID, Name: cc.Name, Type: cc.Type, Min: cc.Min, Max: cc.Max},
+					onWrite: onWrite,
+				}
+				rc.value.Store(cc.Value)
+				controls[cc.NumID] = rc
+			}
+			ch, err := NewMixerChannelIO(rc.control, rc, fmt.Sprintf("%s [%s]", cg.Name, cc.Name), cg.Unit)
+			if err != nil {
+				return nil, nil, fmt.Errorf("gang '%s': %w", cg.Name, err)
+			}
+			channels = append(channels, ch)
+		}
+
+		var levels []*scarlettctl.Control
+		for _, cc := range cg.Levels {
+			levels = append(levels, &scarlettctl.Control{NumID: cc.NumID, Name: cc.Name, Type: cc.Type, Min: cc.Min, Max: cc.Max})
+		}
+
+		gang, err := NewGangedFader(cg.Name, cg.Unit, GangModeMirror, channels, levels, cg.TaperDb)
+		if err != nil {
+			return nil, nil, fmt.Errorf("gang '%s': %w", cg.Name, err)
+		}
+		gang.SetDisplay(cg.Display)
+		if cg.Ceiling != nil {
+			gang.ceiling = *cg.Ceiling
+			gang.hasCeiling = true
+		}
+
+		// Levels are fed by the replayed events, seeded with their captured values
+		gang.SetLevelEvents(true)
+		for _, cc := range cg.Levels {
+			gang.HandleLevelChange(cc.NumID, cc.Value)
+		}
+		gangs = append(gangs, gang)
+	}
+	return gangs, controls, nil
+}
@@ -0,0 +1,123 @@
+package sessionmixer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// writeCapture writes records to a capture file in a temporary directory
+func writeCapture(t *testing.T, records ...CaptureRecord) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "capture.json")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+// stereoGangs is a gangs record for a stereo gang over controls 1 and 2 at value 80
+func stereoGangs() CaptureRecord {
+	control := func(numID uint, name string) CapturedControl {
+		return CapturedControl{NumID: numID, Name: name, Type: scarlettctl.ControlTypeInteger, Min: 0, Max: 160, Value: 80}
+	}
+	return CaptureRecord{Type: CaptureTypeGangs, Gangs: []CapturedGang{{
+		Name:     "Vocal",
+		Unit:     "db",
+		Controls: []CapturedControl{control(1, "Mix A Input 01"), control(2, "Mix A Input 02")},
+	}}}
+}
+
+func TestReplayMatchesCapture(t *testing.T) {
+	path := writeCapture(t,
+		stereoGangs(),
+		CaptureRecord{Type: CaptureTypeChange, Gang: "Vocal", Value: 120},
+		CaptureRecord{Type: CaptureTypeWrite, NumID: 1, Control: "Mix A Input 01", Value: 120},
+		CaptureRecord{Type: CaptureTypeWrite, NumID: 2, Control: "Mix A Input 02", Value: 120},
+		CaptureRecord{Type: CaptureTypeEvent, NumID: 1, Control: "Mix A Input 01", Value: 120},
+		CaptureRecord{Type: CaptureTypeEvent, NumID: 2, Control: "Mix A Input 02", Value: 100},
+		// Writes to controls outside the gangs are not compared
+		CaptureRecord{Type: CaptureTypeWrite, NumID: 50, Control: "Line In 1 Air", Value: 1},
+	)
+
+	var steps []ReplayStep
+	result, err := Replay(path, func(step ReplayStep) { steps = append(steps, step) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Mismatch != "" {
+		t.Errorf("unexpected mismatch: %s", result.Mismatch)
+	}
+	if result.Events != 2 || result.Changes != 1 || result.Recorded != 2 || result.Replayed != 2 {
+		t.Errorf("result = %+v", result)
+	}
+	if len(steps) != 7 || len(steps[1].Writes) != 2 {
+		t.Errorf("expected the change on line 2 to make both writes: %+v", steps)
+	}
+}
+
+func TestReplayReportsDivergence(t *testing.T) {
+	path := writeCapture(t,
+		stereoGangs(),
+		CaptureRecord{Type: CaptureTypeChange, Gang: "Vocal", Value: 120},
+		CaptureRecord{Type: CaptureTypeWrite, NumID: 1, Control: "Mix A Input 01", Value: 120},
+		CaptureRecord{Type: CaptureTypeWrite, NumID: 2, Control: "Mix A Input 02", Value: 110},
+	)
+
+	result, err := Replay(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Mismatch == "" {
+		t.Fatal("expected the replay to diverge from the capture")
+	}
+}
+
+func TestReplayRejectsEventsBeforeGangs(t *testing.T) {
+	path := writeCapture(t, CaptureRecord{Type: CaptureTypeEvent, NumID: 1, Value: 10})
+	if _, err := Replay(path, nil); err == nil {
+		t.Fatal("expected an error for an event before the gang layout")
+	}
+}
+
+func TestCaptureRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.json")
+	if err := StartCapture(path); err != nil {
+		t.Fatal(err)
+	}
+	gang, _ := newFakeGang(t, "Vocal", 0, 160, 80, 1, 2)
+	CaptureGangs([]*GangedFader{gang})
+	em := NewEventMonitor(nil, []*GangedFader{gang})
+	if err := gang.HandleUIChange(120); err != nil {
+		t.Fatal(err)
+	}
+	event(t, em, 2, 100)
+	if err := gang.HandleUIChange(100); err != nil {
+		t.Fatal(err)
+	}
+	if err := StopCapture(); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Replay(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Mismatch != "" {
+		t.Errorf("replay of a live capture diverged: %s", result.Mismatch)
+	}
+	if result.Changes != 2 || result.Events != 1 || result.Recorded != 3 {
+		t.Errorf("result = %+v", result)
+	}
+}
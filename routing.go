@@ -0,0 +1,151 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// RoutingSink wraps a single routing destination (physical output, mixer input, PCM capture...)
+// The underlying control is enumerated; its items are the available sources
+type RoutingSink struct {
+	category scarlettctl.PortCategory
+	channel  *MixerChannel
+}
+
+// GetName returns the ALSA control name of the sink
+func (rs *RoutingSink) GetName() string {
+	return rs.channel.GetControl().Name
+}
+
+// GetSource returns the name of the source currently routed to this sink
+func (rs *RoutingSink) GetSource() string {
+	return enumItemName(rs.channel.GetControl(), rs.channel.GetCurrentValue())
+}
+
+// SetSource routes the named source to this sink
+func (rs *RoutingSink) SetSource(source string) error {
+	for i, item := range rs.channel.GetControl().Items {
+		if item == source {
+			return rs.channel.HandleUIChange(int64(i))
+		}
+	}
+	return fmt.Errorf("sink %s: unknown source '%s'", rs.GetName(), source)
+}
+
+// RoutingPanel renders the device's source-assignment controls as a patchbay
+type RoutingPanel struct {
+	sinks []*RoutingSink
+}
+
+// NewRoutingPanel discovers the routing controls on the card and creates a routing panel
+// The panel is empty if the device exposes no routing controls
+func NewRoutingPanel(card *scarlettctl.Card) (*RoutingPanel, error) {
+	panel := &RoutingPanel{}
+
+	sinks, err := card.GetRoutingSinks()
+	if err != nil {
+		// Devices without routing controls report an error rather than an empty list
+		log.Printf("Routing controls unavailable: %v", err)
+		return panel, nil
+	}
+
+	for _, sink := range sinks {
+		ch, err := NewMixerChannel(sink.Control, sink.Name, "raw")
+		if err != nil {
+			return nil, fmt.Errorf("routing sink %s: %w", sink.Name, err)
+		}
+		panel.sinks = append(panel.sinks, &RoutingSink{category: sink.Category, channel: ch})
+	}
+
+	return panel, nil
+}
+
+// HasSinks returns true if the device provides any routing controls
+func (rp *RoutingPanel) HasSinks() bool {
+	return len(rp.sinks) > 0
+}
+
+// GetSinks returns the routing sinks
+func (rp *RoutingPanel) GetSinks() []*RoutingSink {
+	return rp.sinks
+}
+
+// GetChannels returns the channel for every sink, for event monitor registration
+func (rp *RoutingPanel) GetChannels() []*MixerChannel {
+	var channels []*MixerChannel
+	for _, sink := range rp.sinks {
+		channels = append(channels, sink.channel)
+	}
+	return channels
+}
+
+// GetRouting returns the current routing as a map of sink name to source name
+func (rp *RoutingPanel) GetRouting() map[string]string {
+	routing := make(map[string]string)
+	for _, sink := range rp.sinks {
+		routing[sink.GetName()] = sink.GetSource()
+	}
+	return routing
+}
+
+// SetRouting applies a map of sink name to source name
+// Sinks not present on this device are skipped; the last error is returned
+func (rp *RoutingPanel) SetRouting(routing map[string]string) error {
+	var lastErr error
+	for _, sink := range rp.sinks {
+		source, ok := routing[sink.GetName()]
+		if !ok {
+			continue
+		}
+		if err := sink.SetSource(source); err != nil {
+			log.Printf("Failed to route %s: %v", sink.GetName(), err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// Draw renders the patchbay as a list of sinks grouped by category, each with a source selector
+func (rp *RoutingPanel) Draw() {
+	if len(rp.sinks) == 0 {
+		return
+	}
+
+	for _, category := range []scarlettctl.PortCategory{
+		scarlettctl.PortCategoryHW,
+		scarlettctl.PortCategoryMix,
+		scarlettctl.PortCategoryDSP,
+		scarlettctl.PortCategoryPCM,
+	} {
+		var sinks []*RoutingSink
+		for _, sink := range rp.sinks {
+			if sink.category == category {
+				sinks = append(sinks, sink)
+			}
+		}
+		if len(sinks) == 0 {
+			continue
+		}
+
+		imgui.SeparatorText(category.String())
+		imgui.BeginTableV(fmt.Sprintf("routing_table_%d", category), 2,
+			imgui.TableFlagsNone,
+			scaledVec2(imgui.Vec2{X: 500, Y: 0}), 0.0)
+		imgui.TableSetupColumnV("##sink", imgui.TableColumnFlagsWidthFixed, 300, 0)
+		imgui.TableSetupColumnV("##source", imgui.TableColumnFlagsWidthFixed, 200, 0)
+
+		for _, sink := range sinks {
+			imgui.TableNextRow()
+			imgui.TableNextColumn()
+			imgui.Text(sink.GetName())
+			imgui.TableNextColumn()
+			imgui.SetNextItemWidth(-1)
+			drawControlWidget(fmt.Sprintf("##route_%d", sink.channel.GetControl().NumID), sink.channel)
+		}
+
+		imgui.EndTable()
+	}
+}
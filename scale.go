@@ -0,0 +1,105 @@
+package sessionmixer
+
+import (
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+const (
+	// minUIScale and maxUIScale bound the UI scale factor
+	minUIScale = 0.5
+	maxUIScale = 4.0
+)
+
+// uiScale multiplies fonts, fader dimensions and spacing (1 = 96 dpi layout)
+var uiScale float32 = 1
+
+// uiScaleFixed is set when the scale comes from the config or the environment; otherwise the
+// display's DPI scale is used once the window exists
+var uiScaleFixed bool
+
+// SetUIScale sets the UI scale factor: scale, or the environment (GDK_SCALE and GDK_DPI_SCALE,
+// QT_SCALE_FACTOR) when 0, falling back to the display's DPI scale on the first frame
+func SetUIScale(scale float32) {
+	if scale <= 0 {
+		scale = DetectUIScale()
+	}
+	uiScaleFixed = scale > 0
+	if !uiScaleFixed {
+		scale = 1
+	}
+	if scale < minUIScale || scale > maxUIScale {
+		log.Printf("UI scale %.2f out of range [%.1f..%.1f], clamped", scale, minUIScale, maxUIScale)
+		scale = max(minUIScale, min(maxUIScale, scale))
+	}
+	uiScale = scale
+}
+
+// UIScale returns the UI scale factor
+func UIScale() float32 {
+	return uiScale
+}
+
+// DetectUIScale returns the scale requested by the desktop environment's toolkit variables,
+// or 0 if none is set
+func DetectUIScale() float32 {
+	scale := float32(1)
+	found := false
+	for _, env := range []string{"GDK_SCALE", "GDK_DPI_SCALE"} {
+		if value, ok := envScale(env); ok {
+			scale *= value
+			found = true
+		}
+	}
+	if found {
+		return scale
+	}
+	if value, ok := envScale("QT_SCALE_FACTOR"); ok {
+		return value
+	}
+	return 0
+}
+
+// envScale parses a positive scale factor from an environment variable
+func envScale(env string) (float32, bool) {
+	value, err := strconv.ParseFloat(os.Getenv(env), 32)
+	if err != nil || value <= 0 {
+		return 0, false
+	}
+	return float32(value), true
+}
+
+// uiScaleApplied is set once the style has been scaled
+var uiScaleApplied bool
+
+// applyUIScale scales the ImGui style and fonts on the first frame (UI thread)
+func applyUIScale() {
+	if uiScaleApplied {
+		return
+	}
+	uiScaleApplied = true
+	if !uiScaleFixed {
+		if dpi := imgui.MainViewport().DpiScale(); dpi > 0 {
+			uiScale = max(minUIScale, min(maxUIScale, dpi))
+		}
+	}
+	if uiScale == 1 {
+		return
+	}
+	style := imgui.CurrentStyle()
+	style.ScaleAllSizes(uiScale)
+	style.SetFontScaleDpi(uiScale)
+}
+
+// scaled converts a layout size at scale 1 to the UI scale
+func scaled(size float32) float32 {
+	return size * uiScale
+}
+
+// scaledVec2 converts a layout size at scale 1 to the UI scale
+func scaledVec2(size imgui.Vec2) imgui.Vec2 {
+	return imgui.Vec2{X: scaled(size.X), Y: scaled(size.Y)}
+}
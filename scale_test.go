@@ -0,0 +1,37 @@
+package sessionmixer
+
+import "testing"
+
+func TestDetectUIScale(t *testing.T) {
+	tests := []struct {
+		gdkScale, gdkDpiScale, qtScale string
+		want                           float32
+	}{
+		{"", "", "", 0},
+		{"2", "", "", 2},
+		{"2", "0.75", "", 1.5},
+		{"", "", "1.25", 1.25},
+		{"2", "", "1.25", 2},
+		{"bogus", "", "", 0},
+	}
+	for _, test := range tests {
+		t.Setenv("GDK_SCALE", test.gdkScale)
+		t.Setenv("GDK_DPI_SCALE", test.gdkDpiScale)
+		t.Setenv("QT_SCALE_FACTOR", test.qtScale)
+		if got := DetectUIScale(); got != test.want {
+			t.Errorf("DetectUIScale() with %+v = %v, want %v", test, got, test.want)
+		}
+	}
+}
+
+func TestSetUIScaleClamps(t *testing.T) {
+	defer SetUIScale(1)
+	SetUIScale(10)
+	if got := UIScale(); got != maxUIScale {
+		t.Errorf("UIScale() = %v, want %v", got, maxUIScale)
+	}
+	SetUIScale(1.5)
+	if got := scaled(80); got != 120 {
+		t.Errorf("scaled(80) = %v, want 120", got)
+	}
+}
@@ -0,0 +1,329 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/michaelquigley/df/dd"
+)
+
+// Scene is a named snapshot of the mixer state
+type Scene struct {
+	Name    string            `dd:"+required"`
+	Gangs   map[string]int64  // Gang name -> raw fader value
+	Routing map[string]string // Routing sink control name -> source name
+}
+
+// SceneManager captures, stores and recalls scenes
+// Scenes are stored as individual YAML files in a scenes directory
+type SceneManager struct {
+	dir     string
+	gangs   []*GangedFader
+	routing *RoutingPanel
+
+	mu      sync.Mutex
+	current *Scene // Most recently saved or recalled scene
+}
+
+// SetGangs replaces the gangs scenes capture and recall (e.g. after a profile switch)
+func (scm *SceneManager) SetGangs(gangs []*GangedFader) {
+	scm.mu.Lock()
+	defer scm.mu.Unlock()
+	scm.gangs = gangs
+}
+
+// getGangs returns the current gangs
+func (scm *SceneManager) getGangs() []*GangedFader {
+	scm.mu.Lock()
+	defer scm.mu.Unlock()
+	return scm.gangs
+}
+
+// NewSceneManager creates a scene manager storing scenes in dir
+// routing may be nil if the device has no routing controls
+func NewSceneManager(dir string, gangs []*GangedFader, routing *RoutingPanel) *SceneManager {
+	return &SceneManager{
+		dir:     dir,
+		gangs:   gangs,
+		routing: routing,
+	}
+}
+
+// Capture creates a scene from the current gang values and routing
+func (scm *SceneManager) Capture(name string) *Scene {
+	scene := &Scene{
+		Name:  name,
+		Gangs: make(map[string]int64),
+	}
+	for _, gang := range scm.getGangs() {
+		if !gang.IsReadOnly() {
+			scene.Gangs[gang.GetName()] = gang.GetCurrentValue()
+		}
+	}
+	if scm.routing != nil && scm.routing.HasSinks() {
+		scene.Routing = scm.routing.GetRouting()
+	}
+	return scene
+}
+
+// Save captures the current state and writes it as the named scene
+func (scm *SceneManager) Save(name string) error {
+	path, err := scm.scenePath(name)
+	if err != nil {
+		return err
+	}
+	scene := scm.Capture(name)
+	if err := scm.write(scene, path); err != nil {
+		return err
+	}
+
+	scm.setCurrent(scene)
+	return nil
+}
+
+// Store writes a scene under its own name without applying it
+func (scm *SceneManager) Store(scene *Scene) error {
+	path, err := scm.scenePath(scene.Name)
+	if err != nil {
+		return err
+	}
+	return scm.write(scene, path)
+}
+
+// Differs returns true if applying the scene would change a gang or routing sink
+func (scm *SceneManager) Differs(scene *Scene) bool {
+	current := scm.Capture("")
+	for _, gang := range scm.getGangs() {
+		value, ok := scene.Gangs[gang.GetName()]
+		if ok && !gang.IsSafe() && !gang.IsReadOnly() && value != current.Gangs[gang.GetName()] {
+			return true
+		}
+	}
+	for sink, source := range scene.Routing {
+		if current, ok := current.Routing[sink]; ok && current != source {
+			return true
+		}
+	}
+	return false
+}
+
+// Modified returns a gang's value in the scene if the gang has moved from it; display channels
+// and gangs outside the scene are never modified
+func (scene *Scene) Modified(gang *GangedFader) (int64, bool) {
+	value, ok := scene.Gangs[gang.GetName()]
+	if !ok || gang.IsReadOnly() {
+		return 0, false
+	}
+	return value, value != gang.GetCurrentValue()
+}
+
+// write saves a scene to path, creating the scenes directory if needed
+func (scm *SceneManager) write(scene *Scene, path string) error {
+	if err := os.MkdirAll(scm.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create scenes directory: %w", err)
+	}
+	if err := dd.UnbindToYAML(scene, path); err != nil {
+		return fmt.Errorf("failed to save scene '%s': %w", scene.Name, err)
+	}
+	return nil
+}
+
+// Load reads the named scene from disk without applying it
+func (scm *SceneManager) Load(name string) (*Scene, error) {
+	path, err := scm.scenePath(name)
+	if err != nil {
+		return nil, err
+	}
+	scene, err := dd.NewFromYAML[Scene](path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scene '%s': %w", name, err)
+	}
+	return scene, nil
+}
+
+// Recall loads the named scene and applies it to the hardware
+func (scm *SceneManager) Recall(name string) error {
+	scene, err := scm.Load(name)
+	if err != nil {
+		return err
+	}
+	return scm.Apply(scene)
+}
+
+// Apply writes a scene's gang values and routing to the hardware
+// Gangs and sinks not present in the scene, and recall-safe gangs, are left untouched;
+// the last error is returned
+func (scm *SceneManager) Apply(scene *Scene) error {
+	var lastErr error
+	auditScene(scene.Name)
+
+	for _, gang := range scm.getGangs() {
+		value, ok := scene.Gangs[gang.GetName()]
+		if !ok || gang.IsSafe() || gang.IsReadOnly() {
+			continue
+		}
+		if err := gang.HandleUIChange(value); err != nil {
+			log.Printf("Failed to recall %s: %v", gang.GetName(), err)
+			lastErr = err
+		}
+	}
+
+	if scm.routing != nil && len(scene.Routing) > 0 {
+		if err := scm.routing.SetRouting(scene.Routing); err != nil {
+			lastErr = err
+		}
+	}
+
+	scm.setCurrent(scene)
+	return lastErr
+}
+
+// floorDb stands in for -∞ when interpolating or editing values in dB space
+const floorDb = -80.0
+
+// Morph blends gang values between two scenes in dB space; t=0 is scene a, t=1 is scene b
+// Only gangs present in both scenes, and not recall-safe, are written. Routing cannot be
+// blended, so it is applied only at the end points
+func (scm *SceneManager) Morph(a, b *Scene, t float64) error {
+	t = math.Max(0, math.Min(1, t))
+	var lastErr error
+
+	for _, gang := range scm.getGangs() {
+		valueA, okA := a.Gangs[gang.GetName()]
+		valueB, okB := b.Gangs[gang.GetName()]
+		if !okA || !okB || gang.IsSafe() || gang.IsReadOnly() {
+			continue
+		}
+
+		dbA := math.Max(gang.ValueToDb(valueA), floorDb)
+		dbB := math.Max(gang.ValueToDb(valueB), floorDb)
+		db := dbA + (dbB-dbA)*t
+
+		value := gang.DbToValue(db)
+		if db <= floorDb {
+			value = gang.GetMin()
+		}
+		if err := gang.HandleUIChange(value); err != nil {
+			log.Printf("Failed to morph %s: %v", gang.GetName(), err)
+			lastErr = err
+		}
+	}
+
+	if scm.routing != nil {
+		var routing map[string]string
+		if t <= 0 {
+			routing = a.Routing
+		} else if t >= 1 {
+			routing = b.Routing
+		}
+		if len(routing) > 0 {
+			if err := scm.routing.SetRouting(routing); err != nil {
+				lastErr = err
+			}
+		}
+	}
+
+	return lastErr
+}
+
+// sceneFadeInterval is the step of a scene fade
+const sceneFadeInterval = 20 * time.Millisecond
+
+// Fade recalls the named scene gradually: gang values morph from the current state to the
+// scene over the fade time, then the scene is applied exactly (routing switches at the end)
+// Returns the last error of any step
+func (scm *SceneManager) Fade(name string, fade time.Duration) error {
+	scene, err := scm.Load(name)
+	if err != nil {
+		return err
+	}
+	if fade <= 0 {
+		return scm.Apply(scene)
+	}
+
+	from := scm.Capture("")
+	from.Routing = nil // Routing is left alone until the end
+	var lastErr error
+	started := time.Now()
+	for elapsed := time.Duration(0); elapsed < fade; elapsed = time.Since(started) {
+		if err := scm.Morph(from, scene, float64(elapsed)/float64(fade)); err != nil {
+			lastErr = err
+		}
+		time.Sleep(sceneFadeInterval)
+	}
+	if err := scm.Apply(scene); err != nil {
+		lastErr = err
+	}
+	return lastErr
+}
+
+// List returns the names of all stored scenes, sorted
+func (scm *SceneManager) List() ([]string, error) {
+	entries, err := os.ReadDir(scm.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Step recalls the scene delta places after the current one in List order, wrapping around;
+// without a current scene, stepping forward starts at the first scene and back at the last
+func (scm *SceneManager) Step(delta int) error {
+	names, err := scm.List()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no scenes stored")
+	}
+	pos := -1
+	if current := scm.GetCurrent(); current != nil {
+		pos = slices.Index(names, current.Name)
+	}
+	if pos < 0 && delta < 0 {
+		pos = len(names)
+	}
+	n := len(names)
+	return scm.Recall(names[((pos+delta)%n+n)%n])
+}
+
+// GetCurrent returns the most recently saved or recalled scene, or nil
+func (scm *SceneManager) GetCurrent() *Scene {
+	scm.mu.Lock()
+	defer scm.mu.Unlock()
+	return scm.current
+}
+
+func (scm *SceneManager) setCurrent(scene *Scene) {
+	scm.mu.Lock()
+	defer scm.mu.Unlock()
+	scm.current = scene
+}
+
+// scenePath returns the file path for a scene name
+func (scm *SceneManager) scenePath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid scene name '%s'", name)
+	}
+	return filepath.Join(scm.dir, name+".yaml"), nil
+}
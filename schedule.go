@@ -0,0 +1,156 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/michaelquigley/df/dd"
+)
+
+// schedulerInterval is how often the scheduler checks for due entries
+const schedulerInterval = 10 * time.Second
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// scheduleFile is the layout of a standalone schedule file
+type scheduleFile struct {
+	Schedule []ScheduleEntry
+}
+
+// LoadSchedule returns the configured schedule entries, including those from the schedule file
+// A relative schedule file path is resolved against the configuration directory
+func LoadSchedule(cfg *Config) ([]ScheduleEntry, error) {
+	entries := append([]ScheduleEntry(nil), cfg.Schedule...)
+	if cfg.ScheduleFile == "" {
+		return entries, nil
+	}
+
+	path := cfg.ScheduleFile
+	if !filepath.IsAbs(path) {
+		dir, err := ConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, path)
+	}
+	file, err := dd.NewFromYAML[scheduleFile](path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schedule file '%s': %w", path, err)
+	}
+	return append(entries, file.Schedule...), nil
+}
+
+// scheduledRecall is a parsed schedule entry
+type scheduledRecall struct {
+	hour, minute int
+	days         map[time.Weekday]bool // Nil means every day
+	scene        string
+	lastFired    string // Date (YYYY-MM-DD) the entry last fired
+}
+
+// Scheduler recalls scenes at configured times of day
+type Scheduler struct {
+	scenes  *SceneManager
+	entries []*scheduledRecall
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewScheduler validates the schedule entries and creates a scheduler recalling through scenes
+func NewScheduler(entries []ScheduleEntry, scenes *SceneManager) (*Scheduler, error) {
+	s := &Scheduler{
+		scenes: scenes,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	for _, entry := range entries {
+		at, err := time.Parse("15:04", entry.At)
+		if err != nil {
+			return nil, fmt.Errorf("schedule entry for scene '%s': invalid time '%s' (expected HH:MM)", entry.Scene, entry.At)
+		}
+		recall := &scheduledRecall{
+			hour:   at.Hour(),
+			minute: at.Minute(),
+			scene:  entry.Scene,
+		}
+		for _, day := range entry.Days {
+			key := strings.ToLower(day)
+			if len(key) > 3 {
+				key = key[:3] // Accept full day names
+			}
+			weekday, ok := weekdays[key]
+			if !ok {
+				return nil, fmt.Errorf("schedule entry for scene '%s': invalid day '%s'", entry.Scene, day)
+			}
+			if recall.days == nil {
+				recall.days = make(map[time.Weekday]bool)
+			}
+			recall.days[weekday] = true
+		}
+		s.entries = append(s.entries, recall)
+	}
+	return s, nil
+}
+
+// HasEntries returns true if any scene recalls are scheduled
+func (s *Scheduler) HasEntries() bool {
+	return len(s.entries) > 0
+}
+
+// Start begins checking the schedule in a background goroutine
+func (s *Scheduler) Start() {
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(schedulerInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case now := <-ticker.C:
+				s.Update(now)
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler; blocks until the goroutine has exited
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+}
+
+// Update recalls every entry that is due at now and has not yet fired today
+func (s *Scheduler) Update(now time.Time) {
+	today := now.Format(time.DateOnly)
+	for _, entry := range s.entries {
+		if entry.lastFired == today || now.Hour() != entry.hour || now.Minute() != entry.minute {
+			continue
+		}
+		if entry.days != nil && !entry.days[now.Weekday()] {
+			continue
+		}
+		entry.lastFired = today
+
+		log.Printf("Scheduler: recalling scene '%s' (%02d:%02d)", entry.scene, entry.hour, entry.minute)
+		if err := s.scenes.Recall(entry.scene); err != nil {
+			log.Printf("Scheduler: failed to recall scene '%s': %v", entry.scene, err)
+		}
+	}
+}
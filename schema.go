@@ -0,0 +1,121 @@
+package sessionmixer
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ConfigSchema returns a JSON Schema (draft 2020-12) describing the configuration file
+// The schema is derived from the Config struct using the same field naming (snake_case or the
+// `dd` tag name) and required markers as the dd binder, so it stays in step with the code
+func ConfigSchema() map[string]any {
+	schema := typeSchema(reflect.TypeOf(Config{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "sessionmixer configuration"
+	return schema
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// typeSchema returns the schema of a Go type as bound by dd
+func typeSchema(t reflect.Type) map[string]any {
+	if t == durationType {
+		return map[string]any{
+			"type":        "string",
+			"pattern":     `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`,
+			"description": "duration, e.g. 50ms, 1s, 2m30s",
+		}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]any)
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, isRequired, skip := schemaField(field)
+			if skip {
+				continue
+			}
+			properties[name] = typeSchema(field.Type)
+			if isRequired {
+				required = append(required, name)
+			}
+		}
+		schema := map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	default:
+		return map[string]any{}
+	}
+}
+
+// schemaField returns the external name of a struct field and whether it is required, following
+// the dd tag format: dd:"[name][,+required]" or dd:"-"
+func schemaField(field reflect.StructField) (name string, required, skip bool) {
+	tag := field.Tag.Get("dd")
+	if tag == "-" {
+		return "", false, true
+	}
+	for i, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "+required":
+			required = true
+		case i == 0 && part != "" && !strings.HasPrefix(part, "+"):
+			name = part
+		}
+	}
+	if name == "" {
+		name = snakeCase(field.Name)
+	}
+	return name, required, false
+}
+
+// snakeCase converts a Go field name to dd's snake_case key (e.g. TaperDb -> taper_db,
+// LevelEvents -> level_events)
+func snakeCase(in string) string {
+	runes := []rune(in)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				prevLower := unicode.IsLower(runes[i-1])
+				prevUpper := unicode.IsUpper(runes[i-1])
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if prevLower || (prevUpper && nextLower) {
+					b.WriteByte('_')
+				}
+			}
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
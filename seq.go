@@ -0,0 +1,204 @@
+package sessionmixer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// MIDIVirtualDevice is the device name that creates ALSA sequencer ports instead of opening
+	// a rawmidi device; "virtual:Name" names the ports
+	MIDIVirtualDevice = "virtual"
+
+	// defaultVirtualName names the sequencer client and its ports
+	defaultVirtualName = "SessionMixer"
+
+	seqDevice = "/dev/snd/seq"
+
+	// Sizes of the sequencer ABI structures (uapi/sound/asequencer.h)
+	seqEventSize      = 28
+	seqClientInfoSize = 188
+	seqPortInfoSize   = 168
+
+	// Sequencer event types
+	seqEventNoteOn     = 6
+	seqEventNoteOff    = 7
+	seqEventController = 10
+	seqEventPgmChange  = 11
+	seqEventChanPress  = 12
+	seqEventPitchBend  = 13
+	seqEventControl14  = 14
+
+	seqEventLengthMask     = 3 << 2
+	seqEventLengthVariable = 1 << 2
+	seqExtMask             = 0x0FFFFFFF
+
+	seqQueueDirect        = 253
+	seqAddressSubscribers = 254
+
+	// Port capabilities and types
+	seqPortCapRead      = 1 << 0
+	seqPortCapWrite     = 1 << 1
+	seqPortCapSubsRead  = 1 << 5
+	seqPortCapSubsWrite = 1 << 6
+	seqPortTypeMIDI     = 1 << 1
+	seqPortTypeApp      = 1 << 20
+)
+
+// Sequencer ioctls
+var (
+	seqIoctlClientID      = ioc(iocRead, 'S', 0x01, 4)
+	seqIoctlGetClientInfo = ioc(iocRead|iocWrite, 'S', 0x10, seqClientInfoSize)
+	seqIoctlSetClientInfo = ioc(iocWrite, 'S', 0x11, seqClientInfoSize)
+	seqIoctlCreatePort    = ioc(iocRead|iocWrite, 'S', 0x20, seqPortInfoSize)
+)
+
+// openVirtualMIDIPort creates an ALSA sequencer client with an input and an output port, so
+// DAWs and mapping tools can connect to the mixer (e.g. with aconnect) without a device path
+func openVirtualMIDIPort(device string) (*MIDIPort, error) {
+	name := defaultVirtualName
+	if _, custom, ok := strings.Cut(device, ":"); ok && custom != "" {
+		name = custom
+	}
+	file, err := os.OpenFile(seqDevice, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the ALSA sequencer: %w", err)
+	}
+
+	info := make([]byte, seqClientInfoSize)
+	if err := ioctl(file, seqIoctlClientID, info[0:4]); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read sequencer client: %w", err)
+	}
+	if err := ioctl(file, seqIoctlGetClientInfo, info); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read sequencer client: %w", err)
+	}
+	clear(info[8:72])
+	copy(info[8:71], name)
+	if err := ioctl(file, seqIoctlSetClientInfo, info); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to name sequencer client: %w", err)
+	}
+
+	in, err := seqCreatePort(file, name+" In", seqPortCapWrite|seqPortCapSubsWrite)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	out, err := seqCreatePort(file, name+" Out", seqPortCapRead|seqPortCapSubsRead)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	client := binary.NativeEndian.Uint32(info[0:4])
+	return &MIDIPort{
+		path:    fmt.Sprintf("%s (sequencer client %d, ports %d and %d)", name, client, in, out),
+		file:    file,
+		virtual: true,
+		seqOut:  out,
+	}, nil
+}
+
+// seqCreatePort creates a sequencer port of the client, returning its number
+func seqCreatePort(file *os.File, name string, capability uint32) (byte, error) {
+	info := make([]byte, seqPortInfoSize)
+	copy(info[2:65], name)
+	binary.NativeEndian.PutUint32(info[68:72], capability)
+	binary.NativeEndian.PutUint32(info[72:76], seqPortTypeMIDI|seqPortTypeApp)
+	binary.NativeEndian.PutUint32(info[76:80], 16) // MIDI channels
+	if err := ioctl(file, seqIoctlCreatePort, info); err != nil {
+		return 0, fmt.Errorf("failed to create sequencer port '%s': %w", name, err)
+	}
+	return info[1], nil
+}
+
+// encodeSeqEvent converts a channel voice message to a sequencer event sent directly to the
+// subscribers of port; false for messages without an event
+func encodeSeqEvent(msg MIDIMessage, port byte) ([]byte, bool) {
+	ev := make([]byte, seqEventSize)
+	ev[3] = seqQueueDirect
+	ev[13] = port
+	ev[14] = seqAddressSubscribers
+	ev[16] = msg.Channel()
+	switch msg.Type() {
+	case midiNoteOn, midiNoteOff:
+		ev[0] = seqEventNoteOn
+		if msg.Type() == midiNoteOff {
+			ev[0] = seqEventNoteOff
+		}
+		ev[17] = msg.Data1
+		ev[18] = msg.Data2
+	case midiControlChange:
+		ev[0] = seqEventController
+		binary.NativeEndian.PutUint32(ev[20:24], uint32(msg.Data1))
+		binary.NativeEndian.PutUint32(ev[24:28], uint32(msg.Data2))
+	case 0xC0:
+		ev[0] = seqEventPgmChange
+		binary.NativeEndian.PutUint32(ev[24:28], uint32(msg.Data1))
+	case 0xD0:
+		ev[0] = seqEventChanPress
+		binary.NativeEndian.PutUint32(ev[24:28], uint32(msg.Data1))
+	case midiPitchBend:
+		ev[0] = seqEventPitchBend
+		value := int32(msg.Data2)<<7 | int32(msg.Data1) - 8192
+		binary.NativeEndian.PutUint32(ev[24:28], uint32(value))
+	default:
+		return nil, false
+	}
+	return ev, true
+}
+
+// decodeSeqEvents converts the sequencer events in buf to channel voice messages, skipping
+// other events and any variable-length data; a 14-bit controller becomes its MSB and LSB CCs
+func decodeSeqEvents(buf []byte, handler func(MIDIMessage)) {
+	for len(buf) >= seqEventSize {
+		ev := buf[:seqEventSize]
+		size := seqEventSize
+		if ev[1]&seqEventLengthMask == seqEventLengthVariable {
+			size += int(binary.NativeEndian.Uint32(ev[16:20]) & seqExtMask)
+		}
+		buf = buf[min(size, len(buf)):]
+
+		channel := ev[16] & 0x0F
+		param := binary.NativeEndian.Uint32(ev[20:24])
+		value := int32(binary.NativeEndian.Uint32(ev[24:28]))
+		switch ev[0] {
+		case seqEventNoteOn:
+			handler(MIDIMessage{Status: midiNoteOn | channel, Data1: ev[17] & 0x7F, Data2: ev[18] & 0x7F})
+		case seqEventNoteOff:
+			handler(MIDIMessage{Status: midiNoteOff | channel, Data1: ev[17] & 0x7F, Data2: ev[18] & 0x7F})
+		case seqEventController:
+			handler(MIDIMessage{Status: midiControlChange | channel, Data1: byte(param & 0x7F), Data2: byte(value & 0x7F)})
+		case seqEventControl14:
+			if param < 32 {
+				handler(MIDIMessage{Status: midiControlChange | channel, Data1: byte(param), Data2: byte(value >> 7 & 0x7F)})
+				handler(MIDIMessage{Status: midiControlChange | channel, Data1: byte(param + 32), Data2: byte(value & 0x7F)})
+			} else {
+				handler(MIDIMessage{Status: midiControlChange | channel, Data1: byte(param & 0x7F), Data2: byte(value & 0x7F)})
+			}
+		case seqEventPgmChange:
+			handler(MIDIMessage{Status: 0xC0 | channel, Data1: byte(value & 0x7F)})
+		case seqEventChanPress:
+			handler(MIDIMessage{Status: 0xD0 | channel, Data1: byte(value & 0x7F)})
+		case seqEventPitchBend:
+			bend := max(0, min(16383, value+8192))
+			handler(MIDIMessage{Status: midiPitchBend | channel, Data1: byte(bend & 0x7F), Data2: byte(bend >> 7)})
+		}
+	}
+}
+
+// readSeq passes the messages of incoming sequencer events to handler until the port is closed
+// or fails
+func (p *MIDIPort) readSeq(handler func(MIDIMessage)) error {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := p.file.Read(buf)
+		if err != nil {
+			return err
+		}
+		decodeSeqEvents(buf[:n], handler)
+	}
+}
@@ -0,0 +1,37 @@
+package sessionmixer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSeqEventRoundTrip(t *testing.T) {
+	msgs := []MIDIMessage{
+		{Status: midiNoteOn | 3, Data1: 60, Data2: 100},
+		{Status: midiNoteOff, Data1: 61},
+		{Status: midiControlChange | 15, Data1: 7, Data2: 127},
+		{Status: midiPitchBend | 1, Data1: 0x00, Data2: 0x40},
+		{Status: midiPitchBend, Data1: 0x7F, Data2: 0x7F},
+	}
+	var buf []byte
+	for _, msg := range msgs {
+		ev, ok := encodeSeqEvent(msg, 1)
+		if !ok {
+			t.Fatalf("encodeSeqEvent(%v) failed", msg)
+		}
+		buf = append(buf, ev...)
+	}
+
+	// A variable-length event (sysex) between them is skipped with its data
+	sysex := make([]byte, seqEventSize+3)
+	sysex[0] = 130
+	sysex[1] = seqEventLengthVariable
+	sysex[16] = 3
+	buf = append(buf[:seqEventSize], append(sysex, buf[seqEventSize:]...)...)
+
+	var got []MIDIMessage
+	decodeSeqEvents(buf, func(msg MIDIMessage) { got = append(got, msg) })
+	if !slices.Equal(got, msgs) {
+		t.Errorf("decoded %v, want %v", got, msgs)
+	}
+}
@@ -0,0 +1,160 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+// defaultSmoothingInterval is the default shortest time between two smoothed writes to a gang
+const defaultSmoothingInterval = 20 * time.Millisecond
+
+// smoothTarget is the position a gang is being moved to, and where it has got to
+type smoothTarget struct {
+	target  float64
+	current float64
+}
+
+// Smoother thins out fader positions from a jittery source (a MIDI surface, an OSC tablet)
+// before they reach the hardware: positions within the deadband of the last one are dropped,
+// the rest are written at most once per interval per gang (latest position wins), and an
+// optional speed limit slews the gang toward it; the last position is always written exactly
+type Smoother struct {
+	interval time.Duration
+	speed    float64 // Largest change of fader position per second (0 = unlimited)
+	deadband float64
+	source   string // Write source the positions come from
+	write    func(gang *GangedFader, value int64) error
+
+	mu      sync.Mutex
+	targets map[*GangedFader]*smoothTarget
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewSmoother creates a smoother for positions from source, written with write; a gang claimed
+// by another source (see Claim) is abandoned mid-move
+func NewSmoother(config SmoothingConfig, source string, write func(gang *GangedFader, value int64) error) (*Smoother, error) {
+	if config.Interval < 0 || config.Speed < 0 || config.Deadband < 0 || config.Deadband >= 1 {
+		return nil, fmt.Errorf("smoothing interval, speed and deadband must not be negative, and the deadband must be below 1")
+	}
+	interval := config.Interval
+	if interval == 0 {
+		interval = defaultSmoothingInterval
+	}
+	return &Smoother{
+		interval: interval,
+		speed:    config.Speed,
+		deadband: config.Deadband,
+		source:   source,
+		write:    write,
+		targets:  make(map[*GangedFader]*smoothTarget),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Start writes the pending positions in a background goroutine
+func (smo *Smoother) Start() {
+	go func() {
+		defer close(smo.done)
+		ticker := time.NewTicker(smo.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-smo.stop:
+				return
+			case <-ticker.C:
+				smo.step()
+			}
+		}
+	}()
+}
+
+// Stop stops writing; pending positions are dropped. Blocks until the goroutine has exited
+func (smo *Smoother) Stop() {
+	smo.stopOnce.Do(func() {
+		close(smo.stop)
+		<-smo.done
+	})
+}
+
+// Set moves gang toward fader position pos (0-1); false if pos is dropped as jitter, within the
+// deadband of the position the gang is moving to (or at). The ends of the travel are never dropped
+func (smo *Smoother) Set(gang *GangedFader, pos float64) bool {
+	pos = max(0, min(1, pos))
+	smo.mu.Lock()
+	defer smo.mu.Unlock()
+	t, ok := smo.targets[gang]
+	if !ok {
+		t = &smoothTarget{current: gang.ValueToPosition(gang.GetCurrentValue())}
+		t.target = t.current
+	}
+	if math.Abs(pos-t.target) <= smo.deadband && pos != 0 && pos != 1 {
+		return false
+	}
+	t.target = pos
+	smo.targets[gang] = t
+	return true
+}
+
+// Pending returns true while gang is being moved to a position
+func (smo *Smoother) Pending(gang *GangedFader) bool {
+	smo.mu.Lock()
+	defer smo.mu.Unlock()
+	_, ok := smo.targets[gang]
+	return ok
+}
+
+// Clear drops every pending position (e.g. after a profile switch)
+func (smo *Smoother) Clear() {
+	smo.mu.Lock()
+	defer smo.mu.Unlock()
+	clear(smo.targets)
+}
+
+// step writes the next position of every pending gang, abandoning gangs that were locked or
+// taken over by another source
+func (smo *Smoother) step() {
+	type smoothWrite struct {
+		gang  *GangedFader
+		value int64
+	}
+	var writes []smoothWrite
+	smo.mu.Lock()
+	for gang, t := range smo.targets {
+		if writer, held := gang.GetWriter(); gang.IsLocked() || (held && writer != smo.source) {
+			delete(smo.targets, gang)
+			continue
+		}
+		t.current = slew(t.current, t.target, smo.speed*smo.interval.Seconds())
+		value := gang.PositionToValue(t.current)
+		if t.current == t.target {
+			delete(smo.targets, gang)
+		}
+		writes = append(writes, smoothWrite{gang, value})
+	}
+	smo.mu.Unlock()
+
+	// Written outside the lock, as a write can block on the hardware
+	for _, w := range writes {
+		if err := smo.write(w.gang, w.value); err != nil {
+			log.Printf("Smoothing: failed to set %s: %v", w.gang.GetName(), err)
+		}
+	}
+}
+
+// slew moves from toward to by at most limit (0 = no limit)
+func slew(from, to, limit float64) float64 {
+	if limit <= 0 || math.Abs(to-from) <= limit {
+		return to
+	}
+	if to > from {
+		return from + limit
+	}
+	return from - limit
+}
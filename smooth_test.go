@@ -0,0 +1,75 @@
+package sessionmixer
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSmootherCoalescesPositions(t *testing.T) {
+	gang, fakes := newFakeGang(t, "Vocal", 0, 160, 80, 1)
+	smoother, err := NewSmoother(SmoothingConfig{}, WriteSourceMIDI, (*GangedFader).HandleUIChange)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A burst of positions within one interval is written once, at the latest position
+	for _, pos := range []float64{0.6, 0.7, 0.8, 1} {
+		smoother.Set(gang, pos)
+	}
+	smoother.step()
+	if got := fakes[0].getWrites(); !slices.Equal(got, []int64{160}) {
+		t.Errorf("writes = %v, want [160]", got)
+	}
+	if smoother.Pending(gang) {
+		t.Error("gang still pending after reaching its position")
+	}
+	smoother.step()
+	if got := fakes[0].getWrites(); len(got) != 1 {
+		t.Errorf("an idle step wrote %v", got)
+	}
+}
+
+func TestSmootherSlewLimit(t *testing.T) {
+	gang, fakes := newFakeGang(t, "Vocal", 0, 160, 0, 1)
+	smoother, err := NewSmoother(SmoothingConfig{Interval: 10 * time.Millisecond, Speed: 25}, WriteSourceOSC, (*GangedFader).HandleUIChange)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 25 travels per second is a quarter of the travel per 10ms step
+	smoother.Set(gang, 1)
+	for i := 0; i < 4; i++ {
+		smoother.step()
+	}
+	writes := fakes[0].getWrites()
+	if len(writes) != 4 || writes[3] != 160 || !slices.IsSorted(writes) {
+		t.Errorf("writes = %v, want 4 rising steps ending at 160", writes)
+	}
+	if smoother.Pending(gang) {
+		t.Error("gang still pending after reaching its position")
+	}
+}
+
+func TestSmootherDeadband(t *testing.T) {
+	gang, fakes := newFakeGang(t, "Vocal", 0, 160, 80, 1)
+	smoother, err := NewSmoother(SmoothingConfig{Deadband: 0.01}, WriteSourceMIDI, (*GangedFader).HandleUIChange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos := gang.ValueToPosition(80)
+	if smoother.Set(gang, pos+0.005) {
+		t.Error("jitter within the deadband was accepted")
+	}
+	if !smoother.Set(gang, pos+0.05) {
+		t.Error("a move beyond the deadband was dropped")
+	}
+	smoother.step()
+	if got := fakes[0].getWrites(); len(got) != 1 {
+		t.Errorf("writes = %v, want one", got)
+	}
+
+	if _, err := NewSmoother(SmoothingConfig{Deadband: 1}, WriteSourceMIDI, (*GangedFader).HandleUIChange); err == nil {
+		t.Error("a deadband of the whole travel was accepted")
+	}
+}
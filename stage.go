@@ -0,0 +1,127 @@
+package sessionmixer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+// stagedColor marks values staged but not yet written to the hardware
+var stagedColor = imgui.Vec4{X: 1.0, Y: 0.85, Z: 0.3, W: 1.0}
+
+// Staging holds fader changes made in the UI until they are applied, for careful adjustments on
+// a live rig: moving a fader, typing a value or nudging with the keyboard stages the new value,
+// and Apply writes every staged value at once. Mutes, toggles, scenes, MIDI and remote clients
+// still write immediately
+type Staging struct {
+	enabled bool
+	values  map[*GangedFader]int64
+}
+
+// NewStaging creates a staging area, staging changes from the start if enabled
+func NewStaging(enabled bool) *Staging {
+	return &Staging{enabled: enabled, values: make(map[*GangedFader]int64)}
+}
+
+// IsEnabled returns true if UI fader changes are staged
+func (st *Staging) IsEnabled() bool {
+	return st.enabled
+}
+
+// SetEnabled turns staging on or off; turning it off discards the staged values
+func (st *Staging) SetEnabled(enabled bool) {
+	st.enabled = enabled
+	if !enabled {
+		st.Discard()
+	}
+}
+
+// Stage holds value for the gang (clamped to its range); staging the gang's current value
+// clears it
+func (st *Staging) Stage(gang *GangedFader, value int64) error {
+	value, rangeErr := gang.ClampValue(value)
+	if value == gang.GetCurrentValue() {
+		delete(st.values, gang)
+	} else {
+		st.values[gang] = value
+	}
+	return rangeErr
+}
+
+// Get returns the value staged for the gang
+func (st *Staging) Get(gang *GangedFader) (int64, bool) {
+	value, ok := st.values[gang]
+	return value, ok
+}
+
+// Value returns the value staged for the gang, or its current value if none is staged
+func (st *Staging) Value(gang *GangedFader) int64 {
+	if value, ok := st.values[gang]; ok {
+		return value
+	}
+	return gang.GetCurrentValue()
+}
+
+// Count returns the number of staged gangs
+func (st *Staging) Count() int {
+	return len(st.values)
+}
+
+// Apply writes the staged values of the gangs, in their order, and clears them; guarded jumps
+// are ramped, since pressing Apply already confirmed them. Gangs locked since they were staged,
+// or held by another source the write policy puts first, are skipped
+func (st *Staging) Apply(gangs []*GangedFader) error {
+	var errs []error
+	for _, gang := range gangs {
+		value, ok := st.values[gang]
+		if !ok || gang.IsLocked() || !gang.Claim(WriteSourceUI) {
+			continue
+		}
+		if err := gang.HandleRampedChange(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", gang.GetName(), err))
+		}
+	}
+	st.Discard()
+	return errors.Join(errs...)
+}
+
+// Discard drops the staged values
+func (st *Staging) Discard() {
+	clear(st.values)
+}
+
+// changeGang is the fader bank's change of a gang: staged when staging is on, otherwise
+// written at once (guarded against large jumps) unless the write policy refuses the UI
+func (sm *SessionMixer) changeGang(gang *GangedFader, value int64) error {
+	if sm.staging.IsEnabled() && !gang.IsToggle() {
+		return sm.staging.Stage(gang, value)
+	}
+	if !gang.Claim(WriteSourceUI) {
+		return nil
+	}
+	return gang.HandleGuardedChange(value)
+}
+
+// drawStaging renders the staging toggle and, with values staged, the Apply and Discard buttons
+func (sm *SessionMixer) drawStaging() {
+	enabled := sm.staging.IsEnabled()
+	if imgui.Checkbox(tr("Stage changes"), &enabled) {
+		sm.staging.SetEnabled(enabled)
+	}
+	imgui.SetItemTooltip(tr("Hold fader changes until Apply is pressed; turning this off discards them"))
+	if sm.staging.Count() == 0 {
+		return
+	}
+	imgui.SameLine()
+	imgui.PushStyleColorVec4(imgui.ColButton, stagedColor)
+	imgui.PushStyleColorVec4(imgui.ColText, imgui.Vec4{W: 1.0})
+	if imgui.Button(trf("Apply %d##staging", sm.staging.Count())) {
+		logError(sm.staging.Apply(sm.gangs))
+	}
+	imgui.PopStyleColorV(2)
+	imgui.SameLine()
+	if imgui.Button(tr("Discard##staging")) {
+		sm.staging.Discard()
+	}
+}
@@ -0,0 +1,42 @@
+package sessionmixer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStagingHoldsChangesUntilApplied(t *testing.T) {
+	gang, fakes := newFakeGang(t, "Vocal", 0, 160, 80, 1)
+	staging := NewStaging(true)
+
+	if err := staging.Stage(gang, 100); err != nil {
+		t.Fatal(err)
+	}
+	if got := fakes[0].getWrites(); len(got) != 0 {
+		t.Fatalf("staging wrote %v", got)
+	}
+	if got := staging.Value(gang); got != 100 {
+		t.Errorf("Value() = %d, want the staged 100", got)
+	}
+
+	// Staging the hardware value again clears the gang
+	if err := staging.Stage(gang, 80); err != nil {
+		t.Fatal(err)
+	}
+	if staging.Count() != 0 {
+		t.Fatalf("Count() = %d after staging the current value, want 0", staging.Count())
+	}
+
+	if err := staging.Stage(gang, 90); err != nil {
+		t.Fatal(err)
+	}
+	if err := staging.Apply([]*GangedFader{gang}); err != nil {
+		t.Fatal(err)
+	}
+	if got := fakes[0].getWrites(); !slices.Equal(got, []int64{90}) {
+		t.Errorf("writes after Apply = %v, want [90]", got)
+	}
+	if staging.Count() != 0 {
+		t.Errorf("Count() = %d after Apply, want 0", staging.Count())
+	}
+}
@@ -0,0 +1,341 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// latencyBuckets are the upper bounds of the write latency histogram buckets; writes slower
+// than the last bound are counted in an overflow bucket
+var latencyBuckets = []time.Duration{
+	100 * time.Microsecond,
+	250 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+}
+
+// latencySampleSize is the number of recent latencies kept per control for percentiles
+const latencySampleSize = 1024
+
+// latencySamples keeps the most recent latencies of one control
+type latencySamples struct {
+	samples []time.Duration
+	next    int
+	count   int64
+}
+
+// add records a latency, replacing the oldest once full
+func (ls *latencySamples) add(d time.Duration) {
+	if len(ls.samples) < latencySampleSize {
+		ls.samples = append(ls.samples, d)
+	} else {
+		ls.samples[ls.next] = d
+		ls.next = (ls.next + 1) % latencySampleSize
+	}
+	ls.count++
+}
+
+// percentiles returns the 50th, 95th and 99th percentile and maximum of the recent latencies
+func (ls *latencySamples) percentiles() (p50, p95, p99, maxLatency time.Duration) {
+	if len(ls.samples) == 0 {
+		return 0, 0, 0, 0
+	}
+	sorted := slices.Clone(ls.samples)
+	slices.Sort(sorted)
+	at := func(p float64) time.Duration {
+		return sorted[min(len(sorted)-1, int(p*float64(len(sorted))))]
+	}
+	return at(0.50), at(0.95), at(0.99), sorted[len(sorted)-1]
+}
+
+// pendingWrite is a write waiting for the hardware event that reports its value
+type pendingWrite struct {
+	value int64
+	at    time.Time
+}
+
+// rateWindow is the number of one-second buckets event and write rates are averaged over
+const rateWindow = 10
+
+// rateCounter counts occurrences in one-second buckets over the last rateWindow seconds
+type rateCounter struct {
+	mu      sync.Mutex
+	buckets [rateWindow]int64
+	second  int64 // Unix second of the newest bucket
+	total   int64
+}
+
+// add counts one occurrence at now
+func (rc *rateCounter) add(now time.Time) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.advance(now.Unix())
+	rc.buckets[rc.second%rateWindow]++
+	rc.total++
+}
+
+// rate returns the average occurrences per second over the completed seconds of the window
+func (rc *rateCounter) rate(now time.Time) float64 {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.advance(now.Unix())
+	var sum int64
+	for i := int64(1); i < rateWindow; i++ {
+		sum += rc.buckets[(rc.second-i+rateWindow)%rateWindow]
+	}
+	return float64(sum) / float64(rateWindow-1)
+}
+
+// advance clears the buckets between the newest bucket and second
+func (rc *rateCounter) advance(second int64) {
+	if second <= rc.second {
+		return
+	}
+	for s := max(rc.second+1, second-rateWindow+1); s <= second; s++ {
+		rc.buckets[s%rateWindow] = 0
+	}
+	rc.second = second
+}
+
+// RuntimeStats collects internal performance counters: hardware event rates, control write
+// latency and GUI frame time
+type RuntimeStats struct {
+	started time.Time
+
+	events rateCounter
+	writes rateCounter
+
+	latencyMu     sync.Mutex
+	latencyCounts []int64 // Per latencyBuckets entry, plus overflow
+	latencyMax    time.Duration
+	latencySum    time.Duration
+	controls      map[string]*controlLatency // Control name -> write and round-trip latencies
+	pending       map[uint]pendingWrite      // Control NumID -> last write awaiting its event
+
+	frameTime atomic.Int64 // Most recent frame time (ns)
+	frameMax  atomic.Int64 // Slowest frame since start (ns)
+	frames    atomic.Int64
+}
+
+// Stats is the process-wide runtime statistics collector
+var Stats = newRuntimeStats()
+
+func newRuntimeStats() *RuntimeStats {
+	return &RuntimeStats{
+		started:       time.Now(),
+		latencyCounts: make([]int64, len(latencyBuckets)+1),
+		controls:      make(map[string]*controlLatency),
+		pending:       make(map[uint]pendingWrite),
+	}
+}
+
+// controlLatency holds the latencies of one control
+type controlLatency struct {
+	writes     latencySamples // SetValue duration
+	roundTrips latencySamples // Write start to the hardware event reporting the written value
+}
+
+// control returns the latencies of a control, creating them on first use
+func (rs *RuntimeStats) control(name string) *controlLatency {
+	cl, ok := rs.controls[name]
+	if !ok {
+		cl = &controlLatency{}
+		rs.controls[name] = cl
+	}
+	return cl
+}
+
+// RecordEvent counts a hardware event delivered by the event monitor; an event reporting the
+// value of a pending write completes that write's round trip
+func (rs *RuntimeStats) RecordEvent(control *scarlettctl.Control, value int64) {
+	now := time.Now()
+	rs.events.add(now)
+
+	rs.latencyMu.Lock()
+	defer rs.latencyMu.Unlock()
+	if pw, ok := rs.pending[control.NumID]; ok && pw.value == value {
+		rs.control(control.Name).roundTrips.add(now.Sub(pw.at))
+		delete(rs.pending, control.NumID)
+	}
+}
+
+// RecordWrite counts a write of value to a control that started at started
+func (rs *RuntimeStats) RecordWrite(control *scarlettctl.Control, value int64, started time.Time) {
+	now := time.Now()
+	latency := now.Sub(started)
+	rs.writes.add(now)
+
+	rs.latencyMu.Lock()
+	defer rs.latencyMu.Unlock()
+	rs.control(control.Name).writes.add(latency)
+	rs.pending[control.NumID] = pendingWrite{value: value, at: started}
+	bucket := len(latencyBuckets)
+	for i, bound := range latencyBuckets {
+		if latency <= bound {
+			bucket = i
+			break
+		}
+	}
+	rs.latencyCounts[bucket]++
+	rs.latencySum += latency
+	rs.latencyMax = max(rs.latencyMax, latency)
+}
+
+// RecordFrame records the time taken to draw a GUI frame
+func (rs *RuntimeStats) RecordFrame(d time.Duration) {
+	rs.frameTime.Store(int64(d))
+	rs.frames.Add(1)
+	for {
+		slowest := rs.frameMax.Load()
+		if int64(d) <= slowest || rs.frameMax.CompareAndSwap(slowest, int64(d)) {
+			return
+		}
+	}
+}
+
+// LatencyBucket is one write latency histogram bucket
+type LatencyBucket struct {
+	UpperBound string // "+Inf" for the overflow bucket
+	Count      int64
+}
+
+// LatencyPercentiles summarizes the recent latencies of one kind for a control
+type LatencyPercentiles struct {
+	Count int64
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// newLatencyPercentiles summarizes samples
+func newLatencyPercentiles(ls *latencySamples) LatencyPercentiles {
+	lp := LatencyPercentiles{Count: ls.count}
+	lp.P50, lp.P95, lp.P99, lp.Max = ls.percentiles()
+	return lp
+}
+
+// ControlStats are the write and event round-trip latencies of one control
+type ControlStats struct {
+	Control   string
+	Write     LatencyPercentiles
+	RoundTrip LatencyPercentiles
+}
+
+// StatsSnapshot is a point-in-time copy of the runtime statistics
+type StatsSnapshot struct {
+	Uptime          string
+	Events          int64
+	EventsPerSecond float64
+	Writes          int64
+	WritesPerSecond float64
+	WriteLatencyAvg string
+	WriteLatencyMax string
+	WriteLatency    []LatencyBucket
+	Frames          int64
+	FrameTime       string
+	FrameTimeMax    string
+	Controls        []ControlStats // Sorted by control name
+}
+
+// Snapshot returns the current statistics
+func (rs *RuntimeStats) Snapshot() StatsSnapshot {
+	now := time.Now()
+	snapshot := StatsSnapshot{
+		Uptime:          now.Sub(rs.started).Round(time.Second).String(),
+		EventsPerSecond: rs.events.rate(now),
+		WritesPerSecond: rs.writes.rate(now),
+		Frames:          rs.frames.Load(),
+		FrameTime:       time.Duration(rs.frameTime.Load()).String(),
+		FrameTimeMax:    time.Duration(rs.frameMax.Load()).String(),
+	}
+	rs.events.mu.Lock()
+	snapshot.Events = rs.events.total
+	rs.events.mu.Unlock()
+
+	rs.latencyMu.Lock()
+	defer rs.latencyMu.Unlock()
+	rs.writes.mu.Lock()
+	snapshot.Writes = rs.writes.total
+	rs.writes.mu.Unlock()
+	if snapshot.Writes > 0 {
+		snapshot.WriteLatencyAvg = (rs.latencySum / time.Duration(snapshot.Writes)).String()
+	}
+	snapshot.WriteLatencyMax = rs.latencyMax.String()
+	for i, count := range rs.latencyCounts {
+		bound := "+Inf"
+		if i < len(latencyBuckets) {
+			bound = latencyBuckets[i].String()
+		}
+		snapshot.WriteLatency = append(snapshot.WriteLatency, LatencyBucket{UpperBound: bound, Count: count})
+	}
+	for name, cl := range rs.controls {
+		snapshot.Controls = append(snapshot.Controls, ControlStats{
+			Control:   name,
+			Write:     newLatencyPercentiles(&cl.writes),
+			RoundTrip: newLatencyPercentiles(&cl.roundTrips),
+		})
+	}
+	sort.Slice(snapshot.Controls, func(i, j int) bool { return snapshot.Controls[i].Control < snapshot.Controls[j].Control })
+	return snapshot
+}
+
+// drawStats renders the runtime statistics and the per-control latency table
+func drawStats() {
+	snapshot := Stats.Snapshot()
+	imgui.Text(trf("Events: %.1f/s   Writes: %.1f/s   Frame: %s (max %s)",
+		snapshot.EventsPerSecond, snapshot.WritesPerSecond, snapshot.FrameTime, snapshot.FrameTimeMax))
+	if len(snapshot.Controls) == 0 {
+		imgui.TextDisabled(tr("No control writes yet"))
+		return
+	}
+
+	if imgui.BeginTableV("stats_table", 7, imgui.TableFlagsRowBg|imgui.TableFlagsSizingFixedFit, imgui.Vec2{}, 0.0) {
+		imgui.TableSetupColumn(tr("Control"))
+		imgui.TableSetupColumn(tr("Writes"))
+		imgui.TableSetupColumn(tr("Write p50"))
+		imgui.TableSetupColumn(tr("Write p99"))
+		imgui.TableSetupColumn(tr("Write max"))
+		imgui.TableSetupColumn(tr("Round trip p50"))
+		imgui.TableSetupColumn(tr("Round trip p99"))
+		imgui.TableHeadersRow()
+		for _, cs := range snapshot.Controls {
+			imgui.TableNextRow()
+			imgui.TableNextColumn()
+			imgui.Text(cs.Control)
+			imgui.TableNextColumn()
+			imgui.Text(trf("%d", cs.Write.Count))
+			for _, d := range []time.Duration{cs.Write.P50, cs.Write.P99, cs.Write.Max} {
+				imgui.TableNextColumn()
+				imgui.Text(formatLatency(d))
+			}
+			for _, d := range []time.Duration{cs.RoundTrip.P50, cs.RoundTrip.P99} {
+				imgui.TableNextColumn()
+				if cs.RoundTrip.Count == 0 {
+					imgui.TextDisabled(tr("-"))
+				} else {
+					imgui.Text(formatLatency(d))
+				}
+			}
+		}
+		imgui.EndTable()
+	}
+}
+
+// formatLatency formats a latency in milliseconds
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%.2f ms", float64(d)/float64(time.Millisecond))
+}
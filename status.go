@@ -0,0 +1,135 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// StatusBar displays device status controls (clock source, sync status, sample rate)
+// Values are kept current by the event monitor; any of the controls may be absent
+type StatusBar struct {
+	clockSource *MixerChannel
+	syncStatus  *MixerChannel
+	sampleRate  *MixerChannel
+}
+
+// NewStatusBar discovers the device status controls on the card
+func NewStatusBar(card *scarlettctl.Card) (*StatusBar, error) {
+	controls, err := card.GetControls()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover status controls: %w", err)
+	}
+
+	sb := &StatusBar{}
+	for _, control := range controls {
+		switch control.Type {
+		case scarlettctl.ControlTypeBoolean, scarlettctl.ControlTypeEnumerated,
+			scarlettctl.ControlTypeInteger, scarlettctl.ControlTypeInteger64:
+		default:
+			continue
+		}
+
+		var target **MixerChannel
+		switch {
+		case strings.Contains(control.Name, "Clock Source"):
+			target = &sb.clockSource
+		case strings.Contains(control.Name, "Sync Status"):
+			target = &sb.syncStatus
+		case strings.Contains(control.Name, "Sample Rate"):
+			target = &sb.sampleRate
+		default:
+			continue
+		}
+		if *target != nil {
+			continue // Use the first match
+		}
+
+		ch, err := NewMixerChannel(control, control.Name, "raw")
+		if err != nil {
+			return nil, fmt.Errorf("status control %s: %w", control.Name, err)
+		}
+		*target = ch
+	}
+
+	return sb, nil
+}
+
+// HasStatus returns true if the device provides any status controls
+func (sb *StatusBar) HasStatus() bool {
+	return sb.clockSource != nil || sb.syncStatus != nil || sb.sampleRate != nil
+}
+
+// GetChannels returns the status channels, for event monitor registration
+func (sb *StatusBar) GetChannels() []*MixerChannel {
+	var channels []*MixerChannel
+	for _, ch := range []*MixerChannel{sb.clockSource, sb.syncStatus, sb.sampleRate} {
+		if ch != nil {
+			channels = append(channels, ch)
+		}
+	}
+	return channels
+}
+
+// IsExternalClock returns true if the device is clocked from an external source (ADAT, S/PDIF...)
+func (sb *StatusBar) IsExternalClock() bool {
+	if sb.clockSource == nil {
+		return false
+	}
+	return !strings.EqualFold(statusValueString(sb.clockSource), "Internal")
+}
+
+// IsSyncLost returns true if the sync status reports the device as unlocked
+func (sb *StatusBar) IsSyncLost() bool {
+	if sb.syncStatus == nil {
+		return false
+	}
+	control := sb.syncStatus.GetControl()
+	value := sb.syncStatus.GetCurrentValue()
+	switch control.Type {
+	case scarlettctl.ControlTypeBoolean:
+		return value == 0
+	case scarlettctl.ControlTypeEnumerated:
+		return strings.Contains(strings.ToLower(enumItemName(control, value)), "unlock")
+	}
+	return false
+}
+
+// Draw renders the status bar as a single line
+// A warning is shown when sync is lost while running from an external clock
+func (sb *StatusBar) Draw() {
+	var parts []string
+	if sb.clockSource != nil {
+		parts = append(parts, trf("Clock: %s", statusValueString(sb.clockSource)))
+	}
+	if sb.syncStatus != nil {
+		parts = append(parts, trf("Sync: %s", statusValueString(sb.syncStatus)))
+	}
+	if sb.sampleRate != nil {
+		parts = append(parts, trf("Rate: %s", statusValueString(sb.sampleRate)))
+	}
+	imgui.Text(strings.Join(parts, "  |  "))
+
+	if sb.IsSyncLost() && (sb.clockSource == nil || sb.IsExternalClock()) {
+		imgui.SameLine()
+		imgui.TextColored(imgui.Vec4{X: 1.0, Y: 0.2, Z: 0.2, W: 1.0}, tr("SYNC LOST"))
+	}
+}
+
+// statusValueString formats a status channel value for display
+func statusValueString(ch *MixerChannel) string {
+	control := ch.GetControl()
+	value := ch.GetCurrentValue()
+	switch control.Type {
+	case scarlettctl.ControlTypeEnumerated:
+		return enumItemName(control, value)
+	case scarlettctl.ControlTypeBoolean:
+		if value == 0 {
+			return tr("Off")
+		}
+		return tr("On")
+	}
+	return fmt.Sprintf("%d", value)
+}
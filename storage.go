@@ -0,0 +1,175 @@
+package sessionmixer
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Storage follows the XDG base directory layout:
+//   - config ($XDG_CONFIG_HOME/sessionmixer): session.yaml, schedule files
+//   - data ($XDG_DATA_HOME/sessionmixer): scenes, backups, recordings
+//   - state ($XDG_STATE_HOME/sessionmixer): clip reports, display and window preferences, state journal, audit log
+//
+// Data and state locations can be overridden with the `storage` config section. Files that
+// still exist only at their old location in the config directory keep being used from there.
+
+// storage holds the directory overrides from the config
+var storage StorageConfig
+
+// SetStorage applies the config's directory overrides (nil = XDG defaults)
+func SetStorage(config *StorageConfig) {
+	if config == nil {
+		storage = StorageConfig{}
+		return
+	}
+	storage = *config
+}
+
+// DataDir returns the directory for scenes and recordings
+func DataDir() (string, error) {
+	if storage.DataDir != "" {
+		return expandHome(storage.DataDir)
+	}
+	return xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// StateDir returns the directory for session state
+func StateDir() (string, error) {
+	if storage.StateDir != "" {
+		return expandHome(storage.StateDir)
+	}
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// ScenesDir returns the directory where scenes are stored
+func ScenesDir() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return legacyPath(filepath.Join(dir, "scenes"), "scenes")
+}
+
+// BackupsDir returns the directory where periodic backups of the mix are kept
+func BackupsDir() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "backups"), nil
+}
+
+// RecordingsDir returns the directory where recordings given as a bare file name are written
+func RecordingsDir() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "recordings"), nil
+}
+
+// ClipReportPath returns the path of the clip report saved at the end of a session
+func ClipReportPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return legacyPath(filepath.Join(dir, "clips.yaml"), "clips.yaml")
+}
+
+// JournalPath returns the path of the state journal holding the mix of the running session
+func JournalPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "journal.yaml"), nil
+}
+
+// AuditLogPath returns the path of the audit log of gang changes
+func AuditLogPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.log"), nil
+}
+
+// DisplayPrefsPath returns the path of the remembered value display preferences
+func DisplayPrefsPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return legacyPath(filepath.Join(dir, "display.yaml"), "display.yaml")
+}
+
+// WindowPrefsPath returns the path of the window geometries remembered per profile
+func WindowPrefsPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "window.yaml"), nil
+}
+
+// RecordingPath resolves a recording path: a bare file name is placed in the recordings
+// directory (created if needed); any other path is used as given
+func RecordingPath(path string) (string, error) {
+	if strings.ContainsRune(path, filepath.Separator) {
+		return path, nil
+	}
+	dir, err := RecordingsDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, path), nil
+}
+
+// legacyPath returns path, unless only the pre-XDG location (name in the config directory)
+// exists, in which case that is returned so existing scenes and state are not lost
+func legacyPath(path, name string) (string, error) {
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		return path, nil
+	}
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	legacy := filepath.Join(dir, name)
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy, nil
+	}
+	return path, nil
+}
+
+// xdgDir returns $env/sessionmixer, or ~/fallback/sessionmixer if env is unset or relative
+// (relative values are invalid per the XDG spec)
+func xdgDir(env, fallback string) (string, error) {
+	if base := os.Getenv(env); filepath.IsAbs(base) {
+		return filepath.Join(base, "sessionmixer"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, fallback, "sessionmixer"), nil
+}
+
+// expandHome expands a leading ~/ in a configured path
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[2:]), nil
+}
@@ -0,0 +1,117 @@
+package sessionmixer
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+)
+
+// stripFaderWidth is the width of a strip mode fader
+const stripFaderWidth = 70
+
+// mutedColor marks the mute button of a muted gang in strip mode
+var mutedColor = imgui.Vec4{X: 0.8, Y: 0.2, Z: 0.2, W: 1.0}
+
+// drawWindowToggles renders the strip mode and always-on-top toggles
+func (sm *SessionMixer) drawWindowToggles() {
+	imgui.Checkbox(tr("Strip"), &sm.strip)
+	if sm.window != nil && sm.window.host != nil {
+		imgui.SameLine()
+		if imgui.Checkbox(tr("On top"), &sm.onTop) {
+			sm.window.host.SetAlwaysOnTop(sm.onTop)
+		}
+	}
+}
+
+// applyWindow applies the presentation of a restored window geometry
+func (sm *SessionMixer) applyWindow(geometry WindowGeometry, ok bool) {
+	if !ok {
+		return
+	}
+	sm.strip = geometry.Strip || sm.docked
+	sm.onTop = geometry.OnTop
+	sm.detached = slices.Clone(geometry.Detached)
+	sm.placeDetached = true
+}
+
+// drawStrip renders the visible gangs as one horizontal row of small faders with mute buttons
+// (toggles for switch gangs, meters for display channels), for keeping critical controls in
+// view above a DAW or OBS; the filter and tag view of the full bank select the gangs
+func (sm *SessionMixer) drawStrip() {
+	lead := false // An item precedes the gangs on the row
+	if !sm.docked {
+		if imgui.ArrowButton("##strip_exit", imgui.DirDown) {
+			sm.strip = false
+		}
+		imgui.SetItemTooltip(tr("Show the full mixer"))
+		lead = true
+	}
+	if sm.panic != nil {
+		if lead {
+			imgui.SameLine()
+		}
+		sm.drawPanicButton()
+		lead = true
+	}
+
+	visible := sm.visibleGangs()
+	if len(visible) == 0 {
+		if lead {
+			imgui.SameLine()
+		}
+		imgui.TextDisabled(tr("No gangs match the filter"))
+		return
+	}
+	for n, i := range visible {
+		gang := sm.gangs[i]
+		if lead || n > 0 {
+			imgui.SameLine()
+		}
+		imgui.PushIDInt(int32(i))
+		imgui.BeginGroup()
+		imgui.TextDisabled(gang.GetName())
+
+		locked := gang.IsLocked() && !gang.IsReadOnly()
+		if locked {
+			imgui.BeginDisabled()
+		}
+		switch {
+		case gang.IsReadOnly():
+			drawReadout(gang, scaledVec2(imgui.Vec2{X: stripFaderWidth, Y: 10}))
+		case gang.IsToggle():
+			if value, changed := drawGangToggle("##strip_toggle", gang); changed && !locked && gang.Claim(WriteSourceUI) {
+				logError(gang.HandleUIChange(int64(value)))
+			}
+		default:
+			value := int32(gang.GetCurrentValue())
+			imgui.SetNextItemWidth(scaled(stripFaderWidth))
+			if imgui.SliderIntV("##strip_fader", &value, int32(gang.GetMin()), int32(gang.GetMax()), "", imgui.SliderFlagsNone) && !locked && gang.Claim(WriteSourceUI) {
+				logError(gang.HandleGuardedChange(int64(value)))
+			}
+			imgui.SetItemTooltip(strings.ReplaceAll(gang.FormatValue(gang.GetCurrentValue()), "%", "%%"))
+			imgui.SameLine()
+			muted := gang.IsMuted()
+			if muted {
+				imgui.PushStyleColorVec4(imgui.ColButton, mutedColor)
+			}
+			if imgui.SmallButton(tr("M")) && !locked && gang.Claim(WriteSourceUI) {
+				if muted {
+					logError(gang.Unmute())
+				} else {
+					logError(gang.Mute())
+				}
+			}
+			if muted {
+				imgui.PopStyleColor()
+			}
+		}
+		if locked {
+			imgui.EndDisabled()
+		}
+
+		imgui.EndGroup()
+		sm.trackHovered(gang)
+		imgui.PopID()
+	}
+}
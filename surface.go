@@ -0,0 +1,491 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// surfaceFeedbackInterval is how often gang values are sent back to the surface
+	surfaceFeedbackInterval = 50 * time.Millisecond
+
+	// defaultSurfaceFaders is the fader count of a typical control surface
+	defaultSurfaceFaders = 8
+
+	// Mackie Control bank buttons (note numbers)
+	mackieBankDown = 0x2E
+	mackieBankUp   = 0x2F
+
+	// noFeedback marks a surface fader whose position must be (re)sent
+	noFeedback = math.MinInt64
+
+	// takeoverTolerance is how close (as a fader position) a fader must come to its gang's value
+	// to pick it up without crossing it
+	takeoverTolerance = 0.02
+)
+
+// Surface fader modes: how "cc" surfaces send fader positions
+const (
+	FaderModeCC        = "cc"         // 7-bit CC on fader_cc+i
+	FaderModeCC14      = "cc14"       // 14-bit CC pair: MSB on fader_cc+i, LSB on fader_cc+i+32
+	FaderModePitchBend = "pitch_bend" // 14-bit pitch bend, fader i on MIDI channel channel+i
+)
+
+// Surface encoder modes: how "cc" mode fader CC values are read
+const (
+	EncoderAbsolute       = "absolute"        // The value is the fader position
+	EncoderTwosComplement = "twos_complement" // 1-63 steps up, 127-65 steps down (-1 to -63)
+	EncoderSignMagnitude  = "sign_magnitude"  // 1-63 steps up, 65-127 steps down (bit 6 is the sign)
+)
+
+// Surface maps the faders of a MIDI control surface onto successive banks of gangs
+// Bank up/down moves the surface faders to the next/previous group of gangs; gang values are
+// sent back to the surface so motorized faders follow changes made elsewhere
+type Surface struct {
+	config  SurfaceConfig
+	port    *MIDIPort
+	faders  int
+	mackie  bool
+	mode    string // Fader mode of "cc" surfaces
+	encoder string
+	msb     []byte // Last MSB received from each fader in "cc14" mode (reader goroutine only)
+
+	// Buttons bound to notes (see BindButtons)
+	buttons []*surfaceButton
+
+	smoother *Smoother // nil without smoothing
+
+	bank atomic.Int32
+
+	gangsMu sync.Mutex
+	gangs   []*GangedFader // Replaced by SetGangs; read through getGangs
+
+	mu       sync.Mutex
+	lastSent []int64   // Gang value last sent to (or received from) each surface fader
+	owned    []int64   // Gang value last written by each fader with soft takeover (noFeedback = not picked up)
+	lastPos  []float64 // Last position received from each fader with soft takeover (NaN = none yet)
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewSurface opens the surface's MIDI device and maps its faders onto gangs
+func NewSurface(config SurfaceConfig, gangs []*GangedFader) (*Surface, error) {
+	faders := config.Faders
+	if faders <= 0 {
+		faders = defaultSurfaceFaders
+	}
+	var mackie bool
+	encoder := config.Encoders
+	if encoder == "" {
+		encoder = EncoderAbsolute
+	}
+	switch encoder {
+	case EncoderAbsolute, EncoderTwosComplement, EncoderSignMagnitude:
+	default:
+		return nil, fmt.Errorf("unknown surface encoder mode '%s'", config.Encoders)
+	}
+	mode := config.FaderMode
+	if mode == "" {
+		mode = FaderModeCC
+	}
+	switch config.Protocol {
+	case "", "cc":
+		switch mode {
+		case FaderModeCC:
+			if config.FaderCC+faders > 128 {
+				return nil, fmt.Errorf("surface faders exceed the CC range")
+			}
+		case FaderModeCC14:
+			if config.FaderCC+faders > 32 {
+				return nil, fmt.Errorf("14-bit surface faders must use CCs 0-31")
+			}
+		case FaderModePitchBend:
+			if config.Channel+faders > 16 {
+				return nil, fmt.Errorf("pitch bend surface faders exceed the MIDI channels")
+			}
+		default:
+			return nil, fmt.Errorf("unknown surface fader mode '%s'", config.FaderMode)
+		}
+		if mode != FaderModeCC && encoder != EncoderAbsolute {
+			return nil, fmt.Errorf("encoder modes apply to 7-bit CC faders only")
+		}
+	case "mackie":
+		if faders > 16 {
+			return nil, fmt.Errorf("mackie surfaces support at most 16 faders")
+		}
+		if encoder != EncoderAbsolute || mode != FaderModeCC {
+			return nil, fmt.Errorf("fader and encoder modes apply to 'cc' surfaces only")
+		}
+		mackie = true
+	default:
+		return nil, fmt.Errorf("unknown surface protocol '%s'", config.Protocol)
+	}
+
+	if err := validateButtons(config, mackie); err != nil {
+		return nil, err
+	}
+
+	var smoother *Smoother
+	if config.Smoothing != nil {
+		var err error
+		smoother, err = NewSmoother(*config.Smoothing, WriteSourceMIDI, (*GangedFader).HandleUIChange)
+		if err != nil {
+			return nil, fmt.Errorf("surface: %w", err)
+		}
+	}
+
+	port, err := OpenMIDIPort(config.Device)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Surface{
+		config:   config,
+		port:     port,
+		gangs:    gangs,
+		faders:   faders,
+		mackie:   mackie,
+		mode:     mode,
+		encoder:  encoder,
+		msb:      make([]byte, faders),
+		lastSent: make([]int64, faders),
+		owned:    make([]int64, faders),
+		lastPos:  make([]float64, faders),
+		smoother: smoother,
+		stop:     make(chan struct{}),
+	}
+	s.resetFeedback()
+	return s, nil
+}
+
+// Start begins reading surface input and sending feedback in background goroutines
+func (s *Surface) Start() {
+	if s.smoother != nil {
+		s.smoother.Start()
+	}
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		if err := s.port.Read(s.handleMessage); err != nil {
+			select {
+			case <-s.stop:
+			default:
+				log.Printf("Surface: read from %s failed: %v", s.port.GetPath(), err)
+			}
+		}
+	}()
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(surfaceFeedbackInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.sendFeedback()
+			}
+		}
+	}()
+}
+
+// Stop stops the surface and closes the MIDI device; blocks until the goroutines have exited
+func (s *Surface) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		s.port.Close()
+		s.wg.Wait()
+		if s.smoother != nil {
+			s.smoother.Stop()
+		}
+	})
+}
+
+// GetFaders returns the number of surface faders
+func (s *Surface) GetFaders() int {
+	return s.faders
+}
+
+// GetBank returns the current bank (0-based)
+func (s *Surface) GetBank() int {
+	return int(s.bank.Load())
+}
+
+// GetBankCount returns the number of banks needed to reach every gang
+func (s *Surface) GetBankCount() int {
+	return max(1, (len(s.getGangs())+s.faders-1)/s.faders)
+}
+
+// GetBankRange returns the index of the first gang in the current bank and the number of
+// gangs mapped to surface faders
+func (s *Surface) GetBankRange() (int, int) {
+	first := s.GetBank() * s.faders
+	return first, max(0, min(s.faders, len(s.getGangs())-first))
+}
+
+// SetGangs replaces the gangs mapped to the surface faders (e.g. after a profile switch),
+// keeping the bank where the new gangs allow and resending every fader
+func (s *Surface) SetGangs(gangs []*GangedFader) {
+	s.gangsMu.Lock()
+	s.gangs = gangs
+	s.gangsMu.Unlock()
+	s.bank.Store(int32(max(0, min(s.GetBankCount()-1, s.GetBank()))))
+	s.resetFeedback()
+}
+
+// getGangs returns the gangs mapped to the surface faders (thread-safe)
+func (s *Surface) getGangs() []*GangedFader {
+	s.gangsMu.Lock()
+	defer s.gangsMu.Unlock()
+	return s.gangs
+}
+
+// SetBank selects a bank, clamped to the valid range
+func (s *Surface) SetBank(bank int) {
+	bank = max(0, min(s.GetBankCount()-1, bank))
+	if int(s.bank.Swap(int32(bank))) != bank {
+		s.resetFeedback()
+	}
+}
+
+// BankUp moves the surface to the next group of gangs
+func (s *Surface) BankUp() {
+	s.SetBank(s.GetBank() + 1)
+}
+
+// BankDown moves the surface to the previous group of gangs
+func (s *Surface) BankDown() {
+	s.SetBank(s.GetBank() - 1)
+}
+
+// gangAt returns the gang mapped to surface fader i in the current bank, or nil
+func (s *Surface) gangAt(i int) *GangedFader {
+	gangs := s.getGangs()
+	first := s.GetBank() * s.faders
+	count := max(0, min(s.faders, len(gangs)-first))
+	if i < 0 || i >= count {
+		return nil
+	}
+	return gangs[first+i]
+}
+
+// handleMessage applies fader moves, bank buttons and bound buttons from the surface
+func (s *Surface) handleMessage(msg MIDIMessage) {
+	if t := msg.Type(); (t == midiNoteOn || t == midiNoteOff) && s.handleButton(msg) {
+		return
+	}
+	if s.mackie {
+		switch msg.Type() {
+		case midiPitchBend:
+			pos := float64(int(msg.Data2)<<7|int(msg.Data1)) / 16383.0
+			s.moveFader(int(msg.Channel()), pos)
+		case midiNoteOn:
+			if msg.Data2 == 0 {
+				return // Button release
+			}
+			switch msg.Data1 {
+			case mackieBankUp:
+				s.BankUp()
+			case mackieBankDown:
+				s.BankDown()
+			}
+		}
+		return
+	}
+
+	if s.mode == FaderModePitchBend && msg.Type() == midiPitchBend {
+		if i := int(msg.Channel()) - s.config.Channel; i >= 0 && i < s.faders {
+			s.moveFader(i, float64(int(msg.Data2)<<7|int(msg.Data1))/16383.0)
+		}
+		return
+	}
+	if msg.Type() != midiControlChange || int(msg.Channel()) != s.config.Channel {
+		return
+	}
+	cc := int(msg.Data1)
+	switch {
+	case s.mode == FaderModeCC14 && cc >= s.config.FaderCC && cc < s.config.FaderCC+s.faders:
+		// The MSB moves the fader with the LSB cleared, as the MIDI spec has it; the LSB that
+		// follows refines the position
+		i := cc - s.config.FaderCC
+		s.msb[i] = msg.Data2
+		s.moveFader(i, float64(int(msg.Data2)<<7)/16383.0)
+	case s.mode == FaderModeCC14 && cc >= s.config.FaderCC+32 && cc < s.config.FaderCC+32+s.faders:
+		i := cc - s.config.FaderCC - 32
+		s.moveFader(i, float64(int(s.msb[i])<<7|int(msg.Data2))/16383.0)
+	case cc >= s.config.FaderCC && cc < s.config.FaderCC+s.faders && s.encoder != EncoderAbsolute:
+		s.turnEncoder(cc-s.config.FaderCC, decodeEncoder(s.encoder, msg.Data2))
+	case cc >= s.config.FaderCC && cc < s.config.FaderCC+s.faders:
+		s.moveFader(cc-s.config.FaderCC, float64(msg.Data2)/127.0)
+	case s.config.BankUpCC > 0 && cc == s.config.BankUpCC && msg.Data2 > 0:
+		s.BankUp()
+	case s.config.BankDownCC > 0 && cc == s.config.BankDownCC && msg.Data2 > 0:
+		s.BankDown()
+	}
+}
+
+// moveFader writes a surface fader position to the gang it is mapped to, through the smoother
+// if there is one
+func (s *Surface) moveFader(i int, pos float64) {
+	gang := s.gangAt(i)
+	if gang == nil || gang.IsLocked() {
+		return
+	}
+	value := gang.PositionToValue(pos)
+	s.mu.Lock()
+	if !s.takeover(i, gang, pos) || !gang.Claim(WriteSourceMIDI) || (s.smoother != nil && !s.smoother.Set(gang, pos)) {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSent[i] = value // The surface already shows this position
+	s.owned[i] = value
+	s.mu.Unlock()
+	if s.smoother != nil {
+		return // The smoother writes it
+	}
+	if err := gang.HandleUIChange(value); err != nil {
+		log.Printf("Surface: failed to set %s: %v", gang.GetName(), err)
+	}
+}
+
+// takeover returns true if surface fader i at pos may move gang: with soft takeover a fader
+// picks the gang up once it comes close to or crosses the gang's position, and lets go when the
+// gang is moved by anything else (a scene recall, the UI, the hardware); must be called with
+// s.mu held
+func (s *Surface) takeover(i int, gang *GangedFader, pos float64) bool {
+	if !s.config.Takeover {
+		return true
+	}
+	last := s.lastPos[i]
+	s.lastPos[i] = pos
+	current := gang.GetCurrentValue()
+	if s.owned[i] == current || (s.smoother != nil && s.smoother.Pending(gang)) {
+		return true
+	}
+	s.owned[i] = noFeedback
+	target := gang.ValueToPosition(current)
+	if math.Abs(pos-target) <= takeoverTolerance {
+		return true
+	}
+	return !math.IsNaN(last) && (last-target)*(pos-target) < 0
+}
+
+// AwaitsPickup returns true if surface fader i has soft takeover and does not control its gang
+// until it reaches the gang's value
+func (s *Surface) AwaitsPickup(i int) bool {
+	if !s.config.Takeover {
+		return false
+	}
+	gang := s.gangAt(i)
+	if gang == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.owned[i] != gang.GetCurrentValue() && (s.smoother == nil || !s.smoother.Pending(gang))
+}
+
+// turnEncoder moves the gang mapped to surface encoder i by a number of steps (see Nudge); a
+// fast turn sends larger steps
+func (s *Surface) turnEncoder(i int, steps int) {
+	gang := s.gangAt(i)
+	if gang == nil || gang.IsLocked() || steps == 0 || !gang.Claim(WriteSourceMIDI) {
+		return
+	}
+	if err := gang.HandleUIChange(gang.NudgeValue(gang.GetCurrentValue(), steps)); err != nil {
+		log.Printf("Surface: failed to set %s: %v", gang.GetName(), err)
+	}
+}
+
+// decodeEncoder returns the signed step count of a relative encoder CC value
+func decodeEncoder(mode string, value byte) int {
+	value &= 0x7F
+	switch mode {
+	case EncoderTwosComplement:
+		if value >= 64 {
+			return int(value) - 128
+		}
+		return int(value)
+	case EncoderSignMagnitude:
+		if value&0x40 != 0 {
+			return -int(value & 0x3F)
+		}
+		return int(value)
+	}
+	return 0
+}
+
+// sendFeedback sends the position of every gang that changed since it was last sent, and the
+// button LEDs; surface faders without a gang in the current bank are sent to the bottom
+func (s *Surface) sendFeedback() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.sendButtonFeedback(); err != nil {
+		log.Printf("Surface: failed to send feedback: %v", err)
+		return
+	}
+
+	for i := 0; i < s.faders; i++ {
+		gang := s.gangAt(i)
+		var value int64
+		var pos float64
+		if gang != nil {
+			value = gang.GetCurrentValue()
+			pos = gang.ValueToPosition(value)
+		}
+		if s.lastSent[i] == value || (gang != nil && s.smoother != nil && s.smoother.Pending(gang)) {
+			continue // Motor faders would fight a gang still being smoothed to their position
+		}
+		for _, msg := range s.faderMessages(i, pos) {
+			if err := s.port.Send(msg); err != nil {
+				log.Printf("Surface: failed to send feedback: %v", err)
+				return
+			}
+		}
+		s.lastSent[i] = value
+	}
+}
+
+// faderMessages builds the messages positioning surface fader i
+func (s *Surface) faderMessages(i int, pos float64) []MIDIMessage {
+	v := int(math.Round(pos * 16383))
+	channel := byte(s.config.Channel & 0x0F)
+	switch {
+	case s.mackie:
+		return []MIDIMessage{{Status: midiPitchBend | byte(i), Data1: byte(v & 0x7F), Data2: byte(v >> 7)}}
+	case s.mode == FaderModePitchBend:
+		return []MIDIMessage{{Status: midiPitchBend | byte((s.config.Channel+i)&0x0F), Data1: byte(v & 0x7F), Data2: byte(v >> 7)}}
+	case s.mode == FaderModeCC14:
+		return []MIDIMessage{
+			{Status: midiControlChange | channel, Data1: byte(s.config.FaderCC + i), Data2: byte(v >> 7)},
+			{Status: midiControlChange | channel, Data1: byte(s.config.FaderCC + i + 32), Data2: byte(v & 0x7F)},
+		}
+	}
+	return []MIDIMessage{{
+		Status: midiControlChange | channel,
+		Data1:  byte(s.config.FaderCC + i),
+		Data2:  byte(math.Round(pos * 127)),
+	}}
+}
+
+// resetFeedback forces every surface fader to be resent, and to be picked up again with soft
+// takeover
+func (s *Surface) resetFeedback() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.lastSent {
+		s.lastSent[i] = noFeedback
+		s.owned[i] = noFeedback
+		s.lastPos[i] = math.NaN()
+	}
+	for _, button := range s.buttons {
+		button.lit = -1
+	}
+}
@@ -0,0 +1,101 @@
+package sessionmixer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDecodeEncoder(t *testing.T) {
+	for _, tc := range []struct {
+		mode  string
+		value byte
+		want  int
+	}{
+		{EncoderTwosComplement, 1, 1},
+		{EncoderTwosComplement, 63, 63},
+		{EncoderTwosComplement, 127, -1},
+		{EncoderTwosComplement, 65, -63},
+		{EncoderTwosComplement, 0, 0},
+		{EncoderSignMagnitude, 1, 1},
+		{EncoderSignMagnitude, 65, -1},
+		{EncoderSignMagnitude, 127, -63},
+		{EncoderSignMagnitude, 64, 0},
+		{EncoderAbsolute, 100, 0},
+	} {
+		if got := decodeEncoder(tc.mode, tc.value); got != tc.want {
+			t.Errorf("decodeEncoder(%s, %d) = %d, want %d", tc.mode, tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestValidateButtons(t *testing.T) {
+	config := SurfaceConfig{Buttons: []SurfaceButton{{Note: 60, Action: ButtonMute}, {Note: 61, Action: ButtonRecall}}}
+	if err := validateButtons(config, false); err != nil {
+		t.Fatalf("validateButtons() = %v", err)
+	}
+	config.Buttons[1].Note = 60
+	if err := validateButtons(config, false); err == nil {
+		t.Error("expected an error for a note bound twice")
+	}
+	config.Buttons = []SurfaceButton{{Note: mackieBankUp, Action: ButtonMute}}
+	if err := validateButtons(config, true); err == nil {
+		t.Error("expected an error for a button on a mackie bank note")
+	}
+}
+
+func TestFaderMessages14Bit(t *testing.T) {
+	s := &Surface{config: SurfaceConfig{FaderCC: 7, Channel: 2}, mode: FaderModeCC14}
+	msgs := s.faderMessages(1, 1.0)
+	want := []MIDIMessage{{Status: 0xB2, Data1: 8, Data2: 0x7F}, {Status: 0xB2, Data1: 40, Data2: 0x7F}}
+	if !slices.Equal(msgs, want) {
+		t.Errorf("cc14 faderMessages = %v, want %v", msgs, want)
+	}
+
+	s.mode = FaderModePitchBend
+	msgs = s.faderMessages(3, 0.5)
+	want = []MIDIMessage{{Status: 0xE5, Data1: 0x00, Data2: 0x40}}
+	if !slices.Equal(msgs, want) {
+		t.Errorf("pitch bend faderMessages = %v, want %v", msgs, want)
+	}
+}
+
+func TestSurfaceSoftTakeover(t *testing.T) {
+	gang, _ := newFakeGang(t, "Vocal", 0, 160, 80, 1)
+	s := &Surface{
+		config:   SurfaceConfig{Takeover: true},
+		gangs:    []*GangedFader{gang},
+		faders:   1,
+		lastSent: make([]int64, 1),
+		owned:    make([]int64, 1),
+		lastPos:  make([]float64, 1),
+	}
+	s.resetFeedback()
+
+	// The fader starts below the gang (position 0.5) and only takes over once it crosses it
+	for _, pos := range []float64{0.1, 0.3} {
+		s.moveFader(0, pos)
+		if got := gang.GetCurrentValue(); got != 80 {
+			t.Fatalf("fader at %.1f moved the gang to %d before picking it up", pos, got)
+		}
+	}
+	if !s.AwaitsPickup(0) {
+		t.Error("AwaitsPickup() = false before the fader crossed the gang")
+	}
+	s.moveFader(0, 0.6)
+	if got := gang.GetCurrentValue(); got != 96 {
+		t.Fatalf("gang = %d after crossing, want 96", got)
+	}
+	s.moveFader(0, 0.65)
+	if got := gang.GetCurrentValue(); got != 104 {
+		t.Fatalf("gang = %d once picked up, want 104", got)
+	}
+
+	// A change from elsewhere lets go of the gang
+	if err := gang.HandleUIChange(40); err != nil {
+		t.Fatal(err)
+	}
+	s.moveFader(0, 0.7)
+	if got := gang.GetCurrentValue(); got != 40 {
+		t.Errorf("gang = %d after an outside change, want it held at 40", got)
+	}
+}
@@ -0,0 +1,91 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"slices"
+)
+
+// surfaceButton is a configured surface button with its resolved action
+type surfaceButton struct {
+	note   int
+	action *buttonAction
+	lit    int8 // LED state last sent: 0 off, 1 on, -1 not yet sent
+}
+
+// validateButtons checks the surface's button notes; the actions are resolved by BindButtons
+func validateButtons(config SurfaceConfig, mackie bool) error {
+	var notes []int
+	for i, button := range config.Buttons {
+		if button.Note < 0 || button.Note > 127 {
+			return fmt.Errorf("surface button %d: note %d is out of range", i, button.Note)
+		}
+		if mackie && (button.Note == mackieBankUp || button.Note == mackieBankDown) {
+			return fmt.Errorf("surface button %d: note %d is a mackie bank button", i, button.Note)
+		}
+		if slices.Contains(notes, button.Note) {
+			return fmt.Errorf("surface button %d: note %d is already bound", i, button.Note)
+		}
+		notes = append(notes, button.Note)
+	}
+	return nil
+}
+
+// BindButtons resolves the configured buttons against the scenes, switches and profiles they
+// act on; must be called before Start
+func (s *Surface) BindButtons(targets ButtonTargets) error {
+	s.buttons = nil
+	for i, config := range s.config.Buttons {
+		action, err := newButtonAction(config.Action, config.Gang, config.Scene, config.Switch, config.Profile, s.getGangs(), targets, WriteSourceMIDI)
+		if err != nil {
+			return fmt.Errorf("surface button %d: %w", i, err)
+		}
+		s.buttons = append(s.buttons, &surfaceButton{note: config.Note, action: action, lit: -1})
+	}
+	return nil
+}
+
+// handleButton acts on a note on or off for a bound button, returning false if the note is not
+// bound; a note on with velocity 0 is a release
+func (s *Surface) handleButton(msg MIDIMessage) bool {
+	if int(msg.Channel()) != s.config.Channel {
+		return false
+	}
+	var button *surfaceButton
+	for _, b := range s.buttons {
+		if b.note == int(msg.Data1) {
+			button = b
+		}
+	}
+	if button == nil {
+		return false
+	}
+	pressed := msg.Type() == midiNoteOn && msg.Data2 > 0
+	if err := button.action.handle(s.getGangs(), pressed); err != nil {
+		log.Printf("Surface: button %d (%s) failed: %v", button.note, button.action.action, err)
+	}
+	return true
+}
+
+// sendButtonFeedback lights the LED of every button whose state changed since it was last sent,
+// as a note on with full velocity, or velocity 0 for off; must be called with s.mu held
+func (s *Surface) sendButtonFeedback() error {
+	for _, button := range s.buttons {
+		var lit int8
+		if button.action.isLit(s.getGangs()) {
+			lit = 1
+		}
+		if button.lit == lit {
+			continue
+		}
+		msg := MIDIMessage{Status: midiNoteOn | byte(s.config.Channel&0x0F), Data1: byte(button.note)}
+		if lit == 1 {
+			msg.Data2 = 0x7F
+		}
+		if err := s.port.Send(msg); err != nil {
+			return err
+		}
+		button.lit = lit
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package sessionmixer
+
+import "github.com/AllenDang/cimgui-go/imgui"
+
+// Switch is a labeled boolean or enumerated control rendered as a toggle or selector
+// Used for device settings such as Direct Monitor and loopback
+// Momentary switches are boolean controls drawn as a button that is on only while held
+type Switch struct {
+	name      string
+	channel   *MixerChannel
+	momentary bool
+	held      bool // Momentary button state as of the last frame
+}
+
+// NewSwitch creates a new switch for a channel
+func NewSwitch(name string, channel *MixerChannel) *Switch {
+	return &Switch{
+		name:    name,
+		channel: channel,
+	}
+}
+
+// GetName returns the display name
+func (sw *Switch) GetName() string {
+	return sw.name
+}
+
+// GetChannel returns the underlying channel
+func (sw *Switch) GetChannel() *MixerChannel {
+	return sw.channel
+}
+
+// SetMomentary makes the switch momentary (on only while held) instead of latching
+func (sw *Switch) SetMomentary(momentary bool) {
+	sw.momentary = momentary
+}
+
+// IsMomentary returns true for momentary switches
+func (sw *Switch) IsMomentary() bool {
+	return sw.momentary
+}
+
+// Draw renders the switch widget; label must be unique within the window
+func (sw *Switch) Draw(label string) bool {
+	if !sw.momentary {
+		return drawControlWidget(label, sw.channel)
+	}
+
+	imgui.ButtonV(sw.name+label, imgui.Vec2{X: -1, Y: 0})
+	held := imgui.IsItemActive()
+	if held == sw.held {
+		return false
+	}
+	sw.held = held
+	logError(sw.channel.HandleUIChange(boolToValue(held)))
+	return true
+}
@@ -0,0 +1,59 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExpandGangTemplates generates the gangs of every template: one gang per input and mix, named
+// by substituting {input} and {mix} with the item names (also in tags), with controls named by
+// substituting {input_ch} and {mix_ch} with the items' channels
+// An input and a mix with the same number of channels (a stereo input into a stereo mix) are
+// paired channel by channel; otherwise every input channel feeds every mix channel
+func ExpandGangTemplates(templates []GangTemplate) ([]GangControl, error) {
+	var gangs []GangControl
+	for i, tmpl := range templates {
+		for _, input := range tmpl.Inputs {
+			for _, mix := range tmpl.Mixes {
+				if len(input.Channels) == 0 || len(mix.Channels) == 0 {
+					return nil, fmt.Errorf("gang template %d (%s): inputs and mixes need at least one channel", i, tmpl.Name)
+				}
+				names := strings.NewReplacer("{input}", input.Name, "{mix}", mix.Name)
+				gc := GangControl{
+					Name:    names.Replace(tmpl.Name),
+					Unit:    tmpl.Unit,
+					TaperDb: tmpl.TaperDb,
+					Safe:    tmpl.Safe,
+				}
+				for _, tag := range tmpl.Tags {
+					gc.Tags = append(gc.Tags, names.Replace(tag))
+				}
+				for _, pair := range templatePairs(input.Channels, mix.Channels) {
+					channels := strings.NewReplacer("{input_ch}", pair[0], "{mix_ch}", pair[1])
+					for _, control := range tmpl.Controls {
+						gc.Controls = append(gc.Controls, channels.Replace(control))
+					}
+				}
+				gangs = append(gangs, gc)
+			}
+		}
+	}
+	return gangs, nil
+}
+
+// templatePairs returns the input/mix channel pairs routed by a templated gang
+func templatePairs(inputs, mixes []string) [][2]string {
+	var pairs [][2]string
+	if len(inputs) == len(mixes) {
+		for i := range inputs {
+			pairs = append(pairs, [2]string{inputs[i], mixes[i]})
+		}
+		return pairs
+	}
+	for _, input := range inputs {
+		for _, mix := range mixes {
+			pairs = append(pairs, [2]string{input, mix})
+		}
+	}
+	return pairs
+}
@@ -0,0 +1,101 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// UnitFormatter formats a raw control value of a gang for display
+type UnitFormatter func(gang *GangedFader, value int64) string
+
+// unitFormatters maps each gang unit to its display formatter
+// Units without a formatter are displayed raw
+var unitFormatters = map[string]UnitFormatter{
+	"db":  formatMixerDb,
+	"raw": formatRaw,
+}
+
+// RegisterUnitFormatter installs the display formatter for a unit, replacing any existing one
+// Must be called before gangs are drawn
+func RegisterUnitFormatter(unit string, formatter UnitFormatter) {
+	unitFormatters[unit] = formatter
+}
+
+// unitFormatter returns the display formatter for a unit
+func unitFormatter(unit string) UnitFormatter {
+	if formatter, ok := unitFormatters[unit]; ok {
+		return formatter
+	}
+	return formatRaw
+}
+
+// formatRaw displays the raw integer value
+func formatRaw(_ *GangedFader, value int64) string {
+	return fmt.Sprintf("%d", value)
+}
+
+// formatMixerDb displays a Scarlett mixer value in dB: logarithmic scale from -∞ to +12 dB
+// This matches the formula used in alsa-scarlett-gui for mixer volumes
+func formatMixerDb(gang *GangedFader, value int64) string {
+	db := gang.ValueToDb(value)
+	if math.IsInf(db, -1) {
+		return "-∞ dB"
+	}
+	return fmt.Sprintf("%.2f dB", db)
+}
+
+// RegisterUnits installs a formatter for each custom unit in the config
+// The built-in "db" and "raw" units cannot be redefined
+func RegisterUnits(units []UnitConfig) error {
+	for i, unit := range units {
+		if unit.Name == "" {
+			return fmt.Errorf("unit %d: name is required", i)
+		}
+		if slices.Contains([]string{"db", "raw"}, unit.Name) {
+			return fmt.Errorf("unit %d (%s): built-in units cannot be redefined", i, unit.Name)
+		}
+		formatter, err := newUnitFormatter(unit)
+		if err != nil {
+			return fmt.Errorf("unit %d (%s): %w", i, unit.Name, err)
+		}
+		RegisterUnitFormatter(unit.Name, formatter)
+	}
+	return nil
+}
+
+// newUnitFormatter builds the formatter for a custom unit
+func newUnitFormatter(unit UnitConfig) (UnitFormatter, error) {
+	var logarithmic bool
+	switch unit.Formula {
+	case "", "linear":
+	case "log":
+		logarithmic = true
+	default:
+		return nil, fmt.Errorf("unknown formula '%s'", unit.Formula)
+	}
+	if unit.Decimals < 0 {
+		return nil, fmt.Errorf("decimals must not be negative")
+	}
+	scale := unit.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	return func(gang *GangedFader, value int64) string {
+		x := float64(value)
+		if unit.Normalize {
+			x = 0
+			if gang.GetMax() > gang.GetMin() {
+				x = float64(value-gang.GetMin()) / float64(gang.GetMax()-gang.GetMin())
+			}
+		}
+		if logarithmic {
+			if x <= 0 {
+				return "-∞" + unit.Suffix
+			}
+			x = math.Log10(x)
+		}
+		return fmt.Sprintf("%.*f%s", unit.Decimals, scale*x+unit.Offset, unit.Suffix)
+	}, nil
+}
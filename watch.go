@@ -0,0 +1,64 @@
+package sessionmixer
+
+import (
+	"math"
+	"time"
+)
+
+// watchLevelStepDb is the level change (dB) below which no level event is emitted
+const watchLevelStepDb = 1.0
+
+// WatchEvent is a change of gang value or signal level, emitted by WatchGangs
+type WatchEvent struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"` // "value" or "level"
+	Gang    string    `json:"gang"`
+	Value   *int64    `json:"value,omitempty"`    // Raw gang value (value events)
+	Text    string    `json:"text,omitempty"`     // Value in the gang's unit (value events)
+	Muted   bool      `json:"muted,omitempty"`    // Gang is muted (value events)
+	LevelDb *float64  `json:"level_db,omitempty"` // Peak level in dBFS, -120 for silence (level events)
+}
+
+// WatchGangs samples the gangs at interval and passes every change to emit until stop is closed
+// The current value of every gang is emitted first; level events (gangs with levels only) are
+// emitted when levels is set and the peak moves by at least 1 dB
+// The gangs' values must be kept current by an EventMonitor
+func WatchGangs(gangs []*GangedFader, interval time.Duration, levels bool, stop <-chan struct{}, emit func(WatchEvent)) {
+	values := make(map[*GangedFader]RemoteGang)
+	peaks := make(map[*GangedFader]float64)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		now := time.Now()
+		for _, gang := range gangs {
+			state := remoteGangState(gang)
+			state.Level = 0 // Levels are reported separately
+			if last, ok := values[gang]; !ok || last != state {
+				values[gang] = state
+				value := state.Value
+				emit(WatchEvent{Time: now, Type: "value", Gang: state.Name, Value: &value, Text: state.Text, Muted: state.Muted})
+			}
+
+			if !levels {
+				continue
+			}
+			level, ok := gang.GetMaxLevel()
+			if !ok {
+				continue
+			}
+			db := math.Max(levelToDb(level, gang.levelMax), -120)
+			if last, ok := peaks[gang]; ok && math.Abs(db-last) < watchLevelStepDb {
+				continue
+			}
+			peaks[gang] = db
+			db = math.Round(db*10) / 10
+			emit(WatchEvent{Time: now, Type: "level", Gang: state.Name, LevelDb: &db})
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package sessionmixer
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+// DefaultAppID identifies the mixer window to the compositor when the config does not name one
+const DefaultAppID = "sessionmixer"
+
+// Dock edges of a docked strip
+const (
+	DockTop    = "top"
+	DockBottom = "bottom"
+)
+
+// IsWayland reports whether the session is a Wayland session
+func IsWayland() bool {
+	return os.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+// SetWaylandHints applies the window identity before the window is created
+// The GLFW build of the cimgui-go backend only speaks X11, so under Wayland the window is an
+// XWayland client: the app-id becomes the instance part of WM_CLASS (RESOURCE_NAME), which
+// wlroots compositors match in window rules, and layer-shell is unavailable
+func SetWaylandHints(config *WaylandConfig) error {
+	appID := DefaultAppID
+	if config != nil && config.AppID != "" {
+		appID = config.AppID
+	}
+	if _, set := os.LookupEnv("RESOURCE_NAME"); !set {
+		if err := os.Setenv("RESOURCE_NAME", appID); err != nil {
+			return fmt.Errorf("app-id: %w", err)
+		}
+	}
+
+	if config == nil || config.Dock == "" {
+		return nil
+	}
+	switch config.Dock {
+	case DockTop, DockBottom:
+	default:
+		return fmt.Errorf("unknown dock edge '%s' (expected '%s' or '%s')", config.Dock, DockTop, DockBottom)
+	}
+	if IsWayland() {
+		log.Printf("Docked strip: no layer-shell support (XWayland window); pin it to the %s edge with a compositor rule for '%s'", config.Dock, appID)
+	}
+	return nil
+}
+
+// SetDocked keeps the mixer in strip mode, as a panel widget docked at a screen edge
+// ("" = not docked); placing it at the edge is left to the compositor
+func (sm *SessionMixer) SetDocked(edge string) {
+	sm.docked = edge != ""
+	if sm.docked {
+		sm.strip = true
+	}
+}
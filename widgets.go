@@ -0,0 +1,65 @@
+package sessionmixer
+
+import (
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/michaelquigley/scarlettctl"
+)
+
+// drawControlWidget renders an appropriate widget for a single hardware control
+// Booleans are drawn as checkboxes, enumerations as combos and integers as sliders
+// Changes are written through the channel so the usual equality checks apply
+// Returns true if the user changed the value this frame
+func drawControlWidget(label string, ch *MixerChannel) bool {
+	control := ch.GetControl()
+	value := ch.GetCurrentValue()
+
+	switch control.Type {
+	case scarlettctl.ControlTypeBoolean:
+		enabled := value != 0
+		if imgui.Checkbox(label, &enabled) {
+			logError(ch.HandleUIChange(boolToValue(enabled)))
+			return true
+		}
+
+	case scarlettctl.ControlTypeEnumerated:
+		changed := false
+		if imgui.BeginCombo(label, enumItemName(control, value)) {
+			for i, item := range control.Items {
+				if imgui.SelectableBoolV(item, int64(i) == value, imgui.SelectableFlagsNone, imgui.Vec2{}) {
+					logError(ch.HandleUIChange(int64(i)))
+					changed = true
+				}
+			}
+			imgui.EndCombo()
+		}
+		return changed
+
+	case scarlettctl.ControlTypeInteger, scarlettctl.ControlTypeInteger64:
+		v := int32(value)
+		if imgui.SliderInt(label, &v, int32(control.Min), int32(control.Max)) {
+			logError(ch.HandleUIChange(int64(v)))
+			return true
+		}
+
+	default:
+		imgui.TextDisabled(tr("unsupported"))
+	}
+
+	return false
+}
+
+// enumItemName returns the item name for an enumerated control value
+func enumItemName(control *scarlettctl.Control, value int64) string {
+	if value >= 0 && value < int64(len(control.Items)) {
+		return control.Items[value]
+	}
+	return "?"
+}
+
+// boolToValue converts a boolean to the raw value used by ALSA switch controls
+func boolToValue(enabled bool) int64 {
+	if enabled {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,132 @@
+package sessionmixer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/AllenDang/cimgui-go/imgui"
+	"github.com/michaelquigley/df/dd"
+)
+
+// WindowHost moves, resizes and raises the OS window; the position and size methods match the
+// cimgui-go backends, SetAlwaysOnTop maps to their floating window flag
+type WindowHost interface {
+	GetWindowPos() (x, y int32)
+	SetWindowPos(x, y int)
+	SetWindowSize(width, height int)
+	SetAlwaysOnTop(onTop bool)
+}
+
+// WindowGeometry is a window position and size in screen pixels, and its presentation
+type WindowGeometry struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+	Strip  bool // Strip mode (one row of small faders and mutes)
+	OnTop  bool // Always on top
+
+	Detached []DetachedGeometry // Tag views detached into their own windows
+}
+
+// DetachedGeometry is the position and size in screen pixels of a detached tag view
+type DetachedGeometry struct {
+	Tag    string
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// WindowPrefs are the remembered window geometries by profile name
+type WindowPrefs struct {
+	Profiles map[string]WindowGeometry
+}
+
+// LoadWindowPrefs reads the window preferences from path; a missing file yields defaults
+func LoadWindowPrefs(path string) (*WindowPrefs, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return &WindowPrefs{Profiles: make(map[string]WindowGeometry)}, nil
+	}
+	prefs, err := dd.NewFromYAML[WindowPrefs](path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load window preferences: %w", err)
+	}
+	if prefs.Profiles == nil {
+		prefs.Profiles = make(map[string]WindowGeometry)
+	}
+	return prefs, nil
+}
+
+// Save writes the window preferences to path
+func (wp *WindowPrefs) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := dd.UnbindToYAML(wp, path); err != nil {
+		return fmt.Errorf("failed to save window preferences: %w", err)
+	}
+	return nil
+}
+
+// Get returns the remembered geometry of a profile
+func (wp *WindowPrefs) Get(profile string) (WindowGeometry, bool) {
+	geometry, ok := wp.Profiles[profile]
+	return geometry, ok && geometry.Width > 0 && geometry.Height > 0
+}
+
+// windowTracker follows the window geometry every frame and swaps it on profile switches
+type windowTracker struct {
+	prefs    *WindowPrefs
+	path     string
+	host     WindowHost // nil if the window cannot be moved or resized (size is then only restored at startup)
+	current  WindowGeometry
+	restored bool // Startup geometry applied through the host
+}
+
+// track records the current window geometry and presentation; must be called on the UI thread
+func (wt *windowTracker) track(strip, onTop bool, detached []DetachedGeometry) {
+	wt.current.Strip, wt.current.OnTop = strip, onTop
+	wt.current.Detached = slices.Clone(detached)
+	size := imgui.MainViewport().Size()
+	wt.current.Width, wt.current.Height = int(size.X), int(size.Y)
+	if wt.host != nil {
+		x, y := wt.host.GetWindowPos()
+		wt.current.X, wt.current.Y = int(x), int(y)
+	}
+}
+
+// restore moves and resizes the window to a profile's remembered geometry, and returns it so
+// the caller can apply its presentation
+func (wt *windowTracker) restore(profile string) (WindowGeometry, bool) {
+	geometry, ok := wt.prefs.Get(profile)
+	if !ok {
+		return geometry, false
+	}
+	if wt.host != nil {
+		wt.host.SetWindowSize(geometry.Width, geometry.Height)
+		wt.host.SetWindowPos(geometry.X, geometry.Y)
+		wt.host.SetAlwaysOnTop(geometry.OnTop)
+	}
+	return geometry, true
+}
+
+// switched stores the geometry of the previous profile and restores the new one's
+func (wt *windowTracker) switched(from, to string) (WindowGeometry, bool) {
+	wt.prefs.Profiles[from] = wt.current
+	logError(wt.prefs.Save(wt.path))
+	return wt.restore(to)
+}
+
+// save stores the geometry of the current profile
+func (wt *windowTracker) save(profile string) error {
+	if wt.current.Width <= 0 || wt.current.Height <= 0 {
+		return nil
+	}
+	wt.prefs.Profiles[profile] = wt.current
+	return wt.prefs.Save(wt.path)
+}